@@ -0,0 +1,42 @@
+package main
+
+import (
+	"flag"
+
+	"github.com/OctopusSolutionsEngineering/DuplicationCostCalculator/internal/domain/models"
+	"github.com/OctopusSolutionsEngineering/DuplicationCostCalculator/internal/domain/workflows"
+)
+
+// addCostModelFlags adds the flags that change how the cost is calculated, and returns a function that returns the
+// cost model once the flags have been parsed.
+func addCostModelFlags(flags *flag.FlagSet) func() models.CostModel {
+	defaults := workflows.DefaultCostModel()
+	hoursPerRepo := flags.Float64("hours", defaults.HoursPerRepo, "The hours it takes to make a consistent change to a repository")
+	annualSalary := flags.Float64("salary", defaults.AnnualSalary, "The average annual salary of an engineer")
+	workdaysPerYear := flags.Float64("workdays", defaults.WorkdaysPerYear, "The workdays per year used to calculate the hourly rate")
+	hoursPerWorkday := flags.Float64("workday-hours", defaults.HoursPerWorkday, "The hours per workday used to calculate the hourly rate")
+	hoursPerDrift := flags.Float64("hours-per-drift", defaults.HoursPerDrift, "The extra hours spent on each version drift between a pair of repositories")
+	hoursPerSimilarStep := flags.Float64("hours-per-similar-step", defaults.HoursPerSimilarStep, "The extra hours spent on each step with similar config in a pair of repositories")
+
+	return func() models.CostModel {
+		return workflows.GetCostModel(&models.CostModel{
+			HoursPerRepo:        *hoursPerRepo,
+			AnnualSalary:        *annualSalary,
+			WorkdaysPerYear:     *workdaysPerYear,
+			HoursPerWorkday:     *hoursPerWorkday,
+			HoursPerDrift:       *hoursPerDrift,
+			HoursPerSimilarStep: *hoursPerSimilarStep,
+		})
+	}
+}
+
+func printCost(cost models.CostBreakdown) {
+	println("Cost to make a consistent change:", formatDollars(cost.Total), "Hours:", formatNumber(cost.TotalHours), "Hourly rate:", formatDollars(cost.HourlyRate))
+	println("  Repos with duplication or drift weighted by severity:", formatNumber(cost.WeightedRepos), "*", formatNumber(cost.Model.HoursPerRepo), "hours")
+	if cost.Model.HoursPerDrift > 0 {
+		println("  Version drift:", cost.Drifts, "*", formatNumber(cost.Model.HoursPerDrift), "hours")
+	}
+	if cost.Model.HoursPerSimilarStep > 0 {
+		println("  Steps with similar config:", cost.SimilarSteps, "*", formatNumber(cost.Model.HoursPerSimilarStep), "hours")
+	}
+}
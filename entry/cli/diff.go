@@ -0,0 +1,142 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"os"
+	"strconv"
+
+	"github.com/OctopusSolutionsEngineering/DuplicationCostCalculator/internal/domain/configuration"
+	"github.com/OctopusSolutionsEngineering/DuplicationCostCalculator/internal/domain/models"
+	"github.com/OctopusSolutionsEngineering/DuplicationCostCalculator/internal/domain/workflows"
+	"github.com/OctopusSolutionsEngineering/DuplicationCostCalculator/internal/infrastructure/reportstore"
+)
+
+// runDiff runs the diff command, which compares two reports and prints what changed. It returns the exit code.
+func runDiff(args []string) int {
+	flags := flag.NewFlagSet("diff", flag.ContinueOnError)
+	getCostModel := addCostModelFlags(flags)
+	outputJson := flags.Bool("json", false, "Print the diff as JSON")
+	flags.Usage = func() {
+		println("Usage: app diff [flags] <before> <after>")
+		println("Each report is a JSON file, like the response of the /cost API, or the id of a saved report.")
+		flags.PrintDefaults()
+	}
+
+	if err := flags.Parse(args); err != nil || flags.NArg() != 2 {
+		flags.Usage()
+		return exitUsage
+	}
+
+	before, err := loadReport(flags.Arg(0))
+	if err != nil {
+		println("Error reading report", flags.Arg(0)+":", err.Error())
+		return exitError
+	}
+
+	after, err := loadReport(flags.Arg(1))
+	if err != nil {
+		println("Error reading report", flags.Arg(1)+":", err.Error())
+		return exitError
+	}
+
+	diff := workflows.DiffReports(before, after, getCostModel())
+
+	if *outputJson {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(diff); err != nil {
+			println("Error writing diff:", err.Error())
+			return exitError
+		}
+		return exitOk
+	}
+
+	printDiff(diff)
+	return exitOk
+}
+
+// loadReport reads a report from a JSON file, or from the report history if there is no file with the name.
+// The file can contain a report, or a report saved in the report history.
+func loadReport(name string) (models.Report, error) {
+	content, err := os.ReadFile(name)
+	if errors.Is(err, os.ErrNotExist) {
+		stored, err := reportstore.NewStore(configuration.GetReportDir()).Get("", name)
+		if err != nil {
+			return models.Report{}, err
+		}
+		return stored.Report, nil
+	}
+	if err != nil {
+		return models.Report{}, err
+	}
+
+	var file struct {
+		models.Report
+		// Saved reports include the report in a report property
+		Stored *models.Report `json:"report"`
+	}
+
+	if err := json.Unmarshal(content, &file); err != nil {
+		return models.Report{}, err
+	}
+
+	if file.Stored != nil {
+		return *file.Stored, nil
+	}
+
+	return file.Report, nil
+}
+
+func printDiff(diff models.ReportDiff) {
+	println("Repos with duplication or drift:", diff.NumberOfReposWithDuplicationOrDrift.Before, "->", diff.NumberOfReposWithDuplicationOrDrift.After, "("+formatChange(float64(diff.NumberOfReposWithDuplicationOrDrift.Change), "")+")")
+	println("Cost to make a consistent change:", formatDollars(diff.Cost.Before), "->", formatDollars(diff.Cost.After), "("+formatChange(diff.Cost.Change, "$")+")")
+
+	for _, repo := range diff.AddedRepos {
+		println("Added repo:", repo)
+	}
+	for _, repo := range diff.RemovedRepos {
+		println("Removed repo:", repo)
+	}
+
+	println("Resolved drift:", len(diff.ResolvedDrift))
+	printDriftChanges(diff.ResolvedDrift)
+
+	println("Introduced drift:", len(diff.IntroducedDrift))
+	printDriftChanges(diff.IntroducedDrift)
+
+	println("Changed groups of steps with similar config:", len(diff.SimilarConfigGroups))
+	for _, group := range diff.SimilarConfigGroups {
+		println("  ", group.Uses, "Steps:", group.StepsBefore, "->", group.StepsAfter, "Repos:", group.ReposBefore, "->", group.ReposAfter)
+	}
+}
+
+func printDriftChanges(drift []models.DriftChange) {
+	for _, change := range drift {
+		println("  ", change.Repo1, "vs", change.Repo2, change.Uses, change.Version1, "vs", change.Version2, "("+change.Severity+")")
+	}
+}
+
+func formatDollars(amount float64) string {
+	return "$" + strconv.FormatFloat(amount, 'f', 2, 64)
+}
+
+func formatNumber(number float64) string {
+	return strconv.FormatFloat(number, 'f', -1, 64)
+}
+
+// formatChange formats a change with its sign, like +2 or -$100.00.
+func formatChange(change float64, currency string) string {
+	sign := "+"
+	if change < 0 {
+		sign = "-"
+		change = -change
+	}
+
+	if currency != "" {
+		return sign + currency + strconv.FormatFloat(change, 'f', 2, 64)
+	}
+
+	return sign + formatNumber(change)
+}
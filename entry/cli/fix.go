@@ -0,0 +1,147 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/OctopusSolutionsEngineering/DuplicationCostCalculator/internal/domain/formatting"
+	"github.com/OctopusSolutionsEngineering/DuplicationCostCalculator/internal/domain/models"
+	"github.com/OctopusSolutionsEngineering/DuplicationCostCalculator/internal/domain/workflows"
+	"github.com/OctopusSolutionsEngineering/DuplicationCostCalculator/internal/infrastructure/localfs"
+	"github.com/samber/lo"
+)
+
+// runFix runs the fix command, which aligns the versions of drifted actions in the workflows of local checkouts.
+// It prints the changes as unified diffs, or writes them to the checkouts. It returns the exit code.
+func runFix(args []string) int {
+	flags := flag.NewFlagSet("fix", flag.ContinueOnError)
+	reportName := flags.String("report", "", "A report, as a JSON file or the id of a saved report, with the drifted actions to fix. The checkouts are analyzed if it is not set")
+	target := flags.String("target", workflows.TargetLatest, "The version to align each action to: latest or majority")
+	versions := flags.String("set", "", "Comma separated versions to align actions to, like actions/checkout=v4, which override -target")
+	actions := flags.String("actions", "", "Comma separated actions to fix. Defaults to every action with version drift")
+	write := flags.Bool("write", false, "Write the changes to the checkouts instead of printing them as unified diffs")
+	flags.Usage = func() {
+		println("Usage: app fix [flags] <checkout1> <checkout2> ... <checkoutN>")
+		flags.PrintDefaults()
+	}
+
+	if err := flags.Parse(args); err != nil || flags.NArg() == 0 {
+		flags.Usage()
+		return exitUsage
+	}
+
+	if *target != workflows.TargetLatest && *target != workflows.TargetMajority {
+		println("Unknown target", *target+". The target must be latest or majority")
+		return exitUsage
+	}
+
+	explicitVersions, ok := parseVersions(*versions)
+	if !ok {
+		println("Invalid versions", *versions+". Versions must look like actions/checkout=v4")
+		return exitUsage
+	}
+
+	source := localfs.NewWorkflowSource()
+	allRepoActions := lo.Map(flags.Args(), func(item string, index int) workflows.RepoActions {
+		return workflows.LoadRepoActions(context.Background(), source, item)
+	})
+
+	var report models.Report
+	if *reportName != "" {
+		loaded, err := loadReport(*reportName)
+		if err != nil {
+			println("Error reading report", *reportName+":", err.Error())
+			return exitError
+		}
+		report = loaded
+	} else {
+		report = workflows.GenerateReportFromRepoActions(allRepoActions, workflows.ReportOptions{})
+	}
+
+	targets := workflows.GetAlignmentTargets(allRepoActions, report, workflows.AlignmentOptions{
+		Target:   *target,
+		Versions: explicitVersions,
+		Actions:  splitList(*actions),
+	})
+
+	for _, uses := range slices.Sorted(maps.Keys(targets)) {
+		println("Aligning", uses, "to", targets[uses])
+	}
+
+	exitCode := exitOk
+	for _, dir := range flags.Args() {
+		for _, workflow := range localfs.FindWorkflows(dir) {
+			if !fixWorkflow(filepath.Join(dir, localfs.WorkflowsDir, workflow), targets, *write) {
+				exitCode = exitError
+			}
+		}
+	}
+
+	return exitCode
+}
+
+// fixWorkflow aligns the versions of the actions in a workflow file, and prints or writes the changes. It returns
+// false if the workflow could not be fixed.
+func fixWorkflow(path string, targets map[string]string, write bool) bool {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		println("Error reading workflow", path+":", err.Error())
+		return false
+	}
+
+	fixed, rewrites, err := workflows.RewriteActionVersions(string(content), targets)
+	if err != nil {
+		println("Error parsing workflow", path+":", err.Error())
+		return false
+	}
+
+	if len(rewrites) == 0 {
+		return true
+	}
+
+	if !write {
+		name := filepath.ToSlash(path)
+		os.Stdout.WriteString(formatting.UnifiedDiff(name, name, string(content), fixed))
+		return true
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		println("Error reading workflow", path+":", err.Error())
+		return false
+	}
+
+	if err := os.WriteFile(path, []byte(fixed), info.Mode().Perm()); err != nil {
+		println("Error writing workflow", path+":", err.Error())
+		return false
+	}
+
+	for _, rewrite := range rewrites {
+		println("Updated", path+":"+strconv.Itoa(rewrite.Line), rewrite.Uses, rewrite.FromVersion, "->", rewrite.ToVersion)
+	}
+
+	return true
+}
+
+// parseVersions parses a comma separated list of versions like actions/checkout=v4.
+func parseVersions(value string) (map[string]string, bool) {
+	versions := map[string]string{}
+	for _, item := range splitList(value) {
+		uses, version, found := strings.Cut(item, "=")
+		uses = strings.TrimSpace(uses)
+		version = strings.TrimSpace(version)
+		if !found || uses == "" || version == "" {
+			return nil, false
+		}
+
+		versions[uses] = version
+	}
+
+	return versions, true
+}
@@ -1,32 +1,381 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"flag"
+	"maps"
 	"os"
+	"os/signal"
+	"path/filepath"
+	"slices"
+	"strconv"
 	"strings"
+	"syscall"
+	"time"
 
+	"github.com/OctopusSolutionsEngineering/DuplicationCostCalculator/internal/domain/formatting"
+	"github.com/OctopusSolutionsEngineering/DuplicationCostCalculator/internal/domain/models"
+	"github.com/OctopusSolutionsEngineering/DuplicationCostCalculator/internal/domain/parsing"
 	"github.com/OctopusSolutionsEngineering/DuplicationCostCalculator/internal/domain/workflows"
 	"github.com/OctopusSolutionsEngineering/DuplicationCostCalculator/internal/infrastructure/client"
+	"github.com/OctopusSolutionsEngineering/DuplicationCostCalculator/internal/infrastructure/githubapi"
+	"github.com/OctopusSolutionsEngineering/DuplicationCostCalculator/internal/infrastructure/localfs"
+	"github.com/samber/lo"
 )
 
+// exitOk means the command succeeded.
+const exitOk = 0
+
+// exitError means the command failed, like when a report could not be read or written.
+const exitError = 1
+
+// exitUsage means the arguments or flags were invalid.
+const exitUsage = 2
+
+// exitIncomplete means the report was written, but the analysis stopped early or some repositories could not be
+// loaded, so the results are incomplete.
+const exitIncomplete = 3
+
+// exitThresholds means the report was written, but it exceeded one of the thresholds.
+const exitThresholds = 4
+
 func main() {
-	args := os.Args
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "reports":
+			os.Exit(runReports(os.Args[2:]))
+		case "diff":
+			os.Exit(runDiff(os.Args[2:]))
+		case "fix":
+			os.Exit(runFix(os.Args[2:]))
+		case "pr":
+			os.Exit(runPullRequests(os.Args[2:]))
+		case "propose":
+			os.Exit(runPropose(os.Args[2:]))
+		}
+	}
 
-	if len(args) < 3 {
-		println("Usage: app <repo1> <repo2> ... <repoN>")
-		return
+	os.Exit(runAnalysis())
+}
+
+// getLocalOwners returns the owner of each local checkout, which is the owner in the arguments, or the owner of the
+// checkout's origin remote. The names of local checkouts are paths, so they don't include the owner.
+func getLocalOwners(dirs []string, owner string) map[string]string {
+	return lo.SliceToMap(dirs, func(item string) (string, string) {
+		if owner != "" {
+			return localfs.RepoName(item), owner
+		}
+
+		return localfs.RepoName(item), localfs.GetRemoteOwner(item)
+	})
+}
+
+// runAnalysis compares the repositories in the arguments and writes the report. It returns the exit code.
+func runAnalysis() int {
+	local := flag.Bool("local", false, "Treat the arguments as paths to local repository checkouts instead of GitHub repositories")
+	owner := flag.String("owner", "", "The owner of the local checkouts, whose actions are not third party. Defaults to the owner of each checkout's origin remote on GitHub")
+	topics := flag.String("topic", "", "Comma separated topics that repositories from org: and user: targets must have")
+	excludeArchived := flag.Bool("exclude-archived", false, "Exclude archived repositories from org: and user: targets")
+	excludeForks := flag.Bool("exclude-forks", false, "Exclude forked repositories from org: and user: targets")
+	name := flag.String("name", "", "A glob, like service-*, that the names of repositories from org: and user: targets must match")
+	language := flag.String("language", "", "The primary language of repositories from org: and user: targets")
+	save := flag.Bool("save", false, "Save the report to the report history in DUPCOST_REPORT_DIR")
+	getCostModel := addCostModelFlags(flag.CommandLine)
+	getThresholds := addThresholdFlags(flag.CommandLine)
+	suppressionFile := flag.String("suppressions", "", "A YAML or JSON file of accepted drift and duplication. Defaults to "+workflows.DefaultSuppressionFile+" if it exists")
+	baselineName := flag.String("baseline", "", "Fail if there is version drift that is not in this report, which is a JSON file or the id of a saved report")
+	timeout := flag.Duration("timeout", 0, "Stop the analysis after this long, like 10m, and report the repositories loaded so far. Zero means no timeout")
+	formatName := flag.String("format", "", "The format of the report: text, json, csv, md, or html. Defaults to the extension of the -output file, or text")
+	output := flag.String("output", "", "The file to write the report to. The report is written to stdout by default, except for the text format, which is written to stderr")
+	flag.Usage = func() {
+		println("Usage: app [flags] <repo1> <repo2> ... <repoN>")
+		println("       app [flags] org:<organization> | user:<username>")
+		println("       app reports list | show <id> | delete <id>")
+		println("       app diff [flags] <before> <after>")
+		println("       app fix [flags] <checkout1> <checkout2> ... <checkoutN>")
+		println("       app pr [flags] <repo1> <repo2> ... <repoN>")
+		println("       app propose [flags] <checkout1> <checkout2> ... <checkoutN>")
+		println("Exit codes: 0 success, 1 error, 2 invalid arguments, 3 incomplete results, 4 thresholds exceeded")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	args := flag.Args()
+
+	if len(args) == 0 || (len(args) < 2 && !lo.SomeBy(args, parsing.IsOwnerTarget)) {
+		flag.Usage()
+		return exitUsage
+	}
+
+	format, ok := getOutputFormat(*formatName, *output)
+	if !ok {
+		println("Unknown report format. Set -format to one of", strings.Join(formatting.Formats, ", "))
+		return exitUsage
+	}
+
+	thresholds := getThresholds()
+
+	// The baseline is read before the analysis, so a missing baseline fails fast
+	var baseline *models.Report
+	if *baselineName != "" {
+		report, err := loadReport(*baselineName)
+		if err != nil {
+			println("Error reading baseline report", *baselineName+":", err.Error())
+			return exitError
+		}
+
+		baseline = &report
+		thresholds.NoNewDrift = true
+	}
+
+	suppressions, err := loadSuppressions(*suppressionFile)
+	if err != nil {
+		println("Error reading suppressions:", err.Error())
+		return exitError
+	}
+
+	// Interrupting the analysis stops fetching, and reports the repositories loaded so far
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if *timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, *timeout)
+		defer cancel()
+	}
+
+	var report models.Report
+	repos := args
+	filter := models.RepositoryFilter{
+		Topics:          splitList(*topics),
+		ExcludeArchived: *excludeArchived,
+		ExcludeForks:    *excludeForks,
+		Name:            *name,
+		Language:        *language,
+	}
+
+	// The cost is calculated with the report, so every format reports the same cost
+	costModel := getCostModel()
+	options := workflows.ReportOptions{Suppressions: suppressions, CostModel: &costModel}
+
+	if *local {
+		// Local checkouts are read straight from disk, so no GitHub credentials are required
+		options.Owners = getLocalOwners(args, *owner)
+		report = workflows.GenerateReportFromSourceWithOptions(ctx, localfs.NewWorkflowSource(), args, nil, options)
+	} else {
+		githubClient := client.GetClientLocal()
+
+		repos, err = workflows.ExpandTargets(ctx, githubClient, args, filter)
+		if err != nil {
+			println("Error listing repositories:", err.Error())
+			return exitError
+		}
+
+		println("Scanning", len(repos), "repositories")
+
+		report = workflows.GenerateReportFromSourceWithOptions(ctx, githubapi.NewWorkflowSource(githubClient), repos, nil, options)
+	}
+
+	if format == formatting.FormatText {
+		printFullReport(report)
+	} else {
+		if err := writeReport(*output, format, report); err != nil {
+			println("Error writing report:", err.Error())
+			return exitError
+		}
+		printExpiredSuppressions(report.ExpiredSuppressions)
+		printIncomplete(report.Metadata)
+	}
+
+	if *save {
+		saveReport(models.StoredReport{
+			Repositories: repos,
+			Parameters: models.ReportParameters{
+				Targets: args,
+				Filter:  filter,
+				Local:   *local,
+			},
+			Report: report,
+		})
+	}
+
+	if violations := workflows.CheckThresholds(report, thresholds, baseline); len(violations) != 0 {
+		printViolations(violations)
+		return exitThresholds
+	}
+
+	if isIncomplete(report) {
+		return exitIncomplete
+	}
+
+	return exitOk
+}
+
+// loadSuppressions reads the suppression file, or the default suppression file if it exists.
+func loadSuppressions(name string) ([]models.Suppression, error) {
+	if name == "" {
+		name = workflows.DefaultSuppressionFile
+		if _, err := os.Stat(name); errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+	}
+
+	content, err := os.ReadFile(name)
+	if err != nil {
+		return nil, err
 	}
 
-	githubClient := client.GetClientLocal()
+	return workflows.ParseSuppressions(content)
+}
+
+// getOutputFormat returns the format the report is written in. If no format is set, the format is taken from the
+// extension of the output file, and reports that are not written to a file are printed as text.
+func getOutputFormat(formatName string, output string) (string, bool) {
+	if formatName != "" {
+		return formatting.ParseFormat(formatName)
+	}
+
+	if output == "" {
+		return formatting.FormatText, true
+	}
+
+	return formatting.ParseFormat(strings.TrimPrefix(filepath.Ext(output), "."))
+}
 
-	report := workflows.GenerateReport(githubClient, args[1:])
+// writeReport writes the report to the output file, or to stdout if there is no output file.
+func writeReport(output string, format string, report models.Report) error {
+	if output == "" || output == "-" {
+		return formatting.WriteReport(os.Stdout, format, report)
+	}
+
+	file, err := os.Create(output)
+	if err != nil {
+		return err
+	}
+
+	if err := formatting.WriteReport(file, format, report); err != nil {
+		file.Close()
+		return err
+	}
+
+	return file.Close()
+}
 
+// isIncomplete returns true if the analysis stopped early, or a repository could not be loaded.
+func isIncomplete(report models.Report) bool {
+	return report.Metadata.Incomplete || lo.SomeBy(lo.Values(report.RepoStatus), func(item models.RepoStatus) bool {
+		return item.Status == models.RepoStatusFailed || item.Status == models.RepoStatusPartial
+	})
+}
+
+func printFullReport(report models.Report) {
+	printReport(report)
+	printClusters(report.Clusters)
+	printRepoStatus(report.RepoStatus)
+	printSuppressed(report)
+	printSecurityFindings(report.SecurityFindings)
+	printCost(report.Cost)
+	printRateLimit(report.Metadata.RateLimit)
+	printExpiredSuppressions(report.ExpiredSuppressions)
+	printIncomplete(report.Metadata)
+}
+
+func printReport(report models.Report) {
 	for sourceRepo, comparison := range report.Comparisons {
-		println(sourceRepo, "Advisories:", len(report.WorkflowAdvisories[sourceRepo]), "Contributors:", len(report.Contributors[sourceRepo]))
+		println(sourceRepo, "Advisories:", len(report.WorkflowAdvisories[sourceRepo]), "Contributors:", len(report.Contributors[sourceRepo]), "Shared workflows:", len(report.SharedWorkflows[sourceRepo]))
+		for _, stale := range report.StaleActions[sourceRepo] {
+			println("  Stale:", stale.Uses, stale.CurrentVersion, "->", stale.LatestVersion, "Majors behind:", stale.MajorsBehind, "Minors behind:", stale.MinorsBehind)
+		}
 		for repoName, measurements := range comparison {
 			println("  ", repoName)
 			println("    Steps that indicate duplication risk:", measurements.StepsThatIndicateDuplicationRisk)
 			println("    Steps with different versions:", measurements.StepsWithDifferentVersionsCount, strings.Join(measurements.StepsWithDifferentVersions, ", "))
+			for _, drift := range measurements.VersionDrift {
+				println("      ", drift.Uses, drift.Version1, "vs", drift.Version2, "("+drift.Severity+")")
+			}
 			println("    Steps with similar config:", measurements.StepsWithSimilarConfigCount, strings.Join(measurements.StepsWithSimilarConfig, ", "))
+			println("    Similar scripts:", measurements.SimilarScriptsCount)
+			for _, script := range measurements.SimilarScripts {
+				println("      Similarity:", strconv.FormatFloat(script.Similarity, 'f', 2, 64))
+				println(indent(script.Script1, "        < "))
+				println(indent(script.Script2, "        > "))
+			}
+		}
+	}
+}
+
+func printClusters(clusters []models.ActionCluster) {
+	println("Duplicated steps across repositories:", len(clusters))
+	for _, cluster := range clusters {
+		println("  ", cluster.Uses, "Repos:", len(cluster.Repos), "Steps:", len(cluster.Members))
+		for _, workflow := range cluster.Workflows {
+			println("      ", workflow)
+		}
+	}
+}
+
+func printRepoStatus(repoStatus map[string]models.RepoStatus) {
+	for _, repo := range slices.Sorted(maps.Keys(repoStatus)) {
+		status := repoStatus[repo]
+		if status.Status == models.RepoStatusOk {
+			continue
 		}
+
+		println(repo, "Status:", status.Status)
+		for _, repoError := range status.Errors {
+			println("  ", "["+repoError.Category+"]", repoError.Operation+":", repoError.Message)
+		}
+	}
+}
+
+func printSuppressed(report models.Report) {
+	if len(report.Suppressed) != 0 {
+		println("Suppressed drift and duplication:", len(report.Suppressed))
+	}
+	for _, finding := range report.Suppressed {
+		println("  ", finding.Repo1, "vs", finding.Repo2, finding.Uses, "Drift:", len(finding.VersionDrift), "Similar config:", finding.SimilarConfig, "Expires:", finding.Suppression.Expires, "Reason:", finding.Suppression.Reason)
+	}
+}
+
+func printSecurityFindings(findings []models.SecurityFinding) {
+	if len(findings) != 0 {
+		println("Actions not pinned to a commit SHA:", len(findings))
 	}
+	for _, finding := range findings {
+		println("  ", "["+finding.Severity+"]", finding.Repo, finding.Workflow+":"+strconv.Itoa(finding.Line), finding.Message)
+	}
+}
+
+func printExpiredSuppressions(expired []models.Suppression) {
+	for _, suppression := range expired {
+		println("WARNING: The suppression of", strings.Join(lo.Compact(append([]string{suppression.Action}, suppression.Repos...)), " "), "expired on", suppression.Expires)
+	}
+}
+
+func printRateLimit(rateLimit *models.RateLimitBudget) {
+	if rateLimit == nil {
+		return
+	}
+
+	println("GitHub API budget:", rateLimit.Remaining, "of", rateLimit.Limit, "remaining, resets at", rateLimit.Reset.Format(time.TimeOnly), "Requests:", rateLimit.Requests, "Retries:", rateLimit.Retries)
+}
+
+func printIncomplete(metadata models.ReportMetadata) {
+	if !metadata.Incomplete {
+		return
+	}
+
+	println("WARNING: The analysis", metadata.IncompleteReason, "before every repository was loaded. The results are incomplete.")
+}
+
+// splitList splits a comma separated flag value, ignoring empty items.
+func splitList(value string) []string {
+	return lo.Compact(lo.Map(strings.Split(value, ","), func(item string, index int) string {
+		return strings.TrimSpace(item)
+	}))
+}
+
+func indent(text string, prefix string) string {
+	return prefix + strings.ReplaceAll(text, "\n", "\n"+prefix)
 }
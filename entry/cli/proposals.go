@@ -0,0 +1,70 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"os"
+	"path/filepath"
+
+	"github.com/OctopusSolutionsEngineering/DuplicationCostCalculator/internal/domain/formatting"
+	"github.com/OctopusSolutionsEngineering/DuplicationCostCalculator/internal/domain/models"
+	"github.com/OctopusSolutionsEngineering/DuplicationCostCalculator/internal/domain/workflows"
+	"github.com/OctopusSolutionsEngineering/DuplicationCostCalculator/internal/infrastructure/localfs"
+)
+
+// runPropose runs the propose command, which turns the clusters of duplicated steps into shared composite actions.
+// It prints a refactoring plan as Markdown, and can write the action.yml files. It returns the exit code.
+func runPropose(args []string) int {
+	flags := flag.NewFlagSet("propose", flag.ContinueOnError)
+	reportName := flags.String("report", "", "A report, as a JSON file or the id of a saved report, with the duplicated steps. The checkouts are analyzed if it is not set")
+	actionRepo := flags.String("action-repo", "", "The repository the composite actions are published in, like owner/shared-actions. Defaults to the shared-actions repository of the owner of the analyzed repositories")
+	output := flags.String("output", "", "A directory to write the action.yml file of each composite action to")
+	flags.Usage = func() {
+		println("Usage: app propose [flags] <checkout1> <checkout2> ... <checkoutN>")
+		println("       app propose -report <report> [flags]")
+		flags.PrintDefaults()
+	}
+
+	if err := flags.Parse(args); err != nil || (flags.NArg() == 0 && *reportName == "") {
+		flags.Usage()
+		return exitUsage
+	}
+
+	var report models.Report
+	if *reportName != "" {
+		loaded, err := loadReport(*reportName)
+		if err != nil {
+			println("Error reading report", *reportName+":", err.Error())
+			return exitError
+		}
+		report = loaded
+	} else {
+		report = workflows.GenerateReportFromSource(context.Background(), localfs.NewWorkflowSource(), flags.Args())
+	}
+
+	proposals := workflows.ProposeCompositeActions(report.Clusters, *actionRepo)
+
+	if *output != "" {
+		for _, proposal := range proposals {
+			path := filepath.Join(*output, proposal.Name, "action.yml")
+			if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+				println("Error creating directory", filepath.Dir(path)+":", err.Error())
+				return exitError
+			}
+
+			if err := os.WriteFile(path, []byte(proposal.ActionYml), 0644); err != nil {
+				println("Error writing", path+":", err.Error())
+				return exitError
+			}
+
+			println("Wrote", path)
+		}
+	}
+
+	if err := formatting.WriteProposalsMarkdown(os.Stdout, proposals); err != nil {
+		println("Error writing proposals:", err.Error())
+		return exitError
+	}
+
+	return exitOk
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"maps"
+	"os"
+	"os/signal"
+	"slices"
+	"strconv"
+	"syscall"
+
+	"github.com/OctopusSolutionsEngineering/DuplicationCostCalculator/internal/domain/models"
+	"github.com/OctopusSolutionsEngineering/DuplicationCostCalculator/internal/domain/parsing"
+	"github.com/OctopusSolutionsEngineering/DuplicationCostCalculator/internal/domain/workflows"
+	"github.com/OctopusSolutionsEngineering/DuplicationCostCalculator/internal/infrastructure/client"
+	"github.com/samber/lo"
+)
+
+// runPullRequests runs the pr command, which opens a pull request in each GitHub repository with drifted actions
+// that aligns their versions. Running it again updates the open pull requests. It returns the exit code.
+func runPullRequests(args []string) int {
+	flags := flag.NewFlagSet("pr", flag.ContinueOnError)
+	target := flags.String("target", workflows.TargetLatest, "The version to align each action to: latest or majority")
+	versions := flags.String("set", "", "Comma separated versions to align actions to, like actions/checkout=v4, which override -target")
+	actions := flags.String("actions", "", "Comma separated actions to align. Defaults to every action with version drift")
+	dryRun := flags.Bool("dry-run", false, "Print the changes as unified diffs instead of opening pull requests")
+	flags.Usage = func() {
+		println("Usage: app pr [flags] <repo1> <repo2> ... <repoN>")
+		println("       app pr [flags] org:<organization> | user:<username>")
+		flags.PrintDefaults()
+	}
+
+	if err := flags.Parse(args); err != nil || flags.NArg() == 0 || (flags.NArg() < 2 && !lo.SomeBy(flags.Args(), parsing.IsOwnerTarget)) {
+		flags.Usage()
+		return exitUsage
+	}
+
+	if *target != workflows.TargetLatest && *target != workflows.TargetMajority {
+		println("Unknown target", *target+". The target must be latest or majority")
+		return exitUsage
+	}
+
+	explicitVersions, ok := parseVersions(*versions)
+	if !ok {
+		println("Invalid versions", *versions+". Versions must look like actions/checkout=v4")
+		return exitUsage
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	githubClient := client.GetClientLocal()
+	repos, err := workflows.ExpandTargets(ctx, githubClient, flags.Args(), models.RepositoryFilter{})
+	if err != nil {
+		println("Error listing repositories:", err.Error())
+		return exitError
+	}
+
+	targets, results := workflows.AlignActionVersions(ctx, githubClient, repos, workflows.AlignmentOptions{
+		Target:   *target,
+		Versions: explicitVersions,
+		Actions:  splitList(*actions),
+	}, *dryRun)
+
+	for _, uses := range slices.Sorted(maps.Keys(targets)) {
+		println("Aligning", uses, "to", targets[uses])
+	}
+
+	if len(results) == 0 {
+		println("No repositories have actions to align")
+	}
+
+	exitCode := exitOk
+	for _, result := range results {
+		switch result.Status {
+		case models.PullRequestPlanned:
+			os.Stdout.WriteString(result.Diff)
+		case models.PullRequestCreated:
+			println("Opened pull request", result.Repo+"#"+strconv.Itoa(result.Number), result.Url)
+		case models.PullRequestUpdated:
+			println("Updated pull request", result.Repo+"#"+strconv.Itoa(result.Number), result.Url)
+		default:
+			println("Error opening pull request in", result.Repo+":", result.Error)
+			exitCode = exitError
+		}
+	}
+
+	return exitCode
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/OctopusSolutionsEngineering/DuplicationCostCalculator/internal/domain/configuration"
+	"github.com/OctopusSolutionsEngineering/DuplicationCostCalculator/internal/domain/models"
+	"github.com/OctopusSolutionsEngineering/DuplicationCostCalculator/internal/infrastructure/reportstore"
+)
+
+// saveReport saves the report to the report history. Reports saved by the CLI have no owner, as anyone running
+// the CLI can read the report directory anyway.
+func saveReport(report models.StoredReport) {
+	saved, err := reportstore.NewStore(configuration.GetReportDir()).Save(report)
+	if err != nil {
+		println("Error saving report:", err.Error())
+		return
+	}
+
+	println("Saved report", saved.Id)
+}
+
+// runReports runs the reports command, which lists, shows, and deletes the reports in the report history.
+// It returns the exit code.
+func runReports(args []string) int {
+	store := reportstore.NewStore(configuration.GetReportDir())
+
+	switch {
+	case len(args) == 1 && args[0] == "list":
+		return listReports(store)
+	case len(args) == 2 && args[0] == "show":
+		return showReport(store, args[1])
+	case len(args) == 2 && args[0] == "delete":
+		return deleteReport(store, args[1])
+	}
+
+	println("Usage: app reports list")
+	println("       app reports show <id>")
+	println("       app reports delete <id>")
+	return exitUsage
+}
+
+func listReports(store *reportstore.Store) int {
+	summaries, err := store.List("")
+	if err != nil {
+		println("Error listing reports:", err.Error())
+		return exitError
+	}
+
+	for _, summary := range summaries {
+		incomplete := ""
+		if summary.Incomplete {
+			incomplete = " (incomplete)"
+		}
+
+		println(summary.Id, summary.CreatedAt.Local().Format(time.DateTime), "Repos:", summary.NumberOfRepos, "With duplication or drift:", summary.NumberOfReposWithDuplicationOrDrift, "Targets:", strings.Join(summary.Parameters.Targets, " ")+incomplete)
+	}
+
+	return exitOk
+}
+
+func showReport(store *reportstore.Store, id string) int {
+	report, err := store.Get("", id)
+	if err != nil {
+		printReportError(id, err)
+		return exitError
+	}
+
+	println("Report", report.Id, "created at", report.CreatedAt.Local().Format(time.DateTime))
+	println("Repositories:", strings.Join(report.Repositories, " "))
+	printFullReport(report.Report)
+
+	return exitOk
+}
+
+func deleteReport(store *reportstore.Store, id string) int {
+	if err := store.Delete("", id); err != nil {
+		printReportError(id, err)
+		return exitError
+	}
+
+	println("Deleted report", id)
+	return exitOk
+}
+
+func printReportError(id string, err error) {
+	if errors.Is(err, reportstore.ErrReportNotFound) {
+		println("Report", id, "was not found")
+		return
+	}
+
+	println("Error reading report", id+":", err.Error())
+}
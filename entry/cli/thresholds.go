@@ -0,0 +1,40 @@
+package main
+
+import (
+	"flag"
+
+	"github.com/OctopusSolutionsEngineering/DuplicationCostCalculator/internal/domain/models"
+)
+
+// addThresholdFlags adds the flags that fail the analysis when duplication or drift is too high, or actions are not
+// pinned, and returns a function that returns the thresholds once the flags have been parsed.
+func addThresholdFlags(flags *flag.FlagSet) func() models.Thresholds {
+	maxRepos := flags.Int("max-repos-with-drift", -1, "Fail if more repositories than this have duplication or drift. Negative values are not checked")
+	maxDriftPerPair := flags.Int("max-drift-per-pair", -1, "Fail if a pair of repositories uses more than this many actions with different versions. Negative values are not checked")
+	maxCost := flags.Float64("max-cost", -1, "Fail if the cost of a consistent change is more than this many dollars. Negative values are not checked")
+	requireShaPins := flags.Bool("require-sha-pins", false, "Fail if an action owned by a third party is not pinned to a full commit SHA")
+
+	return func() models.Thresholds {
+		thresholds := models.Thresholds{RequireShaPins: *requireShaPins}
+		if *maxRepos >= 0 {
+			thresholds.MaxReposWithDuplicationOrDrift = maxRepos
+		}
+		if *maxDriftPerPair >= 0 {
+			thresholds.MaxDriftedActionsPerPair = maxDriftPerPair
+		}
+		if *maxCost >= 0 {
+			thresholds.MaxCost = maxCost
+		}
+
+		return thresholds
+	}
+}
+
+func printViolations(violations []models.ThresholdViolation) {
+	for _, violation := range violations {
+		println("FAILED ["+violation.Rule+"]", violation.Message)
+		for _, detail := range violation.Details {
+			println("  ", detail)
+		}
+	}
+}
@@ -22,6 +22,22 @@ func main() {
 
 	r.POST("/cost", handlers2.CostHandler)
 
+	// Long running analyses are run as jobs that report their progress
+	r.POST("/jobs", handlers2.CreateJobHandler)
+	r.GET("/jobs/:id", handlers2.GetJobHandler)
+	r.GET("/jobs/:id/events", handlers2.JobEventsHandler)
+	r.DELETE("/jobs/:id", handlers2.CancelJobHandler)
+
+	// Every report is saved to the report history
+	r.GET("/reports", handlers2.ListReportsHandler)
+	r.GET("/reports/:id", handlers2.GetReportHandler)
+	r.DELETE("/reports/:id", handlers2.DeleteReportHandler)
+	r.GET("/reports/:id/proposals", handlers2.ProposalsHandler)
+	r.POST("/diff", handlers2.DiffHandler)
+
+	// Pull requests that align action versions are only opened when they are enabled
+	r.POST("/pullrequests", handlers2.PullRequestsHandler)
+
 	// Default handler for unmatched routes - redirect to login page
 	r.NoRoute(func(c *gin.Context) {
 		c.Redirect(302, "/")
@@ -1,8 +1,11 @@
 package handlers
 
 import (
+	"crypto/subtle"
 	"net/http"
+	"strings"
 
+	"github.com/OctopusSolutionsEngineering/DuplicationCostCalculator/internal/domain/encryption"
 	"github.com/OctopusSolutionsEngineering/DuplicationCostCalculator/internal/infrastructure/client"
 	"github.com/gin-gonic/gin"
 )
@@ -29,3 +32,48 @@ func IsAuthenticated(c *gin.Context) bool {
 
 	return true
 }
+
+// GetAccessToken returns the GitHub access token from the encrypted github_token cookie. The access token is
+// empty when the app authenticates with a private key. If there is no valid token, a 401 response is written,
+// and false is returned.
+func GetAccessToken(c *gin.Context, getKey func() string) (string, bool) {
+	if client.UsePrivateKeyAuth() {
+		return "", true
+	}
+
+	// Extract access token from cookie
+	token, err := c.Cookie("github_token")
+	if err != nil || token == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "Unauthorized - no access token found",
+		})
+		return "", false
+	}
+
+	decrypted, err := encryption.DecryptStringWrapper(token, getKey)
+
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "Unauthorized - no access token found",
+		})
+		return "", false
+	}
+
+	return decrypted, true
+}
+
+// HasSecret checks the request includes the secret as a bearer token in the Authorization header. If the secret is
+// empty, or the request doesn't include it, a 401 response is written, and false is returned.
+func HasSecret(c *gin.Context, getSecret func() string) bool {
+	secret := getSecret()
+	token, ok := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer ")
+
+	if secret == "" || !ok || subtle.ConstantTimeCompare([]byte(token), []byte(secret)) != 1 {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "Unauthorized - the request must include the pull requests secret",
+		})
+		return false
+	}
+
+	return true
+}
@@ -1,50 +1,41 @@
 package handlers
 
 import (
+	"context"
+	"fmt"
 	"net/http"
 
 	"github.com/OctopusSolutionsEngineering/DuplicationCostCalculator/internal/domain/configuration"
-	"github.com/OctopusSolutionsEngineering/DuplicationCostCalculator/internal/domain/encryption"
 	"github.com/OctopusSolutionsEngineering/DuplicationCostCalculator/internal/domain/models"
 	"github.com/OctopusSolutionsEngineering/DuplicationCostCalculator/internal/domain/workflows"
 	"github.com/OctopusSolutionsEngineering/DuplicationCostCalculator/internal/infrastructure/client"
+	"github.com/OctopusSolutionsEngineering/DuplicationCostCalculator/internal/infrastructure/githubapi"
 	"github.com/gin-gonic/gin"
 	"github.com/google/go-github/v57/github"
 )
 
 func CostHandler(c *gin.Context) {
-	CostHandlerWrapped(c, client.GetClient, workflows.GenerateReport, configuration.GetEncryptionKey)
+	CostHandlerWrapped(c, client.GetClient, workflows.GenerateReport, SaveReport, configuration.GetEncryptionKey)
 }
 
-func CostHandlerWrapped(c *gin.Context, getClient func(string) *github.Client, generateReport func(*github.Client, []string) models.Report, getKey func() string) {
-	accessToken := ""
-
-	if !client.UsePrivateKeyAuth() {
-		// Extract access token from cookie
-		token, err := c.Cookie("github_token")
-		if err != nil || token == "" {
-			c.JSON(http.StatusUnauthorized, gin.H{
-				"error": "Unauthorized - no access token found",
-			})
-			return
-		}
-
-		decrypted, err := encryption.DecryptStringWrapper(token, getKey)
-
-		if err != nil {
-			c.JSON(http.StatusUnauthorized, gin.H{
-				"error": "Unauthorized - no access token found",
-			})
-			return
-		}
-
-		accessToken = decrypted
+// CostRequest is the body of a request to compare repositories.
+type CostRequest struct {
+	// Repositories can include "org:<name>" or "user:<name>" targets to scan every repository of an owner
+	Repositories []string `json:"repositories"`
+	// Filter limits the repositories included from "org:" and "user:" targets
+	Filter models.RepositoryFilter `json:"filter"`
+	// CostModel changes how the cost of the report is calculated. Values that are not set use the defaults.
+	CostModel *models.CostModel `json:"costModel"`
+}
+
+func CostHandlerWrapped(c *gin.Context, getClient func(string) *github.Client, generateReport func(context.Context, *github.Client, []string) models.Report, saveReport func(context.Context, *github.Client, models.StoredReport) (models.StoredReport, error), getKey func() string) {
+	accessToken, ok := GetAccessToken(c, getKey)
+	if !ok {
+		return
 	}
 
 	// Parse request body
-	var requestBody struct {
-		Repositories []string `json:"repositories"`
-	}
+	var requestBody CostRequest
 
 	if err := c.BindJSON(&requestBody); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{
@@ -55,7 +46,61 @@ func CostHandlerWrapped(c *gin.Context, getClient func(string) *github.Client, g
 
 	githubClient := getClient(accessToken)
 
-	report := generateReport(githubClient, requestBody.Repositories)
+	// The analysis stops fetching if the client disconnects
+	ctx := c.Request.Context()
+
+	repositories, err := workflows.ExpandTargets(ctx, githubClient, requestBody.Repositories, requestBody.Filter)
+	if err != nil {
+		writeTargetError(c, err)
+		return
+	}
+
+	report := generateReport(ctx, githubClient, repositories)
+	report.Cost = workflows.CalculateCost(report, workflows.GetCostModel(requestBody.CostModel))
+
+	// The report of a client that disconnected is incomplete, so it is not saved to the report history
+	if ctx.Err() != nil {
+		c.JSON(http.StatusOK, report)
+		return
+	}
+
+	// The report is still returned if it can't be saved to the history, and the error is logged with the request
+	saved, err := saveReport(ctx, githubClient, NewStoredReport(requestBody, repositories, report))
+	if err != nil {
+		_ = c.Error(fmt.Errorf("saving report: %w", err))
+	} else {
+		c.Header("Content-Location", "/reports/"+saved.Id)
+	}
 
 	c.JSON(http.StatusOK, report)
 }
+
+// writeTargetError responds with the error of an "org:" or "user:" target whose repositories could not be listed.
+// A target that doesn't exist is a bad request, rather than an owner without repositories.
+func writeTargetError(c *gin.Context, err error) {
+	status := http.StatusBadGateway
+	switch githubapi.ClassifyError(err) {
+	case models.ErrorCategoryNotFound:
+		status = http.StatusBadRequest
+	case models.ErrorCategoryForbidden:
+		status = http.StatusForbidden
+	case models.ErrorCategoryRateLimited:
+		status = http.StatusTooManyRequests
+	}
+
+	c.JSON(status, gin.H{
+		"error": err.Error(),
+	})
+}
+
+// NewStoredReport returns the report to save to the history for a request.
+func NewStoredReport(request CostRequest, repositories []string, report models.Report) models.StoredReport {
+	return models.StoredReport{
+		Repositories: repositories,
+		Parameters: models.ReportParameters{
+			Targets: request.Repositories,
+			Filter:  request.Filter,
+		},
+		Report: report,
+	}
+}
@@ -2,15 +2,20 @@ package handlers
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
+	"math"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 
 	"github.com/OctopusSolutionsEngineering/DuplicationCostCalculator/internal/domain/encryption"
 	"github.com/OctopusSolutionsEngineering/DuplicationCostCalculator/internal/domain/models"
 	"github.com/gin-gonic/gin"
 	"github.com/google/go-github/v57/github"
+	"github.com/migueleliasweb/go-github-mock/src/mock"
 )
 
 func TestCostHandlerWrapped(t *testing.T) {
@@ -144,7 +149,7 @@ func TestCostHandlerWrapped(t *testing.T) {
 				return github.NewClient(nil)
 			}
 
-			mockGenerateReport := func(client *github.Client, repositories []string) models.Report {
+			mockGenerateReport := func(ctx context.Context, client *github.Client, repositories []string) models.Report {
 				generateReportCalled = true
 				capturedRepositories = repositories
 				return tt.mockReport
@@ -170,7 +175,7 @@ func TestCostHandlerWrapped(t *testing.T) {
 			c.Request = req
 
 			// Call the handler
-			CostHandlerWrapped(c, mockGetClient, mockGenerateReport, getTestKey)
+			CostHandlerWrapped(c, mockGetClient, mockGenerateReport, mockSaveReport, getTestKey)
 
 			// Check status code
 			if w.Code != tt.expectedStatusCode {
@@ -252,7 +257,7 @@ func TestCostHandlerWrappedUnauthorized(t *testing.T) {
 				return nil
 			}
 
-			mockGenerateReport := func(client *github.Client, repositories []string) models.Report {
+			mockGenerateReport := func(ctx context.Context, client *github.Client, repositories []string) models.Report {
 				t.Error("generateReport should not be called when unauthorized")
 				return models.Report{}
 			}
@@ -276,7 +281,7 @@ func TestCostHandlerWrappedUnauthorized(t *testing.T) {
 
 			c.Request = req
 
-			CostHandlerWrapped(c, mockGetClient, mockGenerateReport, getTestKey)
+			CostHandlerWrapped(c, mockGetClient, mockGenerateReport, mockSaveReport, getTestKey)
 
 			if w.Code != http.StatusUnauthorized {
 				t.Errorf("Status code = %d, expected %d", w.Code, http.StatusUnauthorized)
@@ -317,7 +322,7 @@ func TestCostHandlerWrappedInvalidJSON(t *testing.T) {
 				return github.NewClient(nil)
 			}
 
-			mockGenerateReport := func(client *github.Client, repositories []string) models.Report {
+			mockGenerateReport := func(ctx context.Context, client *github.Client, repositories []string) models.Report {
 				// Some cases will reach here, others won't
 				return models.Report{}
 			}
@@ -334,7 +339,7 @@ func TestCostHandlerWrappedInvalidJSON(t *testing.T) {
 
 			c.Request = req
 
-			CostHandlerWrapped(c, mockGetClient, mockGenerateReport, getTestKey)
+			CostHandlerWrapped(c, mockGetClient, mockGenerateReport, mockSaveReport, getTestKey)
 
 			// Malformed JSON and empty body should return 400
 			// Wrong field types and null values are accepted by Gin as empty/zero values
@@ -371,7 +376,7 @@ func TestCostHandlerWrappedAccessTokenPassed(t *testing.T) {
 				return github.NewClient(nil)
 			}
 
-			mockGenerateReport := func(client *github.Client, repositories []string) models.Report {
+			mockGenerateReport := func(ctx context.Context, client *github.Client, repositories []string) models.Report {
 				return models.Report{}
 			}
 
@@ -391,7 +396,7 @@ func TestCostHandlerWrappedAccessTokenPassed(t *testing.T) {
 
 			c.Request = req
 
-			CostHandlerWrapped(c, mockGetClient, mockGenerateReport, getTestKey)
+			CostHandlerWrapped(c, mockGetClient, mockGenerateReport, mockSaveReport, getTestKey)
 
 			if capturedToken != token {
 				t.Errorf("Captured token = %q, expected %q", capturedToken, token)
@@ -434,7 +439,7 @@ func TestCostHandlerWrappedRepositoriesPassed(t *testing.T) {
 				return github.NewClient(nil)
 			}
 
-			mockGenerateReport := func(client *github.Client, repositories []string) models.Report {
+			mockGenerateReport := func(ctx context.Context, client *github.Client, repositories []string) models.Report {
 				capturedRepos = repositories
 				return models.Report{}
 			}
@@ -455,7 +460,7 @@ func TestCostHandlerWrappedRepositoriesPassed(t *testing.T) {
 
 			c.Request = req
 
-			CostHandlerWrapped(c, mockGetClient, mockGenerateReport, getTestKey)
+			CostHandlerWrapped(c, mockGetClient, mockGenerateReport, mockSaveReport, getTestKey)
 
 			if len(capturedRepos) != len(tt.repositories) {
 				t.Errorf("Captured %d repositories, expected %d", len(capturedRepos), len(tt.repositories))
@@ -478,7 +483,7 @@ func TestCostHandlerWrappedResponseFormat(t *testing.T) {
 		return github.NewClient(nil)
 	}
 
-	mockGenerateReport := func(client *github.Client, repositories []string) models.Report {
+	mockGenerateReport := func(ctx context.Context, client *github.Client, repositories []string) models.Report {
 		return models.Report{
 			NumberOfRepos:                       2,
 			NumberOfReposWithDuplicationOrDrift: 1,
@@ -522,7 +527,7 @@ func TestCostHandlerWrappedResponseFormat(t *testing.T) {
 
 	c.Request = req
 
-	CostHandlerWrapped(c, mockGetClient, mockGenerateReport, getTestKey)
+	CostHandlerWrapped(c, mockGetClient, mockGenerateReport, mockSaveReport, getTestKey)
 
 	if w.Code != http.StatusOK {
 		t.Fatalf("Status code = %d, expected %d", w.Code, http.StatusOK)
@@ -571,7 +576,7 @@ func TestCostHandlerWrappedMultipleCalls(t *testing.T) {
 		return github.NewClient(nil)
 	}
 
-	mockGenerateReport := func(client *github.Client, repositories []string) models.Report {
+	mockGenerateReport := func(ctx context.Context, client *github.Client, repositories []string) models.Report {
 		return models.Report{
 			NumberOfRepos: len(repositories),
 		}
@@ -594,7 +599,7 @@ func TestCostHandlerWrappedMultipleCalls(t *testing.T) {
 
 		c.Request = req
 
-		CostHandlerWrapped(c, mockGetClient, mockGenerateReport, getTestKey)
+		CostHandlerWrapped(c, mockGetClient, mockGenerateReport, mockSaveReport, getTestKey)
 
 		if w.Code != http.StatusOK {
 			t.Errorf("Call %d: status code = %d, expected %d", i+1, w.Code, http.StatusOK)
@@ -605,3 +610,253 @@ func TestCostHandlerWrappedMultipleCalls(t *testing.T) {
 		t.Errorf("getClient called %d times, expected 3", callCount)
 	}
 }
+
+func TestCostHandlerWrappedOrgTarget(t *testing.T) {
+	// Test that org: targets are expanded to the filtered repositories of the organization
+	gin.SetMode(gin.TestMode)
+
+	mockedHTTPClient := mock.NewMockedHTTPClient(
+		mock.WithRequestMatch(
+			mock.GetOrgsReposByOrg,
+			[]github.Repository{
+				{FullName: github.String("my-org/repo1"), Name: github.String("repo1")},
+				{FullName: github.String("my-org/repo2"), Name: github.String("repo2")},
+				{FullName: github.String("my-org/old"), Name: github.String("old"), Archived: github.Bool(true)},
+			},
+		),
+	)
+
+	var capturedRepos []string
+
+	mockGetClient := func(accessToken string) *github.Client {
+		return github.NewClient(mockedHTTPClient)
+	}
+
+	mockGenerateReport := func(ctx context.Context, client *github.Client, repositories []string) models.Report {
+		capturedRepos = repositories
+		return models.Report{NumberOfRepos: len(repositories)}
+	}
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+
+	requestBody := map[string]interface{}{
+		"repositories": []string{"org:my-org", "other/repo"},
+		"filter": map[string]interface{}{
+			"excludeArchived": true,
+		},
+	}
+	bodyBytes, _ := json.Marshal(requestBody)
+	req := httptest.NewRequest("POST", "/cost", bytes.NewBuffer(bodyBytes))
+	req.Header.Set("Content-Type", "application/json")
+	req.AddCookie(&http.Cookie{
+		Name:  "github_token",
+		Value: encryption.EncryptStringNoErr("valid-token", getTestKey),
+	})
+
+	c.Request = req
+
+	CostHandlerWrapped(c, mockGetClient, mockGenerateReport, mockSaveReport, getTestKey)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Status code = %d, expected %d", w.Code, http.StatusOK)
+	}
+
+	expected := []string{"my-org/repo1", "my-org/repo2", "other/repo"}
+	if len(capturedRepos) != len(expected) {
+		t.Fatalf("Captured repositories %v, expected %v", capturedRepos, expected)
+	}
+
+	for i, repo := range expected {
+		if capturedRepos[i] != repo {
+			t.Errorf("Repository[%d] = %q, expected %q", i, capturedRepos[i], repo)
+		}
+	}
+}
+
+func TestCostHandlerWrappedOrgTargetError(t *testing.T) {
+	tests := []struct {
+		name           string
+		status         int
+		expectedStatus int
+	}{
+		{"unknown organization", http.StatusNotFound, http.StatusBadRequest},
+		{"forbidden", http.StatusForbidden, http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			gin.SetMode(gin.TestMode)
+
+			mockedHTTPClient := mock.NewMockedHTTPClient(
+				mock.WithRequestMatchHandler(
+					mock.GetOrgsReposByOrg,
+					http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+						mock.WriteError(w, tt.status, "error")
+					}),
+				),
+			)
+
+			mockGetClient := func(accessToken string) *github.Client {
+				return github.NewClient(mockedHTTPClient)
+			}
+
+			mockGenerateReport := func(ctx context.Context, client *github.Client, repositories []string) models.Report {
+				t.Error("Expected no report when the organization can't be listed")
+				return models.Report{}
+			}
+
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+
+			bodyBytes, _ := json.Marshal(map[string]interface{}{"repositories": []string{"org:typo"}})
+			req := httptest.NewRequest("POST", "/cost", bytes.NewBuffer(bodyBytes))
+			req.Header.Set("Content-Type", "application/json")
+			req.AddCookie(&http.Cookie{
+				Name:  "github_token",
+				Value: encryption.EncryptStringNoErr("valid-token", getTestKey),
+			})
+			c.Request = req
+
+			// Act
+			CostHandlerWrapped(c, mockGetClient, mockGenerateReport, mockSaveReport, getTestKey)
+
+			// Assert
+			if w.Code != tt.expectedStatus {
+				t.Errorf("Status code = %d, expected %d", w.Code, tt.expectedStatus)
+			}
+
+			if !strings.Contains(w.Body.String(), "org:typo") {
+				t.Errorf("Expected the error to name the target, got %s", w.Body.String())
+			}
+		})
+	}
+}
+
+func TestCostHandlerWrappedCostModel(t *testing.T) {
+	// Test that the cost is calculated with the cost model in the request
+	gin.SetMode(gin.TestMode)
+
+	mockGetClient := func(accessToken string) *github.Client {
+		return github.NewClient(nil)
+	}
+
+	mockGenerateReport := func(ctx context.Context, client *github.Client, repositories []string) models.Report {
+		return models.Report{WeightedNumberOfReposWithDuplicationOrDrift: 2}
+	}
+
+	tests := []struct {
+		name          string
+		costModel     interface{}
+		expectedTotal float64
+	}{
+		{"default", nil, 2 * 4 * 120000.0 / 365 / 8},
+		{"custom", map[string]interface{}{"hoursPerRepo": 5, "annualSalary": 104000, "workdaysPerYear": 260}, 2 * 5 * 50},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+
+			requestBody := map[string]interface{}{
+				"repositories": []string{"owner/repo1", "owner/repo2"},
+				"costModel":    tt.costModel,
+			}
+			bodyBytes, _ := json.Marshal(requestBody)
+			req := httptest.NewRequest("POST", "/cost", bytes.NewBuffer(bodyBytes))
+			req.Header.Set("Content-Type", "application/json")
+			req.AddCookie(&http.Cookie{
+				Name:  "github_token",
+				Value: encryption.EncryptStringNoErr("valid-token", getTestKey),
+			})
+
+			c.Request = req
+
+			CostHandlerWrapped(c, mockGetClient, mockGenerateReport, mockSaveReport, getTestKey)
+
+			var response models.Report
+			if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+				t.Fatalf("Failed to parse response: %v", err)
+			}
+
+			if math.Abs(response.Cost.Total-tt.expectedTotal) > 0.001 {
+				t.Errorf("Cost = %f, expected %f", response.Cost.Total, tt.expectedTotal)
+			}
+		})
+	}
+}
+
+func TestCostHandlerWrappedSaveReport(t *testing.T) {
+	// Test that the report is saved unless the client disconnected, and that save errors are recorded on the request
+	gin.SetMode(gin.TestMode)
+
+	mockGetClient := func(accessToken string) *github.Client {
+		return github.NewClient(nil)
+	}
+
+	mockGenerateReport := func(ctx context.Context, client *github.Client, repositories []string) models.Report {
+		return models.Report{NumberOfRepos: len(repositories)}
+	}
+
+	tests := []struct {
+		name           string
+		cancelled      bool
+		saveErr        error
+		expectedSaves  int
+		expectedErrors int
+	}{
+		{"saved", false, nil, 1, 0},
+		{"save failed", false, errors.New("storage unavailable"), 1, 1},
+		{"client disconnected", true, nil, 0, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+
+			saves := 0
+			saveReport := func(ctx context.Context, client *github.Client, report models.StoredReport) (models.StoredReport, error) {
+				saves++
+				report.Id = "report-id"
+				return report, tt.saveErr
+			}
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			if tt.cancelled {
+				cancel()
+			}
+
+			bodyBytes, _ := json.Marshal(map[string]interface{}{"repositories": []string{"owner/repo1", "owner/repo2"}})
+			req := httptest.NewRequestWithContext(ctx, "POST", "/cost", bytes.NewBuffer(bodyBytes))
+			req.Header.Set("Content-Type", "application/json")
+			req.AddCookie(&http.Cookie{
+				Name:  "github_token",
+				Value: encryption.EncryptStringNoErr("valid-token", getTestKey),
+			})
+
+			c.Request = req
+
+			CostHandlerWrapped(c, mockGetClient, mockGenerateReport, saveReport, getTestKey)
+
+			if w.Code != http.StatusOK {
+				t.Errorf("Expected status %d, got %d", http.StatusOK, w.Code)
+			}
+
+			if saves != tt.expectedSaves {
+				t.Errorf("Expected %d saves, got %d", tt.expectedSaves, saves)
+			}
+
+			if len(c.Errors) != tt.expectedErrors {
+				t.Errorf("Expected %d errors on the request, got %v", tt.expectedErrors, c.Errors)
+			}
+
+			if location := w.Header().Get("Content-Location"); (location != "") != (tt.expectedSaves == 1 && tt.saveErr == nil) {
+				t.Errorf("Unexpected Content-Location %q", location)
+			}
+		})
+	}
+}
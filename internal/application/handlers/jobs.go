@@ -0,0 +1,184 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/OctopusSolutionsEngineering/DuplicationCostCalculator/internal/application/jobs"
+	"github.com/OctopusSolutionsEngineering/DuplicationCostCalculator/internal/domain/configuration"
+	"github.com/OctopusSolutionsEngineering/DuplicationCostCalculator/internal/domain/models"
+	"github.com/OctopusSolutionsEngineering/DuplicationCostCalculator/internal/domain/workflows"
+	"github.com/OctopusSolutionsEngineering/DuplicationCostCalculator/internal/infrastructure/client"
+	"github.com/gin-gonic/gin"
+	"github.com/google/go-github/v57/github"
+)
+
+// EventKeepAliveInterval is how often a comment is sent on an idle event stream, so proxies don't close it.
+const EventKeepAliveInterval = 15 * time.Second
+
+var jobManager = jobs.NewManager()
+
+func CreateJobHandler(c *gin.Context) {
+	CreateJobHandlerWrapped(c, jobManager, client.GetClient, workflows.GenerateReportWithProgress, SaveReport, configuration.GetEncryptionKey)
+}
+
+func GetJobHandler(c *gin.Context) {
+	GetJobHandlerWrapped(c, jobManager, configuration.GetEncryptionKey)
+}
+
+func JobEventsHandler(c *gin.Context) {
+	JobEventsHandlerWrapped(c, jobManager, configuration.GetEncryptionKey, EventKeepAliveInterval)
+}
+
+func CancelJobHandler(c *gin.Context) {
+	CancelJobHandlerWrapped(c, jobManager, configuration.GetEncryptionKey)
+}
+
+// CreateJobHandlerWrapped starts a job that compares the repositories in the body of the request, and
+// responds with the job before any repositories have been loaded.
+func CreateJobHandlerWrapped(c *gin.Context, manager *jobs.Manager, getClient func(string) *github.Client, generateReport func(context.Context, *github.Client, []string, workflows.ProgressFunc) models.Report, saveReport func(context.Context, *github.Client, models.StoredReport) (models.StoredReport, error), getKey func() string) {
+	accessToken, ok := GetAccessToken(c, getKey)
+	if !ok {
+		return
+	}
+
+	var requestBody CostRequest
+
+	if err := c.BindJSON(&requestBody); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid request body",
+		})
+		return
+	}
+
+	githubClient := getClient(accessToken)
+
+	job := manager.Start(
+		client.GetIdentity(accessToken),
+		func(ctx context.Context) ([]string, error) {
+			return workflows.ExpandTargets(ctx, githubClient, requestBody.Repositories, requestBody.Filter)
+		},
+		func(ctx context.Context, repos []string, progress workflows.ProgressFunc) models.Report {
+			report := generateReport(ctx, githubClient, repos, progress)
+			report.Cost = workflows.CalculateCost(report, workflows.GetCostModel(requestBody.CostModel))
+
+			// The report of a cancelled job is incomplete, so it is not saved to the report history
+			if ctx.Err() != nil {
+				return report
+			}
+
+			if _, err := saveReport(ctx, githubClient, NewStoredReport(requestBody, repos, report)); err != nil {
+				log.Printf("Error saving report: %v", err)
+			}
+
+			return report
+		})
+
+	c.Header("Location", "/jobs/"+job.Id())
+	c.JSON(http.StatusAccepted, job.Status(false))
+}
+
+// GetJobHandlerWrapped responds with the status of a job, including the report of the repositories loaded so far.
+func GetJobHandlerWrapped(c *gin.Context, manager *jobs.Manager, getKey func() string) {
+	job, ok := getJob(c, manager, getKey)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, job.Status(true))
+}
+
+// CancelJobHandlerWrapped cancels a running job. The job completes with an incomplete report of the repositories
+// loaded so far, which can be fetched as usual.
+func CancelJobHandlerWrapped(c *gin.Context, manager *jobs.Manager, getKey func() string) {
+	job, ok := getJob(c, manager, getKey)
+	if !ok {
+		return
+	}
+
+	job.Cancel()
+
+	c.JSON(http.StatusAccepted, job.Status(false))
+}
+
+// JobEventsHandlerWrapped streams the events of a job as Server-Sent Events until the job finishes or the client
+// disconnects. Clients that reconnect with a Last-Event-ID header only receive the events they have not seen.
+func JobEventsHandlerWrapped(c *gin.Context, manager *jobs.Manager, getKey func() string, keepAliveInterval time.Duration) {
+	job, ok := getJob(c, manager, getKey)
+	if !ok {
+		return
+	}
+
+	lastEventId, _ := strconv.Atoi(c.GetHeader("Last-Event-ID"))
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+
+	keepAlive := time.NewTicker(keepAliveInterval)
+	defer keepAlive.Stop()
+
+	for {
+		events, changed := job.Events(lastEventId)
+
+		for _, event := range events {
+			if err := writeEvent(c, event); err != nil {
+				return
+			}
+			lastEventId = event.Id
+
+			if jobs.IsFinished(event) {
+				c.Writer.Flush()
+				return
+			}
+		}
+
+		c.Writer.Flush()
+
+		select {
+		case <-changed:
+		case <-keepAlive.C:
+			if _, err := fmt.Fprint(c.Writer, ": keep-alive\n\n"); err != nil {
+				return
+			}
+		case <-c.Request.Context().Done():
+			return
+		}
+	}
+}
+
+// getJob returns the job in the id parameter, or writes an error response. Jobs are only returned to the
+// user that started them.
+func getJob(c *gin.Context, manager *jobs.Manager, getKey func() string) (*jobs.Job, bool) {
+	accessToken, ok := GetAccessToken(c, getKey)
+	if !ok {
+		return nil, false
+	}
+
+	job, ok := manager.Get(client.GetIdentity(accessToken), c.Param("id"))
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Job not found",
+		})
+		return nil, false
+	}
+
+	return job, true
+}
+
+func writeEvent(c *gin.Context, event models.JobEvent) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintf(c.Writer, "id: %d\nevent: %s\ndata: %s\n\n", event.Id, event.Type, data)
+	return err
+}
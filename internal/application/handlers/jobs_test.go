@@ -0,0 +1,275 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/OctopusSolutionsEngineering/DuplicationCostCalculator/internal/application/jobs"
+	"github.com/OctopusSolutionsEngineering/DuplicationCostCalculator/internal/domain/encryption"
+	"github.com/OctopusSolutionsEngineering/DuplicationCostCalculator/internal/domain/models"
+	"github.com/OctopusSolutionsEngineering/DuplicationCostCalculator/internal/domain/workflows"
+	"github.com/gin-gonic/gin"
+	"github.com/google/go-github/v57/github"
+)
+
+// newJobsRouter returns a router with the job handlers, where generateReport loads each repository as soon
+// as release is closed.
+func newJobsRouter(manager *jobs.Manager, release chan struct{}) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+
+	mockGetClient := func(accessToken string) *github.Client {
+		return github.NewClient(nil)
+	}
+
+	mockGenerateReport := func(ctx context.Context, client *github.Client, repositories []string, progress workflows.ProgressFunc) models.Report {
+		select {
+		case <-release:
+		case <-ctx.Done():
+			return models.Report{Metadata: models.ReportMetadata{Incomplete: true, IncompleteReason: workflows.GetIncompleteReason(ctx.Err())}}
+		}
+		for i, repo := range repositories {
+			progress(workflows.RepoActions{Repo: repo}, i+1, len(repositories))
+		}
+		return models.Report{NumberOfRepos: len(repositories)}
+	}
+
+	r := gin.New()
+	r.POST("/jobs", func(c *gin.Context) {
+		CreateJobHandlerWrapped(c, manager, mockGetClient, mockGenerateReport, mockSaveReport, getTestKey)
+	})
+	r.GET("/jobs/:id", func(c *gin.Context) {
+		GetJobHandlerWrapped(c, manager, getTestKey)
+	})
+	r.GET("/jobs/:id/events", func(c *gin.Context) {
+		JobEventsHandlerWrapped(c, manager, getTestKey, 10*time.Millisecond)
+	})
+	r.DELETE("/jobs/:id", func(c *gin.Context) {
+		CancelJobHandlerWrapped(c, manager, getTestKey)
+	})
+
+	return r
+}
+
+func newJobRequest(method string, path string, body string, token string) *http.Request {
+	req := httptest.NewRequest(method, path, bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		req.AddCookie(&http.Cookie{
+			Name:  "github_token",
+			Value: encryption.EncryptStringNoErr(token, getTestKey),
+		})
+	}
+	return req
+}
+
+func createJob(t *testing.T, r *gin.Engine, token string) models.AnalysisJob {
+	t.Helper()
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, newJobRequest("POST", "/jobs", `{"repositories": ["owner/repo1", "owner/repo2"]}`, token))
+
+	if w.Code != http.StatusAccepted {
+		t.Fatalf("Status code = %d, expected %d", w.Code, http.StatusAccepted)
+	}
+
+	var job models.AnalysisJob
+	if err := json.Unmarshal(w.Body.Bytes(), &job); err != nil {
+		t.Fatalf("Failed to parse job: %v", err)
+	}
+
+	if w.Header().Get("Location") != "/jobs/"+job.Id {
+		t.Errorf("Location = %q, expected the job URL", w.Header().Get("Location"))
+	}
+
+	return job
+}
+
+func TestJobHandlers(t *testing.T) {
+	// Arrange
+	release := make(chan struct{})
+	r := newJobsRouter(jobs.NewManager(), release)
+
+	// Act
+	job := createJob(t, r, "valid-token")
+
+	// Assert - the job is running until the repositories are loaded
+	if job.Status != models.JobStatusRunning || job.Id == "" {
+		t.Errorf("Unexpected job %+v", job)
+	}
+
+	close(release)
+
+	// The event stream ends when the job is finished
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, newJobRequest("GET", "/jobs/"+job.Id+"/events", "", "valid-token"))
+
+	if w.Code != http.StatusOK || w.Header().Get("Content-Type") != "text/event-stream" {
+		t.Fatalf("Unexpected event stream response %d %s", w.Code, w.Header().Get("Content-Type"))
+	}
+
+	body := w.Body.String()
+	for _, expected := range []string{"id: 1\nevent: started\n", "event: progress\ndata: {\"id\":2,\"type\":\"progress\",\"repo\":\"owner/repo1\"", "id: 4\nevent: completed\n"} {
+		if !strings.Contains(body, expected) {
+			t.Errorf("Expected the event stream to contain %q, got %q", expected, body)
+		}
+	}
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, newJobRequest("GET", "/jobs/"+job.Id, "", "valid-token"))
+
+	var completed models.AnalysisJob
+	if err := json.Unmarshal(w.Body.Bytes(), &completed); err != nil {
+		t.Fatalf("Failed to parse job: %v", err)
+	}
+
+	if completed.Status != models.JobStatusCompleted || completed.Report == nil || completed.Report.NumberOfRepos != 2 {
+		t.Errorf("Unexpected completed job %+v", completed)
+	}
+}
+
+func TestJobEventsHandlerLastEventId(t *testing.T) {
+	// Arrange
+	release := make(chan struct{})
+	close(release)
+	r := newJobsRouter(jobs.NewManager(), release)
+	job := createJob(t, r, "valid-token")
+
+	req := newJobRequest("GET", "/jobs/"+job.Id+"/events", "", "valid-token")
+	req.Header.Set("Last-Event-ID", "3")
+
+	// Act
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	// Assert
+	body := w.Body.String()
+	if strings.Contains(body, "event: started") || strings.Contains(body, "event: progress") {
+		t.Errorf("Expected the events that were already seen to be skipped, got %q", body)
+	}
+
+	if !strings.Contains(body, "id: 4\nevent: completed") {
+		t.Errorf("Expected the completed event, got %q", body)
+	}
+}
+
+func TestJobEventsHandlerKeepAlive(t *testing.T) {
+	// Arrange - the job never finishes, so the stream ends when the client disconnects
+	r := newJobsRouter(jobs.NewManager(), make(chan struct{}))
+	job := createJob(t, r, "valid-token")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	// Act
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, newJobRequest("GET", "/jobs/"+job.Id+"/events", "", "valid-token").WithContext(ctx))
+
+	// Assert
+	if !strings.Contains(w.Body.String(), ": keep-alive\n\n") {
+		t.Errorf("Expected keep-alive comments on an idle stream, got %q", w.Body.String())
+	}
+}
+
+func TestCancelJobHandler(t *testing.T) {
+	// Arrange - the job never loads its repositories unless it is cancelled
+	r := newJobsRouter(jobs.NewManager(), make(chan struct{}))
+	job := createJob(t, r, "valid-token")
+
+	// Jobs can only be cancelled by the user that started them
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, newJobRequest("DELETE", "/jobs/"+job.Id, "", "other-token"))
+
+	if w.Code != http.StatusNotFound {
+		t.Errorf("Status code = %d, expected %d for another user", w.Code, http.StatusNotFound)
+	}
+
+	// Act
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, newJobRequest("DELETE", "/jobs/"+job.Id, "", "valid-token"))
+
+	// Assert
+	if w.Code != http.StatusAccepted {
+		t.Fatalf("Status code = %d, expected %d", w.Code, http.StatusAccepted)
+	}
+
+	// The event stream ends when the cancelled job is finished
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, newJobRequest("GET", "/jobs/"+job.Id+"/events", "", "valid-token"))
+
+	if !strings.Contains(w.Body.String(), "event: completed") {
+		t.Errorf("Expected the cancelled job to complete, got %q", w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, newJobRequest("GET", "/jobs/"+job.Id, "", "valid-token"))
+
+	var cancelled models.AnalysisJob
+	if err := json.Unmarshal(w.Body.Bytes(), &cancelled); err != nil {
+		t.Fatalf("Failed to parse job: %v", err)
+	}
+
+	if cancelled.Report == nil || cancelled.Report.Metadata.IncompleteReason != "cancelled" {
+		t.Errorf("Expected an incomplete report, got %+v", cancelled)
+	}
+}
+
+func TestJobHandlersAccess(t *testing.T) {
+	// Arrange
+	r := newJobsRouter(jobs.NewManager(), make(chan struct{}))
+	job := createJob(t, r, "valid-token")
+
+	tests := []struct {
+		name               string
+		path               string
+		token              string
+		expectedStatusCode int
+	}{
+		{"no token", "/jobs/" + job.Id, "", http.StatusUnauthorized},
+		{"another user", "/jobs/" + job.Id, "other-token", http.StatusNotFound},
+		{"another user's events", "/jobs/" + job.Id + "/events", "other-token", http.StatusNotFound},
+		{"unknown job", "/jobs/missing", "valid-token", http.StatusNotFound},
+		{"owner", "/jobs/" + job.Id, "valid-token", http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, newJobRequest("GET", tt.path, "", tt.token))
+
+			if w.Code != tt.expectedStatusCode {
+				t.Errorf("Status code = %d, expected %d", w.Code, tt.expectedStatusCode)
+			}
+		})
+	}
+}
+
+func TestCreateJobHandlerInvalidRequest(t *testing.T) {
+	r := newJobsRouter(jobs.NewManager(), make(chan struct{}))
+
+	tests := []struct {
+		name               string
+		body               string
+		token              string
+		expectedStatusCode int
+	}{
+		{"no token", `{"repositories": ["owner/repo1"]}`, "", http.StatusUnauthorized},
+		{"malformed JSON", `{invalid json}`, "valid-token", http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, newJobRequest("POST", "/jobs", tt.body, tt.token))
+
+			if w.Code != tt.expectedStatusCode {
+				t.Errorf("Status code = %d, expected %d", w.Code, tt.expectedStatusCode)
+			}
+		})
+	}
+}
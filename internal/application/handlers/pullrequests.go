@@ -0,0 +1,108 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/OctopusSolutionsEngineering/DuplicationCostCalculator/internal/domain/configuration"
+	"github.com/OctopusSolutionsEngineering/DuplicationCostCalculator/internal/domain/models"
+	"github.com/OctopusSolutionsEngineering/DuplicationCostCalculator/internal/domain/workflows"
+	"github.com/OctopusSolutionsEngineering/DuplicationCostCalculator/internal/infrastructure/client"
+	"github.com/gin-gonic/gin"
+	"github.com/google/go-github/v57/github"
+)
+
+func PullRequestsHandler(c *gin.Context) {
+	PullRequestsHandlerWrapped(c, client.GetClient, workflows.AlignActionVersions, IsPullRequestsEnabled, configuration.GetPullRequestsSecret, configuration.GetEncryptionKey)
+}
+
+// IsPullRequestsEnabled returns true if the server can open pull requests. It must be turned on, and the server
+// must authenticate as a GitHub App, so the pull requests are opened by the app rather than a user. The app doesn't
+// know who is calling it, so a secret must also be set, which callers include to prove they can open pull requests.
+func IsPullRequestsEnabled() bool {
+	return configuration.GetEnablePullRequests() && client.UsePrivateKeyAuth() && configuration.GetPullRequestsSecret() != ""
+}
+
+// PullRequestsRequest is the body of a request to open pull requests that align action versions.
+type PullRequestsRequest struct {
+	// Repositories can include "org:<name>" or "user:<name>" targets to scan every repository of an owner
+	Repositories []string `json:"repositories"`
+	// Filter limits the repositories included from "org:" and "user:" targets
+	Filter models.RepositoryFilter `json:"filter"`
+	// Target is the version to align each action to: "latest" or "majority". It defaults to latest.
+	Target string `json:"target"`
+	// Versions maps actions to the version they are aligned to, overriding the target.
+	Versions map[string]string `json:"versions"`
+	// Actions limits the drifted actions that are aligned. Every drifted action is aligned if it is empty.
+	Actions []string `json:"actions"`
+	// DryRun returns the changes without opening pull requests.
+	DryRun bool `json:"dryRun"`
+}
+
+// PullRequestsResponse is the version each action is aligned to, and the pull request opened in each repository.
+type PullRequestsResponse struct {
+	Targets      map[string]string          `json:"targets"`
+	PullRequests []models.PullRequestResult `json:"pullRequests"`
+}
+
+// PullRequestsHandlerWrapped opens pull requests that align the action versions of the repositories in the body of
+// the request. The server authenticates as a GitHub App, which can change every repository it is installed in, so
+// the request must include the secret returned by getSecret.
+func PullRequestsHandlerWrapped(c *gin.Context, getClient func(string) *github.Client, alignActionVersions func(context.Context, *github.Client, []string, workflows.AlignmentOptions, bool) (map[string]string, []models.PullRequestResult), isEnabled func() bool, getSecret func() string, getKey func() string) {
+	if !isEnabled() {
+		c.JSON(http.StatusForbidden, gin.H{
+			"error": "Pull requests are not enabled. Set " + configuration.DUPCOST_ENABLE_PULL_REQUESTS + " to true and " + configuration.DUPCOST_PULL_REQUESTS_SECRET + " to a secret, and authenticate as a GitHub App",
+		})
+		return
+	}
+
+	if !HasSecret(c, getSecret) {
+		return
+	}
+
+	accessToken, ok := GetAccessToken(c, getKey)
+	if !ok {
+		return
+	}
+
+	var requestBody PullRequestsRequest
+
+	if err := c.BindJSON(&requestBody); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid request body",
+		})
+		return
+	}
+
+	if requestBody.Target == "" {
+		requestBody.Target = workflows.TargetLatest
+	}
+
+	if requestBody.Target != workflows.TargetLatest && requestBody.Target != workflows.TargetMajority {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "The target must be latest or majority",
+		})
+		return
+	}
+
+	githubClient := getClient(accessToken)
+
+	ctx := c.Request.Context()
+
+	repositories, err := workflows.ExpandTargets(ctx, githubClient, requestBody.Repositories, requestBody.Filter)
+	if err != nil {
+		writeTargetError(c, err)
+		return
+	}
+
+	targets, results := alignActionVersions(ctx, githubClient, repositories, workflows.AlignmentOptions{
+		Target:   requestBody.Target,
+		Versions: requestBody.Versions,
+		Actions:  requestBody.Actions,
+	}, requestBody.DryRun)
+
+	c.JSON(http.StatusOK, PullRequestsResponse{
+		Targets:      targets,
+		PullRequests: results,
+	})
+}
@@ -0,0 +1,165 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/OctopusSolutionsEngineering/DuplicationCostCalculator/internal/domain/encryption"
+	"github.com/OctopusSolutionsEngineering/DuplicationCostCalculator/internal/domain/models"
+	"github.com/OctopusSolutionsEngineering/DuplicationCostCalculator/internal/domain/workflows"
+	"github.com/OctopusSolutionsEngineering/DuplicationCostCalculator/internal/infrastructure/fakegithub"
+	"github.com/gin-gonic/gin"
+	"github.com/google/go-github/v57/github"
+)
+
+const testPullRequestsSecret = "pull-requests-secret"
+
+func getTestPullRequestsSecret() string {
+	return testPullRequestsSecret
+}
+
+func newPullRequestsContext(body string, cookie bool, authorization string) (*gin.Context, *httptest.ResponseRecorder) {
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+
+	req := httptest.NewRequest("POST", "/pullrequests", bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	if authorization != "" {
+		req.Header.Set("Authorization", authorization)
+	}
+	if cookie {
+		req.AddCookie(&http.Cookie{
+			Name:  "github_token",
+			Value: encryption.EncryptStringNoErr("valid-token", getTestKey),
+		})
+	}
+	c.Request = req
+
+	return c, w
+}
+
+func TestPullRequestsHandlerWrapped(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	authorized := "Bearer " + testPullRequestsSecret
+
+	tests := []struct {
+		name               string
+		enabled            bool
+		cookie             bool
+		authorization      string
+		body               string
+		expectedStatusCode int
+		expectedOptions    workflows.AlignmentOptions
+		expectedDryRun     bool
+	}{
+		{"disabled", false, true, authorized, `{"repositories": ["owner/repo1", "owner/repo2"]}`, http.StatusForbidden, workflows.AlignmentOptions{}, false},
+		{"missing secret", true, true, "", `{"repositories": ["owner/repo1", "owner/repo2"]}`, http.StatusUnauthorized, workflows.AlignmentOptions{}, false},
+		{"wrong secret", true, true, "Bearer guessed", `{"repositories": ["owner/repo1", "owner/repo2"]}`, http.StatusUnauthorized, workflows.AlignmentOptions{}, false},
+		{"secret without bearer", true, true, testPullRequestsSecret, `{"repositories": ["owner/repo1", "owner/repo2"]}`, http.StatusUnauthorized, workflows.AlignmentOptions{}, false},
+		{"missing access token", true, false, authorized, `{"repositories": ["owner/repo1", "owner/repo2"]}`, http.StatusUnauthorized, workflows.AlignmentOptions{}, false},
+		{"invalid body", true, true, authorized, `{"repositories": `, http.StatusBadRequest, workflows.AlignmentOptions{}, false},
+		{"invalid target", true, true, authorized, `{"repositories": ["owner/repo1"], "target": "oldest"}`, http.StatusBadRequest, workflows.AlignmentOptions{}, false},
+		{"default target", true, true, authorized, `{"repositories": ["owner/repo1", "owner/repo2"]}`, http.StatusOK, workflows.AlignmentOptions{Target: workflows.TargetLatest}, false},
+		{"options", true, true, authorized, `{"repositories": ["owner/repo1", "owner/repo2"], "target": "majority", "actions": ["actions/checkout"], "dryRun": true}`,
+			http.StatusOK, workflows.AlignmentOptions{Target: workflows.TargetMajority, Actions: []string{"actions/checkout"}}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			c, w := newPullRequestsContext(tt.body, tt.cookie, tt.authorization)
+
+			var capturedOptions workflows.AlignmentOptions
+			var capturedDryRun bool
+			alignCalled := false
+			mockAlign := func(ctx context.Context, client *github.Client, repos []string, options workflows.AlignmentOptions, dryRun bool) (map[string]string, []models.PullRequestResult) {
+				alignCalled = true
+				capturedOptions = options
+				capturedDryRun = dryRun
+				return map[string]string{"actions/checkout": "v4"}, []models.PullRequestResult{{Repo: "owner/repo2", Status: models.PullRequestCreated}}
+			}
+
+			// Act
+			PullRequestsHandlerWrapped(c, func(string) *github.Client { return nil }, mockAlign, func() bool { return tt.enabled }, getTestPullRequestsSecret, getTestKey)
+
+			// Assert
+			if w.Code != tt.expectedStatusCode {
+				t.Fatalf("Status code = %d, expected %d: %s", w.Code, tt.expectedStatusCode, w.Body.String())
+			}
+
+			if alignCalled != (tt.expectedStatusCode == http.StatusOK) {
+				t.Errorf("alignActionVersions called = %v, expected it only for successful requests", alignCalled)
+			}
+
+			if !alignCalled {
+				return
+			}
+
+			if capturedOptions.Target != tt.expectedOptions.Target || len(capturedOptions.Actions) != len(tt.expectedOptions.Actions) || capturedDryRun != tt.expectedDryRun {
+				t.Errorf("Unexpected options %+v dry run %v", capturedOptions, capturedDryRun)
+			}
+
+			var response PullRequestsResponse
+			if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil || len(response.PullRequests) != 1 || response.Targets["actions/checkout"] != "v4" {
+				t.Errorf("Unexpected response %s", w.Body.String())
+			}
+		})
+	}
+}
+
+func TestPullRequestsHandlerWrapped_FakeGitHub(t *testing.T) {
+	// Arrange
+	gin.SetMode(gin.TestMode)
+	server := fakegithub.NewServer().
+		AddRepo("owner/repo1", map[string]string{".github/workflows/build.yml": "jobs:\n  build:\n    steps:\n      - uses: actions/checkout@v4\n"}).
+		AddRepo("owner/repo2", map[string]string{".github/workflows/build.yml": "jobs:\n  build:\n    steps:\n      - uses: actions/checkout@v3\n"})
+	defer server.Close()
+
+	getClient := func(string) *github.Client { return server.Client() }
+	body := `{"repositories": ["owner/repo1", "owner/repo2"]}`
+
+	// Act - the request is sent twice
+	for i := 0; i < 2; i++ {
+		c, w := newPullRequestsContext(body, true, "Bearer "+testPullRequestsSecret)
+		PullRequestsHandlerWrapped(c, getClient, workflows.AlignActionVersions, func() bool { return true }, getTestPullRequestsSecret, getTestKey)
+
+		if w.Code != http.StatusOK {
+			t.Fatalf("Status code = %d, expected %d: %s", w.Code, http.StatusOK, w.Body.String())
+		}
+	}
+
+	// Assert - a single pull request aligns repo2 with repo1
+	pullRequests := server.GetPullRequests("owner/repo2")
+	if len(pullRequests) != 1 || len(server.GetPullRequests("owner/repo1")) != 0 {
+		t.Errorf("Expected a single pull request in repo2, got %+v", pullRequests)
+	}
+
+	if files := server.GetFiles("owner/repo2", workflows.AlignmentBranch); files[".github/workflows/build.yml"] != "jobs:\n  build:\n    steps:\n      - uses: actions/checkout@v4\n" {
+		t.Errorf("Unexpected files on the branch %v", files)
+	}
+}
+
+func TestPullRequestsHandlerWrapped_NoSecretConfigured(t *testing.T) {
+	// Arrange - an empty secret must not match a request with an empty bearer token
+	gin.SetMode(gin.TestMode)
+	c, w := newPullRequestsContext(`{"repositories": ["owner/repo1"]}`, true, "Bearer ")
+
+	alignCalled := false
+	mockAlign := func(ctx context.Context, client *github.Client, repos []string, options workflows.AlignmentOptions, dryRun bool) (map[string]string, []models.PullRequestResult) {
+		alignCalled = true
+		return map[string]string{}, []models.PullRequestResult{}
+	}
+
+	// Act
+	PullRequestsHandlerWrapped(c, func(string) *github.Client { return nil }, mockAlign, func() bool { return true }, func() string { return "" }, getTestKey)
+
+	// Assert
+	if w.Code != http.StatusUnauthorized || alignCalled {
+		t.Errorf("Expected the request to be rejected, got %d and align called = %v", w.Code, alignCalled)
+	}
+}
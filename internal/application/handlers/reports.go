@@ -0,0 +1,225 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/OctopusSolutionsEngineering/DuplicationCostCalculator/internal/domain/configuration"
+	"github.com/OctopusSolutionsEngineering/DuplicationCostCalculator/internal/domain/models"
+	"github.com/OctopusSolutionsEngineering/DuplicationCostCalculator/internal/domain/workflows"
+	"github.com/OctopusSolutionsEngineering/DuplicationCostCalculator/internal/infrastructure/client"
+	"github.com/OctopusSolutionsEngineering/DuplicationCostCalculator/internal/infrastructure/githubapi"
+	"github.com/OctopusSolutionsEngineering/DuplicationCostCalculator/internal/infrastructure/reportstore"
+	"github.com/gin-gonic/gin"
+	"github.com/google/go-github/v57/github"
+)
+
+var reportStore = reportstore.NewStore(configuration.GetReportDir())
+
+func ListReportsHandler(c *gin.Context) {
+	ListReportsHandlerWrapped(c, reportStore, client.GetClient, GetReportOwner, configuration.GetEncryptionKey)
+}
+
+func GetReportHandler(c *gin.Context) {
+	GetReportHandlerWrapped(c, reportStore, client.GetClient, GetReportOwner, configuration.GetEncryptionKey)
+}
+
+func DeleteReportHandler(c *gin.Context) {
+	DeleteReportHandlerWrapped(c, reportStore, client.GetClient, GetReportOwner, configuration.GetEncryptionKey)
+}
+
+func ProposalsHandler(c *gin.Context) {
+	ProposalsHandlerWrapped(c, reportStore, client.GetClient, GetReportOwner, configuration.GetEncryptionKey)
+}
+
+func DiffHandler(c *gin.Context) {
+	DiffHandlerWrapped(c, reportStore, client.GetClient, GetReportOwner, configuration.GetEncryptionKey)
+}
+
+// SaveReport saves the report to the report history, owned by the user the client is authenticated as.
+func SaveReport(ctx context.Context, githubClient *github.Client, report models.StoredReport) (models.StoredReport, error) {
+	return SaveReportWrapped(ctx, reportStore, GetReportOwner, githubClient, report)
+}
+
+func SaveReportWrapped(ctx context.Context, store *reportstore.Store, getOwner func(context.Context, *github.Client) (string, error), githubClient *github.Client, report models.StoredReport) (models.StoredReport, error) {
+	owner, err := getOwner(ctx, githubClient)
+	if err != nil {
+		return models.StoredReport{}, err
+	}
+
+	report.Owner = owner
+	return store.Save(report)
+}
+
+// GetReportOwner returns who owns the reports generated with the client. Reports belong to the GitHub user rather
+// than the access token, so they are kept when the token expires. When the app authenticates with a private key,
+// everyone uses the same installation, so they share its reports.
+func GetReportOwner(ctx context.Context, githubClient *github.Client) (string, error) {
+	if client.UsePrivateKeyAuth() {
+		return "installation", nil
+	}
+
+	return githubapi.GetUserIdentity(ctx, githubClient)
+}
+
+// ListReportsHandlerWrapped responds with a summary of each of the user's saved reports, newest first.
+func ListReportsHandlerWrapped(c *gin.Context, store *reportstore.Store, getClient func(string) *github.Client, getOwner func(context.Context, *github.Client) (string, error), getKey func() string) {
+	owner, ok := getReportOwner(c, getClient, getOwner, getKey)
+	if !ok {
+		return
+	}
+
+	summaries, err := store.List(owner)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to list reports",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, summaries)
+}
+
+// GetReportHandlerWrapped responds with a saved report, including the full report.
+func GetReportHandlerWrapped(c *gin.Context, store *reportstore.Store, getClient func(string) *github.Client, getOwner func(context.Context, *github.Client) (string, error), getKey func() string) {
+	owner, ok := getReportOwner(c, getClient, getOwner, getKey)
+	if !ok {
+		return
+	}
+
+	report, err := store.Get(owner, c.Param("id"))
+	if err != nil {
+		writeReportError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+// ProposalsHandlerWrapped responds with a shared composite action for each cluster of duplicated steps in a saved
+// report, and the steps that replace them. The optional actionRepo query parameter is the repository the composite
+// actions are published in.
+func ProposalsHandlerWrapped(c *gin.Context, store *reportstore.Store, getClient func(string) *github.Client, getOwner func(context.Context, *github.Client) (string, error), getKey func() string) {
+	owner, ok := getReportOwner(c, getClient, getOwner, getKey)
+	if !ok {
+		return
+	}
+
+	report, err := store.Get(owner, c.Param("id"))
+	if err != nil {
+		writeReportError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, workflows.ProposeCompositeActions(report.Report.Clusters, c.Query("actionRepo")))
+}
+
+// DeleteReportHandlerWrapped removes a saved report from the history.
+func DeleteReportHandlerWrapped(c *gin.Context, store *reportstore.Store, getClient func(string) *github.Client, getOwner func(context.Context, *github.Client) (string, error), getKey func() string) {
+	owner, ok := getReportOwner(c, getClient, getOwner, getKey)
+	if !ok {
+		return
+	}
+
+	if err := store.Delete(owner, c.Param("id")); err != nil {
+		writeReportError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// getReportOwner returns the owner of the reports the user can access, or writes an error response.
+func getReportOwner(c *gin.Context, getClient func(string) *github.Client, getOwner func(context.Context, *github.Client) (string, error), getKey func() string) (string, bool) {
+	accessToken, ok := GetAccessToken(c, getKey)
+	if !ok {
+		return "", false
+	}
+
+	owner, err := getOwner(c.Request.Context(), getClient(accessToken))
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "Unauthorized - the GitHub user could not be found",
+		})
+		return "", false
+	}
+
+	return owner, true
+}
+
+func writeReportError(c *gin.Context, err error) {
+	if errors.Is(err, reportstore.ErrReportNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Report not found",
+		})
+		return
+	}
+
+	c.JSON(http.StatusInternalServerError, gin.H{
+		"error": "Failed to read the report",
+	})
+}
+
+// DiffRequest is the body of a request to compare two reports. Each report is either included in the request,
+// or is the id of a saved report.
+type DiffRequest struct {
+	Before   *models.Report `json:"before"`
+	After    *models.Report `json:"after"`
+	BeforeId string         `json:"beforeId"`
+	AfterId  string         `json:"afterId"`
+	// CostModel is used to calculate the cost of both reports. Values that are not set use the defaults.
+	CostModel *models.CostModel `json:"costModel"`
+}
+
+// DiffHandlerWrapped responds with the changes between two reports, like the drift that was resolved or introduced.
+func DiffHandlerWrapped(c *gin.Context, store *reportstore.Store, getClient func(string) *github.Client, getOwner func(context.Context, *github.Client) (string, error), getKey func() string) {
+	owner, ok := getReportOwner(c, getClient, getOwner, getKey)
+	if !ok {
+		return
+	}
+
+	var requestBody DiffRequest
+
+	if err := c.BindJSON(&requestBody); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid request body",
+		})
+		return
+	}
+
+	before, ok := getDiffReport(c, store, owner, requestBody.Before, requestBody.BeforeId)
+	if !ok {
+		return
+	}
+
+	after, ok := getDiffReport(c, store, owner, requestBody.After, requestBody.AfterId)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, workflows.DiffReports(before, after, workflows.GetCostModel(requestBody.CostModel)))
+}
+
+// getDiffReport returns the report included in the request, or the saved report with the id, or writes an error
+// response.
+func getDiffReport(c *gin.Context, store *reportstore.Store, owner string, report *models.Report, id string) (models.Report, bool) {
+	if report != nil {
+		return *report, true
+	}
+
+	if id == "" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Two reports, or the ids of two saved reports, are required",
+		})
+		return models.Report{}, false
+	}
+
+	stored, err := store.Get(owner, id)
+	if err != nil {
+		writeReportError(c, err)
+		return models.Report{}, false
+	}
+
+	return stored.Report, true
+}
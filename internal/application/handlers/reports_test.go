@@ -0,0 +1,296 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/OctopusSolutionsEngineering/DuplicationCostCalculator/internal/domain/encryption"
+	"github.com/OctopusSolutionsEngineering/DuplicationCostCalculator/internal/domain/models"
+	"github.com/OctopusSolutionsEngineering/DuplicationCostCalculator/internal/infrastructure/reportstore"
+	"github.com/gin-gonic/gin"
+	"github.com/google/go-github/v57/github"
+)
+
+// mockSaveReport returns the report without saving it.
+func mockSaveReport(ctx context.Context, client *github.Client, report models.StoredReport) (models.StoredReport, error) {
+	return report, nil
+}
+
+// mockGetOwner makes every client belong to the same user.
+func mockGetOwner(ctx context.Context, client *github.Client) (string, error) {
+	return "user:1", nil
+}
+
+func newReportsRouter(store *reportstore.Store, getOwner func(context.Context, *github.Client) (string, error)) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+
+	mockGetClient := func(accessToken string) *github.Client {
+		return github.NewClient(nil)
+	}
+
+	r := gin.New()
+	r.GET("/reports", func(c *gin.Context) {
+		ListReportsHandlerWrapped(c, store, mockGetClient, getOwner, getTestKey)
+	})
+	r.GET("/reports/:id", func(c *gin.Context) {
+		GetReportHandlerWrapped(c, store, mockGetClient, getOwner, getTestKey)
+	})
+	r.GET("/reports/:id/proposals", func(c *gin.Context) {
+		ProposalsHandlerWrapped(c, store, mockGetClient, getOwner, getTestKey)
+	})
+	r.DELETE("/reports/:id", func(c *gin.Context) {
+		DeleteReportHandlerWrapped(c, store, mockGetClient, getOwner, getTestKey)
+	})
+	r.POST("/diff", func(c *gin.Context) {
+		DiffHandlerWrapped(c, store, mockGetClient, getOwner, getTestKey)
+	})
+
+	return r
+}
+
+func TestReportHandlers(t *testing.T) {
+	// Arrange
+	store := reportstore.NewStore(t.TempDir())
+	saved, _ := store.Save(models.StoredReport{Owner: "user:1", Repositories: []string{"owner/repo1", "owner/repo2"}, Report: models.Report{NumberOfRepos: 2}})
+	store.Save(models.StoredReport{Owner: "user:2", Repositories: []string{"owner/repo3"}})
+
+	r := newReportsRouter(store, mockGetOwner)
+
+	// Act & Assert - only the user's reports are listed
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, newJobRequest("GET", "/reports", "", "valid-token"))
+
+	var summaries []models.ReportSummary
+	if err := json.Unmarshal(w.Body.Bytes(), &summaries); err != nil {
+		t.Fatalf("Failed to parse the reports: %v", err)
+	}
+
+	if w.Code != http.StatusOK || len(summaries) != 1 || summaries[0].Id != saved.Id || summaries[0].NumberOfRepos != 2 {
+		t.Errorf("Unexpected reports %d %+v", w.Code, summaries)
+	}
+
+	// The full report can be fetched
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, newJobRequest("GET", "/reports/"+saved.Id, "", "valid-token"))
+
+	var report models.StoredReport
+	if err := json.Unmarshal(w.Body.Bytes(), &report); err != nil {
+		t.Fatalf("Failed to parse the report: %v", err)
+	}
+
+	if w.Code != http.StatusOK || report.Id != saved.Id || report.Report.NumberOfRepos != 2 || len(report.Repositories) != 2 {
+		t.Errorf("Unexpected report %d %+v", w.Code, report)
+	}
+
+	// The report can be deleted
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, newJobRequest("DELETE", "/reports/"+saved.Id, "", "valid-token"))
+
+	if w.Code != http.StatusNoContent {
+		t.Errorf("Status code = %d, expected %d", w.Code, http.StatusNoContent)
+	}
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, newJobRequest("GET", "/reports/"+saved.Id, "", "valid-token"))
+
+	if w.Code != http.StatusNotFound {
+		t.Errorf("Expected the deleted report to be missing, got %d", w.Code)
+	}
+}
+
+func TestProposalsHandler(t *testing.T) {
+	// Arrange
+	store := reportstore.NewStore(t.TempDir())
+	saved, _ := store.Save(models.StoredReport{Owner: "user:1", Report: models.Report{Clusters: []models.ActionCluster{
+		{
+			Uses:  "actions/setup-node",
+			Repos: []string{"owner/repo1", "owner/repo2"},
+			Members: []models.ActionClusterMember{
+				{Repo: "owner/repo1", Version: "v4", With: map[string]string{"node-version": "20"}},
+				{Repo: "owner/repo2", Version: "v4", With: map[string]string{"node-version": "18"}},
+			},
+		},
+	}}})
+
+	r := newReportsRouter(store, mockGetOwner)
+
+	// Act
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, newJobRequest("GET", "/reports/"+saved.Id+"/proposals?actionRepo=org/actions", "", "valid-token"))
+
+	// Assert
+	var proposals []models.CompositeActionProposal
+	if err := json.Unmarshal(w.Body.Bytes(), &proposals); err != nil {
+		t.Fatalf("Failed to parse the proposals: %v", err)
+	}
+
+	if w.Code != http.StatusOK || len(proposals) != 1 || proposals[0].Location != "org/actions/setup-node@v1" || len(proposals[0].Replacements) != 2 {
+		t.Errorf("Unexpected proposals %d %+v", w.Code, proposals)
+	}
+}
+
+func TestReportHandlersAccess(t *testing.T) {
+	// Arrange
+	store := reportstore.NewStore(t.TempDir())
+	other, _ := store.Save(models.StoredReport{Owner: "user:2"})
+
+	failingGetOwner := func(ctx context.Context, client *github.Client) (string, error) {
+		return "", errors.New("bad credentials")
+	}
+
+	tests := []struct {
+		name               string
+		method             string
+		path               string
+		token              string
+		getOwner           func(context.Context, *github.Client) (string, error)
+		expectedStatusCode int
+	}{
+		{"no token", "GET", "/reports", "", mockGetOwner, http.StatusUnauthorized},
+		{"unknown user", "GET", "/reports", "valid-token", failingGetOwner, http.StatusUnauthorized},
+		{"another user's report", "GET", "/reports/" + other.Id, "valid-token", mockGetOwner, http.StatusNotFound},
+		{"another user's proposals", "GET", "/reports/" + other.Id + "/proposals", "valid-token", mockGetOwner, http.StatusNotFound},
+		{"delete another user's report", "DELETE", "/reports/" + other.Id, "valid-token", mockGetOwner, http.StatusNotFound},
+		{"invalid id", "GET", "/reports/..%2Fsecret", "valid-token", mockGetOwner, http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := newReportsRouter(store, tt.getOwner)
+
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, newJobRequest(tt.method, tt.path, "", tt.token))
+
+			if w.Code != tt.expectedStatusCode {
+				t.Errorf("Status code = %d, expected %d", w.Code, tt.expectedStatusCode)
+			}
+		})
+	}
+
+	if _, err := store.Get("user:2", other.Id); err != nil {
+		t.Errorf("Expected the other user's report to be kept, got %v", err)
+	}
+}
+
+func TestCostHandlerWrappedSavesReport(t *testing.T) {
+	tests := []struct {
+		name                    string
+		getOwner                func(context.Context, *github.Client) (string, error)
+		expectedContentLocation bool
+	}{
+		{"saved", mockGetOwner, true},
+		{"save failed", func(ctx context.Context, client *github.Client) (string, error) {
+			return "", errors.New("bad credentials")
+		}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			gin.SetMode(gin.TestMode)
+			store := reportstore.NewStore(t.TempDir())
+
+			mockGetClient := func(accessToken string) *github.Client {
+				return github.NewClient(nil)
+			}
+
+			mockGenerateReport := func(ctx context.Context, client *github.Client, repositories []string) models.Report {
+				return models.Report{NumberOfRepos: len(repositories)}
+			}
+
+			saveReport := func(ctx context.Context, client *github.Client, report models.StoredReport) (models.StoredReport, error) {
+				return SaveReportWrapped(ctx, store, tt.getOwner, client, report)
+			}
+
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest("POST", "/cost", bytes.NewBufferString(`{"repositories": ["owner/repo1", "owner/repo2"], "filter": {"excludeForks": true}}`))
+			c.Request.Header.Set("Content-Type", "application/json")
+			c.Request.AddCookie(&http.Cookie{
+				Name:  "github_token",
+				Value: encryption.EncryptStringNoErr("valid-token", getTestKey),
+			})
+
+			// Act
+			CostHandlerWrapped(c, mockGetClient, mockGenerateReport, saveReport, getTestKey)
+
+			// Assert - the report is returned whether or not it was saved
+			if w.Code != http.StatusOK {
+				t.Fatalf("Status code = %d, expected %d", w.Code, http.StatusOK)
+			}
+
+			summaries, _ := store.List("")
+			location := w.Header().Get("Content-Location")
+
+			if !tt.expectedContentLocation {
+				if location != "" || len(summaries) != 0 {
+					t.Errorf("Expected the report not to be saved, got %q %+v", location, summaries)
+				}
+				return
+			}
+
+			if len(summaries) != 1 || location != "/reports/"+summaries[0].Id {
+				t.Fatalf("Expected the saved report to be linked, got %q %+v", location, summaries)
+			}
+
+			saved, _ := store.Get("user:1", summaries[0].Id)
+			if saved.Report.NumberOfRepos != 2 || len(saved.Parameters.Targets) != 2 || !saved.Parameters.Filter.ExcludeForks {
+				t.Errorf("Unexpected saved report %+v", saved)
+			}
+		})
+	}
+}
+
+func TestDiffHandler(t *testing.T) {
+	// Arrange
+	store := reportstore.NewStore(t.TempDir())
+	saved, _ := store.Save(models.StoredReport{Owner: "user:1", Report: models.Report{NumberOfReposWithDuplicationOrDrift: 4, WeightedNumberOfReposWithDuplicationOrDrift: 2}})
+	other, _ := store.Save(models.StoredReport{Owner: "user:2"})
+
+	r := newReportsRouter(store, mockGetOwner)
+
+	tests := []struct {
+		name               string
+		body               string
+		expectedStatusCode int
+		expectedChange     int
+		expectedCost       float64
+	}{
+		{"reports", `{"before": {"numberOfReposWithDuplicationOrDrift": 3}, "after": {"numberOfReposWithDuplicationOrDrift": 1}}`, http.StatusOK, -2, 0},
+		{"saved report", `{"beforeId": "` + saved.Id + `", "after": {"numberOfReposWithDuplicationOrDrift": 1}, "costModel": {"hoursPerRepo": 1, "annualSalary": 2920}}`, http.StatusOK, -3, -2},
+		{"another user's report", `{"beforeId": "` + other.Id + `", "after": {}}`, http.StatusNotFound, 0, 0},
+		{"missing report", `{"before": {}}`, http.StatusBadRequest, 0, 0},
+		{"malformed JSON", `{invalid json}`, http.StatusBadRequest, 0, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, newJobRequest("POST", "/diff", tt.body, "valid-token"))
+
+			// Assert
+			if w.Code != tt.expectedStatusCode {
+				t.Fatalf("Status code = %d, expected %d", w.Code, tt.expectedStatusCode)
+			}
+
+			if w.Code != http.StatusOK {
+				return
+			}
+
+			var diff models.ReportDiff
+			if err := json.Unmarshal(w.Body.Bytes(), &diff); err != nil {
+				t.Fatalf("Failed to parse the diff: %v", err)
+			}
+
+			if diff.NumberOfReposWithDuplicationOrDrift.Change != tt.expectedChange || diff.Cost.Change != tt.expectedCost {
+				t.Errorf("Unexpected diff %+v", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,292 @@
+package jobs
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"maps"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/OctopusSolutionsEngineering/DuplicationCostCalculator/internal/domain/models"
+	"github.com/OctopusSolutionsEngineering/DuplicationCostCalculator/internal/domain/workflows"
+)
+
+// JobRetention is how long a job is kept after it has finished, so a page that was reloaded can still
+// fetch the report.
+const JobRetention = time.Hour
+
+// GenerateReportFunc compares the repositories, calling progress as each repository is loaded. The context is
+// cancelled when the job is cancelled.
+type GenerateReportFunc func(ctx context.Context, repos []string, progress workflows.ProgressFunc) models.Report
+
+// GetReposFunc returns the repositories compared by a job. The context is cancelled when the job is cancelled.
+// The job fails if an error is returned.
+type GetReposFunc func(ctx context.Context) ([]string, error)
+
+// Manager runs jobs in the background and keeps them until they expire.
+type Manager struct {
+	mutex sync.Mutex
+	jobs  map[string]*Job
+	now   func() time.Time
+}
+
+func NewManager() *Manager {
+	return &Manager{
+		jobs: map[string]*Job{},
+		now:  time.Now,
+	}
+}
+
+// Start creates a job for the owner, and runs it in the background. getRepos returns the repositories to
+// compare, which may take some time if organizations have to be expanded, so it is also run in the background.
+// Jobs keep running after the request that started them has finished, so they run on their own context, which
+// is cancelled by Cancel.
+func (m *Manager) Start(owner string, getRepos GetReposFunc, generateReport GenerateReportFunc) *Job {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.removeExpired()
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	now := m.now()
+	job := &Job{
+		owner:  owner,
+		now:    m.now,
+		cancel: cancel,
+		status: models.AnalysisJob{
+			Id:           NewJobId(),
+			Status:       models.JobStatusRunning,
+			Repositories: []string{},
+			CreatedAt:    now,
+			UpdatedAt:    now,
+		},
+		loaded:  []workflows.RepoActions{},
+		events:  []models.JobEvent{},
+		changed: make(chan struct{}),
+	}
+
+	m.jobs[job.status.Id] = job
+
+	go job.run(ctx, getRepos, generateReport)
+
+	return job
+}
+
+// Get returns the job with the id. Jobs can only be read by the owner that started them.
+func (m *Manager) Get(owner string, id string) (*Job, bool) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	job, ok := m.jobs[id]
+	if !ok || job.owner != owner {
+		return nil, false
+	}
+
+	return job, true
+}
+
+// removeExpired removes the jobs that finished more than JobRetention ago.
+func (m *Manager) removeExpired() {
+	for _, id := range slices.Collect(maps.Keys(m.jobs)) {
+		status := m.jobs[id].Status(false)
+		if status.Status != models.JobStatusRunning && m.now().Sub(status.UpdatedAt) > JobRetention {
+			delete(m.jobs, id)
+		}
+	}
+}
+
+// Job is an analysis running in the background. The progress is recorded as a list of events that can be
+// replayed by clients that connect after the job has started.
+type Job struct {
+	owner  string
+	now    func() time.Time
+	cancel context.CancelFunc
+	mutex  sync.Mutex
+	status models.AnalysisJob
+	// loaded are the repositories that have been loaded so far, used to build a partial report.
+	loaded []workflows.RepoActions
+	// partial is the report of the loaded repositories. It is built when it is requested.
+	partial *models.Report
+	events  []models.JobEvent
+	// changed is closed, and replaced, each time an event is added.
+	changed chan struct{}
+}
+
+// Id returns the id of the job.
+func (j *Job) Id() string {
+	j.mutex.Lock()
+	defer j.mutex.Unlock()
+
+	return j.status.Id
+}
+
+// Cancel stops loading repositories. The job completes with a report of the repositories loaded so far, which
+// is flagged as incomplete. Cancelling a job that has finished has no effect.
+func (j *Job) Cancel() {
+	j.cancel()
+}
+
+// Status returns the state of the job. If includeReport is true, the report is included, which is a partial
+// report of the repositories loaded so far if the job is still running. The partial report is built without
+// holding the lock, so the job keeps loading repositories while it is built.
+func (j *Job) Status(includeReport bool) models.AnalysisJob {
+	status, loaded := j.snapshot(includeReport)
+	if len(loaded) == 0 {
+		return status
+	}
+
+	partial := workflows.GenerateReportFromRepoActions(loaded, workflows.ReportOptions{})
+	status.Report = &partial
+
+	j.keepPartial(&partial, len(loaded))
+
+	return status
+}
+
+// snapshot returns a copy of the state of the job, and the repositories to build a partial report from if the
+// report is included and there is no report yet.
+func (j *Job) snapshot(includeReport bool) (models.AnalysisJob, []workflows.RepoActions) {
+	j.mutex.Lock()
+	defer j.mutex.Unlock()
+
+	status := j.status
+	status.Repositories = slices.Clone(j.status.Repositories)
+
+	switch {
+	case !includeReport:
+		status.Report = nil
+	case status.Report == nil && j.partial != nil:
+		status.Report = j.partial
+	case status.Report == nil:
+		return status, slices.Clone(j.loaded)
+	}
+
+	return status, nil
+}
+
+// keepPartial keeps the partial report of the first loadedCount repositories, unless more repositories were
+// loaded, or the job finished, while it was built.
+func (j *Job) keepPartial(partial *models.Report, loadedCount int) {
+	j.mutex.Lock()
+	defer j.mutex.Unlock()
+
+	if j.status.Report == nil && len(j.loaded) == loadedCount {
+		j.partial = partial
+	}
+}
+
+// Events returns the events with an id greater than afterId, and a channel that is closed when there are
+// new events.
+func (j *Job) Events(afterId int) ([]models.JobEvent, <-chan struct{}) {
+	j.mutex.Lock()
+	defer j.mutex.Unlock()
+
+	events := []models.JobEvent{}
+	for _, event := range j.events {
+		if event.Id > afterId {
+			events = append(events, event)
+		}
+	}
+
+	return events, j.changed
+}
+
+func (j *Job) run(ctx context.Context, getRepos GetReposFunc, generateReport GenerateReportFunc) {
+	defer j.cancel()
+	defer func() {
+		// A job that panics must not take down the server, and must still finish
+		if r := recover(); r != nil {
+			j.fail(fmt.Sprint(r))
+		}
+	}()
+
+	repos, err := getRepos(ctx)
+	if err != nil {
+		j.fail(err.Error())
+		return
+	}
+
+	j.start(repos)
+
+	report := generateReport(ctx, repos, j.progress)
+
+	j.complete(report)
+}
+
+func (j *Job) start(repos []string) {
+	j.mutex.Lock()
+	defer j.mutex.Unlock()
+
+	j.status.Repositories = slices.Clone(repos)
+	j.status.Total = len(repos)
+	j.addEvent(models.JobEvent{Type: models.JobEventStarted, Total: len(repos)})
+}
+
+func (j *Job) progress(repoActions workflows.RepoActions, completed int, total int) {
+	repoStatus := workflows.GetRepoStatuses([]workflows.RepoActions{repoActions})[repoActions.Repo]
+
+	j.mutex.Lock()
+	defer j.mutex.Unlock()
+
+	j.loaded = append(j.loaded, repoActions)
+	j.partial = nil
+	j.status.Completed = completed
+	j.status.Total = total
+	j.addEvent(models.JobEvent{
+		Type:       models.JobEventProgress,
+		Repo:       repoActions.Repo,
+		RepoStatus: repoStatus.Status,
+		Completed:  completed,
+		Total:      total,
+	})
+}
+
+func (j *Job) complete(report models.Report) {
+	j.mutex.Lock()
+	defer j.mutex.Unlock()
+
+	j.status.Status = models.JobStatusCompleted
+	j.status.Report = &report
+	j.status.Completed = j.status.Total
+	// The loaded repositories are only needed for partial reports
+	j.loaded = nil
+	j.partial = nil
+	j.addEvent(models.JobEvent{Type: models.JobEventCompleted, Completed: j.status.Completed, Total: j.status.Total})
+}
+
+func (j *Job) fail(message string) {
+	j.mutex.Lock()
+	defer j.mutex.Unlock()
+
+	j.status.Status = models.JobStatusFailed
+	j.status.Error = message
+	j.addEvent(models.JobEvent{Type: models.JobEventFailed, Completed: j.status.Completed, Total: j.status.Total, Error: message})
+}
+
+// addEvent records the event and wakes up anyone waiting for events. The mutex must be held.
+func (j *Job) addEvent(event models.JobEvent) {
+	event.Id = len(j.events) + 1
+	j.events = append(j.events, event)
+	j.status.UpdatedAt = j.now()
+
+	close(j.changed)
+	j.changed = make(chan struct{})
+}
+
+// IsFinished returns true if the event is the last event of a job.
+func IsFinished(event models.JobEvent) bool {
+	return event.Type == models.JobEventCompleted || event.Type == models.JobEventFailed
+}
+
+// NewJobId returns a random id that can't be guessed.
+func NewJobId() string {
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		panic(err)
+	}
+	return hex.EncodeToString(id)
+}
@@ -0,0 +1,244 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/OctopusSolutionsEngineering/DuplicationCostCalculator/internal/domain/models"
+	"github.com/OctopusSolutionsEngineering/DuplicationCostCalculator/internal/domain/workflows"
+)
+
+// waitForEvent waits until the job has an event of the type, and returns all the events so far.
+func waitForEvent(t *testing.T, job *Job, eventType string) []models.JobEvent {
+	t.Helper()
+
+	timeout := time.After(5 * time.Second)
+	for {
+		events, changed := job.Events(0)
+		for _, event := range events {
+			if event.Type == eventType {
+				return events
+			}
+		}
+
+		select {
+		case <-changed:
+		case <-timeout:
+			t.Fatalf("Timed out waiting for a %s event, got %+v", eventType, events)
+		}
+	}
+}
+
+func TestManager_Start(t *testing.T) {
+	// Arrange
+	manager := NewManager()
+	release := make(chan struct{})
+
+	generateReport := func(ctx context.Context, repos []string, progress workflows.ProgressFunc) models.Report {
+		progress(workflows.RepoActions{Repo: "owner/repo1", Workflows: []string{"jobs: {}"}}, 1, len(repos))
+		<-release
+		progress(workflows.RepoActions{Repo: "owner/repo2"}, 2, len(repos))
+		return models.Report{NumberOfRepos: 2}
+	}
+
+	// Act
+	job := manager.Start("owner-identity", func(ctx context.Context) ([]string, error) {
+		return []string{"owner/repo1", "owner/repo2"}, nil
+	}, generateReport)
+
+	// Assert - the job reports progress while it is running
+	events := waitForEvent(t, job, models.JobEventProgress)
+
+	status := job.Status(true)
+	if status.Status != models.JobStatusRunning || status.Completed != 1 || status.Total != 2 {
+		t.Errorf("Unexpected running status %+v", status)
+	}
+
+	if status.Report == nil || status.Report.NumberOfRepos != 1 {
+		t.Errorf("Expected a partial report of the loaded repository, got %+v", status.Report)
+	}
+
+	if events[0].Type != models.JobEventStarted || events[0].Total != 2 || events[1].Repo != "owner/repo1" || events[1].RepoStatus != models.RepoStatusOk {
+		t.Errorf("Unexpected events %+v", events)
+	}
+
+	close(release)
+	events = waitForEvent(t, job, models.JobEventCompleted)
+
+	status = job.Status(true)
+	if status.Status != models.JobStatusCompleted || status.Completed != 2 || status.Report.NumberOfRepos != 2 {
+		t.Errorf("Unexpected completed status %+v", status)
+	}
+
+	if len(events) != 4 || events[2].RepoStatus != models.RepoStatusNoWorkflows {
+		t.Errorf("Unexpected events %+v", events)
+	}
+
+	for i, event := range events {
+		if event.Id != i+1 {
+			t.Errorf("Expected event %d to have id %d, got %d", i, i+1, event.Id)
+		}
+	}
+
+	if status := job.Status(false); status.Report != nil {
+		t.Errorf("Expected the report to be excluded, got %+v", status.Report)
+	}
+}
+
+func TestJob_StatusDoesNotKeepStalePartialReport(t *testing.T) {
+	// Arrange
+	job := &Job{now: time.Now, changed: make(chan struct{})}
+	job.progress(workflows.RepoActions{Repo: "owner/repo1", Workflows: []string{"jobs: {}"}}, 1, 2)
+
+	_, loaded := job.snapshot(true)
+	partial := workflows.GenerateReportFromRepoActions(loaded, workflows.ReportOptions{})
+
+	// Act - another repository is loaded while the partial report is built
+	job.progress(workflows.RepoActions{Repo: "owner/repo2", Workflows: []string{"jobs: {}"}}, 2, 2)
+	job.keepPartial(&partial, len(loaded))
+
+	// Assert
+	if status := job.Status(true); status.Report == nil || status.Report.NumberOfRepos != 2 {
+		t.Errorf("Expected a partial report of both repositories, got %+v", status.Report)
+	}
+}
+
+func TestManager_Failed(t *testing.T) {
+	// Arrange
+	manager := NewManager()
+
+	// Act
+	job := manager.Start("owner-identity", func(ctx context.Context) ([]string, error) {
+		return []string{"owner/repo1"}, nil
+	}, func(ctx context.Context, repos []string, progress workflows.ProgressFunc) models.Report {
+		panic("unexpected response")
+	})
+
+	// Assert
+	events := waitForEvent(t, job, models.JobEventFailed)
+
+	if status := job.Status(true); status.Status != models.JobStatusFailed || status.Error != "unexpected response" {
+		t.Errorf("Unexpected failed status %+v", status)
+	}
+
+	if !IsFinished(events[len(events)-1]) {
+		t.Errorf("Expected the last event to finish the job, got %+v", events)
+	}
+}
+
+func TestManager_FailedToListRepositories(t *testing.T) {
+	// Arrange
+	manager := NewManager()
+
+	// Act
+	job := manager.Start("owner-identity", func(ctx context.Context) ([]string, error) {
+		return []string{}, errors.New("listing the repositories of org:typo: 404 Not Found")
+	}, func(ctx context.Context, repos []string, progress workflows.ProgressFunc) models.Report {
+		t.Error("Expected no report when the repositories can't be listed")
+		return models.Report{}
+	})
+
+	// Assert
+	waitForEvent(t, job, models.JobEventFailed)
+
+	if status := job.Status(true); status.Status != models.JobStatusFailed || status.Error != "listing the repositories of org:typo: 404 Not Found" {
+		t.Errorf("Unexpected failed status %+v", status)
+	}
+}
+
+func TestJob_Cancel(t *testing.T) {
+	// Arrange
+	manager := NewManager()
+
+	job := manager.Start("owner-identity", func(ctx context.Context) ([]string, error) {
+		return []string{"owner/repo1", "owner/repo2"}, nil
+	}, func(ctx context.Context, repos []string, progress workflows.ProgressFunc) models.Report {
+		progress(workflows.RepoActions{Repo: "owner/repo1"}, 1, len(repos))
+		<-ctx.Done()
+		return models.Report{NumberOfRepos: 1, Metadata: models.ReportMetadata{Incomplete: true}}
+	})
+	waitForEvent(t, job, models.JobEventProgress)
+
+	// Act
+	job.Cancel()
+
+	// Assert
+	waitForEvent(t, job, models.JobEventCompleted)
+
+	if status := job.Status(true); status.Report == nil || !status.Report.Metadata.Incomplete {
+		t.Errorf("Expected an incomplete report of the loaded repositories, got %+v", status)
+	}
+}
+
+func TestManager_Get(t *testing.T) {
+	// Arrange
+	manager := NewManager()
+	job := manager.Start("owner-identity", func(ctx context.Context) ([]string, error) { return []string{}, nil }, func(ctx context.Context, repos []string, progress workflows.ProgressFunc) models.Report {
+		return models.Report{}
+	})
+
+	// Act & Assert
+	if found, ok := manager.Get("owner-identity", job.Id()); !ok || found != job {
+		t.Error("Expected the owner to find the job")
+	}
+
+	if _, ok := manager.Get("other-identity", job.Id()); ok {
+		t.Error("Expected other users not to find the job")
+	}
+
+	if _, ok := manager.Get("owner-identity", "missing"); ok {
+		t.Error("Expected an unknown id not to be found")
+	}
+}
+
+func TestManager_RemovesExpiredJobs(t *testing.T) {
+	// Arrange
+	now := time.Now()
+	manager := NewManager()
+	manager.now = func() time.Time { return now }
+
+	job := manager.Start("owner-identity", func(ctx context.Context) ([]string, error) { return []string{}, nil }, func(ctx context.Context, repos []string, progress workflows.ProgressFunc) models.Report {
+		return models.Report{}
+	})
+	waitForEvent(t, job, models.JobEventCompleted)
+
+	// Act
+	now = now.Add(JobRetention + time.Minute)
+	manager.Start("owner-identity", func(ctx context.Context) ([]string, error) { return []string{}, nil }, func(ctx context.Context, repos []string, progress workflows.ProgressFunc) models.Report {
+		return models.Report{}
+	})
+
+	// Assert
+	if _, ok := manager.Get("owner-identity", job.Id()); ok {
+		t.Error("Expected the expired job to be removed")
+	}
+}
+
+func TestEvents_AfterId(t *testing.T) {
+	// Arrange
+	manager := NewManager()
+	job := manager.Start("owner-identity", func(ctx context.Context) ([]string, error) { return []string{"owner/repo1"}, nil }, func(ctx context.Context, repos []string, progress workflows.ProgressFunc) models.Report {
+		progress(workflows.RepoActions{Repo: "owner/repo1"}, 1, 1)
+		return models.Report{}
+	})
+	waitForEvent(t, job, models.JobEventCompleted)
+
+	// Act
+	events, _ := job.Events(2)
+
+	// Assert
+	if len(events) != 1 || events[0].Type != models.JobEventCompleted {
+		t.Errorf("Expected only the events after id 2, got %+v", events)
+	}
+}
+
+func TestNewJobId(t *testing.T) {
+	id1 := NewJobId()
+	id2 := NewJobId()
+
+	if len(id1) != 32 || id1 == id2 {
+		t.Errorf("Expected unique 32 character ids, got %s and %s", id1, id2)
+	}
+}
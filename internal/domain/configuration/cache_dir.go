@@ -0,0 +1,10 @@
+package configuration
+
+import "os"
+
+const DUPCOST_CACHE_DIR = "DUPCOST_CACHE_DIR"
+
+// GetCacheDir returns the directory GitHub API responses are cached in. Responses are not cached if it is empty.
+func GetCacheDir() string {
+	return os.Getenv(DUPCOST_CACHE_DIR)
+}
@@ -0,0 +1,39 @@
+package configuration
+
+import (
+	"testing"
+)
+
+func TestGetCacheDir(t *testing.T) {
+	tests := []struct {
+		name     string
+		envValue string
+		expected string
+	}{
+		{
+			name:     "absolute path",
+			envValue: "/var/cache/dupcost",
+			expected: "/var/cache/dupcost",
+		},
+		{
+			name:     "relative path",
+			envValue: "./cache",
+			expected: "./cache",
+		},
+		{
+			name:     "disabled",
+			envValue: "",
+			expected: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(DUPCOST_CACHE_DIR, tt.envValue)
+
+			if result := GetCacheDir(); result != tt.expected {
+				t.Errorf("GetCacheDir() = %q, expected %q", result, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,15 @@
+package configuration
+
+import (
+	"os"
+	"strconv"
+)
+
+const DUPCOST_ENABLE_PULL_REQUESTS = "DUPCOST_ENABLE_PULL_REQUESTS"
+
+// GetEnablePullRequests returns true if the server can open pull requests that align action versions. It is off by
+// default, as the pull requests change the analyzed repositories.
+func GetEnablePullRequests() bool {
+	enabled, err := strconv.ParseBool(os.Getenv(DUPCOST_ENABLE_PULL_REQUESTS))
+	return err == nil && enabled
+}
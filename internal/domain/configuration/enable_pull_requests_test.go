@@ -0,0 +1,30 @@
+package configuration
+
+import (
+	"testing"
+)
+
+func TestGetEnablePullRequests(t *testing.T) {
+	tests := []struct {
+		name     string
+		envValue string
+		expected bool
+	}{
+		{name: "true", envValue: "true", expected: true},
+		{name: "one", envValue: "1", expected: true},
+		{name: "uppercase", envValue: "TRUE", expected: true},
+		{name: "false", envValue: "false", expected: false},
+		{name: "empty", envValue: "", expected: false},
+		{name: "invalid", envValue: "yes please", expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(DUPCOST_ENABLE_PULL_REQUESTS, tt.envValue)
+
+			if result := GetEnablePullRequests(); result != tt.expected {
+				t.Errorf("GetEnablePullRequests() = %v, expected %v", result, tt.expected)
+			}
+		})
+	}
+}
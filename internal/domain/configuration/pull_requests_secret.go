@@ -0,0 +1,11 @@
+package configuration
+
+import "os"
+
+const DUPCOST_PULL_REQUESTS_SECRET = "DUPCOST_PULL_REQUESTS_SECRET"
+
+// GetPullRequestsSecret returns the secret that requests to open pull requests must include. The server opens pull
+// requests as the GitHub App, not as the user, so the secret limits who can change the analyzed repositories.
+func GetPullRequestsSecret() string {
+	return os.Getenv(DUPCOST_PULL_REQUESTS_SECRET)
+}
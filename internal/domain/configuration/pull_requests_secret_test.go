@@ -0,0 +1,26 @@
+package configuration
+
+import (
+	"testing"
+)
+
+func TestGetPullRequestsSecret(t *testing.T) {
+	tests := []struct {
+		name     string
+		envValue string
+		expected string
+	}{
+		{name: "set", envValue: "a-long-random-secret", expected: "a-long-random-secret"},
+		{name: "empty", envValue: "", expected: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(DUPCOST_PULL_REQUESTS_SECRET, tt.envValue)
+
+			if result := GetPullRequestsSecret(); result != tt.expected {
+				t.Errorf("GetPullRequestsSecret() = %q, expected %q", result, tt.expected)
+			}
+		})
+	}
+}
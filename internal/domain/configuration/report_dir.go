@@ -0,0 +1,23 @@
+package configuration
+
+import (
+	"os"
+	"path/filepath"
+)
+
+const DUPCOST_REPORT_DIR = "DUPCOST_REPORT_DIR"
+
+// GetReportDir returns the directory the report history is saved in. It defaults to a directory in the user's
+// configuration directory.
+func GetReportDir() string {
+	if dir := os.Getenv(DUPCOST_REPORT_DIR); dir != "" {
+		return dir
+	}
+
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		return "dupcost-reports"
+	}
+
+	return filepath.Join(configDir, "dupcost", "reports")
+}
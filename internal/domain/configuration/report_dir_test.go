@@ -0,0 +1,44 @@
+package configuration
+
+import (
+	"testing"
+)
+
+func TestGetReportDir(t *testing.T) {
+	tests := []struct {
+		name          string
+		envValue      string
+		xdgConfigHome string
+		expected      string
+	}{
+		{
+			name:          "absolute path",
+			envValue:      "/var/lib/dupcost/reports",
+			xdgConfigHome: "/home/user/.config",
+			expected:      "/var/lib/dupcost/reports",
+		},
+		{
+			name:          "relative path",
+			envValue:      "./reports",
+			xdgConfigHome: "/home/user/.config",
+			expected:      "./reports",
+		},
+		{
+			name:          "default",
+			envValue:      "",
+			xdgConfigHome: "/home/user/.config",
+			expected:      "/home/user/.config/dupcost/reports",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(DUPCOST_REPORT_DIR, tt.envValue)
+			t.Setenv("XDG_CONFIG_HOME", tt.xdgConfigHome)
+
+			if result := GetReportDir(); result != tt.expected {
+				t.Errorf("GetReportDir() = %q, expected %q", result, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,53 @@
+package formatting
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/OctopusSolutionsEngineering/DuplicationCostCalculator/internal/domain/models"
+)
+
+var csvHeader = []string{
+	"repo1",
+	"repo2",
+	"stepsThatIndicateDuplicationRisk",
+	"stepsWithDifferentVersionsCount",
+	"stepsWithSimilarConfigCount",
+	"similarScriptsCount",
+	"highestDriftSeverity",
+	"versionDrift",
+	"stepsWithSimilarConfig",
+}
+
+// WriteCsv writes one row for each pair of repositories that were compared. Columns with more than one value,
+// like the version drift, separate the values with semicolons.
+func WriteCsv(writer io.Writer, report models.Report) error {
+	csvWriter := csv.NewWriter(writer)
+
+	if err := csvWriter.Write(csvHeader); err != nil {
+		return err
+	}
+
+	for _, pair := range report.GetRepoPairs() {
+		measurements := pair.Measurements
+		err := csvWriter.Write([]string{
+			pair.Repo1,
+			pair.Repo2,
+			strconv.Itoa(measurements.StepsThatIndicateDuplicationRisk),
+			strconv.Itoa(measurements.StepsWithDifferentVersionsCount),
+			strconv.Itoa(measurements.StepsWithSimilarConfigCount),
+			strconv.Itoa(measurements.SimilarScriptsCount),
+			measurements.HighestDriftSeverity,
+			strings.Join(formatDrift(measurements.VersionDrift), "; "),
+			strings.Join(measurements.StepsWithSimilarConfig, "; "),
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	csvWriter.Flush()
+	return csvWriter.Error()
+}
@@ -0,0 +1,39 @@
+package formatting
+
+import (
+	"bytes"
+	"encoding/csv"
+	"slices"
+	"testing"
+)
+
+func TestWriteCsv(t *testing.T) {
+	// Arrange
+	buffer := bytes.Buffer{}
+
+	// Act
+	err := WriteCsv(&buffer, newTestReport())
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Failed to write the report: %v", err)
+	}
+
+	rows, err := csv.NewReader(&buffer).ReadAll()
+	if err != nil {
+		t.Fatalf("Failed to parse the CSV: %v", err)
+	}
+
+	if len(rows) != 4 || !slices.Equal(rows[0], csvHeader) {
+		t.Fatalf("Expected a header and one row per repo pair, got %v", rows)
+	}
+
+	expected := []string{"owner/repo1", "owner/repo2", "2", "1", "1", "0", "major", "actions/checkout v4 vs v3 (major)", "azure/webapps-deploy"}
+	if !slices.Equal(rows[1], expected) {
+		t.Errorf("Row = %v, expected %v", rows[1], expected)
+	}
+
+	if rows[3][0] != "owner/repo2" || rows[3][1] != "owner/repo3" || rows[3][2] != "0" {
+		t.Errorf("Unexpected row %v", rows[3])
+	}
+}
@@ -0,0 +1,77 @@
+package formatting
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/OctopusSolutionsEngineering/DuplicationCostCalculator/internal/domain/models"
+)
+
+const FormatText = "text"
+const FormatJson = "json"
+const FormatCsv = "csv"
+const FormatMarkdown = "md"
+const FormatHtml = "html"
+
+// Formats are the formats a report can be written in. The text format is the human readable output of the CLI,
+// and is not written by this package.
+var Formats = []string{FormatText, FormatJson, FormatCsv, FormatMarkdown, FormatHtml}
+
+// ParseFormat returns the format with the name, accepting "markdown" as well as "md". ok is false for an
+// unknown format.
+func ParseFormat(name string) (format string, ok bool) {
+	format = strings.ToLower(strings.TrimSpace(name))
+	if format == "markdown" {
+		format = FormatMarkdown
+	}
+
+	return format, slices.Contains(Formats, format)
+}
+
+// WriteReport writes the report to the writer in one of the machine readable formats.
+func WriteReport(writer io.Writer, format string, report models.Report) error {
+	switch format {
+	case FormatJson:
+		return WriteJson(writer, report)
+	case FormatCsv:
+		return WriteCsv(writer, report)
+	case FormatMarkdown:
+		return WriteMarkdown(writer, report)
+	case FormatHtml:
+		return WriteHtml(writer, report)
+	}
+
+	return fmt.Errorf("the report can not be written as %q", format)
+}
+
+// WriteJson writes the report in the same shape as the response of the /cost API.
+func WriteJson(writer io.Writer, report models.Report) error {
+	encoder := json.NewEncoder(writer)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(report)
+}
+
+// getFailedRepos returns the repositories that were not completely loaded, sorted by repository.
+func getFailedRepos(report models.Report) []string {
+	return slices.DeleteFunc(slices.Sorted(maps.Keys(report.RepoStatus)), func(repo string) bool {
+		status := report.RepoStatus[repo].Status
+		return status == models.RepoStatusOk || status == models.RepoStatusNoWorkflows
+	})
+}
+
+func formatDrift(drift []models.VersionDrift) []string {
+	result := make([]string, 0, len(drift))
+	for _, item := range drift {
+		result = append(result, item.Uses+" "+item.Version1+" vs "+item.Version2+" ("+item.Severity+")")
+	}
+
+	return result
+}
+
+func formatDollars(amount float64) string {
+	return fmt.Sprintf("$%.2f", amount)
+}
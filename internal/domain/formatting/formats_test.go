@@ -0,0 +1,143 @@
+package formatting
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/OctopusSolutionsEngineering/DuplicationCostCalculator/internal/domain/models"
+)
+
+// newTestReport returns a report of three repositories, where repo1 and repo2 have drift, and repo3 failed to load.
+func newTestReport() models.Report {
+	drift := models.RepoMeasurements{
+		StepsWithDifferentVersions:       []string{"actions/checkout"},
+		StepsWithDifferentVersionsCount:  1,
+		StepsWithSimilarConfig:           []string{"azure/webapps-deploy"},
+		StepsWithSimilarConfigCount:      1,
+		StepsThatIndicateDuplicationRisk: 2,
+		VersionDrift:                     []models.VersionDrift{{Uses: "actions/checkout", Version1: "v4", Version2: "v3", Severity: "major"}},
+		HighestDriftSeverity:             "major",
+	}
+
+	return models.Report{
+		NumberOfRepos:                               3,
+		NumberOfReposWithDuplicationOrDrift:         2,
+		WeightedNumberOfReposWithDuplicationOrDrift: 2,
+		Comparisons: map[string]map[string]models.RepoMeasurements{
+			"owner/repo1": {"owner/repo2": drift, "owner/repo3": {}},
+			"owner/repo2": {"owner/repo1": drift, "owner/repo3": {}},
+			"owner/repo3": {"owner/repo1": {}, "owner/repo2": {}},
+		},
+		Clusters: []models.ActionCluster{{
+			Uses:      "azure/webapps-deploy",
+			Repos:     []string{"owner/repo1", "owner/repo2"},
+			Workflows: []string{"owner/repo1/deploy.yml", "owner/repo2/deploy.yml"},
+			Members:   []models.ActionClusterMember{{Repo: "owner/repo1"}, {Repo: "owner/repo2"}},
+		}},
+		RepoStatus: map[string]models.RepoStatus{
+			"owner/repo1": {Status: models.RepoStatusOk},
+			"owner/repo2": {Status: models.RepoStatusNoWorkflows},
+			"owner/repo3": {Status: models.RepoStatusFailed, Errors: []models.RepoError{{Category: models.ErrorCategoryNotFound, Operation: "list workflows", Message: "404 Not Found"}}},
+		},
+		Suppressed: []models.SuppressedFinding{{
+			Repo1:        "owner/repo1",
+			Repo2:        "owner/repo2",
+			Uses:         "actions/setup-node",
+			VersionDrift: []models.VersionDrift{{Uses: "actions/setup-node", Version1: "v4", Version2: "v3", Severity: "major"}},
+			Suppression:  models.Suppression{Action: "actions/setup-node", Expires: "2025-12-31", Reason: "Migrating to Node 20"},
+		}},
+		SecurityFindings: []models.SecurityFinding{{
+			Repo:       "owner/repo1",
+			Workflow:   "deploy.yml",
+			Line:       12,
+			Uses:       "azure/webapps-deploy",
+			Version:    "main",
+			Kind:       models.ReferenceBranch,
+			Severity:   models.SecuritySeverityCritical,
+			ThirdParty: true,
+		}},
+		Cost: models.CostBreakdown{
+			Model:         models.CostModel{HoursPerRepo: 4},
+			HourlyRate:    41.1,
+			WeightedRepos: 2,
+			RepoHours:     8,
+			TotalHours:    8,
+			Total:         328.8,
+		},
+	}
+}
+
+func TestParseFormat(t *testing.T) {
+	tests := []struct {
+		name           string
+		expectedFormat string
+		expectedOk     bool
+	}{
+		{"json", FormatJson, true},
+		{"CSV", FormatCsv, true},
+		{"md", FormatMarkdown, true},
+		{"markdown", FormatMarkdown, true},
+		{" html ", FormatHtml, true},
+		{"text", FormatText, true},
+		{"xml", "xml", false},
+		{"", "", false},
+	}
+
+	for _, tt := range tests {
+		format, ok := ParseFormat(tt.name)
+		if format != tt.expectedFormat || ok != tt.expectedOk {
+			t.Errorf("ParseFormat(%q) = (%q, %v), expected (%q, %v)", tt.name, format, ok, tt.expectedFormat, tt.expectedOk)
+		}
+	}
+}
+
+func TestGetRepoPairs(t *testing.T) {
+	pairs := newTestReport().GetRepoPairs()
+
+	expected := [][2]string{{"owner/repo1", "owner/repo2"}, {"owner/repo1", "owner/repo3"}, {"owner/repo2", "owner/repo3"}}
+	if len(pairs) != len(expected) {
+		t.Fatalf("Expected each pair once, got %+v", pairs)
+	}
+
+	for i, pair := range pairs {
+		if pair.Repo1 != expected[i][0] || pair.Repo2 != expected[i][1] {
+			t.Errorf("Pair %d = %s vs %s, expected %s vs %s", i, pair.Repo1, pair.Repo2, expected[i][0], expected[i][1])
+		}
+	}
+
+	if pairs[0].Measurements.StepsThatIndicateDuplicationRisk != 2 {
+		t.Errorf("Unexpected measurements %+v", pairs[0].Measurements)
+	}
+}
+
+func TestWriteReportJson(t *testing.T) {
+	// Arrange
+	report := newTestReport()
+	buffer := bytes.Buffer{}
+
+	// Act
+	err := WriteReport(&buffer, FormatJson, report)
+
+	// Assert - the report has the same shape as the /cost response
+	if err != nil {
+		t.Fatalf("Failed to write the report: %v", err)
+	}
+
+	var result models.Report
+	if err := json.Unmarshal(buffer.Bytes(), &result); err != nil {
+		t.Fatalf("Failed to parse the report: %v", err)
+	}
+
+	if result.NumberOfRepos != 3 || result.Cost.Total != report.Cost.Total || len(result.Comparisons["owner/repo1"]) != 2 {
+		t.Errorf("Unexpected report %+v", result)
+	}
+}
+
+func TestWriteReportUnknownFormat(t *testing.T) {
+	for _, format := range []string{FormatText, "xml"} {
+		if err := WriteReport(&bytes.Buffer{}, format, newTestReport()); err == nil {
+			t.Errorf("Expected writing the %q format to fail", format)
+		}
+	}
+}
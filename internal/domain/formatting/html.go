@@ -0,0 +1,35 @@
+package formatting
+
+import (
+	_ "embed"
+	"html/template"
+	"io"
+	"strconv"
+
+	"github.com/OctopusSolutionsEngineering/DuplicationCostCalculator/internal/domain/models"
+	"github.com/samber/lo"
+)
+
+//go:embed report.html
+var htmlTemplateSource string
+
+var htmlTemplate = template.Must(template.New("report").Funcs(template.FuncMap{
+	"dollars": formatDollars,
+	"number": func(number float64) string {
+		return strconv.FormatFloat(number, 'f', -1, 64)
+	},
+}).Parse(htmlTemplateSource))
+
+// WriteHtml writes the report as a self-contained HTML page, with no scripts or external stylesheets, so it can be
+// attached to a build or opened offline.
+func WriteHtml(writer io.Writer, report models.Report) error {
+	return htmlTemplate.Execute(writer, struct {
+		Report      models.Report
+		Pairs       []models.RepoPair
+		FailedRepos map[string]models.RepoStatus
+	}{
+		Report:      report,
+		Pairs:       report.GetRepoPairs(),
+		FailedRepos: lo.PickByKeys(report.RepoStatus, getFailedRepos(report)),
+	})
+}
@@ -0,0 +1,57 @@
+package formatting
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/OctopusSolutionsEngineering/DuplicationCostCalculator/internal/domain/models"
+)
+
+func TestWriteHtml(t *testing.T) {
+	// Arrange
+	report := newTestReport()
+	report.RepoStatus["owner/repo3"] = models.RepoStatus{
+		Status: models.RepoStatusFailed,
+		Errors: []models.RepoError{{Category: models.ErrorCategoryParseError, Operation: "load workflow build.yml", Message: "<script>alert(1)</script>"}},
+	}
+	buffer := bytes.Buffer{}
+
+	// Act
+	err := WriteHtml(&buffer, report)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Failed to write the report: %v", err)
+	}
+
+	page := buffer.String()
+	expected := []string{
+		"<!DOCTYPE html>",
+		"$328.80",
+		"<td>owner/repo1</td>",
+		"actions/checkout v4 vs v3 (major)",
+		"<td>azure/webapps-deploy</td>",
+		"&lt;script&gt;alert(1)&lt;/script&gt;",
+		"<td>Migrating to Node 20</td>",
+		"<td>deploy.yml</td>",
+		"<td>critical</td>",
+	}
+
+	for _, item := range expected {
+		if !strings.Contains(page, item) {
+			t.Errorf("Expected the HTML to contain %q", item)
+		}
+	}
+
+	// The page is self-contained, and values from the report are escaped
+	for _, item := range []string{"<script>alert(1)", "<link", "src=\"http"} {
+		if strings.Contains(page, item) {
+			t.Errorf("Expected the HTML not to contain %q", item)
+		}
+	}
+
+	if strings.Contains(page, "Incomplete Analysis") {
+		t.Errorf("Expected no incomplete warning for a complete report")
+	}
+}
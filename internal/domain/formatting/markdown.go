@@ -0,0 +1,156 @@
+package formatting
+
+import (
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/OctopusSolutionsEngineering/DuplicationCostCalculator/internal/domain/models"
+)
+
+// WriteMarkdown writes a summary of the report as GitHub flavored Markdown, suitable for pull request comments and
+// wikis.
+func WriteMarkdown(writer io.Writer, report models.Report) error {
+	builder := strings.Builder{}
+
+	builder.WriteString("# Duplication cost report\n\n")
+
+	if report.Metadata.Incomplete {
+		builder.WriteString("> **Warning:** The analysis " + report.Metadata.IncompleteReason + " before every repository was loaded. The results are incomplete.\n\n")
+	}
+
+	builder.WriteString("| Metric | Value |\n|---|---|\n")
+	builder.WriteString(fmt.Sprintf("| Repositories | %d |\n", report.NumberOfRepos))
+	builder.WriteString(fmt.Sprintf("| Repositories with duplication or drift | %d |\n", report.NumberOfReposWithDuplicationOrDrift))
+	builder.WriteString(fmt.Sprintf("| Weighted repositories with duplication or drift | %s |\n", strconv.FormatFloat(report.WeightedNumberOfReposWithDuplicationOrDrift, 'f', -1, 64)))
+	builder.WriteString(fmt.Sprintf("| Repositories using shared workflows | %d |\n", report.NumberOfReposUsingSharedWorkflows))
+	builder.WriteString(fmt.Sprintf("| Cost to make a consistent change | %s |\n", formatDollars(report.Cost.Total)))
+	builder.WriteString(fmt.Sprintf("| Hours to make a consistent change | %s |\n\n", strconv.FormatFloat(report.Cost.TotalHours, 'f', -1, 64)))
+
+	pairs := report.GetRepoPairs()
+	if len(pairs) != 0 {
+		builder.WriteString("## Repository comparisons\n\n")
+		builder.WriteString("| Repository 1 | Repository 2 | Duplication risk | Different versions | Similar config | Similar scripts | Highest drift |\n")
+		builder.WriteString("|---|---|---|---|---|---|---|\n")
+		for _, pair := range pairs {
+			measurements := pair.Measurements
+			builder.WriteString(fmt.Sprintf("| %s | %s | %d | %d | %d | %d | %s |\n",
+				escapeMarkdown(pair.Repo1),
+				escapeMarkdown(pair.Repo2),
+				measurements.StepsThatIndicateDuplicationRisk,
+				measurements.StepsWithDifferentVersionsCount,
+				measurements.StepsWithSimilarConfigCount,
+				measurements.SimilarScriptsCount,
+				escapeMarkdown(measurements.HighestDriftSeverity)))
+		}
+		builder.WriteString("\n")
+	}
+
+	driftPairs := slices.DeleteFunc(slices.Clone(pairs), func(pair models.RepoPair) bool {
+		return len(pair.Measurements.VersionDrift) == 0
+	})
+	if len(driftPairs) != 0 {
+		builder.WriteString("## Version drift\n\n")
+		for _, pair := range driftPairs {
+			builder.WriteString("- " + escapeMarkdown(pair.Repo1) + " vs " + escapeMarkdown(pair.Repo2) + "\n")
+			for _, drift := range formatDrift(pair.Measurements.VersionDrift) {
+				builder.WriteString("  - " + escapeMarkdown(drift) + "\n")
+			}
+		}
+		builder.WriteString("\n")
+	}
+
+	if len(report.Clusters) != 0 {
+		builder.WriteString("## Duplicated steps\n\n")
+		builder.WriteString("| Action | Repositories | Steps |\n|---|---|---|\n")
+		for _, cluster := range report.Clusters {
+			builder.WriteString(fmt.Sprintf("| %s | %d | %d |\n", escapeMarkdown(cluster.Uses), len(cluster.Repos), len(cluster.Members)))
+		}
+		builder.WriteString("\n")
+	}
+
+	staleRepos := slices.Sorted(maps.Keys(report.StaleActions))
+	staleRepos = slices.DeleteFunc(staleRepos, func(repo string) bool {
+		return len(report.StaleActions[repo]) == 0
+	})
+	if len(staleRepos) != 0 {
+		builder.WriteString("## Stale actions\n\n")
+		for _, repo := range staleRepos {
+			builder.WriteString("- " + escapeMarkdown(repo) + "\n")
+			for _, stale := range report.StaleActions[repo] {
+				builder.WriteString("  - " + escapeMarkdown(stale.Uses+" "+stale.CurrentVersion+" -> "+stale.LatestVersion) + "\n")
+			}
+		}
+		builder.WriteString("\n")
+	}
+
+	if len(report.Suppressed) != 0 {
+		builder.WriteString("## Suppressed\n\n")
+		builder.WriteString("| Repository 1 | Repository 2 | Action | Version drift | Similar config | Expires | Reason |\n")
+		builder.WriteString("|---|---|---|---|---|---|---|\n")
+		for _, finding := range report.Suppressed {
+			builder.WriteString(fmt.Sprintf("| %s | %s | %s | %s | %t | %s | %s |\n",
+				escapeMarkdown(finding.Repo1),
+				escapeMarkdown(finding.Repo2),
+				escapeMarkdown(finding.Uses),
+				escapeMarkdown(strings.Join(formatDrift(finding.VersionDrift), ", ")),
+				finding.SimilarConfig,
+				escapeMarkdown(finding.Suppression.Expires),
+				escapeMarkdown(finding.Suppression.Reason)))
+		}
+		builder.WriteString("\n")
+	}
+
+	if len(report.SecurityFindings) != 0 {
+		builder.WriteString("## Actions not pinned to a commit SHA\n\n")
+		builder.WriteString("| Repository | Workflow | Line | Action | Version | Kind | Third party | Severity |\n")
+		builder.WriteString("|---|---|---|---|---|---|---|---|\n")
+		for _, finding := range report.SecurityFindings {
+			builder.WriteString(fmt.Sprintf("| %s | %s | %d | %s | %s | %s | %t | %s |\n",
+				escapeMarkdown(finding.Repo),
+				escapeMarkdown(finding.Workflow),
+				finding.Line,
+				escapeMarkdown(finding.Uses),
+				escapeMarkdown(finding.Version),
+				finding.Kind,
+				finding.ThirdParty,
+				finding.Severity))
+		}
+		builder.WriteString("\n")
+	}
+
+	failedRepos := getFailedRepos(report)
+	if len(failedRepos) != 0 {
+		builder.WriteString("## Repositories that were not completely loaded\n\n")
+		for _, repo := range failedRepos {
+			status := report.RepoStatus[repo]
+			builder.WriteString("- " + escapeMarkdown(repo) + " (" + status.Status + ")\n")
+			for _, repoError := range status.Errors {
+				builder.WriteString("  - [" + repoError.Category + "] " + escapeMarkdown(repoError.Operation+": "+repoError.Message) + "\n")
+			}
+		}
+		builder.WriteString("\n")
+	}
+
+	_, err := io.WriteString(writer, builder.String())
+	return err
+}
+
+// escapeMarkdown escapes the characters that would break a table cell or be treated as formatting, and keeps the
+// value on one line.
+func escapeMarkdown(value string) string {
+	return strings.NewReplacer(
+		"\\", "\\\\",
+		"|", "\\|",
+		"*", "\\*",
+		"_", "\\_",
+		"`", "\\`",
+		"<", "&lt;",
+		">", "&gt;",
+		"\r", " ",
+		"\n", " ",
+	).Replace(value)
+}
@@ -0,0 +1,67 @@
+package formatting
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestWriteMarkdown(t *testing.T) {
+	// Arrange
+	report := newTestReport()
+	report.Metadata.Incomplete = true
+	report.Metadata.IncompleteReason = "timed out"
+	buffer := bytes.Buffer{}
+
+	// Act
+	err := WriteMarkdown(&buffer, report)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Failed to write the report: %v", err)
+	}
+
+	markdown := buffer.String()
+	expected := []string{
+		"# Duplication cost report",
+		"The analysis timed out before every repository was loaded",
+		"| Cost to make a consistent change | $328.80 |",
+		"| owner/repo1 | owner/repo2 | 2 | 1 | 1 | 0 | major |",
+		"  - actions/checkout v4 vs v3 (major)",
+		"| azure/webapps-deploy | 2 | 2 |",
+		"- owner/repo3 (failed)",
+		"  - [not-found] list workflows: 404 Not Found",
+		"| owner/repo1 | owner/repo2 | actions/setup-node | actions/setup-node v4 vs v3 (major) | false | 2025-12-31 | Migrating to Node 20 |",
+		"| owner/repo1 | deploy.yml | 12 | azure/webapps-deploy | main | branch | true | critical |",
+	}
+
+	for _, item := range expected {
+		if !strings.Contains(markdown, item) {
+			t.Errorf("Expected the Markdown to contain %q, got:\n%s", item, markdown)
+		}
+	}
+
+	// Repos that loaded without errors are not listed as failed
+	if strings.Contains(markdown, "owner/repo2 (no-workflows)") {
+		t.Errorf("Expected only repos with errors to be listed, got:\n%s", markdown)
+	}
+}
+
+func TestEscapeMarkdown(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected string
+	}{
+		{"owner/repo", "owner/repo"},
+		{"a|b", "a\\|b"},
+		{"my_action", "my\\_action"},
+		{"<script>", "&lt;script&gt;"},
+		{"line1\nline2", "line1 line2"},
+	}
+
+	for _, tt := range tests {
+		if result := escapeMarkdown(tt.value); result != tt.expected {
+			t.Errorf("escapeMarkdown(%q) = %q, expected %q", tt.value, result, tt.expected)
+		}
+	}
+}
@@ -0,0 +1,50 @@
+package formatting
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/OctopusSolutionsEngineering/DuplicationCostCalculator/internal/domain/models"
+)
+
+// WriteProposalsMarkdown writes the proposed composite actions as a refactoring plan in GitHub flavored Markdown.
+// Each proposal has the action.yml file to publish, and the step that replaces each of the duplicated steps.
+func WriteProposalsMarkdown(writer io.Writer, proposals []models.CompositeActionProposal) error {
+	builder := strings.Builder{}
+
+	builder.WriteString("# Shared composite actions\n\n")
+
+	if len(proposals) == 0 {
+		builder.WriteString("No duplicated steps can be replaced by a shared composite action.\n")
+	}
+
+	for _, proposal := range proposals {
+		builder.WriteString("## " + escapeMarkdown(proposal.Name) + "\n\n")
+		builder.WriteString(fmt.Sprintf("Replaces %d steps that call %s in %d repositories with `%s`.\n\n",
+			len(proposal.Replacements),
+			escapeMarkdown(proposal.Uses),
+			len(proposal.Repos),
+			proposal.Location))
+
+		builder.WriteString("### action.yml\n\n")
+		builder.WriteString("```yaml\n" + proposal.ActionYml + "```\n\n")
+
+		builder.WriteString("### Replacement steps\n\n")
+		for _, replacement := range proposal.Replacements {
+			location := replacement.Repo
+			if replacement.Workflow != "" {
+				location += " " + replacement.Workflow
+			}
+			if replacement.Step != "" {
+				location += " (" + replacement.Step + ")"
+			}
+
+			builder.WriteString(escapeMarkdown(location) + ":\n\n")
+			builder.WriteString("```yaml\n" + replacement.Snippet + "```\n\n")
+		}
+	}
+
+	_, err := io.WriteString(writer, builder.String())
+	return err
+}
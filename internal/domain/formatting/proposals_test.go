@@ -0,0 +1,58 @@
+package formatting
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/OctopusSolutionsEngineering/DuplicationCostCalculator/internal/domain/models"
+)
+
+func TestWriteProposalsMarkdown(t *testing.T) {
+	// Arrange
+	proposals := []models.CompositeActionProposal{
+		{
+			Name:      "setup-node",
+			Uses:      "actions/setup-node",
+			Location:  "owner/shared-actions/setup-node@v1",
+			Repos:     []string{"owner/repo1", "owner/repo2"},
+			ActionYml: "name: setup-node\n",
+			Replacements: []models.StepReplacement{
+				{Repo: "owner/repo1", Workflow: "build.yml", Step: "Setup Node", Snippet: "- uses: owner/shared-actions/setup-node@v1\n"},
+				{Repo: "owner/repo2", Workflow: "ci.yml", Snippet: "- uses: owner/shared-actions/setup-node@v1\n"},
+			},
+		},
+	}
+	buffer := bytes.Buffer{}
+
+	// Act
+	err := WriteProposalsMarkdown(&buffer, proposals)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Failed to write the proposals: %v", err)
+	}
+
+	markdown := buffer.String()
+	expected := []string{
+		"## setup-node",
+		"Replaces 2 steps that call actions/setup-node in 2 repositories with `owner/shared-actions/setup-node@v1`.",
+		"```yaml\nname: setup-node\n```",
+		"owner/repo1 build.yml (Setup Node):\n\n```yaml\n- uses: owner/shared-actions/setup-node@v1\n```",
+		"owner/repo2 ci.yml:",
+	}
+
+	for _, item := range expected {
+		if !strings.Contains(markdown, item) {
+			t.Errorf("Expected the Markdown to contain %q, got:\n%s", item, markdown)
+		}
+	}
+}
+
+func TestWriteProposalsMarkdownEmpty(t *testing.T) {
+	buffer := bytes.Buffer{}
+
+	if err := WriteProposalsMarkdown(&buffer, nil); err != nil || !strings.Contains(buffer.String(), "No duplicated steps") {
+		t.Errorf("Expected a message when there are no proposals, got %q %v", buffer.String(), err)
+	}
+}
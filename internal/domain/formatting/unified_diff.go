@@ -0,0 +1,146 @@
+package formatting
+
+import (
+	"fmt"
+	"strings"
+)
+
+// diffContext is the number of unchanged lines shown around each change in a unified diff.
+const diffContext = 3
+
+type diffOp struct {
+	kind byte
+	line string
+}
+
+// UnifiedDiff returns the changes between two versions of a file in the unified diff format used by git and
+// patch, or an empty string if they are the same.
+func UnifiedDiff(oldName string, newName string, before string, after string) string {
+	if before == after {
+		return ""
+	}
+
+	ops := diffLines(splitLines(before), splitLines(after))
+
+	builder := strings.Builder{}
+	builder.WriteString("--- " + oldName + "\n")
+	builder.WriteString("+++ " + newName + "\n")
+
+	// Each hunk covers a group of changes that are close enough to share their context
+	for start := 0; start < len(ops); {
+		if ops[start].kind == ' ' {
+			start++
+			continue
+		}
+
+		hunkStart := max(start-diffContext, 0)
+		end := start
+		for end < len(ops) {
+			nextChange := end + 1
+			for nextChange < len(ops) && ops[nextChange].kind == ' ' {
+				nextChange++
+			}
+
+			if nextChange == len(ops) || nextChange-end-1 > 2*diffContext {
+				break
+			}
+
+			end = nextChange
+		}
+		hunkEnd := min(end+diffContext+1, len(ops))
+
+		oldLine, newLine := getLineNumbers(ops, hunkStart)
+		oldCount, newCount := 0, 0
+		for _, op := range ops[hunkStart:hunkEnd] {
+			if op.kind != '+' {
+				oldCount++
+			}
+			if op.kind != '-' {
+				newCount++
+			}
+		}
+
+		builder.WriteString(fmt.Sprintf("@@ -%s +%s @@\n", formatRange(oldLine, oldCount), formatRange(newLine, newCount)))
+		for _, op := range ops[hunkStart:hunkEnd] {
+			builder.WriteString(string(op.kind) + op.line + "\n")
+		}
+
+		start = hunkEnd
+	}
+
+	return builder.String()
+}
+
+// diffLines returns the operations that turn the old lines into the new lines, using the longest common
+// subsequence of the lines.
+func diffLines(oldLines []string, newLines []string) []diffOp {
+	lengths := make([][]int, len(oldLines)+1)
+	for i := range lengths {
+		lengths[i] = make([]int, len(newLines)+1)
+	}
+
+	for i := len(oldLines) - 1; i >= 0; i-- {
+		for j := len(newLines) - 1; j >= 0; j-- {
+			if oldLines[i] == newLines[j] {
+				lengths[i][j] = lengths[i+1][j+1] + 1
+			} else {
+				lengths[i][j] = max(lengths[i+1][j], lengths[i][j+1])
+			}
+		}
+	}
+
+	ops := []diffOp{}
+	i, j := 0, 0
+	for i < len(oldLines) || j < len(newLines) {
+		switch {
+		case i < len(oldLines) && j < len(newLines) && oldLines[i] == newLines[j]:
+			ops = append(ops, diffOp{' ', oldLines[i]})
+			i++
+			j++
+		case j == len(newLines) || (i < len(oldLines) && lengths[i+1][j] >= lengths[i][j+1]):
+			ops = append(ops, diffOp{'-', oldLines[i]})
+			i++
+		default:
+			ops = append(ops, diffOp{'+', newLines[j]})
+			j++
+		}
+	}
+
+	return ops
+}
+
+// getLineNumbers returns the 1-based line numbers in the old and new files of the operation at the index.
+func getLineNumbers(ops []diffOp, index int) (int, int) {
+	oldLine, newLine := 1, 1
+	for _, op := range ops[:index] {
+		if op.kind != '+' {
+			oldLine++
+		}
+		if op.kind != '-' {
+			newLine++
+		}
+	}
+
+	return oldLine, newLine
+}
+
+// formatRange formats the start and length of a hunk. An empty range starts at the line before it.
+func formatRange(start int, count int) string {
+	if count == 0 {
+		return fmt.Sprintf("%d,0", start-1)
+	}
+
+	if count == 1 {
+		return fmt.Sprintf("%d", start)
+	}
+
+	return fmt.Sprintf("%d,%d", start, count)
+}
+
+func splitLines(content string) []string {
+	if content == "" {
+		return []string{}
+	}
+
+	return strings.Split(strings.TrimSuffix(content, "\n"), "\n")
+}
@@ -0,0 +1,32 @@
+package formatting
+
+import "testing"
+
+func TestUnifiedDiff(t *testing.T) {
+	before := "line1\nline2\nline3\nline4\nline5\nline6\nline7\nline8\nline9\nline10\nline11\nline12\n"
+
+	tests := []struct {
+		name     string
+		after    string
+		expected string
+	}{
+		{"same", before, ""},
+		{"changed line", "line1\nline2\nline3\nline4\nline5\nLINE6\nline7\nline8\nline9\nline10\nline11\nline12\n",
+			"--- a/file\n+++ b/file\n@@ -3,7 +3,7 @@\n line3\n line4\n line5\n-line6\n+LINE6\n line7\n line8\n line9\n"},
+		{"separate hunks", "LINE1\nline2\nline3\nline4\nline5\nline6\nline7\nline8\nline9\nline10\nline11\nLINE12\n",
+			"--- a/file\n+++ b/file\n@@ -1,4 +1,4 @@\n-line1\n+LINE1\n line2\n line3\n line4\n@@ -9,4 +9,4 @@\n line9\n line10\n line11\n-line12\n+LINE12\n"},
+		{"added line", "line1\nnew\nline2\nline3\nline4\nline5\nline6\nline7\nline8\nline9\nline10\nline11\nline12\n",
+			"--- a/file\n+++ b/file\n@@ -1,4 +1,5 @@\n line1\n+new\n line2\n line3\n line4\n"},
+		{"new file", "", "--- a/file\n+++ b/file\n@@ -1,12 +0,0 @@\n-line1\n-line2\n-line3\n-line4\n-line5\n-line6\n-line7\n-line8\n-line9\n-line10\n-line11\n-line12\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := UnifiedDiff("a/file", "b/file", before, tt.after)
+
+			if result != tt.expected {
+				t.Errorf("UnifiedDiff() =\n%s\nexpected\n%s", result, tt.expected)
+			}
+		})
+	}
+}
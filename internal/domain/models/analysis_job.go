@@ -0,0 +1,56 @@
+package models
+
+import "time"
+
+// JobStatusRunning means the repositories of the job are still being loaded and compared.
+const JobStatusRunning = "running"
+
+// JobStatusCompleted means the report of the job is complete.
+const JobStatusCompleted = "completed"
+
+// JobStatusFailed means the job stopped before the report was complete.
+const JobStatusFailed = "failed"
+
+// JobEventStarted is sent when the repositories of a job are known.
+const JobEventStarted = "started"
+
+// JobEventProgress is sent when a repository has been loaded.
+const JobEventProgress = "progress"
+
+// JobEventCompleted is sent when the report is complete.
+const JobEventCompleted = "completed"
+
+// JobEventFailed is sent when the job fails.
+const JobEventFailed = "failed"
+
+// AnalysisJob is an analysis that runs in the background.
+type AnalysisJob struct {
+	Id string `json:"id"`
+	// Status is one of "running", "completed", or "failed".
+	Status       string   `json:"status"`
+	Repositories []string `json:"repositories"`
+	// Completed is the number of repositories that have been loaded.
+	Completed int `json:"completed"`
+	Total     int `json:"total"`
+	// Report is the report of the repositories loaded so far while the job is running, and the full report
+	// once it has completed.
+	Report    *Report   `json:"report,omitempty"`
+	Error     string    `json:"error,omitempty"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// JobEvent describes the progress of a job.
+type JobEvent struct {
+	// Id increases with each event of a job, so a client that reconnects can skip the events it has seen.
+	Id int `json:"id"`
+	// Type is one of "started", "progress", "completed", or "failed".
+	Type string `json:"type"`
+	// Repo is the repository that was loaded for a "progress" event.
+	Repo string `json:"repo,omitempty"`
+	// RepoStatus is the status of the repository that was loaded for a "progress" event.
+	RepoStatus string `json:"repoStatus,omitempty"`
+	Completed  int    `json:"completed"`
+	Total      int    `json:"total"`
+	Error      string `json:"error,omitempty"`
+}
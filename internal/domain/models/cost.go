@@ -0,0 +1,34 @@
+package models
+
+// CostModel describes how the cost of making a consistent change across the repositories is calculated.
+type CostModel struct {
+	// HoursPerRepo is the time it takes to make a consistent change to a repository with duplication or drift.
+	HoursPerRepo float64 `json:"hoursPerRepo"`
+	// AnnualSalary is the average annual salary of an engineer, in dollars.
+	AnnualSalary float64 `json:"annualSalary"`
+	// WorkdaysPerYear and HoursPerWorkday convert the annual salary to an hourly rate.
+	WorkdaysPerYear float64 `json:"workdaysPerYear"`
+	HoursPerWorkday float64 `json:"hoursPerWorkday"`
+	// HoursPerDrift is the optional extra time spent on each version drift between a pair of repositories.
+	HoursPerDrift float64 `json:"hoursPerDrift"`
+	// HoursPerSimilarStep is the optional extra time spent on each step with similar config in a pair of repositories.
+	HoursPerSimilarStep float64 `json:"hoursPerSimilarStep"`
+}
+
+// CostBreakdown is the cost of making a consistent change across the repositories, and how it was calculated.
+type CostBreakdown struct {
+	Model      CostModel `json:"model"`
+	HourlyRate float64   `json:"hourlyRate"`
+	// WeightedRepos is the number of repositories with duplication or drift, weighted by the severity of their drift.
+	WeightedRepos float64 `json:"weightedRepos"`
+	// Drifts is the number of version drifts between each pair of repositories.
+	Drifts int `json:"drifts"`
+	// SimilarSteps is the number of steps with similar config in each pair of repositories.
+	SimilarSteps     int     `json:"similarSteps"`
+	RepoHours        float64 `json:"repoHours"`
+	DriftHours       float64 `json:"driftHours"`
+	SimilarStepHours float64 `json:"similarStepHours"`
+	TotalHours       float64 `json:"totalHours"`
+	// Total is the cost in dollars.
+	Total float64 `json:"total"`
+}
@@ -12,6 +12,8 @@ type Action struct {
 	Uses string `json:"uses"`
 	// UsesVersion is the version of the GitHub Action.
 	UsesVersion string `json:"uses_version"`
+	// ResolvedVersion is the tag that a SHA pinned UsesVersion points to, if it is known.
+	ResolvedVersion string `json:"resolved_version"`
 	// Settings is a map of all other settings defined for the action excluding 'env' and 'with'.
 	Settings map[string]string `json:"settings"`
 	// Env is a map of environment variables set for the action.
@@ -20,6 +22,15 @@ type Action struct {
 	With map[string]string `json:"with"`
 	// This is used by script steps
 	Run string `json:"run"`
+	// ReusableWorkflow is true when the action is a job that calls a reusable workflow.
+	ReusableWorkflow bool `json:"reusable_workflow"`
+	// Secrets is a map of secrets passed to a reusable workflow.
+	Secrets map[string]string `json:"secrets"`
+	// Workflow is the name of the workflow file that the action was defined in, if it is known.
+	Workflow string `json:"workflow"`
+	// Source is the "uses" value of the composite action that this step was expanded from.
+	// It is empty for steps defined directly in a workflow.
+	Source string `json:"source"`
 	// A locality sensitive hash of the action configuration.
 	Hash *tlsh.TLSH
 }
@@ -55,6 +66,15 @@ func (action *Action) GenerateHash() {
 		}
 	}
 
+	secretsString := collections.MapToString(action.Secrets)
+	if secretsString != "" {
+		_, err := action1Hash.Write([]byte(secretsString))
+		foundConfig = true
+		if err != nil {
+			return
+		}
+	}
+
 	if foundConfig {
 		action1Hash.Sum(nil)
 
@@ -0,0 +1,27 @@
+package models
+
+import "time"
+
+// ReportMetadata describes how a report was generated, as opposed to what was found.
+type ReportMetadata struct {
+	// RateLimit is the GitHub API budget remaining after the report was generated. It is nil when the
+	// workflows were not loaded from the GitHub API.
+	RateLimit *RateLimitBudget `json:"rateLimit,omitempty"`
+	// Incomplete is true if the analysis was cancelled or timed out before every repository was loaded.
+	// The repositories that were not loaded have a "cancelled" error in their status.
+	Incomplete bool `json:"incomplete"`
+	// IncompleteReason explains why the analysis stopped early, like "cancelled" or "timed out".
+	IncompleteReason string `json:"incompleteReason,omitempty"`
+}
+
+// RateLimitBudget is the state of the GitHub API rate limit, as reported by the X-RateLimit-* headers.
+type RateLimitBudget struct {
+	Limit     int       `json:"limit"`
+	Remaining int       `json:"remaining"`
+	Used      int       `json:"used"`
+	Reset     time.Time `json:"reset"`
+	// Requests is the number of requests sent to the API, including retries.
+	Requests int `json:"requests"`
+	// Retries is the number of requests that were sent again after a rate limit or server error.
+	Retries int `json:"retries"`
+}
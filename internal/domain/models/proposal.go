@@ -0,0 +1,45 @@
+package models
+
+const InputPropertyWith = "with"
+const InputPropertyEnv = "env"
+
+// CompositeActionProposal is a shared composite action that can replace a cluster of similar steps, and the step
+// that replaces each member of the cluster.
+type CompositeActionProposal struct {
+	// Name is the name of the composite action, which is also the directory it is published in.
+	Name string `json:"name"`
+	// Uses is the action called by the clustered steps, and wrapped by the composite action.
+	Uses string `json:"uses"`
+	// Version is the version of the action called by the composite action.
+	Version string `json:"version"`
+	// Location is the "uses" value that calls the composite action once it is published.
+	Location string                 `json:"location"`
+	Repos    []string               `json:"repos"`
+	Inputs   []CompositeActionInput `json:"inputs"`
+	// ActionYml is the content of the composite action's action.yml file.
+	ActionYml    string            `json:"actionYml"`
+	Replacements []StepReplacement `json:"replacements"`
+}
+
+// CompositeActionInput is an input of a composite action, which is passed to the wrapped action.
+type CompositeActionInput struct {
+	Name string `json:"name"`
+	// Property is "with" for an input of the wrapped action, or "env" for an environment variable.
+	Property string `json:"property"`
+	// Key is the name of the input or environment variable of the wrapped action.
+	Key string `json:"key"`
+	// Default is the value shared by every member of the cluster. Inputs without a default are set by the callers.
+	Default *string `json:"default,omitempty"`
+	// Required inputs must be passed by every caller. Inputs set by only some members are optional, and have no default.
+	Required bool `json:"required"`
+}
+
+// StepReplacement is the step that calls the composite action in place of a member of the cluster.
+type StepReplacement struct {
+	Repo     string `json:"repo"`
+	Workflow string `json:"workflow"`
+	// Step is the name of the replaced step, if it has one.
+	Step string `json:"step"`
+	// Snippet is the YAML of the replacement step.
+	Snippet string `json:"snippet"`
+}
@@ -0,0 +1,21 @@
+package models
+
+const PullRequestCreated = "created"
+const PullRequestUpdated = "updated"
+const PullRequestPlanned = "planned"
+const PullRequestFailed = "failed"
+
+// PullRequestResult is the outcome of proposing the aligned action versions to a repository.
+type PullRequestResult struct {
+	Repo string `json:"repo"`
+	// Status is "created" or "updated" when a pull request was opened or an existing one was updated, "planned"
+	// for a dry run, or "failed".
+	Status string `json:"status"`
+	Number int    `json:"number,omitempty"`
+	Url    string `json:"url,omitempty"`
+	// Files are the paths of the changed workflow files.
+	Files []string `json:"files"`
+	// Diff is the unified diff of the changes to the workflow files.
+	Diff  string `json:"diff,omitempty"`
+	Error string `json:"error,omitempty"`
+}
@@ -1,5 +1,10 @@
 package models
 
+import (
+	"maps"
+	"slices"
+)
+
 type Report struct {
 	NumberOfRepos                       int                                    `json:"numberOfRepos"`
 	NumberOfReposWithDuplicationOrDrift int                                    `json:"numberOfReposWithDuplicationOrDrift"`
@@ -8,6 +13,50 @@ type Report struct {
 	UniqueContributors                  []string                               `json:"uniqueContributors"`
 	WorkflowAdvisories                  map[string][]string                    `json:"workflowAdvisories"`
 	ActionAuthors                       map[string][]string                    `json:"actionAuthors"`
+	SharedWorkflows                     map[string][]string                    `json:"sharedWorkflows"`
+	NumberOfReposUsingSharedWorkflows   int                                    `json:"numberOfReposUsingSharedWorkflows"`
+	StaleActions                        map[string][]ActionStaleness           `json:"staleActions"`
+	RecommendedVersions                 map[string]string                      `json:"recommendedVersions"`
+	Clusters                            []ActionCluster                        `json:"clusters"`
+	RepoStatus                          map[string]RepoStatus                  `json:"repoStatus"`
+	Metadata                            ReportMetadata                         `json:"metadata"`
+	// WeightedNumberOfReposWithDuplicationOrDrift counts each repo with duplication or drift, weighted by the
+	// severity of its worst drift, so repos with only patch drift contribute less to the cost.
+	WeightedNumberOfReposWithDuplicationOrDrift float64 `json:"weightedNumberOfReposWithDuplicationOrDrift"`
+	// Cost is the cost of making a consistent change across the repositories.
+	Cost CostBreakdown `json:"cost"`
+	// Suppressed is the drift and duplication that was accepted by a suppression, and is not included in the
+	// comparisons, counts, or cost.
+	Suppressed []SuppressedFinding `json:"suppressed"`
+	// ExpiredSuppressions are the suppressions that were ignored because they have expired.
+	ExpiredSuppressions []Suppression `json:"expiredSuppressions"`
+	// SecurityFindings are the actions and reusable workflows that are not pinned to a full commit SHA.
+	SecurityFindings []SecurityFinding `json:"securityFindings"`
+}
+
+// RepoPair is the comparison of two repositories in a report.
+type RepoPair struct {
+	Repo1        string
+	Repo2        string
+	Measurements RepoMeasurements
+}
+
+// GetRepoPairs returns each pair of repositories that were compared once, sorted by repository. The comparisons
+// include each pair in both directions with the same measurements, so only the direction where repo1 sorts first,
+// which the versions are relative to, is returned.
+func (report Report) GetRepoPairs() []RepoPair {
+	pairs := []RepoPair{}
+	for _, repo1 := range slices.Sorted(maps.Keys(report.Comparisons)) {
+		for _, repo2 := range slices.Sorted(maps.Keys(report.Comparisons[repo1])) {
+			if repo1 >= repo2 {
+				continue
+			}
+
+			pairs = append(pairs, RepoPair{Repo1: repo1, Repo2: repo2, Measurements: report.Comparisons[repo1][repo2]})
+		}
+	}
+
+	return pairs
 }
 
 type RepoMeasurements struct {
@@ -16,4 +65,62 @@ type RepoMeasurements struct {
 	StepsWithSimilarConfig           []string `json:"stepsWithSimilarConfig"`
 	StepsWithSimilarConfigCount      int      `json:"stepsWithSimilarConfigCount"`
 	StepsThatIndicateDuplicationRisk int      `json:"stepsThatIndicateDuplicationRisk"`
+	// VersionDrift describes each difference in action versions and its severity.
+	VersionDrift []VersionDrift `json:"versionDrift"`
+	// HighestDriftSeverity is the most severe of the VersionDrift entries, or an empty string if there is no drift.
+	HighestDriftSeverity string `json:"highestDriftSeverity"`
+	// SimilarScripts lists the run scripts that are similar between the repos. These are reported separately
+	// from the steps with similar config and are not included in StepsThatIndicateDuplicationRisk.
+	SimilarScripts      []SimilarScript `json:"similarScripts"`
+	SimilarScriptsCount int             `json:"similarScriptsCount"`
+}
+
+type VersionDrift struct {
+	Uses     string `json:"uses"`
+	Version1 string `json:"version1"`
+	Version2 string `json:"version2"`
+	// Severity is one of "major", "minor", "patch" or "ref-type".
+	Severity string `json:"severity"`
+}
+
+// ActionStaleness describes how far an action used by a repo is behind the latest stable version of the action.
+type ActionStaleness struct {
+	Uses           string `json:"uses"`
+	CurrentVersion string `json:"currentVersion"`
+	LatestVersion  string `json:"latestVersion"`
+	MajorsBehind   int    `json:"majorsBehind"`
+	MinorsBehind   int    `json:"minorsBehind"`
+}
+
+// SimilarScript is a pair of run scripts that are similar once whitespace, comments and expression formatting are ignored.
+type SimilarScript struct {
+	Script1 string `json:"script1"`
+	Script2 string `json:"script2"`
+	// Similarity is between 0 and 1, where 1 means the normalized scripts are identical.
+	Similarity float64 `json:"similarity"`
+}
+
+// ActionCluster is a group of steps across repositories that call the same action with similar configuration.
+type ActionCluster struct {
+	// Uses is the action called by every member of the cluster, or "(built-in step)" for run steps.
+	Uses    string                `json:"uses"`
+	Members []ActionClusterMember `json:"members"`
+	// Repos are the unique repositories that contain a member of the cluster.
+	Repos []string `json:"repos"`
+	// Workflows are the unique workflow files, prefixed with their repository, that contain a member of the cluster.
+	Workflows []string `json:"workflows"`
+	// Centroid is the member whose configuration is closest to all the other members.
+	Centroid ActionClusterMember `json:"centroid"`
+}
+
+// ActionClusterMember is the location and configuration of a step in an ActionCluster.
+type ActionClusterMember struct {
+	Repo     string            `json:"repo"`
+	Workflow string            `json:"workflow"`
+	Id       string            `json:"id"`
+	Version  string            `json:"version"`
+	Settings map[string]string `json:"settings"`
+	Env      map[string]string `json:"env"`
+	With     map[string]string `json:"with"`
+	Secrets  map[string]string `json:"secrets"`
 }
@@ -0,0 +1,52 @@
+package models
+
+// ReportDiff describes how duplication and drift changed between two reports of the same repositories.
+type ReportDiff struct {
+	// AddedRepos are the repositories that are only in the later report.
+	AddedRepos []string `json:"addedRepos"`
+	// RemovedRepos are the repositories that are only in the earlier report.
+	RemovedRepos []string `json:"removedRepos"`
+	// ResolvedDrift is the version drift between repositories in both reports that is only in the earlier report.
+	ResolvedDrift []DriftChange `json:"resolvedDrift"`
+	// IntroducedDrift is the version drift between repositories in both reports that is only in the later report.
+	IntroducedDrift []DriftChange `json:"introducedDrift"`
+	// SimilarConfigGroups are the actions whose steps with similar configuration changed.
+	SimilarConfigGroups                         []SimilarConfigChange `json:"similarConfigGroups"`
+	NumberOfReposWithDuplicationOrDrift         CountChange           `json:"numberOfReposWithDuplicationOrDrift"`
+	WeightedNumberOfReposWithDuplicationOrDrift AmountChange          `json:"weightedNumberOfReposWithDuplicationOrDrift"`
+	// Cost is the dollar cost of making a consistent change across the repositories.
+	Cost AmountChange `json:"cost"`
+}
+
+// DriftChange is version drift between two repositories that was resolved or introduced.
+type DriftChange struct {
+	Repo1 string `json:"repo1"`
+	Repo2 string `json:"repo2"`
+	VersionDrift
+}
+
+// SimilarConfigChange describes how the steps that call an action with similar configuration changed. The steps
+// are the members of the report's clusters for the action.
+type SimilarConfigChange struct {
+	Uses         string   `json:"uses"`
+	StepsBefore  int      `json:"stepsBefore"`
+	StepsAfter   int      `json:"stepsAfter"`
+	ReposBefore  int      `json:"reposBefore"`
+	ReposAfter   int      `json:"reposAfter"`
+	RemovedRepos []string `json:"removedRepos"`
+	AddedRepos   []string `json:"addedRepos"`
+}
+
+// CountChange is how a count changed between two reports.
+type CountChange struct {
+	Before int `json:"before"`
+	After  int `json:"after"`
+	Change int `json:"change"`
+}
+
+// AmountChange is how an amount changed between two reports.
+type AmountChange struct {
+	Before float64 `json:"before"`
+	After  float64 `json:"after"`
+	Change float64 `json:"change"`
+}
@@ -0,0 +1,24 @@
+package models
+
+// Repository describes a repository found when listing the repositories of an organization or user.
+type Repository struct {
+	// FullName is the repository in the format "owner/repo".
+	FullName string   `json:"fullName"`
+	Name     string   `json:"name"`
+	Topics   []string `json:"topics"`
+	Archived bool     `json:"archived"`
+	Fork     bool     `json:"fork"`
+	Language string   `json:"language"`
+}
+
+// RepositoryFilter limits the repositories included when scanning an organization or user.
+type RepositoryFilter struct {
+	// Topics are the topics a repository must have. A repository must have all the topics to be included.
+	Topics          []string `json:"topics"`
+	ExcludeArchived bool     `json:"excludeArchived"`
+	ExcludeForks    bool     `json:"excludeForks"`
+	// Name is a glob, like "service-*", matched against the name of the repository without the owner.
+	Name string `json:"name"`
+	// Language is the primary language of the repository, compared case insensitively.
+	Language string `json:"language"`
+}
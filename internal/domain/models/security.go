@@ -0,0 +1,32 @@
+package models
+
+const ReferenceUnversioned = "unversioned"
+const ReferenceBranch = "branch"
+const ReferenceFloatingTag = "floating-tag"
+const ReferenceTag = "tag"
+
+const SecuritySeverityLow = "low"
+const SecuritySeverityMedium = "medium"
+const SecuritySeverityHigh = "high"
+const SecuritySeverityCritical = "critical"
+
+// SecuritySeverities are the severities of security findings, from the least to the most severe.
+var SecuritySeverities = []string{SecuritySeverityLow, SecuritySeverityMedium, SecuritySeverityHigh, SecuritySeverityCritical}
+
+// SecurityFinding is an action or reusable workflow that is not pinned to a full commit SHA, so the code that runs
+// can change without the workflow changing.
+type SecurityFinding struct {
+	Repo     string `json:"repo"`
+	Workflow string `json:"workflow"`
+	// Line is the line of the "uses" value in the workflow file, starting at 1.
+	Line    int    `json:"line"`
+	Uses    string `json:"uses"`
+	Version string `json:"version"`
+	// Kind is one of "unversioned", "branch", "floating-tag", like v4 or latest, or "tag", like v4.1.0.
+	Kind string `json:"kind"`
+	// Severity is one of "low", "medium", "high", or "critical".
+	Severity string `json:"severity"`
+	// ThirdParty is true if the action is not owned by GitHub or by the owner of the repository.
+	ThirdParty bool   `json:"thirdParty"`
+	Message    string `json:"message"`
+}
@@ -0,0 +1,38 @@
+package models
+
+const ErrorCategoryNotFound = "not-found"
+const ErrorCategoryForbidden = "forbidden"
+const ErrorCategoryRateLimited = "rate-limited"
+const ErrorCategoryParseError = "parse-error"
+const ErrorCategoryInvalidRepository = "invalid-repository"
+const ErrorCategoryCancelled = "cancelled"
+const ErrorCategoryUnknown = "unknown"
+
+// RepoStatusOk means all the workflows of the repository were loaded and compared.
+const RepoStatusOk = "ok"
+
+// RepoStatusNoWorkflows means the repository was loaded, but it does not have any workflows.
+const RepoStatusNoWorkflows = "no-workflows"
+
+// RepoStatusPartial means some workflows were compared, but some information about the repository could not be loaded.
+const RepoStatusPartial = "partial"
+
+// RepoStatusFailed means no workflows could be compared because the repository could not be loaded.
+const RepoStatusFailed = "failed"
+
+// RepoStatus describes whether a repository was loaded successfully, and any errors found while loading it.
+type RepoStatus struct {
+	// Status is one of "ok", "no-workflows", "partial", or "failed".
+	Status string      `json:"status"`
+	Errors []RepoError `json:"errors"`
+}
+
+// RepoError is an error found while loading or parsing a repository.
+type RepoError struct {
+	// Category is one of "not-found", "forbidden", "rate-limited", "parse-error", "invalid-repository",
+	// "cancelled", or "unknown".
+	Category string `json:"category"`
+	// Operation describes what was being loaded, like "list workflows" or "load workflow build.yml".
+	Operation string `json:"operation"`
+	Message   string `json:"message"`
+}
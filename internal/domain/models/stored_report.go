@@ -0,0 +1,36 @@
+package models
+
+import "time"
+
+// StoredReport is a report saved to the report history, with what was compared and how.
+type StoredReport struct {
+	Id string `json:"id"`
+	// Owner identifies who generated the report. Reports are only returned to the owner that generated them.
+	Owner     string    `json:"owner,omitempty"`
+	CreatedAt time.Time `json:"createdAt"`
+	// Repositories are the repositories that were compared, after org: and user: targets were expanded.
+	Repositories []string         `json:"repositories"`
+	Parameters   ReportParameters `json:"parameters"`
+	Report       Report           `json:"report"`
+}
+
+// ReportParameters are the options a report was requested with.
+type ReportParameters struct {
+	// Targets are the repositories, local paths, or org: and user: targets the report was requested for.
+	Targets []string         `json:"targets"`
+	Filter  RepositoryFilter `json:"filter"`
+	// Local is true if the repositories were read from local checkouts instead of GitHub.
+	Local bool `json:"local,omitempty"`
+}
+
+// ReportSummary describes a stored report without the full report, for listing the report history.
+type ReportSummary struct {
+	Id                                  string           `json:"id"`
+	CreatedAt                           time.Time        `json:"createdAt"`
+	Repositories                        []string         `json:"repositories"`
+	Parameters                          ReportParameters `json:"parameters"`
+	NumberOfRepos                       int              `json:"numberOfRepos"`
+	NumberOfReposWithDuplicationOrDrift int              `json:"numberOfReposWithDuplicationOrDrift"`
+	// Incomplete is true if the analysis was stopped before every repository was loaded.
+	Incomplete bool `json:"incomplete"`
+}
@@ -0,0 +1,35 @@
+package models
+
+// SuppressionFile is the format of a checked-in file that lists the drift and duplication that has been accepted.
+type SuppressionFile struct {
+	Suppressions []Suppression `json:"suppressions" yaml:"suppressions"`
+}
+
+// Suppression accepts the drift and duplication of an action, between a pair of repositories, or of an action in a
+// repository, until it expires. Matches are excluded from the counts and the cost, and are listed in the suppressed
+// findings of the report instead.
+type Suppression struct {
+	// Action is the action that is accepted, like "actions/setup-node", without a version. An empty action accepts
+	// every action in the repositories.
+	Action string `json:"action" yaml:"action"`
+	// Repos limits the suppression to comparisons with one repository, or to the comparison of a pair of
+	// repositories. Empty repos accept the action in every repository.
+	Repos []string `json:"repos" yaml:"repos"`
+	// Expires is the last day the suppression applies, like "2025-12-31".
+	Expires string `json:"expires" yaml:"expires"`
+	// Reason explains why the drift or duplication is accepted.
+	Reason string `json:"reason" yaml:"reason"`
+}
+
+// SuppressedFinding is the drift or duplication of an action between two repositories that was accepted by a
+// suppression.
+type SuppressedFinding struct {
+	Repo1 string `json:"repo1"`
+	Repo2 string `json:"repo2"`
+	Uses  string `json:"uses"`
+	// VersionDrift is the version drift of the action that was suppressed.
+	VersionDrift []VersionDrift `json:"versionDrift"`
+	// SimilarConfig is true if steps that call the action with similar configuration were suppressed.
+	SimilarConfig bool        `json:"similarConfig"`
+	Suppression   Suppression `json:"suppression"`
+}
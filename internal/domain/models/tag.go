@@ -0,0 +1,8 @@
+package models
+
+// Tag is a git tag in the repository of an action.
+type Tag struct {
+	Name string `json:"name"`
+	// Commit is the SHA of the commit that the tag points to.
+	Commit string `json:"commit"`
+}
@@ -0,0 +1,30 @@
+package models
+
+const ThresholdMaxReposWithDuplicationOrDrift = "max-repos-with-drift"
+const ThresholdMaxDriftedActionsPerPair = "max-drift-per-pair"
+const ThresholdMaxCost = "max-cost"
+const ThresholdNoNewDrift = "no-new-drift"
+const ThresholdRequireShaPins = "require-sha-pins"
+
+// Thresholds are the limits a report must stay within, like when the CLI is run in CI. A nil limit is not checked.
+type Thresholds struct {
+	// MaxReposWithDuplicationOrDrift is the most repositories that can have duplication or drift.
+	MaxReposWithDuplicationOrDrift *int `json:"maxReposWithDuplicationOrDrift"`
+	// MaxDriftedActionsPerPair is the most actions that can be used with different versions by a pair of repositories.
+	MaxDriftedActionsPerPair *int `json:"maxDriftedActionsPerPair"`
+	// MaxCost is the highest cost, in dollars, of making a consistent change across the repositories.
+	MaxCost *float64 `json:"maxCost"`
+	// NoNewDrift fails the report if it has version drift that is not in the baseline report.
+	NoNewDrift bool `json:"noNewDrift"`
+	// RequireShaPins fails the report if an action owned by a third party is not pinned to a full commit SHA.
+	RequireShaPins bool `json:"requireShaPins"`
+}
+
+// ThresholdViolation describes a threshold that a report exceeded.
+type ThresholdViolation struct {
+	// Rule is one of "max-repos-with-drift", "max-drift-per-pair", "max-cost", "no-new-drift", or "require-sha-pins".
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+	// Details lists what exceeded the threshold, like each pair of repositories with too much drift.
+	Details []string `json:"details"`
+}
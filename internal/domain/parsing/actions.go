@@ -10,3 +10,19 @@ func GetActionIdAndVersion(uses string) (string, string) {
 		return uses, "latest"
 	}
 }
+
+// IsLocalReference returns true if the uses value references an action or workflow in the same repository.
+func IsLocalReference(uses string) bool {
+	return strings.HasPrefix(uses, "./")
+}
+
+// SplitActionPath splits an action ID like "org/repo/path/to/action" into the repository
+// ("org/repo") and the path to the action within the repository ("path/to/action").
+func SplitActionPath(actionId string) (string, string) {
+	parts := strings.Split(actionId, "/")
+	if len(parts) < 2 || parts[0] == "" || parts[1] == "" {
+		return "", ""
+	}
+
+	return parts[0] + "/" + parts[1], strings.Join(parts[2:], "/")
+}
@@ -148,3 +148,42 @@ func TestGetActionIdAndVersionConsistency(t *testing.T) {
 		t.Errorf("GetActionIdAndVersion(%q) returned inconsistent versions: %q, %q, %q", uses, version1, version2, version3)
 	}
 }
+
+func TestIsLocalReference(t *testing.T) {
+	tests := map[string]bool{
+		"./.github/actions/my-action":    true,
+		"./.github/workflows/build.yml":  true,
+		"actions/checkout@v4":            false,
+		"docker://alpine:3.8":            false,
+		"org/repo/.github/workflows/a.y": false,
+		"":                               false,
+	}
+
+	for uses, expected := range tests {
+		if result := IsLocalReference(uses); result != expected {
+			t.Errorf("IsLocalReference(%q) = %v, expected %v", uses, result, expected)
+		}
+	}
+}
+
+func TestSplitActionPath(t *testing.T) {
+	tests := []struct {
+		actionId     string
+		expectedRepo string
+		expectedPath string
+	}{
+		{"actions/checkout", "actions/checkout", ""},
+		{"my-org/actions/setup/node", "my-org/actions", "setup/node"},
+		{"my-org/shared/.github/workflows/build.yml", "my-org/shared", ".github/workflows/build.yml"},
+		{"checkout", "", ""},
+		{"/checkout", "", ""},
+		{"", "", ""},
+	}
+
+	for _, tt := range tests {
+		repo, path := SplitActionPath(tt.actionId)
+		if repo != tt.expectedRepo || path != tt.expectedPath {
+			t.Errorf("SplitActionPath(%q) = (%q, %q), expected (%q, %q)", tt.actionId, repo, path, tt.expectedRepo, tt.expectedPath)
+		}
+	}
+}
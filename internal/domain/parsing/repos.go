@@ -1,20 +1,24 @@
 package parsing
 
 import (
+	"errors"
 	"fmt"
 	"strings"
 )
 
+// ErrInvalidRepository is wrapped by the error returned when a repository is not in the format "owner/repo".
+var ErrInvalidRepository = errors.New("invalid repository format")
+
 func SplitRepo(repo string) (string, string, error) {
 	// Split repo into owner and name
 	parts := strings.Split(SanitizeRepo(repo), "/")
 	// Ignore any paths that may have been on the end of a url
 	if len(parts) < 2 {
-		return "", "", fmt.Errorf("invalid repository format: %s", repo)
+		return "", "", fmt.Errorf("%w: %s", ErrInvalidRepository, repo)
 	}
 
 	if parts[0] == "" || parts[1] == "" {
-		return "", "", fmt.Errorf("invalid repository format: %s", repo)
+		return "", "", fmt.Errorf("%w: %s", ErrInvalidRepository, repo)
 	}
 
 	return strings.TrimSpace(parts[0]), strings.TrimSpace(parts[1]), nil
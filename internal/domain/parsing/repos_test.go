@@ -1,6 +1,7 @@
 package parsing
 
 import (
+	"errors"
 	"testing"
 )
 
@@ -147,6 +148,8 @@ func TestSplitRepo(t *testing.T) {
 			if tt.expectError {
 				if err == nil {
 					t.Errorf("SplitRepo(%q) expected error but got none", tt.repo)
+				} else if !errors.Is(err, ErrInvalidRepository) {
+					t.Errorf("SplitRepo(%q) expected error to wrap ErrInvalidRepository, got %v", tt.repo, err)
 				}
 			} else {
 				if err != nil {
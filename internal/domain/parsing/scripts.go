@@ -0,0 +1,130 @@
+package parsing
+
+import (
+	"strings"
+	"unicode"
+)
+
+// ScriptSeparator is the token used for anything that ends a shell command, such as a newline or ";".
+const ScriptSeparator = ";"
+
+// ScriptOperators are the shell operators that are split into their own tokens, longest first.
+var ScriptOperators = []string{"&&", "||", ">>", "|", "&", ">", "<", "(", ")", ";"}
+
+// TokenizeScript splits a shell script into tokens, removing comments and insignificant whitespace.
+// Quoted strings are kept as single tokens, newlines are converted to ScriptSeparator, and
+// GitHub expressions like "${{ github.sha }}" are normalized to "${{github.sha}}".
+func TokenizeScript(script string) []string {
+	tokens := []string{}
+	current := strings.Builder{}
+	runes := []rune(strings.ReplaceAll(script, "\r\n", "\n"))
+
+	flush := func() {
+		if current.Len() > 0 {
+			tokens = append(tokens, current.String())
+			current.Reset()
+		}
+	}
+
+	addSeparator := func() {
+		flush()
+		// Repeated separators and separators at the start of the script carry no meaning
+		if len(tokens) > 0 && tokens[len(tokens)-1] != ScriptSeparator {
+			tokens = append(tokens, ScriptSeparator)
+		}
+	}
+
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+
+		switch {
+		case r == '\\' && i+1 < len(runes) && runes[i+1] == '\n':
+			// A line continuation is just whitespace
+			flush()
+			i++
+		case r == '\n':
+			addSeparator()
+		case unicode.IsSpace(r):
+			flush()
+		case r == '#' && current.Len() == 0:
+			// Comments run to the end of the line
+			for i+1 < len(runes) && runes[i+1] != '\n' {
+				i++
+			}
+		case hasRunePrefix(runes[i:], "${{"):
+			end := indexRunes(runes[i:], "}}")
+			if end == -1 {
+				current.WriteString(string(runes[i:]))
+				i = len(runes)
+				continue
+			}
+			expression := string(runes[i+3 : i+end])
+			current.WriteString("${{" + strings.Join(strings.Fields(expression), "") + "}}")
+			i += end + 1
+		case r == '\'' || r == '"':
+			// Quoted strings are kept as they are, including the quotes
+			end := i + 1
+			for end < len(runes) && runes[end] != r {
+				if r == '"' && runes[end] == '\\' {
+					end++
+				}
+				end++
+			}
+			current.WriteString(string(runes[i:min(end+1, len(runes))]))
+			i = end
+		default:
+			operator, found := getScriptOperator(runes[i:])
+			if !found {
+				current.WriteRune(r)
+				continue
+			}
+
+			if operator == ScriptSeparator {
+				addSeparator()
+			} else {
+				flush()
+				tokens = append(tokens, operator)
+			}
+			i += len(operator) - 1
+		}
+	}
+
+	flush()
+
+	if len(tokens) > 0 && tokens[len(tokens)-1] == ScriptSeparator {
+		tokens = tokens[:len(tokens)-1]
+	}
+
+	return tokens
+}
+
+// NormalizeScript returns the tokens of a shell script joined with single spaces.
+func NormalizeScript(script string) string {
+	return strings.Join(TokenizeScript(script), " ")
+}
+
+func getScriptOperator(runes []rune) (string, bool) {
+	for _, operator := range ScriptOperators {
+		if hasRunePrefix(runes, operator) {
+			return operator, true
+		}
+	}
+
+	return "", false
+}
+
+func hasRunePrefix(runes []rune, prefix string) bool {
+	prefixRunes := []rune(prefix)
+	return len(runes) >= len(prefixRunes) && string(runes[:len(prefixRunes)]) == prefix
+}
+
+// indexRunes returns the index of the first rune of substr in runes, or -1 if it is not present.
+func indexRunes(runes []rune, substr string) int {
+	for i := range runes {
+		if hasRunePrefix(runes[i:], substr) {
+			return i
+		}
+	}
+
+	return -1
+}
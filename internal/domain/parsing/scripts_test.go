@@ -0,0 +1,92 @@
+package parsing
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestTokenizeScript(t *testing.T) {
+	tests := []struct {
+		name     string
+		script   string
+		expected []string
+	}{
+		{
+			name:     "empty script",
+			script:   "",
+			expected: []string{},
+		},
+		{
+			name:     "whitespace is collapsed",
+			script:   "  npm   ci  \n\n  npm   test  \n",
+			expected: []string{"npm", "ci", ";", "npm", "test"},
+		},
+		{
+			name:     "comments are removed",
+			script:   "# Install dependencies\nnpm ci # quietly\necho a#b",
+			expected: []string{"npm", "ci", ";", "echo", "a#b"},
+		},
+		{
+			name:     "quoted strings are single tokens",
+			script:   `echo "hello   world" 'it''s'`,
+			expected: []string{"echo", `"hello   world"`, "'it''s'"},
+		},
+		{
+			name:     "escaped quotes in double quoted strings",
+			script:   `echo "say \"hi\"" done`,
+			expected: []string{"echo", `"say \"hi\""`, "done"},
+		},
+		{
+			name:     "operators are split",
+			script:   "cat file|grep x&&echo ok>>out.txt;ls",
+			expected: []string{"cat", "file", "|", "grep", "x", "&&", "echo", "ok", ">>", "out.txt", ";", "ls"},
+		},
+		{
+			name:     "line continuations",
+			script:   "docker build \\\n  --tag app \\\n  .",
+			expected: []string{"docker", "build", "--tag", "app", "."},
+		},
+		{
+			name:     "expressions are normalized",
+			script:   "echo ${{   github.sha }} ${{github.ref_name}}",
+			expected: []string{"echo", "${{github.sha}}", "${{github.ref_name}}"},
+		},
+		{
+			name:     "expressions with spaces in strings",
+			script:   "echo ${{ format('{0} {1}', 'a', 'b') }}-suffix",
+			expected: []string{"echo", "${{format('{0}{1}','a','b')}}-suffix"},
+		},
+		{
+			name:     "unterminated expression",
+			script:   "echo ${{ github.sha",
+			expected: []string{"echo", "${{ github.sha"},
+		},
+		{
+			name:     "windows line endings",
+			script:   "npm ci\r\nnpm test",
+			expected: []string{"npm", "ci", ";", "npm", "test"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := TokenizeScript(tt.script)
+			if !slices.Equal(result, tt.expected) {
+				t.Errorf("TokenizeScript(%q) = %q, expected %q", tt.script, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestNormalizeScript(t *testing.T) {
+	script1 := `
+# Build the image
+docker build -t app:${{ github.sha }} .
+docker push app:${{ github.sha }}
+`
+	script2 := "docker build  -t app:${{github.sha}} . ; docker push app:${{github.sha}}"
+
+	if NormalizeScript(script1) != NormalizeScript(script2) {
+		t.Errorf("Expected scripts to normalize to the same value, got %q and %q", NormalizeScript(script1), NormalizeScript(script2))
+	}
+}
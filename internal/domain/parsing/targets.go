@@ -0,0 +1,30 @@
+package parsing
+
+import "strings"
+
+const TargetOrg = "org"
+const TargetUser = "user"
+
+// ParseTarget splits a target like "org:my-org" or "user:octocat" into the kind of target and the name
+// of the organization or user. ok is false for any other value, which is treated as a single repository.
+func ParseTarget(target string) (kind string, name string, ok bool) {
+	kind, name, found := strings.Cut(strings.TrimSpace(target), ":")
+	if !found {
+		return "", "", false
+	}
+
+	kind = strings.ToLower(strings.TrimSpace(kind))
+	name = strings.TrimSpace(name)
+
+	if (kind != TargetOrg && kind != TargetUser) || name == "" {
+		return "", "", false
+	}
+
+	return kind, name, true
+}
+
+// IsOwnerTarget returns true if the target lists the repositories of an organization or user.
+func IsOwnerTarget(target string) bool {
+	_, _, ok := ParseTarget(target)
+	return ok
+}
@@ -0,0 +1,32 @@
+package parsing
+
+import "testing"
+
+func TestParseTarget(t *testing.T) {
+	tests := []struct {
+		target       string
+		expectedKind string
+		expectedName string
+		expectedOk   bool
+	}{
+		{"org:my-org", TargetOrg, "my-org", true},
+		{"user:octocat", TargetUser, "octocat", true},
+		{" ORG: my-org ", TargetOrg, "my-org", true},
+		{"org:", "", "", false},
+		{"team:my-team", "", "", false},
+		{"owner/repo", "", "", false},
+		{"https://github.com/owner/repo", "", "", false},
+		{"", "", "", false},
+	}
+
+	for _, tt := range tests {
+		kind, name, ok := ParseTarget(tt.target)
+		if kind != tt.expectedKind || name != tt.expectedName || ok != tt.expectedOk {
+			t.Errorf("ParseTarget(%q) = (%q, %q, %v), expected (%q, %q, %v)", tt.target, kind, name, ok, tt.expectedKind, tt.expectedName, tt.expectedOk)
+		}
+
+		if IsOwnerTarget(tt.target) != tt.expectedOk {
+			t.Errorf("IsOwnerTarget(%q) = %v, expected %v", tt.target, !tt.expectedOk, tt.expectedOk)
+		}
+	}
+}
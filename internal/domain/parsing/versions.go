@@ -0,0 +1,98 @@
+package parsing
+
+import (
+	"regexp"
+	"strconv"
+)
+
+const RefTypeSha = "sha"
+const RefTypeTag = "tag"
+const RefTypeBranch = "branch"
+const RefTypeNone = "none"
+
+var shaRegex = regexp.MustCompile(`^[0-9a-fA-F]{40}$`)
+var semVerRegex = regexp.MustCompile(`^[vV]?(\d+)(?:\.(\d+))?(?:\.(\d+))?(?:-([0-9A-Za-z.-]+))?(?:\+[0-9A-Za-z.-]+)?$`)
+
+// SemVer is a semantic version parsed from an action version like "v4", "v4.1" or "v4.1.0".
+type SemVer struct {
+	Major      int
+	Minor      int
+	Patch      int
+	Prerelease string
+	// Precision is the number of version components that were specified, from 1 for "v4" to 3 for "v4.1.0".
+	Precision int
+}
+
+// ParseSemVer parses a version like "v4.1.0". The second return value is false if the version
+// is not a semantic version.
+func ParseSemVer(version string) (SemVer, bool) {
+	matches := semVerRegex.FindStringSubmatch(version)
+	if matches == nil {
+		return SemVer{}, false
+	}
+
+	semVer := SemVer{Prerelease: matches[4], Precision: 1}
+	semVer.Major, _ = strconv.Atoi(matches[1])
+
+	if matches[2] != "" {
+		semVer.Minor, _ = strconv.Atoi(matches[2])
+		semVer.Precision = 2
+	}
+
+	if matches[3] != "" {
+		semVer.Patch, _ = strconv.Atoi(matches[3])
+		semVer.Precision = 3
+	}
+
+	return semVer, true
+}
+
+// Compare returns -1, 0 or 1 if the version is lower than, equal to, or higher than the other version.
+// Missing components are treated as zero, and prereleases are lower than the matching release.
+func (v SemVer) Compare(other SemVer) int {
+	for _, diff := range []int{v.Major - other.Major, v.Minor - other.Minor, v.Patch - other.Patch} {
+		if diff < 0 {
+			return -1
+		}
+		if diff > 0 {
+			return 1
+		}
+	}
+
+	switch {
+	case v.Prerelease == other.Prerelease:
+		return 0
+	case v.Prerelease == "":
+		return 1
+	case other.Prerelease == "":
+		return -1
+	case v.Prerelease < other.Prerelease:
+		return -1
+	default:
+		return 1
+	}
+}
+
+// IsSha returns true if the version is a full length commit SHA.
+func IsSha(version string) bool {
+	return shaRegex.MatchString(version)
+}
+
+// GetRefType classifies the version of an action as a commit SHA, a tag, or a branch.
+// Versions that look like semantic versions are assumed to be tags. Actions without a
+// version return RefTypeNone.
+func GetRefType(version string) string {
+	if version == "" || version == "latest" {
+		return RefTypeNone
+	}
+
+	if IsSha(version) {
+		return RefTypeSha
+	}
+
+	if _, ok := ParseSemVer(version); ok {
+		return RefTypeTag
+	}
+
+	return RefTypeBranch
+}
@@ -0,0 +1,80 @@
+package parsing
+
+import "testing"
+
+func TestParseSemVer(t *testing.T) {
+	tests := []struct {
+		version    string
+		expected   SemVer
+		expectedOk bool
+	}{
+		{"v4", SemVer{Major: 4, Precision: 1}, true},
+		{"4", SemVer{Major: 4, Precision: 1}, true},
+		{"v4.1", SemVer{Major: 4, Minor: 1, Precision: 2}, true},
+		{"v4.1.0", SemVer{Major: 4, Minor: 1, Patch: 0, Precision: 3}, true},
+		{"V10.20.30", SemVer{Major: 10, Minor: 20, Patch: 30, Precision: 3}, true},
+		{"v4.1.0-rc.1", SemVer{Major: 4, Minor: 1, Prerelease: "rc.1", Precision: 3}, true},
+		{"v3.0.0+build.123", SemVer{Major: 3, Precision: 3}, true},
+		{"main", SemVer{}, false},
+		{"latest", SemVer{}, false},
+		{"", SemVer{}, false},
+		{"v1.2.3.4", SemVer{}, false},
+		{"8e5e7e5ab8b370d6c329ec480221332ada57f0ab", SemVer{}, false},
+	}
+
+	for _, tt := range tests {
+		result, ok := ParseSemVer(tt.version)
+		if ok != tt.expectedOk {
+			t.Errorf("ParseSemVer(%q) ok = %v, expected %v", tt.version, ok, tt.expectedOk)
+		}
+		if result != tt.expected {
+			t.Errorf("ParseSemVer(%q) = %+v, expected %+v", tt.version, result, tt.expected)
+		}
+	}
+}
+
+func TestSemVerCompare(t *testing.T) {
+	tests := []struct {
+		version1 string
+		version2 string
+		expected int
+	}{
+		{"v4", "v3", 1},
+		{"v3", "v4", -1},
+		{"v4", "v4.0.0", 0},
+		{"v4.1.0", "v4.0.9", 1},
+		{"v4.1.1", "v4.1.2", -1},
+		{"v4.1.0-rc.1", "v4.1.0", -1},
+		{"v4.1.0", "v4.1.0-rc.1", 1},
+		{"v4.1.0-rc.1", "v4.1.0-rc.2", -1},
+	}
+
+	for _, tt := range tests {
+		semVer1, _ := ParseSemVer(tt.version1)
+		semVer2, _ := ParseSemVer(tt.version2)
+		if result := semVer1.Compare(semVer2); result != tt.expected {
+			t.Errorf("Compare(%q, %q) = %d, expected %d", tt.version1, tt.version2, result, tt.expected)
+		}
+	}
+}
+
+func TestGetRefType(t *testing.T) {
+	tests := map[string]string{
+		"v4":         RefTypeTag,
+		"v4.1.0":     RefTypeTag,
+		"3":          RefTypeTag,
+		"main":       RefTypeBranch,
+		"release/v1": RefTypeBranch,
+		"8e5e7e5ab8b370d6c329ec480221332ada57f0ab": RefTypeSha,
+		"8E5E7E5AB8B370D6C329EC480221332ADA57F0AB": RefTypeSha,
+		"8e5e7e5": RefTypeBranch,
+		"latest":  RefTypeNone,
+		"":        RefTypeNone,
+	}
+
+	for version, expected := range tests {
+		if result := GetRefType(version); result != expected {
+			t.Errorf("GetRefType(%q) = %q, expected %q", version, result, expected)
+		}
+	}
+}
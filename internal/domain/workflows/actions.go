@@ -1,17 +1,157 @@
 package workflows
 
-import "github.com/OctopusSolutionsEngineering/DuplicationCostCalculator/internal/domain/models"
+import (
+	"slices"
+	"strings"
+
+	"github.com/OctopusSolutionsEngineering/DuplicationCostCalculator/internal/domain/models"
+	"github.com/OctopusSolutionsEngineering/DuplicationCostCalculator/internal/domain/parsing"
+	"github.com/samber/lo"
+)
+
+const DriftSeverityMajor = "major"
+const DriftSeverityMinor = "minor"
+const DriftSeverityPatch = "patch"
+const DriftSeverityRefType = "ref-type"
+
+// DriftSeverities lists the drift severities from most to least severe.
+var DriftSeverities = []string{DriftSeverityMajor, DriftSeverityRefType, DriftSeverityMinor, DriftSeverityPatch}
+
+// DriftSeverityWeights is the fraction of the effort of a consistent change that each drift severity represents.
+var DriftSeverityWeights = map[string]float64{
+	DriftSeverityMajor:   1,
+	DriftSeverityRefType: 0.5,
+	DriftSeverityMinor:   0.5,
+	DriftSeverityPatch:   0.25,
+}
 
 // HasVersionDrift checks if two actions represent the same action but with different versions.
 // It returns true if:
 // - Both actions have a non-empty Uses field
 // - Both actions have non-empty UsesVersion fields
 // - The Uses fields match (same action)
-// - The UsesVersion fields differ (different versions)
+// - The versions differ (different versions), after resolving SHA pinned versions to tags
 func HasVersionDrift(action1, action2 models.Action) bool {
 	return action1.Uses != "" &&
 		action1.UsesVersion != "" &&
 		action2.UsesVersion != "" &&
 		action1.Uses == action2.Uses &&
-		action1.UsesVersion != action2.UsesVersion
+		GetEffectiveVersion(action1) != GetEffectiveVersion(action2)
+}
+
+// GetEffectiveVersion returns the tag that a SHA pinned action resolves to, or the version of the action.
+func GetEffectiveVersion(action models.Action) string {
+	if action.ResolvedVersion != "" {
+		return action.ResolvedVersion
+	}
+
+	return action.UsesVersion
+}
+
+// ClassifyVersionDrift returns the severity of the version drift between two actions, or an empty
+// string if there is no drift. Versions with different ref types (tag, branch, or SHA) are a "ref-type"
+// drift. Branches and SHAs that could not be resolved to a tag can not be measured, and are treated as
+// "major" drift.
+func ClassifyVersionDrift(action1, action2 models.Action) string {
+	if !HasVersionDrift(action1, action2) {
+		return ""
+	}
+
+	version1 := GetEffectiveVersion(action1)
+	version2 := GetEffectiveVersion(action2)
+
+	if parsing.GetRefType(version1) != parsing.GetRefType(version2) {
+		return DriftSeverityRefType
+	}
+
+	semVer1, ok1 := parsing.ParseSemVer(version1)
+	semVer2, ok2 := parsing.ParseSemVer(version2)
+
+	if !ok1 || !ok2 {
+		return DriftSeverityMajor
+	}
+
+	return ClassifySemVerDrift(semVer1, semVer2)
+}
+
+// DriftSeverityCache remembers the severity of the drift between pairs of versions, so the versions used by many
+// repositories are only parsed once when generating a report.
+type DriftSeverityCache map[[2]string]string
+
+// Classify returns the same result as ClassifyVersionDrift, reusing the severity of any pair of versions that has
+// already been classified.
+func (c DriftSeverityCache) Classify(action1, action2 models.Action) string {
+	if !HasVersionDrift(action1, action2) {
+		return ""
+	}
+
+	key := [2]string{GetEffectiveVersion(action1), GetEffectiveVersion(action2)}
+	if severity, ok := c[key]; ok {
+		return severity
+	}
+
+	severity := ClassifyVersionDrift(action1, action2)
+	c[key] = severity
+
+	return severity
+}
+
+// ClassifySemVerDrift returns the severity of the difference between two semantic versions.
+// A version like "v4" floats across minor releases, so comparing it to "v4.1.0" is a minor drift.
+func ClassifySemVerDrift(version1, version2 parsing.SemVer) string {
+	if version1.Major != version2.Major {
+		return DriftSeverityMajor
+	}
+
+	if version1.Precision < 2 || version2.Precision < 2 || version1.Minor != version2.Minor {
+		return DriftSeverityMinor
+	}
+
+	return DriftSeverityPatch
+}
+
+// GetHighestDriftSeverity returns the most severe drift in the list, or an empty string if the list is empty.
+func GetHighestDriftSeverity(drift []models.VersionDrift) string {
+	for _, severity := range DriftSeverities {
+		if lo.ContainsBy(drift, func(item models.VersionDrift) bool {
+			return item.Severity == severity
+		}) {
+			return severity
+		}
+	}
+
+	return ""
+}
+
+// ResolveShaToTag returns the name of the tag that points to the commit SHA, or an empty string if there is none.
+// When several tags point to the same commit, the most specific semantic version is preferred,
+// so "v4.1.0" is returned instead of "v4".
+func ResolveShaToTag(sha string, tags []models.Tag) string {
+	matchingTags := lo.Filter(tags, func(item models.Tag, index int) bool {
+		return strings.EqualFold(item.Commit, sha)
+	})
+
+	if len(matchingTags) == 0 {
+		return ""
+	}
+
+	slices.SortStableFunc(matchingTags, func(a, b models.Tag) int {
+		semVerA, okA := parsing.ParseSemVer(a.Name)
+		semVerB, okB := parsing.ParseSemVer(b.Name)
+
+		switch {
+		case okA && !okB:
+			return -1
+		case !okA && okB:
+			return 1
+		case !okA && !okB:
+			return 0
+		case semVerA.Precision != semVerB.Precision:
+			return semVerB.Precision - semVerA.Precision
+		default:
+			return semVerB.Compare(semVerA)
+		}
+	})
+
+	return matchingTags[0].Name
 }
@@ -1,6 +1,7 @@
 package workflows
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/OctopusSolutionsEngineering/DuplicationCostCalculator/internal/domain/models"
@@ -192,3 +193,146 @@ func TestHasVersionDrift(t *testing.T) {
 		})
 	}
 }
+
+func TestClassifyVersionDrift(t *testing.T) {
+	const sha1 = "8e5e7e5ab8b370d6c329ec480221332ada57f0ab"
+	const sha2 = "b4ffde65f46336ab88eb53be808477a3936bae11"
+
+	tests := []struct {
+		name     string
+		action1  models.Action
+		action2  models.Action
+		expected string
+	}{
+		{
+			name:     "no drift",
+			action1:  models.Action{Uses: "actions/checkout", UsesVersion: "v4"},
+			action2:  models.Action{Uses: "actions/checkout", UsesVersion: "v4"},
+			expected: "",
+		},
+		{
+			name:     "different actions",
+			action1:  models.Action{Uses: "actions/checkout", UsesVersion: "v4"},
+			action2:  models.Action{Uses: "actions/setup-go", UsesVersion: "v2"},
+			expected: "",
+		},
+		{
+			name:     "major drift",
+			action1:  models.Action{Uses: "actions/checkout", UsesVersion: "v2"},
+			action2:  models.Action{Uses: "actions/checkout", UsesVersion: "v4"},
+			expected: DriftSeverityMajor,
+		},
+		{
+			name:     "major version compared to full version",
+			action1:  models.Action{Uses: "actions/checkout", UsesVersion: "v4"},
+			action2:  models.Action{Uses: "actions/checkout", UsesVersion: "v4.1.0"},
+			expected: DriftSeverityMinor,
+		},
+		{
+			name:     "minor drift",
+			action1:  models.Action{Uses: "actions/checkout", UsesVersion: "v4.0.0"},
+			action2:  models.Action{Uses: "actions/checkout", UsesVersion: "v4.1.0"},
+			expected: DriftSeverityMinor,
+		},
+		{
+			name:     "patch drift",
+			action1:  models.Action{Uses: "actions/checkout", UsesVersion: "v4.1.0"},
+			action2:  models.Action{Uses: "actions/checkout", UsesVersion: "v4.1.2"},
+			expected: DriftSeverityPatch,
+		},
+		{
+			name:     "minor version compared to patch version",
+			action1:  models.Action{Uses: "actions/checkout", UsesVersion: "v4.1"},
+			action2:  models.Action{Uses: "actions/checkout", UsesVersion: "v4.1.2"},
+			expected: DriftSeverityPatch,
+		},
+		{
+			name:     "branch compared to tag",
+			action1:  models.Action{Uses: "actions/checkout", UsesVersion: "main"},
+			action2:  models.Action{Uses: "actions/checkout", UsesVersion: "v4"},
+			expected: DriftSeverityRefType,
+		},
+		{
+			name:     "unresolved SHA compared to tag",
+			action1:  models.Action{Uses: "actions/checkout", UsesVersion: sha1},
+			action2:  models.Action{Uses: "actions/checkout", UsesVersion: "v4"},
+			expected: DriftSeverityRefType,
+		},
+		{
+			name:     "SHA resolved to the same tag",
+			action1:  models.Action{Uses: "actions/checkout", UsesVersion: sha1, ResolvedVersion: "v4.1.0"},
+			action2:  models.Action{Uses: "actions/checkout", UsesVersion: "v4.1.0"},
+			expected: "",
+		},
+		{
+			name:     "SHA resolved to a different major tag",
+			action1:  models.Action{Uses: "actions/checkout", UsesVersion: sha1, ResolvedVersion: "v3.6.0"},
+			action2:  models.Action{Uses: "actions/checkout", UsesVersion: "v4.1.0"},
+			expected: DriftSeverityMajor,
+		},
+		{
+			name:     "two unresolved SHAs",
+			action1:  models.Action{Uses: "actions/checkout", UsesVersion: sha1},
+			action2:  models.Action{Uses: "actions/checkout", UsesVersion: sha2},
+			expected: DriftSeverityMajor,
+		},
+		{
+			name:     "two branches",
+			action1:  models.Action{Uses: "actions/checkout", UsesVersion: "main"},
+			action2:  models.Action{Uses: "actions/checkout", UsesVersion: "develop"},
+			expected: DriftSeverityMajor,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if result := ClassifyVersionDrift(tt.action1, tt.action2); result != tt.expected {
+				t.Errorf("ClassifyVersionDrift() = %q, expected %q", result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestGetHighestDriftSeverity(t *testing.T) {
+	if result := GetHighestDriftSeverity([]models.VersionDrift{}); result != "" {
+		t.Errorf("Expected no severity for an empty list, got %q", result)
+	}
+
+	drift := []models.VersionDrift{
+		{Severity: DriftSeverityPatch},
+		{Severity: DriftSeverityRefType},
+		{Severity: DriftSeverityMinor},
+	}
+
+	if result := GetHighestDriftSeverity(drift); result != DriftSeverityRefType {
+		t.Errorf("Expected ref-type severity, got %q", result)
+	}
+}
+
+func TestResolveShaToTag(t *testing.T) {
+	const sha = "8e5e7e5ab8b370d6c329ec480221332ada57f0ab"
+
+	tags := []models.Tag{
+		{Name: "v4", Commit: sha},
+		{Name: "v4.1.0", Commit: sha},
+		{Name: "stable", Commit: sha},
+		{Name: "v4.1", Commit: sha},
+		{Name: "v3.0.0", Commit: "b4ffde65f46336ab88eb53be808477a3936bae11"},
+	}
+
+	if result := ResolveShaToTag(sha, tags); result != "v4.1.0" {
+		t.Errorf("Expected the most specific tag v4.1.0, got %q", result)
+	}
+
+	if result := ResolveShaToTag(strings.ToUpper(sha), tags); result != "v4.1.0" {
+		t.Errorf("Expected SHA matching to ignore case, got %q", result)
+	}
+
+	if result := ResolveShaToTag("0000000000000000000000000000000000000000", tags); result != "" {
+		t.Errorf("Expected no tag for an unknown SHA, got %q", result)
+	}
+
+	if result := ResolveShaToTag(sha, []models.Tag{{Name: "stable", Commit: sha}}); result != "stable" {
+		t.Errorf("Expected non-semver tags to be used when there is no alternative, got %q", result)
+	}
+}
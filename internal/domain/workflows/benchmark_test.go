@@ -0,0 +1,153 @@
+package workflows
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/OctopusSolutionsEngineering/DuplicationCostCalculator/internal/domain/models"
+	"github.com/samber/lo"
+)
+
+// syntheticActions are the actions that synthetic workflows are built from. Each workflow uses a different
+// subset and version of these actions so the repos have a realistic mix of drift and duplication.
+var syntheticActions = []string{
+	"actions/checkout",
+	"actions/setup-go",
+	"actions/setup-node",
+	"actions/cache",
+	"actions/upload-artifact",
+	"actions/download-artifact",
+	"docker/login-action",
+	"docker/build-push-action",
+	"docker/metadata-action",
+	"azure/login",
+}
+
+// generateSyntheticWorkflow builds a workflow with a mix of actions and scripts that differs by repo and workflow.
+func generateSyntheticWorkflow(repo int, workflow int) string {
+	builder := strings.Builder{}
+	builder.WriteString("jobs:\n  build:\n    steps:\n")
+
+	for step := 0; step < 8; step++ {
+		action := syntheticActions[(repo+workflow+step)%len(syntheticActions)]
+		builder.WriteString(fmt.Sprintf("      - uses: %s@v%d\n", action, (repo+step)%4+1))
+		builder.WriteString("        with:\n")
+		builder.WriteString(fmt.Sprintf("          path: ./build/output/directory/%d\n", step))
+		builder.WriteString(fmt.Sprintf("          key: ${{ runner.os }}-cache-key-for-step-%d-%d\n", step, workflow%3))
+		builder.WriteString("          retention-days: 14\n")
+	}
+
+	builder.WriteString("      - run: |\n")
+	builder.WriteString("          npm ci --no-audit\n")
+	builder.WriteString(fmt.Sprintf("          npm run build -- --configuration production-%d\n", workflow%2))
+	builder.WriteString("          npm test -- --coverage --reporters=default\n")
+
+	return builder.String()
+}
+
+// generateSyntheticWorkflows builds the given number of repos, each with the given number of workflows.
+func generateSyntheticWorkflows(repos int, workflowsPerRepo int) map[string][]string {
+	result := map[string][]string{}
+
+	for repo := 0; repo < repos; repo++ {
+		name := fmt.Sprintf("org/repo%d", repo)
+		for workflow := 0; workflow < workflowsPerRepo; workflow++ {
+			result[name] = append(result[name], generateSyntheticWorkflow(repo, workflow))
+		}
+	}
+
+	return result
+}
+
+func BenchmarkGenerateReportFromWorkflows(b *testing.B) {
+	sizes := []struct {
+		repos     int
+		workflows int
+	}{
+		{10, 10},
+		{50, 10},
+		{100, 5},
+	}
+
+	for _, size := range sizes {
+		workflows := generateSyntheticWorkflows(size.repos, size.workflows)
+
+		b.Run(fmt.Sprintf("repos=%d/workflows=%d", size.repos, size.workflows), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				GenerateReportFromWorkflows(workflows, nil, nil)
+			}
+		})
+	}
+}
+
+func BenchmarkGenerateReportWithSuppressions(b *testing.B) {
+	allRepoActions := lo.MapToSlice(generateSyntheticWorkflows(100, 5), func(repo string, workflows []string) RepoActions {
+		return RepoActions{Repo: repo, Workflows: workflows}
+	})
+	options := ReportOptions{Suppressions: []models.Suppression{{Action: "actions/checkout", Expires: "2999-12-31", Reason: "Benchmark"}}}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		GenerateReportFromRepoActions(allRepoActions, options)
+	}
+}
+
+func BenchmarkFindActionsWithDifferentVersions(b *testing.B) {
+	actionsMap := ConvertWorkflowToActionsMap(generateSyntheticWorkflows(2, 200))
+	actions1 := flattenSyntheticRepo(actionsMap, "org/repo0")
+	actions2 := flattenSyntheticRepo(actionsMap, "org/repo1")
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		FindActionsWithDifferentVersions(actions1, actions2)
+	}
+}
+
+func BenchmarkFindActionsWithSimilarConfigurations(b *testing.B) {
+	actionsMap := ConvertWorkflowToActionsMap(generateSyntheticWorkflows(2, 200))
+	actions1 := flattenSyntheticRepo(actionsMap, "org/repo0")
+	actions2 := flattenSyntheticRepo(actionsMap, "org/repo1")
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		FindActionsWithSimilarConfigurations(actions1, actions2)
+	}
+}
+
+func BenchmarkFindVersionDrift(b *testing.B) {
+	actionsMap := ConvertWorkflowToActionsMap(generateSyntheticWorkflows(2, 200))
+	actions1 := flattenSyntheticRepo(actionsMap, "org/repo0")
+	actions2 := flattenSyntheticRepo(actionsMap, "org/repo1")
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		FindVersionDrift(actions1, actions2)
+	}
+}
+
+// generateSyntheticScripts builds run steps with different scripts, like the build and deploy scripts of a repo.
+func generateSyntheticScripts(repo int, count int) []models.Action {
+	return lo.Times(count, func(index int) models.Action {
+		builder := strings.Builder{}
+		for line := 0; line < 6; line++ {
+			builder.WriteString(fmt.Sprintf("./scripts/task-%d.sh --target %d --env env-%d\n", (index*7+line)%101, (repo+line)%5, index%13))
+		}
+
+		return models.Action{Id: fmt.Sprintf("%d-%d", repo, index), Run: builder.String()}
+	})
+}
+
+func BenchmarkFindSimilarScripts(b *testing.B) {
+	indexed1 := IndexRepoActions([][]models.Action{generateSyntheticScripts(0, 500)})
+	indexed2 := IndexRepoActions([][]models.Action{generateSyntheticScripts(1, 500)})
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		FindSimilarScriptsFromShingles(indexed1.Actions, indexed1.ScriptShingles, indexed2.Actions, indexed2.ScriptShingles, indexed2.ScriptIndex)
+	}
+}
+
+func flattenSyntheticRepo(actionsMap map[string][][]models.Action, repo string) []models.Action {
+	return lo.Flatten(actionsMap[repo])
+}
@@ -0,0 +1,167 @@
+package workflows
+
+import (
+	"cmp"
+	"maps"
+	"slices"
+
+	"github.com/OctopusSolutionsEngineering/DuplicationCostCalculator/internal/domain/models"
+	"github.com/samber/lo"
+)
+
+// MinClusterRepos is the number of repositories a cluster must span to be reported. Similar steps within a
+// single repository are not candidates for a shared action.
+const MinClusterRepos = 2
+
+// ClusterActions groups the actions in all the repositories into clusters of steps that call the same action
+// with similar configuration. Each action is compared to the first member of each existing cluster for the same
+// action, and joins the first cluster within HighSimilarity. Comparing to a fixed member prevents a chain of
+// slightly different steps from being merged into a single cluster.
+// Only clusters that span at least MinClusterRepos repositories are returned, sorted by the number of repositories.
+func ClusterActions(repoActions map[string][][]models.Action) []models.ActionCluster {
+	return ClusterActionsWithSuppressions(repoActions, nil)
+}
+
+// ClusterActionsWithSuppressions is ClusterActions, excluding the duplication that is accepted by the suppressions.
+func ClusterActionsWithSuppressions(repoActions map[string][][]models.Action, suppressions []models.Suppression) []models.ActionCluster {
+	clusters := map[string][][]ClusterCandidate{}
+
+	for _, repo := range slices.Sorted(maps.Keys(repoActions)) {
+		for _, action := range lo.Flatten(repoActions[repo]) {
+			// Actions without enough configuration to hash can't be compared
+			if action.Hash == nil {
+				continue
+			}
+
+			uses := action.Uses
+			if uses == "" {
+				uses = BuiltInStep
+			}
+
+			candidate := ClusterCandidate{Repo: repo, Action: action}
+
+			index := slices.IndexFunc(clusters[uses], func(cluster []ClusterCandidate) bool {
+				return cluster[0].Action.Hash.Diff(action.Hash) <= HighSimilarity
+			})
+
+			if index == -1 {
+				clusters[uses] = append(clusters[uses], []ClusterCandidate{candidate})
+				continue
+			}
+
+			// A step from a remote composite action that is already in the cluster is shared rather than
+			// duplicated, so it is only counted once
+			if lo.SomeBy(clusters[uses][index], func(item ClusterCandidate) bool {
+				return IsSharedCompositeStep(item.Action, action)
+			}) {
+				continue
+			}
+
+			clusters[uses][index] = append(clusters[uses][index], candidate)
+		}
+	}
+
+	result := []models.ActionCluster{}
+	for uses, usesClusters := range clusters {
+		for _, cluster := range usesClusters {
+			cluster = RemoveSuppressedCandidates(suppressions, cluster)
+			if len(cluster) == 0 {
+				continue
+			}
+
+			actionCluster := BuildActionCluster(uses, cluster)
+			if len(actionCluster.Repos) >= MinClusterRepos {
+				result = append(result, actionCluster)
+			}
+		}
+	}
+
+	slices.SortFunc(result, func(a, b models.ActionCluster) int {
+		return cmp.Or(
+			cmp.Compare(len(b.Repos), len(a.Repos)),
+			cmp.Compare(len(b.Members), len(a.Members)),
+			cmp.Compare(a.Uses, b.Uses),
+			cmp.Compare(a.Centroid.Repo+a.Centroid.Id, b.Centroid.Repo+b.Centroid.Id),
+		)
+	})
+
+	return result
+}
+
+// RemoveSuppressedCandidates removes the members of a cluster whose duplication with every other repository in the
+// cluster is accepted by a suppression. A member is kept while it duplicates a step in any repository that it is
+// not suppressed with.
+func RemoveSuppressedCandidates(suppressions []models.Suppression, cluster []ClusterCandidate) []ClusterCandidate {
+	if len(suppressions) == 0 {
+		return cluster
+	}
+
+	repos := lo.Uniq(lo.Map(cluster, func(item ClusterCandidate, index int) string {
+		return item.Repo
+	}))
+
+	return lo.Filter(cluster, func(item ClusterCandidate, index int) bool {
+		return lo.SomeBy(repos, func(repo string) bool {
+			if repo == item.Repo {
+				return false
+			}
+
+			_, suppressed := MatchSuppression(suppressions, item.Repo, repo, item.Action.Uses)
+			return !suppressed
+		})
+	})
+}
+
+// ClusterCandidate is an action and the repository it was found in.
+type ClusterCandidate struct {
+	Repo   string
+	Action models.Action
+}
+
+// BuildActionCluster creates the report entry for a cluster of similar actions.
+func BuildActionCluster(uses string, cluster []ClusterCandidate) models.ActionCluster {
+	members := lo.Map(cluster, func(item ClusterCandidate, index int) models.ActionClusterMember {
+		return ConvertToClusterMember(item)
+	})
+
+	return models.ActionCluster{
+		Uses:    uses,
+		Members: members,
+		Repos: lo.Uniq(lo.Map(members, func(item models.ActionClusterMember, index int) string {
+			return item.Repo
+		})),
+		Workflows: lo.Uniq(lo.Map(members, func(item models.ActionClusterMember, index int) string {
+			return item.Repo + "/" + item.Workflow
+		})),
+		Centroid: ConvertToClusterMember(FindCentroid(cluster)),
+	}
+}
+
+// FindCentroid returns the member of the cluster with the smallest total distance to all the other members.
+// This is the configuration that best represents the cluster, and the best starting point for a shared action.
+func FindCentroid(cluster []ClusterCandidate) ClusterCandidate {
+	return lo.MinBy(cluster, func(a ClusterCandidate, b ClusterCandidate) bool {
+		return GetTotalDistance(a, cluster) < GetTotalDistance(b, cluster)
+	})
+}
+
+// GetTotalDistance returns the sum of the TLSH distances between the candidate and each member of the cluster.
+func GetTotalDistance(candidate ClusterCandidate, cluster []ClusterCandidate) int {
+	return lo.SumBy(cluster, func(item ClusterCandidate) int {
+		return candidate.Action.Hash.Diff(item.Action.Hash)
+	})
+}
+
+// ConvertToClusterMember captures the location and configuration of a clustered action.
+func ConvertToClusterMember(candidate ClusterCandidate) models.ActionClusterMember {
+	return models.ActionClusterMember{
+		Repo:     candidate.Repo,
+		Workflow: candidate.Action.Workflow,
+		Id:       candidate.Action.Id,
+		Version:  candidate.Action.UsesVersion,
+		Settings: candidate.Action.Settings,
+		Env:      candidate.Action.Env,
+		With:     candidate.Action.With,
+		Secrets:  candidate.Action.Secrets,
+	}
+}
@@ -0,0 +1,193 @@
+package workflows
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/OctopusSolutionsEngineering/DuplicationCostCalculator/internal/domain/models"
+	"github.com/OctopusSolutionsEngineering/DuplicationCostCalculator/internal/infrastructure/memory"
+)
+
+const deployWorkflow = `
+jobs:
+  deploy:
+    steps:
+      - uses: actions/checkout@v4
+      - uses: azure/webapps-deploy@v3
+        with:
+          app-name: my-application-production
+          package: ./dist/application-package.zip
+          slot-name: staging-deployment-slot
+          publish-profile: ${{ secrets.AZURE_WEBAPP_PUBLISH_PROFILE }}
+        env:
+          DEPLOYMENT_ENVIRONMENT: production
+`
+
+const otherDeployWorkflow = `
+jobs:
+  release:
+    steps:
+      - uses: azure/webapps-deploy@v3
+        with:
+          images: ghcr.io/another-organization/container-image:latest
+          resource-group-name: completely-different-group
+        env:
+          REGISTRY_USERNAME: ${{ github.actor }}
+          REGISTRY_PASSWORD: ${{ secrets.GITHUB_TOKEN }}
+`
+
+func newDeployAction(repo string, env string) ClusterCandidate {
+	action := models.Action{
+		Id:          repo + "-1",
+		Uses:        "azure/webapps-deploy",
+		UsesVersion: "v3",
+		With: map[string]string{
+			"app-name":        "my-application-production",
+			"package":         "./dist/application-package.zip",
+			"slot-name":       "staging-deployment-slot",
+			"publish-profile": "${{ secrets.AZURE_WEBAPP_PUBLISH_PROFILE }}",
+		},
+		Env: map[string]string{
+			"DEPLOYMENT_ENVIRONMENT": env,
+		},
+	}
+	action.GenerateHash()
+
+	return ClusterCandidate{Repo: repo, Action: action}
+}
+
+func TestClusterActions(t *testing.T) {
+	// Arrange
+	source := memory.NewWorkflowSource().
+		AddWorkflow("owner/repo1", "deploy.yml", deployWorkflow).
+		AddWorkflow("owner/repo2", "release.yml", deployWorkflow).
+		AddWorkflow("owner/repo3", "cd.yml", deployWorkflow).
+		AddWorkflow("owner/repo3", "other.yml", otherDeployWorkflow).
+		AddWorkflow("owner/repo4", "deploy.yml", otherDeployWorkflow)
+
+	// Act
+	report := GenerateReportFromSource(context.Background(), source, []string{"owner/repo1", "owner/repo2", "owner/repo3", "owner/repo4"})
+
+	// Assert
+	if len(report.Clusters) != 2 {
+		t.Fatalf("Expected 2 clusters, got %d: %+v", len(report.Clusters), report.Clusters)
+	}
+
+	cluster := report.Clusters[0]
+
+	if cluster.Uses != "azure/webapps-deploy" {
+		t.Errorf("Expected the cluster to be for azure/webapps-deploy, got %s", cluster.Uses)
+	}
+
+	if !slices.Equal(cluster.Repos, []string{"owner/repo1", "owner/repo2", "owner/repo3"}) {
+		t.Errorf("Unexpected repos %v", cluster.Repos)
+	}
+
+	if !slices.Equal(cluster.Workflows, []string{"owner/repo1/deploy.yml", "owner/repo2/release.yml", "owner/repo3/cd.yml"}) {
+		t.Errorf("Unexpected workflows %v", cluster.Workflows)
+	}
+
+	if len(cluster.Members) != 3 {
+		t.Errorf("Expected 3 members, got %d", len(cluster.Members))
+	}
+
+	if cluster.Centroid.With["app-name"] != "my-application-production" || cluster.Centroid.Version != "v3" {
+		t.Errorf("Unexpected centroid %+v", cluster.Centroid)
+	}
+
+	if !slices.Equal(report.Clusters[1].Repos, []string{"owner/repo3", "owner/repo4"}) {
+		t.Errorf("Expected the second cluster to contain the other deployment, got %v", report.Clusters[1].Repos)
+	}
+}
+
+func TestClusterActions_SingleRepo(t *testing.T) {
+	// Arrange - the same step repeated in one repo is not a candidate for a shared action
+	repoActions := ConvertWorkflowToActionsMap(map[string][]string{
+		"repo1": {deployWorkflow, deployWorkflow},
+	})
+
+	// Act
+	clusters := ClusterActions(repoActions)
+
+	// Assert
+	if len(clusters) != 0 {
+		t.Errorf("Expected no clusters, got %+v", clusters)
+	}
+}
+
+func TestClusterActions_DifferentUses(t *testing.T) {
+	// Arrange - identical configuration passed to different actions is not clustered
+	renamed := models.Action{Id: "1-1", Uses: "azure/other-deploy", UsesVersion: "v3", With: newDeployAction("repo2", "production").Action.With, Env: newDeployAction("repo2", "production").Action.Env}
+	renamed.GenerateHash()
+
+	repoActions := map[string][][]models.Action{
+		"repo1": {{newDeployAction("repo1", "production").Action}},
+		"repo2": {{renamed}},
+	}
+
+	// Act
+	clusters := ClusterActions(repoActions)
+
+	// Assert
+	if len(clusters) != 0 {
+		t.Errorf("Expected no clusters, got %+v", clusters)
+	}
+}
+
+func TestClusterActionsWithSuppressions(t *testing.T) {
+	// Arrange
+	repoActions := ConvertWorkflowToActionsMap(map[string][]string{
+		"owner/legacy": {deployWorkflow},
+		"owner/repo1":  {deployWorkflow},
+		"owner/repo2":  {deployWorkflow},
+	})
+
+	tests := []struct {
+		name          string
+		suppressions  []models.Suppression
+		expectedRepos []string
+	}{
+		{"no suppressions", nil, []string{"owner/legacy", "owner/repo1", "owner/repo2"}},
+		{"action", []models.Suppression{{Action: "azure/webapps-deploy"}}, nil},
+		{"repo", []models.Suppression{{Repos: []string{"owner/legacy"}}}, []string{"owner/repo1", "owner/repo2"}},
+		{"pair", []models.Suppression{{Repos: []string{"owner/legacy", "owner/repo1"}}}, []string{"owner/legacy", "owner/repo1", "owner/repo2"}},
+		{"other action", []models.Suppression{{Action: "actions/checkout"}}, []string{"owner/legacy", "owner/repo1", "owner/repo2"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			clusters := ClusterActionsWithSuppressions(repoActions, tt.suppressions)
+
+			// Assert
+			if tt.expectedRepos == nil {
+				if len(clusters) != 0 {
+					t.Errorf("Expected the suppressed cluster to be removed, got %+v", clusters)
+				}
+				return
+			}
+
+			if len(clusters) != 1 || !slices.Equal(clusters[0].Repos, tt.expectedRepos) {
+				t.Errorf("Expected a cluster of %v, got %+v", tt.expectedRepos, clusters)
+			}
+		})
+	}
+}
+
+func TestFindCentroid(t *testing.T) {
+	// Arrange
+	cluster := []ClusterCandidate{
+		newDeployAction("repo1", "production-environment-one"),
+		newDeployAction("repo2", "production"),
+		newDeployAction("repo3", "production"),
+	}
+
+	// Act
+	centroid := FindCentroid(cluster)
+
+	// Assert
+	if centroid.Action.Env["DEPLOYMENT_ENVIRONMENT"] != "production" {
+		t.Errorf("Expected the most common configuration to be the centroid, got %+v", centroid.Action.Env)
+	}
+}
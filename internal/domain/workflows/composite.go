@@ -0,0 +1,150 @@
+package workflows
+
+import (
+	"context"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/OctopusSolutionsEngineering/DuplicationCostCalculator/internal/domain/collections"
+	"github.com/OctopusSolutionsEngineering/DuplicationCostCalculator/internal/domain/models"
+	"github.com/OctopusSolutionsEngineering/DuplicationCostCalculator/internal/domain/parsing"
+	"github.com/samber/lo"
+	"gopkg.in/yaml.v3"
+)
+
+// MaxCompositeActionDepth limits how deeply composite actions calling other composite actions are followed.
+const MaxCompositeActionDepth = 5
+
+// LoadCompositeActions finds the composite actions called by the workflows, including composite actions
+// called by other composite actions. The returned map is keyed by the "uses" value of the calling step
+// and contains the content of the composite action's action.yml file.
+func LoadCompositeActions(ctx context.Context, source WorkflowSource, repo string, workflows []string) map[string]string {
+	compositeActions := map[string]string{}
+	checked := map[string]bool{}
+
+	pending := lo.FlatMap(workflows, func(item string, index int) []string {
+		return GetStepUses(item)
+	})
+
+	for depth := 0; depth < MaxCompositeActionDepth && len(pending) > 0; depth++ {
+		var next []string
+
+		for _, uses := range lo.Uniq(pending) {
+			if ctx.Err() != nil {
+				return compositeActions
+			}
+
+			if checked[uses] {
+				continue
+			}
+			checked[uses] = true
+
+			actionRepo, path, ref, ok := GetCompositeActionLocation(repo, uses)
+			if !ok {
+				continue
+			}
+
+			content := source.ActionToString(ctx, actionRepo, path, ref)
+
+			// Only composite actions have steps that can be expanded
+			if len(GetCompositeActionSteps(content)) == 0 {
+				continue
+			}
+
+			compositeActions[uses] = content
+			next = append(next, GetStepUses(content)...)
+		}
+
+		pending = next
+	}
+
+	return compositeActions
+}
+
+// GetCompositeActionLocation returns the repository, path, and ref of the action.yml file referenced by
+// the "uses" value of a step. Local actions like "./.github/actions/setup" are found in the repo that
+// calls them, and have an empty ref. Docker actions and references without a version can not be loaded.
+func GetCompositeActionLocation(repo string, uses string) (string, string, string, bool) {
+	if uses == "" || strings.HasPrefix(uses, "docker://") {
+		return "", "", "", false
+	}
+
+	if parsing.IsLocalReference(uses) {
+		return repo, strings.TrimSuffix(strings.TrimPrefix(uses, "./"), "/"), "", true
+	}
+
+	if !strings.Contains(uses, "@") {
+		return "", "", "", false
+	}
+
+	actionId, ref := parsing.GetActionIdAndVersion(uses)
+	actionRepo, path := parsing.SplitActionPath(actionId)
+
+	if actionRepo == "" || ref == "" {
+		return "", "", "", false
+	}
+
+	return actionRepo, path, ref, true
+}
+
+// IsSharedCompositeStep returns true if both actions were expanded from the same version of the same remote
+// composite action. These repositories already share the steps, so they are not duplication. Steps from local
+// composite actions are copies in each repository, and are still compared.
+func IsSharedCompositeStep(action1 models.Action, action2 models.Action) bool {
+	return action1.Source != "" && action1.Source == action2.Source && !parsing.IsLocalReference(action1.Source)
+}
+
+// GetCompositeActionSteps returns the steps defined by a composite action. An empty slice is returned
+// if the content is not a composite action.
+func GetCompositeActionSteps(action string) []interface{} {
+	var actionMap map[string]interface{}
+
+	if err := yaml.Unmarshal([]byte(action), &actionMap); err != nil {
+		return []interface{}{}
+	}
+
+	runsMap := collections.GetChildMap(actionMap, "runs")
+	if collections.GetStringProperty(runsMap, "using") != "composite" {
+		return []interface{}{}
+	}
+
+	steps, ok := runsMap["steps"].([]interface{})
+	if !ok {
+		return []interface{}{}
+	}
+
+	return steps
+}
+
+// GetStepUses returns the "uses" values of all the steps in a workflow or composite action.
+func GetStepUses(content string) []string {
+	var contentMap map[string]interface{}
+
+	if err := yaml.Unmarshal([]byte(content), &contentMap); err != nil {
+		return []string{}
+	}
+
+	steps := GetCompositeActionSteps(content)
+
+	jobsMap := collections.GetChildMap(contentMap, "jobs")
+	for _, key := range slices.Sorted(maps.Keys(jobsMap)) {
+		jobMap, ok := jobsMap[key].(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		if jobSteps, ok := jobMap["steps"].([]interface{}); ok {
+			steps = append(steps, jobSteps...)
+		}
+	}
+
+	return lo.FilterMap(steps, func(item interface{}, index int) (string, bool) {
+		stepMap, ok := item.(map[string]interface{})
+		if !ok {
+			return "", false
+		}
+		uses := collections.GetStringProperty(stepMap, "uses")
+		return uses, uses != ""
+	})
+}
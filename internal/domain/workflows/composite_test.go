@@ -0,0 +1,428 @@
+package workflows
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"testing"
+
+	"github.com/OctopusSolutionsEngineering/DuplicationCostCalculator/internal/infrastructure/memory"
+)
+
+const setupCompositeAction = `
+name: Setup
+runs:
+  using: composite
+  steps:
+    - uses: actions/setup-node@v3
+      with:
+        node-version: '18'
+    - run: npm ci
+      shell: bash
+`
+
+const nodeAction = `
+name: Node Action
+runs:
+  using: node20
+  main: index.js
+`
+
+func TestGetCompositeActionLocation(t *testing.T) {
+	tests := []struct {
+		name         string
+		uses         string
+		expectedRepo string
+		expectedPath string
+		expectedRef  string
+		expectedOk   bool
+	}{
+		{
+			name:         "local action",
+			uses:         "./.github/actions/setup",
+			expectedRepo: "owner/repo",
+			expectedPath: ".github/actions/setup",
+			expectedOk:   true,
+		},
+		{
+			name:         "local action with trailing slash",
+			uses:         "./.github/actions/setup/",
+			expectedRepo: "owner/repo",
+			expectedPath: ".github/actions/setup",
+			expectedOk:   true,
+		},
+		{
+			name:         "remote action in repository root",
+			uses:         "actions/checkout@v4",
+			expectedRepo: "actions/checkout",
+			expectedPath: "",
+			expectedRef:  "v4",
+			expectedOk:   true,
+		},
+		{
+			name:         "remote action in subdirectory",
+			uses:         "my-org/actions/setup/node@v1",
+			expectedRepo: "my-org/actions",
+			expectedPath: "setup/node",
+			expectedRef:  "v1",
+			expectedOk:   true,
+		},
+		{
+			name:       "docker action",
+			uses:       "docker://alpine:3.8",
+			expectedOk: false,
+		},
+		{
+			name:       "remote action without version",
+			uses:       "actions/checkout",
+			expectedOk: false,
+		},
+		{
+			name:       "empty uses",
+			uses:       "",
+			expectedOk: false,
+		},
+		{
+			name:       "remote action without repo",
+			uses:       "checkout@v4",
+			expectedOk: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo, path, ref, ok := GetCompositeActionLocation("owner/repo", tt.uses)
+			if ok != tt.expectedOk {
+				t.Fatalf("GetCompositeActionLocation(%q) ok = %v, expected %v", tt.uses, ok, tt.expectedOk)
+			}
+			if repo != tt.expectedRepo || path != tt.expectedPath || ref != tt.expectedRef {
+				t.Errorf("GetCompositeActionLocation(%q) = (%q, %q, %q), expected (%q, %q, %q)",
+					tt.uses, repo, path, ref, tt.expectedRepo, tt.expectedPath, tt.expectedRef)
+			}
+		})
+	}
+}
+
+func TestGetCompositeActionSteps(t *testing.T) {
+	if steps := GetCompositeActionSteps(setupCompositeAction); len(steps) != 2 {
+		t.Errorf("Expected 2 steps, got %d", len(steps))
+	}
+
+	if steps := GetCompositeActionSteps(nodeAction); len(steps) != 0 {
+		t.Errorf("Expected no steps for a node action, got %d", len(steps))
+	}
+
+	if steps := GetCompositeActionSteps("not: valid: yaml: ["); len(steps) != 0 {
+		t.Errorf("Expected no steps for invalid YAML, got %d", len(steps))
+	}
+
+	if steps := GetCompositeActionSteps(""); len(steps) != 0 {
+		t.Errorf("Expected no steps for empty content, got %d", len(steps))
+	}
+}
+
+func TestGetStepUses(t *testing.T) {
+	workflow := `
+jobs:
+  build:
+    steps:
+      - uses: actions/checkout@v4
+      - run: echo hello
+      - uses: ./.github/actions/setup
+  deploy:
+    uses: my-org/shared/.github/workflows/deploy.yml@v1
+`
+
+	uses := GetStepUses(workflow)
+	if !slices.Equal(uses, []string{"actions/checkout@v4", "./.github/actions/setup"}) {
+		t.Errorf("Unexpected uses values: %v", uses)
+	}
+
+	uses = GetStepUses(setupCompositeAction)
+	if !slices.Equal(uses, []string{"actions/setup-node@v3"}) {
+		t.Errorf("Unexpected uses values for composite action: %v", uses)
+	}
+}
+
+func TestLoadCompositeActions(t *testing.T) {
+	workflow := `
+jobs:
+  build:
+    steps:
+      - uses: actions/checkout@v4
+      - uses: ./.github/actions/setup
+      - uses: my-org/actions/deploy@v2
+`
+
+	deployAction := `
+runs:
+  using: composite
+  steps:
+    - uses: my-org/actions/login@v1
+`
+
+	loginAction := `
+runs:
+  using: composite
+  steps:
+    - run: echo login
+      shell: bash
+`
+
+	source := memory.NewWorkflowSource().
+		AddAction("owner/repo", ".github/actions/setup", "", setupCompositeAction).
+		AddAction("actions/checkout", "", "v4", nodeAction).
+		AddAction("my-org/actions", "deploy", "v2", deployAction).
+		AddAction("my-org/actions", "login", "v1", loginAction)
+
+	compositeActions := LoadCompositeActions(context.Background(), source, "owner/repo", []string{workflow})
+
+	if len(compositeActions) != 3 {
+		t.Fatalf("Expected 3 composite actions, got %d: %v", len(compositeActions), compositeActions)
+	}
+
+	if compositeActions["./.github/actions/setup"] != setupCompositeAction {
+		t.Error("Expected the local composite action to be loaded")
+	}
+
+	if _, ok := compositeActions["actions/checkout@v4"]; ok {
+		t.Error("Expected non-composite actions to be ignored")
+	}
+
+	if compositeActions["my-org/actions/login@v1"] != loginAction {
+		t.Error("Expected nested composite actions to be loaded")
+	}
+}
+
+func TestParseWorkflowWithCompositeActions(t *testing.T) {
+	workflow := `
+jobs:
+  build:
+    steps:
+      - uses: ./.github/actions/setup
+      - uses: actions/checkout@v4
+`
+
+	actions := ParseWorkflowWithCompositeActions(workflow, 1, map[string]string{
+		"./.github/actions/setup": setupCompositeAction,
+	})
+
+	if len(actions) != 4 {
+		t.Fatalf("Expected 4 actions, got %d", len(actions))
+	}
+
+	if actions[0].Uses != "./.github/actions/setup" || actions[0].Source != "" {
+		t.Errorf("Expected the calling step first, got %+v", actions[0])
+	}
+
+	if actions[1].Uses != "actions/setup-node" || actions[1].Source != "./.github/actions/setup" {
+		t.Errorf("Expected the expanded setup-node step, got %+v", actions[1])
+	}
+
+	if actions[2].Run != "npm ci" || actions[2].Source != "./.github/actions/setup" {
+		t.Errorf("Expected the expanded script step, got %+v", actions[2])
+	}
+
+	if actions[3].Uses != "actions/checkout" || actions[3].Source != "" {
+		t.Errorf("Expected the checkout step last, got %+v", actions[3])
+	}
+
+	ids := map[string]bool{}
+	for _, action := range actions {
+		if ids[action.Id] {
+			t.Errorf("Duplicate action ID %s", action.Id)
+		}
+		ids[action.Id] = true
+	}
+}
+
+func TestParseWorkflowWithRecursiveCompositeActions(t *testing.T) {
+	workflow := `
+jobs:
+  build:
+    steps:
+      - uses: ./.github/actions/loop
+`
+
+	loopAction := `
+runs:
+  using: composite
+  steps:
+    - uses: ./.github/actions/loop
+`
+
+	actions := ParseWorkflowWithCompositeActions(workflow, 1, map[string]string{
+		"./.github/actions/loop": loopAction,
+	})
+
+	if len(actions) != MaxCompositeActionDepth+1 {
+		t.Errorf("Expected recursion to stop after %d levels, got %d actions", MaxCompositeActionDepth, len(actions))
+	}
+}
+
+func TestGenerateReportFromSourceCompositeActionDrift(t *testing.T) {
+	workflow := `
+jobs:
+  build:
+    steps:
+      - uses: ./.github/actions/setup
+`
+
+	setupV4 := `
+runs:
+  using: composite
+  steps:
+    - uses: actions/setup-node@v4
+`
+
+	source := memory.NewWorkflowSource().
+		AddWorkflow("owner/repo1", "build.yml", workflow).
+		AddWorkflow("owner/repo2", "build.yml", workflow).
+		AddAction("owner/repo1", ".github/actions/setup", "", setupCompositeAction).
+		AddAction("owner/repo2", ".github/actions/setup", "", setupV4)
+
+	report := GenerateReportFromSource(context.Background(), source, []string{"owner/repo1", "owner/repo2"})
+
+	comparison := report.Comparisons["owner/repo1"]["owner/repo2"]
+	if !slices.Contains(comparison.StepsWithDifferentVersions, "actions/setup-node") {
+		t.Errorf("Expected drift in the composite action steps to be reported, got %v", comparison.StepsWithDifferentVersions)
+	}
+}
+
+func TestGenerateReportFromSourceSharedRemoteCompositeAction(t *testing.T) {
+	workflow := `
+jobs:
+  build:
+    steps:
+      - uses: my-org/shared/setup@v1
+`
+
+	sharedSetup := `
+runs:
+  using: composite
+  steps:
+    - uses: actions/setup-node@v3
+      with:
+        node-version: '18'
+        cache: npm
+        registry-url: https://registry.npmjs.org
+    - run: |
+        npm ci --ignore-scripts
+        npm run build --if-present
+        npm test -- --coverage
+      shell: bash
+`
+
+	source := memory.NewWorkflowSource().
+		AddWorkflow("owner/repo1", "build.yml", workflow).
+		AddWorkflow("owner/repo2", "build.yml", workflow).
+		AddAction("my-org/shared", "setup", "v1", sharedSetup)
+
+	report := GenerateReportFromSource(context.Background(), source, []string{"owner/repo1", "owner/repo2"})
+
+	comparison := report.Comparisons["owner/repo1"]["owner/repo2"]
+	if comparison.StepsWithSimilarConfigCount != 0 || comparison.StepsThatIndicateDuplicationRisk != 0 {
+		t.Errorf("Expected steps from a shared remote composite action not to be duplication, got %+v", comparison)
+	}
+
+	if comparison.SimilarScriptsCount != 0 {
+		t.Errorf("Expected scripts from a shared remote composite action not to be similar, got %v", comparison.SimilarScripts)
+	}
+
+	if report.NumberOfReposWithDuplicationOrDrift != 0 {
+		t.Errorf("Expected no repos with duplication or drift, got %d", report.NumberOfReposWithDuplicationOrDrift)
+	}
+
+	if len(report.Clusters) != 0 {
+		t.Errorf("Expected no clusters for a shared remote composite action, got %+v", report.Clusters)
+	}
+}
+
+// countingSource is a source that counts the actions it loads.
+type countingSource struct {
+	*memory.WorkflowSource
+	mutex   sync.Mutex
+	actions map[string]int
+}
+
+func (s *countingSource) ActionToString(ctx context.Context, repo string, path string, ref string) string {
+	s.mutex.Lock()
+	s.actions[repo+"/"+path+"@"+ref]++
+	s.mutex.Unlock()
+
+	return s.WorkflowSource.ActionToString(ctx, repo, path, ref)
+}
+
+func TestGenerateReportFromSourceLoadsRemoteActionsOnce(t *testing.T) {
+	// Arrange - every repository calls the same remote composite action, and has its own local action
+	workflow := `
+jobs:
+  build:
+    steps:
+      - uses: my-org/shared/setup@v1
+      - uses: ./.github/actions/setup
+`
+
+	source := &countingSource{WorkflowSource: memory.NewWorkflowSource(), actions: map[string]int{}}
+	repos := []string{"owner/repo1", "owner/repo2", "owner/repo3"}
+	for _, repo := range repos {
+		source.AddWorkflow(repo, "build.yml", workflow).
+			AddAction(repo, ".github/actions/setup", "", setupCompositeAction)
+	}
+	source.AddAction("my-org/shared", "setup", "v1", setupCompositeAction)
+
+	// Act
+	report := GenerateReportFromSource(context.Background(), source, repos)
+
+	// Assert
+	if report.NumberOfRepos != 3 {
+		t.Fatalf("Expected 3 repos, got %d", report.NumberOfRepos)
+	}
+
+	if count := source.actions["my-org/shared/setup@v1"]; count != 1 {
+		t.Errorf("Expected the remote action to be loaded once, got %d", count)
+	}
+
+	for _, repo := range repos {
+		if count := source.actions[repo+"/.github/actions/setup@"]; count != 1 {
+			t.Errorf("Expected the local action of %s to be loaded once, got %d", repo, count)
+		}
+	}
+}
+
+func TestGenerateReportFromSourceCopiedLocalCompositeAction(t *testing.T) {
+	workflow := `
+jobs:
+  build:
+    steps:
+      - uses: ./.github/actions/setup
+`
+
+	setup := `
+runs:
+  using: composite
+  steps:
+    - uses: actions/setup-node@v3
+      with:
+        node-version: '18'
+        cache: npm
+        registry-url: https://registry.npmjs.org
+`
+
+	source := memory.NewWorkflowSource().
+		AddWorkflow("owner/repo1", "build.yml", workflow).
+		AddWorkflow("owner/repo2", "build.yml", workflow).
+		AddAction("owner/repo1", ".github/actions/setup", "", setup).
+		AddAction("owner/repo2", ".github/actions/setup", "", setup)
+
+	report := GenerateReportFromSource(context.Background(), source, []string{"owner/repo1", "owner/repo2"})
+
+	comparison := report.Comparisons["owner/repo1"]["owner/repo2"]
+	if !slices.Contains(comparison.StepsWithSimilarConfig, "actions/setup-node") {
+		t.Errorf("Expected steps copied between local composite actions to be duplication, got %+v", comparison)
+	}
+
+	if len(report.Clusters) != 1 {
+		t.Errorf("Expected the copied steps to be clustered, got %+v", report.Clusters)
+	}
+}
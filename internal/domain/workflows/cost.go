@@ -0,0 +1,91 @@
+package workflows
+
+import (
+	"github.com/OctopusSolutionsEngineering/DuplicationCostCalculator/internal/domain/models"
+)
+
+// DefaultHoursPerRepo is the default number of hours it takes to make a consistent change to a repository.
+const DefaultHoursPerRepo = 4
+
+// DefaultAnnualSalary is the default average annual salary of an engineer, in dollars.
+const DefaultAnnualSalary = 120000
+
+// DefaultWorkdaysPerYear and DefaultHoursPerWorkday convert the annual salary to an hourly rate.
+const DefaultWorkdaysPerYear = 365
+const DefaultHoursPerWorkday = 8
+
+// DefaultCostModel returns the cost model used when none is supplied. Drift and similar steps have no extra weighting.
+func DefaultCostModel() models.CostModel {
+	return models.CostModel{
+		HoursPerRepo:    DefaultHoursPerRepo,
+		AnnualSalary:    DefaultAnnualSalary,
+		WorkdaysPerYear: DefaultWorkdaysPerYear,
+		HoursPerWorkday: DefaultHoursPerWorkday,
+	}
+}
+
+// GetCostModel returns the model with any values that are not set replaced by the defaults. A nil model
+// returns the default model.
+func GetCostModel(model *models.CostModel) models.CostModel {
+	defaults := DefaultCostModel()
+	if model == nil {
+		return defaults
+	}
+
+	result := *model
+	if result.HoursPerRepo <= 0 {
+		result.HoursPerRepo = defaults.HoursPerRepo
+	}
+	if result.AnnualSalary <= 0 {
+		result.AnnualSalary = defaults.AnnualSalary
+	}
+	if result.WorkdaysPerYear <= 0 {
+		result.WorkdaysPerYear = defaults.WorkdaysPerYear
+	}
+	if result.HoursPerWorkday <= 0 {
+		result.HoursPerWorkday = defaults.HoursPerWorkday
+	}
+	result.HoursPerDrift = max(result.HoursPerDrift, 0)
+	result.HoursPerSimilarStep = max(result.HoursPerSimilarStep, 0)
+
+	return result
+}
+
+// CalculateCost returns the cost of making a consistent change across the repositories in the report:
+// (the weighted number of repositories with duplication or drift * hours per repo + the version drifts * hours per
+// drift + the similar steps * hours per similar step) * an hourly rate of the annual salary / workdays per year /
+// hours per workday.
+func CalculateCost(report models.Report, model models.CostModel) models.CostBreakdown {
+	hourlyRate := model.AnnualSalary / model.WorkdaysPerYear / model.HoursPerWorkday
+	drifts, similarSteps := countDriftAndSimilarSteps(report)
+
+	repoHours := report.WeightedNumberOfReposWithDuplicationOrDrift * model.HoursPerRepo
+	driftHours := float64(drifts) * model.HoursPerDrift
+	similarStepHours := float64(similarSteps) * model.HoursPerSimilarStep
+	totalHours := repoHours + driftHours + similarStepHours
+
+	return models.CostBreakdown{
+		Model:            model,
+		HourlyRate:       hourlyRate,
+		WeightedRepos:    report.WeightedNumberOfReposWithDuplicationOrDrift,
+		Drifts:           drifts,
+		SimilarSteps:     similarSteps,
+		RepoHours:        repoHours,
+		DriftHours:       driftHours,
+		SimilarStepHours: similarStepHours,
+		TotalHours:       totalHours,
+		Total:            totalHours * hourlyRate,
+	}
+}
+
+// countDriftAndSimilarSteps counts the version drift and steps with similar config of each pair of repositories.
+func countDriftAndSimilarSteps(report models.Report) (int, int) {
+	drifts := 0
+	similarSteps := 0
+	for _, pair := range report.GetRepoPairs() {
+		drifts += len(pair.Measurements.VersionDrift)
+		similarSteps += pair.Measurements.StepsWithSimilarConfigCount
+	}
+
+	return drifts, similarSteps
+}
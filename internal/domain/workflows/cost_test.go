@@ -0,0 +1,106 @@
+package workflows
+
+import (
+	"context"
+	"math"
+	"testing"
+
+	"github.com/OctopusSolutionsEngineering/DuplicationCostCalculator/internal/domain/models"
+	"github.com/OctopusSolutionsEngineering/DuplicationCostCalculator/internal/infrastructure/memory"
+)
+
+func TestCalculateCost(t *testing.T) {
+	// Two pairs with drift and similar steps, where each pair appears twice in the comparisons
+	report := models.Report{
+		WeightedNumberOfReposWithDuplicationOrDrift: 2,
+		Comparisons: map[string]map[string]models.RepoMeasurements{
+			"owner/repo1": {
+				"owner/repo2": {VersionDrift: []models.VersionDrift{{Uses: "actions/checkout"}}, StepsWithSimilarConfigCount: 3},
+				"owner/repo3": {VersionDrift: []models.VersionDrift{{Uses: "actions/checkout"}, {Uses: "actions/cache"}}},
+			},
+			"owner/repo2": {
+				"owner/repo1": {VersionDrift: []models.VersionDrift{{Uses: "actions/checkout"}}, StepsWithSimilarConfigCount: 3},
+			},
+			"owner/repo3": {
+				"owner/repo1": {VersionDrift: []models.VersionDrift{{Uses: "actions/checkout"}, {Uses: "actions/cache"}}},
+			},
+		},
+	}
+
+	tests := []struct {
+		name          string
+		model         models.CostModel
+		expectedHours float64
+		expectedTotal float64
+	}{
+		{"default", DefaultCostModel(), 8, 8 * 120000.0 / 365 / 8},
+		{"workdays", models.CostModel{HoursPerRepo: 4, AnnualSalary: 104000, WorkdaysPerYear: 260, HoursPerWorkday: 8}, 8, 8 * 50},
+		{"weighted drift and similar steps", models.CostModel{HoursPerRepo: 1, AnnualSalary: 2920, WorkdaysPerYear: 365, HoursPerWorkday: 8, HoursPerDrift: 0.5, HoursPerSimilarStep: 2}, 2 + 1.5 + 6, 9.5},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			cost := CalculateCost(report, tt.model)
+
+			// Assert
+			if cost.Drifts != 3 || cost.SimilarSteps != 3 || cost.WeightedRepos != 2 {
+				t.Errorf("Unexpected counts %+v", cost)
+			}
+
+			if math.Abs(cost.TotalHours-tt.expectedHours) > 0.001 || math.Abs(cost.Total-tt.expectedTotal) > 0.001 {
+				t.Errorf("CalculateCost() = %f hours, $%f, expected %f hours, $%f", cost.TotalHours, cost.Total, tt.expectedHours, tt.expectedTotal)
+			}
+
+			if math.Abs(cost.RepoHours+cost.DriftHours+cost.SimilarStepHours-cost.TotalHours) > 0.001 || cost.Model != tt.model {
+				t.Errorf("Expected the breakdown to add up to the total, got %+v", cost)
+			}
+		})
+	}
+}
+
+func TestGetCostModel(t *testing.T) {
+	tests := []struct {
+		name     string
+		model    *models.CostModel
+		expected models.CostModel
+	}{
+		{"nil", nil, DefaultCostModel()},
+		{"empty", &models.CostModel{}, DefaultCostModel()},
+		{"partial", &models.CostModel{HoursPerRepo: 6, HoursPerDrift: 1}, models.CostModel{HoursPerRepo: 6, AnnualSalary: DefaultAnnualSalary, WorkdaysPerYear: DefaultWorkdaysPerYear, HoursPerWorkday: DefaultHoursPerWorkday, HoursPerDrift: 1}},
+		{"negative", &models.CostModel{AnnualSalary: -1, HoursPerSimilarStep: -2}, DefaultCostModel()},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if result := GetCostModel(tt.model); result != tt.expected {
+				t.Errorf("GetCostModel() = %+v, expected %+v", result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestGenerateReportCost(t *testing.T) {
+	// Arrange
+	repoActions := []RepoActions{
+		{Repo: "owner/repo1", Workflows: []string{checkoutV4Workflow}},
+		{Repo: "owner/repo2", Workflows: []string{checkoutV3Workflow}},
+	}
+	model := &models.CostModel{HoursPerRepo: 2, HoursPerDrift: 1}
+
+	// Act
+	defaultReport := GenerateReportFromSource(context.Background(), memory.NewWorkflowSource().
+		AddWorkflow("owner/repo1", "build.yml", checkoutV4Workflow).
+		AddWorkflow("owner/repo2", "build.yml", checkoutV3Workflow), []string{"owner/repo1", "owner/repo2"})
+	report := GenerateReportFromRepoActions(repoActions, ReportOptions{CostModel: model})
+
+	// Assert - the default cost matches the cost the web UI calculated
+	expectedDefault := defaultReport.WeightedNumberOfReposWithDuplicationOrDrift * DefaultHoursPerRepo * (DefaultAnnualSalary / 365.0 / 8)
+	if defaultReport.Cost.Total <= 0 || math.Abs(defaultReport.Cost.Total-expectedDefault) > 0.001 {
+		t.Errorf("Expected the default cost to be %f, got %+v", expectedDefault, defaultReport.Cost)
+	}
+
+	if report.Cost.Model != GetCostModel(model) || report.Cost.Drifts != 1 || report.Cost.TotalHours != report.WeightedNumberOfReposWithDuplicationOrDrift*2+1 {
+		t.Errorf("Expected the cost to use the model, got %+v", report.Cost)
+	}
+}
@@ -0,0 +1,134 @@
+package workflows
+
+import (
+	"slices"
+
+	"github.com/OctopusSolutionsEngineering/DuplicationCostCalculator/internal/domain/models"
+	"github.com/samber/lo"
+)
+
+// DiffReports compares two reports of the same repositories, with before being the earlier report. Drift is only
+// compared between repositories that are in both reports, so adding or removing a repository does not look like
+// drift being introduced or resolved. The cost of both reports is calculated with the cost model, so the change is
+// not affected by the model each report was generated with.
+func DiffReports(before models.Report, after models.Report, costModel models.CostModel) models.ReportDiff {
+	beforeRepos := getReportRepos(before)
+	afterRepos := getReportRepos(after)
+	commonRepos := lo.Intersect(beforeRepos, afterRepos)
+
+	beforeDrift := getDriftChanges(before, commonRepos)
+	afterDrift := getDriftChanges(after, commonRepos)
+
+	beforeCost := CalculateCost(before, costModel).Total
+	afterCost := CalculateCost(after, costModel).Total
+
+	return models.ReportDiff{
+		AddedRepos:          sortedUnique(lo.Without(afterRepos, beforeRepos...)),
+		RemovedRepos:        sortedUnique(lo.Without(beforeRepos, afterRepos...)),
+		ResolvedDrift:       getMissingDrift(beforeDrift, afterDrift),
+		IntroducedDrift:     getMissingDrift(afterDrift, beforeDrift),
+		SimilarConfigGroups: DiffSimilarConfigGroups(before.Clusters, after.Clusters),
+		NumberOfReposWithDuplicationOrDrift: models.CountChange{
+			Before: before.NumberOfReposWithDuplicationOrDrift,
+			After:  after.NumberOfReposWithDuplicationOrDrift,
+			Change: after.NumberOfReposWithDuplicationOrDrift - before.NumberOfReposWithDuplicationOrDrift,
+		},
+		WeightedNumberOfReposWithDuplicationOrDrift: models.AmountChange{
+			Before: before.WeightedNumberOfReposWithDuplicationOrDrift,
+			After:  after.WeightedNumberOfReposWithDuplicationOrDrift,
+			Change: after.WeightedNumberOfReposWithDuplicationOrDrift - before.WeightedNumberOfReposWithDuplicationOrDrift,
+		},
+		Cost: models.AmountChange{
+			Before: beforeCost,
+			After:  afterCost,
+			Change: afterCost - beforeCost,
+		},
+	}
+}
+
+// DiffSimilarConfigGroups compares the clusters of steps with similar configuration for each action, returning
+// the actions whose steps changed, sorted by action.
+func DiffSimilarConfigGroups(before []models.ActionCluster, after []models.ActionCluster) []models.SimilarConfigChange {
+	beforeGroups := lo.GroupBy(before, func(item models.ActionCluster) string { return item.Uses })
+	afterGroups := lo.GroupBy(after, func(item models.ActionCluster) string { return item.Uses })
+
+	changes := []models.SimilarConfigChange{}
+	for _, uses := range sortedUnique(lo.Union(lo.Keys(beforeGroups), lo.Keys(afterGroups))) {
+		beforeMembers := getClusterMembers(beforeGroups[uses])
+		afterMembers := getClusterMembers(afterGroups[uses])
+		beforeRepos := lo.Uniq(lo.Map(beforeMembers, func(item models.ActionClusterMember, index int) string { return item.Repo }))
+		afterRepos := lo.Uniq(lo.Map(afterMembers, func(item models.ActionClusterMember, index int) string { return item.Repo }))
+
+		removedRepos := sortedUnique(lo.Without(beforeRepos, afterRepos...))
+		addedRepos := sortedUnique(lo.Without(afterRepos, beforeRepos...))
+
+		if len(beforeMembers) == len(afterMembers) && len(removedRepos) == 0 && len(addedRepos) == 0 {
+			continue
+		}
+
+		changes = append(changes, models.SimilarConfigChange{
+			Uses:         uses,
+			StepsBefore:  len(beforeMembers),
+			StepsAfter:   len(afterMembers),
+			ReposBefore:  len(beforeRepos),
+			ReposAfter:   len(afterRepos),
+			RemovedRepos: removedRepos,
+			AddedRepos:   addedRepos,
+		})
+	}
+
+	return changes
+}
+
+// getReportRepos returns the repositories in a report, including those that failed to load.
+func getReportRepos(report models.Report) []string {
+	return lo.Union(lo.Keys(report.RepoStatus), lo.Keys(report.Comparisons))
+}
+
+// getDriftChanges returns the version drift between each pair of the repos.
+func getDriftChanges(report models.Report, repos []string) []models.DriftChange {
+	included := lo.Keyify(repos)
+	drift := []models.DriftChange{}
+	for _, pair := range report.GetRepoPairs() {
+		if _, ok := included[pair.Repo1]; !ok {
+			continue
+		}
+		if _, ok := included[pair.Repo2]; !ok {
+			continue
+		}
+
+		for _, versionDrift := range pair.Measurements.VersionDrift {
+			drift = append(drift, models.DriftChange{Repo1: pair.Repo1, Repo2: pair.Repo2, VersionDrift: versionDrift})
+		}
+	}
+
+	return drift
+}
+
+// getMissingDrift returns the drift in source that is not in target. The severity is ignored, so drift between the
+// same versions is matched even if it was classified differently.
+func getMissingDrift(source []models.DriftChange, target []models.DriftChange) []models.DriftChange {
+	targetKeys := lo.SliceToMap(target, func(item models.DriftChange) (string, bool) {
+		return getDriftKey(item), true
+	})
+
+	return lo.Filter(source, func(item models.DriftChange, index int) bool {
+		return !targetKeys[getDriftKey(item)]
+	})
+}
+
+func getDriftKey(drift models.DriftChange) string {
+	return drift.Repo1 + "\n" + drift.Repo2 + "\n" + drift.Uses + "\n" + drift.Version1 + "\n" + drift.Version2
+}
+
+func getClusterMembers(clusters []models.ActionCluster) []models.ActionClusterMember {
+	return lo.FlatMap(clusters, func(item models.ActionCluster, index int) []models.ActionClusterMember {
+		return item.Members
+	})
+}
+
+func sortedUnique(items []string) []string {
+	result := lo.Uniq(items)
+	slices.Sort(result)
+	return result
+}
@@ -0,0 +1,118 @@
+package workflows
+
+import (
+	"context"
+	"math"
+	"slices"
+	"testing"
+
+	"github.com/OctopusSolutionsEngineering/DuplicationCostCalculator/internal/infrastructure/memory"
+)
+
+const checkoutV3Workflow = `
+jobs:
+  build:
+    steps:
+      - uses: actions/checkout@v3
+`
+
+const checkoutV4Workflow = `
+jobs:
+  build:
+    steps:
+      - uses: actions/checkout@v4
+`
+
+func TestDiffReports(t *testing.T) {
+	// Arrange - repo2 has drift with repo1 and repo3, and the same deployment step as repo1
+	before := GenerateReportFromSource(context.Background(), memory.NewWorkflowSource().
+		AddWorkflow("owner/repo1", "build.yml", checkoutV4Workflow).
+		AddWorkflow("owner/repo1", "deploy.yml", deployWorkflow).
+		AddWorkflow("owner/repo2", "build.yml", checkoutV3Workflow).
+		AddWorkflow("owner/repo2", "deploy.yml", deployWorkflow).
+		AddWorkflow("owner/repo3", "build.yml", checkoutV4Workflow), []string{"owner/repo1", "owner/repo2", "owner/repo3"})
+
+	// repo2 was updated, and no longer shares the deployment step, while repo4 was added with older versions
+	after := GenerateReportFromSource(context.Background(), memory.NewWorkflowSource().
+		AddWorkflow("owner/repo1", "build.yml", checkoutV4Workflow).
+		AddWorkflow("owner/repo1", "deploy.yml", deployWorkflow).
+		AddWorkflow("owner/repo2", "build.yml", checkoutV4Workflow).
+		AddWorkflow("owner/repo3", "build.yml", checkoutV4Workflow).
+		AddWorkflow("owner/repo4", "build.yml", checkoutV3Workflow), []string{"owner/repo1", "owner/repo2", "owner/repo3", "owner/repo4"})
+
+	// Act
+	diff := DiffReports(before, after, DefaultCostModel())
+
+	// Assert
+	if !slices.Equal(diff.AddedRepos, []string{"owner/repo4"}) || len(diff.RemovedRepos) != 0 {
+		t.Errorf("Unexpected added %v and removed %v repos", diff.AddedRepos, diff.RemovedRepos)
+	}
+
+	if len(diff.ResolvedDrift) != 2 {
+		t.Fatalf("Expected the drift of repo2 to be resolved, got %+v", diff.ResolvedDrift)
+	}
+
+	resolved := diff.ResolvedDrift[0]
+	if resolved.Repo1 != "owner/repo1" || resolved.Repo2 != "owner/repo2" || resolved.Uses != "actions/checkout" || resolved.Version1 != "v4" || resolved.Version2 != "v3" {
+		t.Errorf("Unexpected resolved drift %+v", resolved)
+	}
+
+	// The drift of the added repo is not introduced drift, because it was not compared before
+	if len(diff.IntroducedDrift) != 0 {
+		t.Errorf("Expected no introduced drift, got %+v", diff.IntroducedDrift)
+	}
+
+	if len(diff.SimilarConfigGroups) != 1 {
+		t.Fatalf("Expected the deployment group to change, got %+v", diff.SimilarConfigGroups)
+	}
+
+	group := diff.SimilarConfigGroups[0]
+	if group.Uses != "azure/webapps-deploy" || group.StepsBefore != 2 || group.StepsAfter != 0 || !slices.Equal(group.RemovedRepos, []string{"owner/repo1", "owner/repo2"}) {
+		t.Errorf("Unexpected similar config change %+v", group)
+	}
+
+	change := diff.NumberOfReposWithDuplicationOrDrift
+	if change.Before != before.NumberOfReposWithDuplicationOrDrift || change.After != after.NumberOfReposWithDuplicationOrDrift || change.Change != change.After-change.Before {
+		t.Errorf("Unexpected change in repos with duplication or drift %+v", change)
+	}
+
+	expectedCost := after.Cost.Total - before.Cost.Total
+	if math.Abs(diff.Cost.Change-expectedCost) > 0.001 || diff.Cost.Before <= 0 {
+		t.Errorf("Unexpected cost change %+v, expected %f", diff.Cost, expectedCost)
+	}
+}
+
+func TestDiffReportsIntroducedDrift(t *testing.T) {
+	// Arrange
+	before := GenerateReportFromSource(context.Background(), memory.NewWorkflowSource().
+		AddWorkflow("owner/repo1", "build.yml", checkoutV4Workflow).
+		AddWorkflow("owner/repo2", "build.yml", checkoutV4Workflow), []string{"owner/repo1", "owner/repo2"})
+
+	after := GenerateReportFromSource(context.Background(), memory.NewWorkflowSource().
+		AddWorkflow("owner/repo1", "build.yml", checkoutV4Workflow).
+		AddWorkflow("owner/repo2", "build.yml", checkoutV3Workflow), []string{"owner/repo1", "owner/repo2"})
+
+	// Act
+	diff := DiffReports(before, after, DefaultCostModel())
+
+	// Assert
+	if len(diff.IntroducedDrift) != 1 || diff.IntroducedDrift[0].Severity != "major" || len(diff.ResolvedDrift) != 0 {
+		t.Errorf("Expected the drift to be introduced, got %+v and %+v", diff.IntroducedDrift, diff.ResolvedDrift)
+	}
+
+	if diff.NumberOfReposWithDuplicationOrDrift.Change != 2 || diff.Cost.Change <= 0 {
+		t.Errorf("Expected the cost to increase, got %+v %+v", diff.NumberOfReposWithDuplicationOrDrift, diff.Cost)
+	}
+}
+
+func TestDiffReportsSameReport(t *testing.T) {
+	report := GenerateReportFromSource(context.Background(), memory.NewWorkflowSource().
+		AddWorkflow("owner/repo1", "build.yml", checkoutV4Workflow).
+		AddWorkflow("owner/repo2", "build.yml", checkoutV3Workflow), []string{"owner/repo1", "owner/repo2"})
+
+	diff := DiffReports(report, report, DefaultCostModel())
+
+	if len(diff.ResolvedDrift) != 0 || len(diff.IntroducedDrift) != 0 || len(diff.SimilarConfigGroups) != 0 || len(diff.AddedRepos) != 0 || diff.Cost.Change != 0 {
+		t.Errorf("Expected no changes, got %+v", diff)
+	}
+}
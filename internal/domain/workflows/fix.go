@@ -0,0 +1,180 @@
+package workflows
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/OctopusSolutionsEngineering/DuplicationCostCalculator/internal/domain/models"
+	"github.com/OctopusSolutionsEngineering/DuplicationCostCalculator/internal/domain/parsing"
+	"github.com/samber/lo"
+	"gopkg.in/yaml.v3"
+)
+
+// TargetLatest aligns each action to the latest stable version from its tags, or the highest version in use.
+const TargetLatest = "latest"
+
+// TargetMajority aligns each action to the version used by the most repositories.
+const TargetMajority = "majority"
+
+// ActionRewrite is a change to the version of an action in a workflow.
+type ActionRewrite struct {
+	Uses        string
+	FromVersion string
+	ToVersion   string
+	// Line is the 1-based line of the "uses" value in the workflow.
+	Line int
+}
+
+// GetDriftedActions returns the actions that have version drift between any pair of repositories in the report,
+// sorted by action.
+func GetDriftedActions(report models.Report) []string {
+	drifted := []string{}
+	for _, comparisons := range report.Comparisons {
+		for _, measurements := range comparisons {
+			for _, drift := range measurements.VersionDrift {
+				drifted = append(drifted, drift.Uses)
+			}
+		}
+	}
+
+	return sortedUnique(drifted)
+}
+
+// GetTargetVersions returns the version each action should be aligned to, using the latest or majority strategy.
+// The recommended versions are the latest stable versions from the tags of each action, which the latest strategy
+// prefers over the versions in use. Actions that are only pinned to SHAs or branches have no target version.
+func GetTargetVersions(repoActions map[string][][]models.Action, actions []string, strategy string, recommendedVersions map[string]string) map[string]string {
+	// Count the repositories that use each version of each action
+	repoCounts := map[string]map[string]int{}
+	for _, actionsList := range repoActions {
+		repoVersions := lo.Uniq(lo.FilterMap(lo.Flatten(actionsList), func(item models.Action, index int) ([2]string, bool) {
+			_, ok := parsing.ParseSemVer(item.UsesVersion)
+			return [2]string{item.Uses, item.UsesVersion}, ok && slices.Contains(actions, item.Uses)
+		}))
+
+		for _, version := range repoVersions {
+			if repoCounts[version[0]] == nil {
+				repoCounts[version[0]] = map[string]int{}
+			}
+			repoCounts[version[0]][version[1]]++
+		}
+	}
+
+	targets := map[string]string{}
+	for _, uses := range actions {
+		if recommended, ok := recommendedVersions[uses]; ok && strategy == TargetLatest {
+			targets[uses] = recommended
+			continue
+		}
+
+		versions := lo.Keys(repoCounts[uses])
+		if len(versions) == 0 {
+			continue
+		}
+
+		// Sort the highest version first, so it wins ties between versions used by the same number of repos
+		slices.SortFunc(versions, func(a, b string) int {
+			return compareVersions(b, a)
+		})
+
+		if strategy == TargetMajority {
+			targets[uses] = lo.MaxBy(versions, func(a string, b string) bool {
+				return repoCounts[uses][a] > repoCounts[uses][b]
+			})
+		} else {
+			targets[uses] = versions[0]
+		}
+	}
+
+	return targets
+}
+
+// compareVersions compares two semantic versions, preferring the more specific version when they are equal.
+func compareVersions(a string, b string) int {
+	semVerA, _ := parsing.ParseSemVer(a)
+	semVerB, _ := parsing.ParseSemVer(b)
+
+	if comparison := semVerA.Compare(semVerB); comparison != 0 {
+		return comparison
+	}
+
+	if semVerA.Precision != semVerB.Precision {
+		return semVerA.Precision - semVerB.Precision
+	}
+
+	return strings.Compare(a, b)
+}
+
+// RewriteActionVersions changes the version in each "uses" value of the workflow that calls an action in the
+// targets to the target version. The workflow is parsed into YAML nodes to find the "uses" values, and only the
+// versions are replaced in the original text, so comments and formatting are kept. Actions pinned to a SHA are
+// not changed, as they were pinned on purpose.
+func RewriteActionVersions(workflow string, targets map[string]string) (string, []ActionRewrite, error) {
+	var document yaml.Node
+	if err := yaml.Unmarshal([]byte(workflow), &document); err != nil {
+		return workflow, nil, err
+	}
+
+	lines := strings.Split(workflow, "\n")
+	rewrites := []ActionRewrite{}
+
+	for _, node := range findUsesNodes(&document) {
+		uses, version := parsing.GetActionIdAndVersion(node.Value)
+		target, ok := targets[uses]
+		if !ok || !strings.Contains(node.Value, "@") || version == target || parsing.IsSha(version) {
+			continue
+		}
+
+		line := node.Line - 1
+		if line < 0 || line >= len(lines) {
+			continue
+		}
+
+		// The column counts characters rather than bytes, and quoted values start after the quote
+		start := len(string([]rune(lines[line])[:min(node.Column-1, len([]rune(lines[line])))]))
+		index := strings.Index(lines[line][start:], node.Value)
+		if index < 0 {
+			continue
+		}
+
+		valueStart := start + index
+		valueEnd := valueStart + len(node.Value)
+		versionStart := valueStart + strings.Index(node.Value, "@") + 1
+		lines[line] = lines[line][:versionStart] + target + lines[line][valueEnd:]
+
+		rewrites = append(rewrites, ActionRewrite{
+			Uses:        uses,
+			FromVersion: version,
+			ToVersion:   target,
+			Line:        node.Line,
+		})
+	}
+
+	return strings.Join(lines, "\n"), rewrites, nil
+}
+
+// findUsesNodes returns the scalar values of every "uses" key in the document, which are the actions called by
+// steps and the reusable workflows called by jobs.
+func findUsesNodes(node *yaml.Node) []*yaml.Node {
+	result := []*yaml.Node{}
+
+	if node.Kind == yaml.MappingNode {
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i], node.Content[i+1]
+			if key.Value == "uses" && value.Kind == yaml.ScalarNode {
+				result = append(result, value)
+				continue
+			}
+
+			result = append(result, findUsesNodes(value)...)
+		}
+
+		return result
+	}
+
+	for _, child := range node.Content {
+		result = append(result, findUsesNodes(child)...)
+	}
+
+	return result
+}
@@ -0,0 +1,161 @@
+package workflows
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/OctopusSolutionsEngineering/DuplicationCostCalculator/internal/domain/models"
+	"github.com/OctopusSolutionsEngineering/DuplicationCostCalculator/internal/infrastructure/memory"
+)
+
+const commentedWorkflow = `# Builds the app
+name: Build
+
+on: [push]
+
+jobs:
+  build:
+    runs-on: ubuntu-latest
+    steps:
+      # Check out the code
+      - uses: actions/checkout@v3 # keep in sync with deploy.yml
+      - name: Setup
+        uses: "actions/setup-node@v3"
+        with:
+          node-version: 20
+      - uses: actions/cache@8e5e7e5ab8b370d6c329ec480221332ada57f0ab
+  deploy:
+    uses: 'owner/workflows/.github/workflows/deploy.yml@v1'
+`
+
+func TestRewriteActionVersions(t *testing.T) {
+	// Arrange
+	targets := map[string]string{
+		"actions/checkout":   "v4",
+		"actions/setup-node": "v4.0.2",
+		"actions/cache":      "v4",
+		"owner/workflows/.github/workflows/deploy.yml": "v2",
+	}
+
+	// Act
+	result, rewrites, err := RewriteActionVersions(commentedWorkflow, targets)
+
+	// Assert - only the versions change, and SHA pinned actions are kept
+	if err != nil {
+		t.Fatalf("Failed to rewrite the workflow: %v", err)
+	}
+
+	expected := `# Builds the app
+name: Build
+
+on: [push]
+
+jobs:
+  build:
+    runs-on: ubuntu-latest
+    steps:
+      # Check out the code
+      - uses: actions/checkout@v4 # keep in sync with deploy.yml
+      - name: Setup
+        uses: "actions/setup-node@v4.0.2"
+        with:
+          node-version: 20
+      - uses: actions/cache@8e5e7e5ab8b370d6c329ec480221332ada57f0ab
+  deploy:
+    uses: 'owner/workflows/.github/workflows/deploy.yml@v2'
+`
+
+	if result != expected {
+		t.Errorf("Unexpected workflow:\n%s", result)
+	}
+
+	if len(rewrites) != 3 {
+		t.Fatalf("Expected 3 rewrites, got %+v", rewrites)
+	}
+
+	if rewrites[0] != (ActionRewrite{Uses: "actions/checkout", FromVersion: "v3", ToVersion: "v4", Line: 11}) {
+		t.Errorf("Unexpected rewrite %+v", rewrites[0])
+	}
+}
+
+func TestRewriteActionVersionsUnchanged(t *testing.T) {
+	tests := []struct {
+		name    string
+		targets map[string]string
+	}{
+		{"no targets", map[string]string{}},
+		{"already on target", map[string]string{"actions/checkout": "v3"}},
+		{"other action", map[string]string{"actions/upload-artifact": "v4"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, rewrites, err := RewriteActionVersions(commentedWorkflow, tt.targets)
+
+			if err != nil || result != commentedWorkflow || len(rewrites) != 0 {
+				t.Errorf("Expected the workflow to be unchanged, got %+v %v\n%s", rewrites, err, result)
+			}
+		})
+	}
+}
+
+func TestRewriteActionVersionsInvalid(t *testing.T) {
+	result, _, err := RewriteActionVersions("jobs: [", map[string]string{"actions/checkout": "v4"})
+
+	if err == nil || result != "jobs: [" {
+		t.Errorf("Expected invalid YAML to fail, got %v", err)
+	}
+}
+
+func TestGetTargetVersions(t *testing.T) {
+	// Arrange - two repos use v3 of checkout, and one uses v4
+	repoActions := map[string][][]models.Action{
+		"owner/repo1": {{{Uses: "actions/checkout", UsesVersion: "v3"}, {Uses: "actions/cache", UsesVersion: "v3"}}},
+		"owner/repo2": {{{Uses: "actions/checkout", UsesVersion: "v3"}, {Uses: "actions/cache", UsesVersion: "v4"}}},
+		"owner/repo3": {{{Uses: "actions/checkout", UsesVersion: "v4"}, {Uses: "actions/checkout", UsesVersion: "main"}}},
+	}
+	actions := []string{"actions/checkout", "actions/cache"}
+
+	tests := []struct {
+		name        string
+		strategy    string
+		recommended map[string]string
+		expected    map[string]string
+	}{
+		{"latest in use", TargetLatest, nil, map[string]string{"actions/checkout": "v4", "actions/cache": "v4"}},
+		{"latest tag", TargetLatest, map[string]string{"actions/checkout": "v4.2.0"}, map[string]string{"actions/checkout": "v4.2.0", "actions/cache": "v4"}},
+		{"majority", TargetMajority, map[string]string{"actions/checkout": "v4.2.0"}, map[string]string{"actions/checkout": "v3", "actions/cache": "v4"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			targets := GetTargetVersions(repoActions, actions, tt.strategy, tt.recommended)
+
+			// Assert - ties are won by the highest version
+			if len(targets) != len(tt.expected) {
+				t.Fatalf("GetTargetVersions() = %v, expected %v", targets, tt.expected)
+			}
+
+			for uses, version := range tt.expected {
+				if targets[uses] != version {
+					t.Errorf("Target of %s = %q, expected %q", uses, targets[uses], version)
+				}
+			}
+		})
+	}
+}
+
+func TestGetDriftedActions(t *testing.T) {
+	report := GenerateReportFromSource(context.Background(), memory.NewWorkflowSource().
+		AddWorkflow("owner/repo1", "build.yml", checkoutAndCacheV4Workflow).
+		AddWorkflow("owner/repo2", "build.yml", checkoutV3Workflow).
+		AddWorkflow("owner/repo3", "build.yml", checkoutAndCacheV4Workflow), []string{"owner/repo1", "owner/repo2", "owner/repo3"})
+
+	drifted := GetDriftedActions(report)
+
+	if !slices.Equal(drifted, []string{"actions/checkout"}) {
+		t.Errorf("GetDriftedActions() = %v, expected only checkout to drift", drifted)
+	}
+}
@@ -0,0 +1,110 @@
+package workflows
+
+import (
+	"github.com/OctopusSolutionsEngineering/DuplicationCostCalculator/internal/domain/models"
+	"github.com/samber/lo"
+)
+
+// IndexActionsByUses groups actions by the action they call. Drift and duplication are only possible between
+// actions that call the same action, so comparing two repositories only needs to look within each group.
+func IndexActionsByUses(actions []models.Action) map[string][]models.Action {
+	return lo.GroupBy(actions, func(item models.Action) string {
+		return item.Uses
+	})
+}
+
+// GetUniqueVersions returns the first action for each unique combination of action, version and resolved version.
+// Version drift only depends on these fields, so comparing the unique versions finds the same drift as comparing
+// every action.
+func GetUniqueVersions(actions []models.Action) []models.Action {
+	return lo.UniqBy(actions, func(item models.Action) [3]string {
+		return [3]string{item.Uses, item.UsesVersion, item.ResolvedVersion}
+	})
+}
+
+// IndexedRepoActions holds the actions of a repository in the forms used to compare it to other repositories.
+// These are calculated once per repository rather than once per pair of repositories.
+type IndexedRepoActions struct {
+	Actions              []models.Action
+	ActionsByUses        map[string][]models.Action
+	UniqueVersions       []models.Action
+	UniqueVersionsByUses map[string][]models.Action
+	ScriptShingles       [][]string
+	ScriptIndex          ScriptIndex
+}
+
+// IndexRepoActions flattens and indexes the actions from the workflows of a repository.
+func IndexRepoActions(actionsList [][]models.Action) IndexedRepoActions {
+	actions := lo.Flatten(actionsList)
+	uniqueVersions := GetUniqueVersions(actions)
+	scriptShingles := GetScriptShingles(actions)
+
+	return IndexedRepoActions{
+		Actions:              actions,
+		ActionsByUses:        IndexActionsByUses(actions),
+		UniqueVersions:       uniqueVersions,
+		UniqueVersionsByUses: IndexActionsByUses(uniqueVersions),
+		ScriptShingles:       scriptShingles,
+		ScriptIndex:          NewScriptIndex(scriptShingles),
+	}
+}
+
+// FilterIndexedRepoActions returns the indexed actions that call an action for which keep returns true. The index
+// is filtered rather than rebuilt, so the scripts are not tokenized again for each pair of repositories.
+func FilterIndexedRepoActions(indexed IndexedRepoActions, keep func(uses string) bool) IndexedRepoActions {
+	result := IndexedRepoActions{
+		Actions:              []models.Action{},
+		ActionsByUses:        lo.PickBy(indexed.ActionsByUses, func(key string, value []models.Action) bool { return keep(key) }),
+		UniqueVersions:       lo.Filter(indexed.UniqueVersions, func(item models.Action, index int) bool { return keep(item.Uses) }),
+		UniqueVersionsByUses: lo.PickBy(indexed.UniqueVersionsByUses, func(key string, value []models.Action) bool { return keep(key) }),
+		ScriptShingles:       [][]string{},
+	}
+
+	// The shingles are in the same order as the actions
+	for i, action := range indexed.Actions {
+		if keep(action.Uses) {
+			result.Actions = append(result.Actions, action)
+			result.ScriptShingles = append(result.ScriptShingles, indexed.ScriptShingles[i])
+		}
+	}
+
+	result.ScriptIndex = NewScriptIndex(result.ScriptShingles)
+
+	return result
+}
+
+// ActionSet is an ordered collection of actions that are unique by ID.
+type ActionSet struct {
+	Items []models.Action
+	ids   map[string]bool
+}
+
+func NewActionSet() *ActionSet {
+	return &ActionSet{Items: []models.Action{}, ids: map[string]bool{}}
+}
+
+// Add appends the action if an action with the same ID has not already been added.
+func (s *ActionSet) Add(action models.Action) {
+	if !s.ids[action.Id] {
+		s.ids[action.Id] = true
+		s.Items = append(s.Items, action)
+	}
+}
+
+// StringSet is an ordered collection of unique strings.
+type StringSet struct {
+	Items []string
+	seen  map[string]bool
+}
+
+func NewStringSet() *StringSet {
+	return &StringSet{Items: []string{}, seen: map[string]bool{}}
+}
+
+// Add appends the value if it has not already been added.
+func (s *StringSet) Add(value string) {
+	if !s.seen[value] {
+		s.seen[value] = true
+		s.Items = append(s.Items, value)
+	}
+}
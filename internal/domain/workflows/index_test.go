@@ -0,0 +1,118 @@
+package workflows
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/OctopusSolutionsEngineering/DuplicationCostCalculator/internal/domain/models"
+)
+
+func TestIndexActionsByUses(t *testing.T) {
+	actions := []models.Action{
+		{Id: "1", Uses: "actions/checkout", UsesVersion: "v3"},
+		{Id: "2", Run: "npm ci"},
+		{Id: "3", Uses: "actions/checkout", UsesVersion: "v4"},
+	}
+
+	index := IndexActionsByUses(actions)
+
+	if len(index) != 2 {
+		t.Fatalf("Expected 2 groups, got %d", len(index))
+	}
+
+	if len(index["actions/checkout"]) != 2 || index["actions/checkout"][0].Id != "1" || index["actions/checkout"][1].Id != "3" {
+		t.Errorf("Expected checkout actions in their original order, got %+v", index["actions/checkout"])
+	}
+
+	if len(index[""]) != 1 || index[""][0].Id != "2" {
+		t.Errorf("Expected the script step to be grouped under an empty uses, got %+v", index[""])
+	}
+}
+
+func TestGetUniqueVersions(t *testing.T) {
+	actions := []models.Action{
+		{Id: "1", Uses: "actions/checkout", UsesVersion: "v4"},
+		{Id: "2", Uses: "actions/checkout", UsesVersion: "v4"},
+		{Id: "3", Uses: "actions/checkout", UsesVersion: checkoutSha, ResolvedVersion: "v4.1.1"},
+		{Id: "4", Uses: "actions/checkout", UsesVersion: checkoutSha},
+		{Id: "5", Uses: "actions/setup-go", UsesVersion: "v4"},
+	}
+
+	unique := GetUniqueVersions(actions)
+
+	ids := []string{}
+	for _, action := range unique {
+		ids = append(ids, action.Id)
+	}
+
+	if !slices.Equal(ids, []string{"1", "3", "4", "5"}) {
+		t.Errorf("Unexpected unique versions %v", ids)
+	}
+}
+
+func TestIndexRepoActions(t *testing.T) {
+	indexed := IndexRepoActions([][]models.Action{
+		{{Id: "1-1", Uses: "actions/checkout", UsesVersion: "v4"}},
+		{{Id: "2-1", Uses: "actions/checkout", UsesVersion: "v4"}, {Id: "2-2", Run: "npm ci && npm test"}},
+	})
+
+	if len(indexed.Actions) != 3 || len(indexed.UniqueVersions) != 2 || len(indexed.ScriptShingles) != 3 {
+		t.Errorf("Unexpected index %+v", indexed)
+	}
+
+	if indexed.ScriptShingles[0] != nil || indexed.ScriptShingles[2] == nil {
+		t.Errorf("Expected only the script step to have shingles, got %v", indexed.ScriptShingles)
+	}
+}
+
+func TestActionSet(t *testing.T) {
+	set := NewActionSet()
+	set.Add(models.Action{Id: "1", Uses: "a"})
+	set.Add(models.Action{Id: "2", Uses: "b"})
+	set.Add(models.Action{Id: "1", Uses: "c"})
+
+	if len(set.Items) != 2 || set.Items[0].Uses != "a" || set.Items[1].Uses != "b" {
+		t.Errorf("Expected the first action with each ID, got %+v", set.Items)
+	}
+}
+
+func TestStringSet(t *testing.T) {
+	set := NewStringSet()
+	for _, value := range []string{"b", "a", "b", "c", "a"} {
+		set.Add(value)
+	}
+
+	if !slices.Equal(set.Items, []string{"b", "a", "c"}) {
+		t.Errorf("Expected unique values in insertion order, got %v", set.Items)
+	}
+}
+
+func TestDriftSeverityCache(t *testing.T) {
+	cache := DriftSeverityCache{}
+
+	tests := []struct {
+		action1  models.Action
+		action2  models.Action
+		expected string
+	}{
+		{models.Action{Uses: "actions/checkout", UsesVersion: "v3"}, models.Action{Uses: "actions/checkout", UsesVersion: "v4"}, DriftSeverityMajor},
+		{models.Action{Uses: "actions/setup-go", UsesVersion: "v3"}, models.Action{Uses: "actions/setup-go", UsesVersion: "v4"}, DriftSeverityMajor},
+		{models.Action{Uses: "actions/checkout", UsesVersion: "v4"}, models.Action{Uses: "actions/checkout", UsesVersion: "v4"}, ""},
+		{models.Action{Uses: "actions/checkout", UsesVersion: "v3"}, models.Action{Uses: "actions/setup-go", UsesVersion: "v4"}, ""},
+		{models.Action{Uses: "actions/checkout", UsesVersion: "v4.1.0"}, models.Action{Uses: "actions/checkout", UsesVersion: "v4.1.2"}, DriftSeverityPatch},
+	}
+
+	for _, tt := range tests {
+		if result := cache.Classify(tt.action1, tt.action2); result != tt.expected {
+			t.Errorf("Classify(%+v, %+v) = %q, expected %q", tt.action1, tt.action2, result, tt.expected)
+		}
+
+		if result := ClassifyVersionDrift(tt.action1, tt.action2); result != tt.expected {
+			t.Errorf("ClassifyVersionDrift(%+v, %+v) = %q, expected %q", tt.action1, tt.action2, result, tt.expected)
+		}
+	}
+
+	if len(cache) != 2 {
+		t.Errorf("Expected 2 cached version pairs, got %d", len(cache))
+	}
+}
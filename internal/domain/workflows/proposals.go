@@ -0,0 +1,304 @@
+package workflows
+
+import (
+	"maps"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/OctopusSolutionsEngineering/DuplicationCostCalculator/internal/domain/models"
+	"github.com/OctopusSolutionsEngineering/DuplicationCostCalculator/internal/domain/parsing"
+	"github.com/samber/lo"
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultActionRepoName is the name of the repository that proposed composite actions are published in, when
+// the repository is not chosen.
+const DefaultActionRepoName = "shared-actions"
+
+// ProposedActionVersion is the version callers use to call a proposed composite action, once it is published.
+const ProposedActionVersion = "v1"
+
+// replacementSettings are the settings of a step that are kept first, in this order, when it is replaced.
+var replacementSettings = []string{"name", "id", "if"}
+
+// ProposeCompositeActions returns a shared composite action for each cluster of similar steps that call the same
+// published action. Clusters of run steps, local actions, Docker actions, and reusable workflows are skipped, as
+// they can't be wrapped by a composite action.
+// actionRepo is the repository the composite actions are published in, like "owner/shared-actions". It defaults
+// to the shared-actions repository of the owner of the clustered repositories.
+func ProposeCompositeActions(clusters []models.ActionCluster, actionRepo string) []models.CompositeActionProposal {
+	if actionRepo == "" {
+		actionRepo = getDefaultActionRepo(lo.Uniq(lo.FlatMap(clusters, func(item models.ActionCluster, index int) []string {
+			return item.Repos
+		})))
+	}
+
+	proposals := []models.CompositeActionProposal{}
+	nameCounts := map[string]int{}
+	for _, cluster := range clusters {
+		if !canWrapAction(cluster.Uses) {
+			continue
+		}
+
+		// The same action can have several clusters with different configurations
+		name := path.Base(cluster.Uses)
+		nameCounts[name]++
+		if nameCounts[name] > 1 {
+			name += "-" + strconv.Itoa(nameCounts[name])
+		}
+
+		proposals = append(proposals, ProposeCompositeAction(cluster, name, actionRepo))
+	}
+
+	return proposals
+}
+
+// ProposeCompositeAction returns a composite action that calls the clustered action with the configuration shared
+// by the members of the cluster. Each "with" input and environment variable set by a member becomes an input of the
+// composite action. Values that every member shares are the defaults of the inputs, so the callers only pass the
+// values that differ. Values with expressions are always passed by the callers, as contexts like secrets are not
+// available to a composite action's defaults. Values set by only some members are optional inputs, which are only
+// passed by the callers that set them. The composite action calls the highest version used by the members.
+func ProposeCompositeAction(cluster models.ActionCluster, name string, actionRepo string) models.CompositeActionProposal {
+	version := getProposalVersion(cluster)
+	inputs := GetCompositeActionInputs(cluster.Members)
+	location := actionRepo + "/" + name + "@" + ProposedActionVersion
+
+	replacements := lo.Map(cluster.Members, func(item models.ActionClusterMember, index int) models.StepReplacement {
+		return models.StepReplacement{
+			Repo:     item.Repo,
+			Workflow: item.Workflow,
+			Step:     item.Settings["name"],
+			Snippet:  getReplacementSnippet(item, inputs, location),
+		}
+	})
+
+	return models.CompositeActionProposal{
+		Name:         name,
+		Uses:         cluster.Uses,
+		Version:      version,
+		Location:     location,
+		Repos:        cluster.Repos,
+		Inputs:       inputs,
+		ActionYml:    getCompositeActionYml(name, cluster, version, inputs),
+		Replacements: replacements,
+	}
+}
+
+// GetCompositeActionInputs returns an input for each "with" input, then each environment variable, set by any
+// member, sorted by key. An input has a default if every member sets it to the same value without an expression,
+// and is required if every member sets it otherwise. An input set by only some members is optional, without a
+// default, so only the callers that set it pass a value.
+func GetCompositeActionInputs(members []models.ActionClusterMember) []models.CompositeActionInput {
+	withKeys := sortedUnique(lo.FlatMap(members, func(item models.ActionClusterMember, index int) []string {
+		return lo.Keys(item.With)
+	}))
+	envKeys := sortedUnique(lo.FlatMap(members, func(item models.ActionClusterMember, index int) []string {
+		return lo.Keys(item.Env)
+	}))
+
+	inputs := []models.CompositeActionInput{}
+	for _, property := range []string{models.InputPropertyWith, models.InputPropertyEnv} {
+		keys := lo.Ternary(property == models.InputPropertyWith, withKeys, envKeys)
+
+		for _, key := range keys {
+			values := lo.FilterMap(members, func(item models.ActionClusterMember, index int) (string, bool) {
+				value, ok := getMemberProperty(item, property)[key]
+				return value, ok
+			})
+
+			input := models.CompositeActionInput{
+				Name:     getInputName(property, key, withKeys),
+				Property: property,
+				Key:      key,
+			}
+
+			switch {
+			case len(values) != len(members):
+				// The callers that don't set the value don't pass it, so the input has no default
+			case len(lo.Uniq(values)) == 1 && !strings.Contains(values[0], "${{"):
+				input.Default = lo.ToPtr(values[0])
+			default:
+				input.Required = true
+			}
+
+			inputs = append(inputs, input)
+		}
+	}
+
+	return inputs
+}
+
+// getInputName returns the name of the composite action input for a "with" input or environment variable.
+// Environment variables are named like inputs, and prefixed with "env-" if that clashes with a "with" input.
+func getInputName(property string, key string, withKeys []string) string {
+	if property == models.InputPropertyWith {
+		return key
+	}
+
+	name := strings.ReplaceAll(strings.ToLower(key), "_", "-")
+	if slices.Contains(withKeys, name) {
+		return "env-" + name
+	}
+
+	return name
+}
+
+func getMemberProperty(member models.ActionClusterMember, property string) map[string]string {
+	if property == models.InputPropertyEnv {
+		return member.Env
+	}
+
+	return member.With
+}
+
+// getProposalVersion returns the highest semantic version used by the members of the cluster, or the version of
+// the centroid if none of them use a semantic version.
+func getProposalVersion(cluster models.ActionCluster) string {
+	versions := lo.Filter(lo.Map(cluster.Members, func(item models.ActionClusterMember, index int) string {
+		return item.Version
+	}), func(item string, index int) bool {
+		_, ok := parsing.ParseSemVer(item)
+		return ok
+	})
+
+	if len(versions) == 0 {
+		return cluster.Centroid.Version
+	}
+
+	return slices.MaxFunc(versions, compareVersions)
+}
+
+// getDefaultActionRepo returns the shared-actions repository of the owner of the repositories, or of a
+// placeholder owner if they have different owners.
+func getDefaultActionRepo(repos []string) string {
+	owners := lo.Uniq(lo.Map(repos, func(item string, index int) string {
+		owner, _, err := parsing.SplitRepo(item)
+		return lo.Ternary(err == nil, owner, "")
+	}))
+
+	if len(owners) == 1 && owners[0] != "" {
+		return owners[0] + "/" + DefaultActionRepoName
+	}
+
+	return "OWNER/" + DefaultActionRepoName
+}
+
+// canWrapAction returns true if the action is published in a repository, and can be called by a composite action.
+func canWrapAction(uses string) bool {
+	return uses != BuiltInStep &&
+		!strings.HasPrefix(uses, "./") &&
+		!strings.HasPrefix(uses, "docker://") &&
+		!strings.Contains(uses, "/.github/workflows/")
+}
+
+// getCompositeActionYml returns the action.yml file of a composite action that passes its inputs to the action.
+func getCompositeActionYml(name string, cluster models.ActionCluster, version string, inputs []models.CompositeActionInput) string {
+	inputsNode := newMappingNode()
+	with := newMappingNode()
+	env := newMappingNode()
+	for _, input := range inputs {
+		description := "The " + input.Key + " input of " + cluster.Uses + "."
+		if input.Property == models.InputPropertyEnv {
+			description = "The " + input.Key + " environment variable of " + cluster.Uses + "."
+		}
+
+		inputNode := newMappingNode()
+		appendPair(inputNode, "description", newScalarNode(description))
+		appendPair(inputNode, "required", newScalarNode(strconv.FormatBool(input.Required)))
+		if input.Default != nil {
+			appendPair(inputNode, "default", newScalarNode(*input.Default))
+		}
+		appendPair(inputsNode, input.Name, inputNode)
+
+		appendPair(lo.Ternary(input.Property == models.InputPropertyEnv, env, with), input.Key, newScalarNode("${{ inputs."+input.Name+" }}"))
+	}
+
+	step := newMappingNode()
+	appendPair(step, "uses", newScalarNode(cluster.Uses+"@"+version))
+	if len(with.Content) != 0 {
+		appendPair(step, "with", with)
+	}
+	if len(env.Content) != 0 {
+		appendPair(step, "env", env)
+	}
+
+	runs := newMappingNode()
+	appendPair(runs, "using", newScalarNode("composite"))
+	appendPair(runs, "steps", &yaml.Node{Kind: yaml.SequenceNode, Content: []*yaml.Node{step}})
+
+	action := newMappingNode()
+	appendPair(action, "name", newScalarNode(name))
+	appendPair(action, "description", newScalarNode("Calls "+cluster.Uses+" with the configuration shared by "+
+		strconv.Itoa(len(cluster.Repos))+" repositories."))
+	if len(inputsNode.Content) != 0 {
+		appendPair(action, "inputs", inputsNode)
+	}
+	appendPair(action, "runs", runs)
+
+	return marshalNode(action)
+}
+
+// getReplacementSnippet returns the step that calls the composite action in place of the member. The step keeps
+// the member's settings, and passes the values it sets for the inputs that don't have a default.
+func getReplacementSnippet(member models.ActionClusterMember, inputs []models.CompositeActionInput, location string) string {
+	with := newMappingNode()
+	for _, input := range inputs {
+		if value, ok := getMemberProperty(member, input.Property)[input.Key]; ok && input.Default == nil {
+			appendPair(with, input.Name, newScalarNode(value))
+		}
+	}
+
+	step := newMappingNode()
+	for _, setting := range replacementSettings {
+		if value, ok := member.Settings[setting]; ok {
+			appendPair(step, setting, newScalarNode(value))
+		}
+	}
+
+	appendPair(step, "uses", newScalarNode(location))
+	if len(with.Content) != 0 {
+		appendPair(step, "with", with)
+	}
+
+	for _, setting := range slices.Sorted(maps.Keys(member.Settings)) {
+		if !slices.Contains(replacementSettings, setting) {
+			appendPair(step, setting, newScalarNode(member.Settings[setting]))
+		}
+	}
+
+	return marshalNode(&yaml.Node{Kind: yaml.SequenceNode, Content: []*yaml.Node{step}})
+}
+
+func newMappingNode() *yaml.Node {
+	return &yaml.Node{Kind: yaml.MappingNode}
+}
+
+// newScalarNode returns a node for the value. The value is written without quotes where YAML allows, so values
+// like "true" or "20" keep the type they were parsed from.
+func newScalarNode(value string) *yaml.Node {
+	node := &yaml.Node{Kind: yaml.ScalarNode, Value: value}
+	if value == "" {
+		node.Style = yaml.DoubleQuotedStyle
+	}
+
+	return node
+}
+
+func appendPair(mapping *yaml.Node, key string, value *yaml.Node) {
+	mapping.Content = append(mapping.Content, newScalarNode(key), value)
+}
+
+func marshalNode(node *yaml.Node) string {
+	builder := strings.Builder{}
+	encoder := yaml.NewEncoder(&builder)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(node); err != nil {
+		return ""
+	}
+	encoder.Close()
+
+	return builder.String()
+}
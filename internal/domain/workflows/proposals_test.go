@@ -0,0 +1,220 @@
+package workflows
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/OctopusSolutionsEngineering/DuplicationCostCalculator/internal/domain/models"
+	"gopkg.in/yaml.v3"
+)
+
+func newSetupNodeCluster() models.ActionCluster {
+	return models.ActionCluster{
+		Uses:  "actions/setup-node",
+		Repos: []string{"owner/repo1", "owner/repo2"},
+		Members: []models.ActionClusterMember{
+			{
+				Repo:     "owner/repo1",
+				Workflow: "build.yml",
+				Version:  "v3",
+				Settings: map[string]string{"name": "Setup Node", "if": "github.event_name == 'push'", "continue-on-error": "true"},
+				With:     map[string]string{"node-version": "18", "cache": "npm", "token": "${{ secrets.NPM_TOKEN }}"},
+				Env:      map[string]string{"NODE_ENV": "production"},
+			},
+			{
+				Repo:     "owner/repo2",
+				Workflow: "ci.yml",
+				Version:  "v4.0.2",
+				Settings: map[string]string{},
+				With:     map[string]string{"node-version": "20", "cache": "npm", "token": "${{ secrets.NPM_TOKEN }}", "registry-url": "https://npm.pkg.github.com"},
+				Env:      map[string]string{"NODE_ENV": "production"},
+			},
+		},
+	}
+}
+
+func TestGetCompositeActionInputs(t *testing.T) {
+	// Act
+	inputs := GetCompositeActionInputs(newSetupNodeCluster().Members)
+
+	// Assert - shared values are defaults, expressions are always passed, and values set by only some callers are optional
+	expected := []struct {
+		name       string
+		property   string
+		defaultVal string
+		required   bool
+	}{
+		{"cache", models.InputPropertyWith, "npm", false},
+		{"node-version", models.InputPropertyWith, "", true},
+		{"registry-url", models.InputPropertyWith, "", false},
+		{"token", models.InputPropertyWith, "", true},
+		{"node-env", models.InputPropertyEnv, "production", false},
+	}
+
+	if len(inputs) != len(expected) {
+		t.Fatalf("Unexpected inputs %+v", inputs)
+	}
+
+	for i, input := range inputs {
+		defaultVal := ""
+		if input.Default != nil {
+			defaultVal = *input.Default
+		}
+
+		if input.Name != expected[i].name || input.Property != expected[i].property || defaultVal != expected[i].defaultVal || input.Required != expected[i].required {
+			t.Errorf("Input %d = %+v (default %q), expected %+v", i, input, defaultVal, expected[i])
+		}
+	}
+}
+
+func TestGetCompositeActionInputsNameClash(t *testing.T) {
+	inputs := GetCompositeActionInputs([]models.ActionClusterMember{
+		{With: map[string]string{"token": "a"}, Env: map[string]string{"TOKEN": "b"}},
+	})
+
+	if len(inputs) != 2 || inputs[1].Name != "env-token" || inputs[1].Key != "TOKEN" {
+		t.Errorf("Expected the environment variable to be prefixed, got %+v", inputs)
+	}
+}
+
+func TestProposeCompositeAction(t *testing.T) {
+	// Act
+	proposal := ProposeCompositeAction(newSetupNodeCluster(), "setup-node", "owner/shared-actions")
+
+	// Assert
+	if proposal.Location != "owner/shared-actions/setup-node@v1" || proposal.Version != "v4.0.2" {
+		t.Errorf("Unexpected proposal %+v", proposal)
+	}
+
+	expectedYml := `name: setup-node
+description: Calls actions/setup-node with the configuration shared by 2 repositories.
+inputs:
+  cache:
+    description: The cache input of actions/setup-node.
+    required: false
+    default: npm
+  node-version:
+    description: The node-version input of actions/setup-node.
+    required: true
+  registry-url:
+    description: The registry-url input of actions/setup-node.
+    required: false
+  token:
+    description: The token input of actions/setup-node.
+    required: true
+  node-env:
+    description: The NODE_ENV environment variable of actions/setup-node.
+    required: false
+    default: production
+runs:
+  using: composite
+  steps:
+    - uses: actions/setup-node@v4.0.2
+      with:
+        cache: ${{ inputs.cache }}
+        node-version: ${{ inputs.node-version }}
+        registry-url: ${{ inputs.registry-url }}
+        token: ${{ inputs.token }}
+      env:
+        NODE_ENV: ${{ inputs.node-env }}
+`
+	if proposal.ActionYml != expectedYml {
+		t.Errorf("Unexpected action.yml:\n%s", proposal.ActionYml)
+	}
+
+	if len(proposal.Replacements) != 2 {
+		t.Fatalf("Expected a replacement for each member, got %+v", proposal.Replacements)
+	}
+
+	expectedSnippet := `- name: Setup Node
+  if: github.event_name == 'push'
+  uses: owner/shared-actions/setup-node@v1
+  with:
+    node-version: 18
+    token: ${{ secrets.NPM_TOKEN }}
+  continue-on-error: true
+`
+	if replacement := proposal.Replacements[0]; replacement.Snippet != expectedSnippet || replacement.Step != "Setup Node" || replacement.Workflow != "build.yml" {
+		t.Errorf("Unexpected replacement %+v\n%s", replacement, replacement.Snippet)
+	}
+
+	// The generated files are valid YAML
+	var action map[string]interface{}
+	if err := yaml.Unmarshal([]byte(proposal.ActionYml), &action); err != nil || action["runs"] == nil {
+		t.Errorf("Expected the action.yml to be valid YAML, got %v", err)
+	}
+}
+
+func TestProposeCompositeActionInputSetByOneMember(t *testing.T) {
+	// Arrange - only one member sets fetch-depth, so the other must not pass a value for it
+	cluster := models.ActionCluster{
+		Uses:  "actions/checkout",
+		Repos: []string{"o/a", "o/b"},
+		Members: []models.ActionClusterMember{
+			{Repo: "o/a", Version: "v4", With: map[string]string{"fetch-depth": "0", "submodules": "true"}},
+			{Repo: "o/b", Version: "v4", With: map[string]string{"submodules": "true"}},
+		},
+	}
+
+	// Act
+	proposal := ProposeCompositeAction(cluster, "checkout", "o/shared-actions")
+
+	// Assert
+	if len(proposal.Inputs) != 2 || proposal.Inputs[0].Key != "fetch-depth" || proposal.Inputs[0].Required || proposal.Inputs[0].Default != nil {
+		t.Errorf("Expected fetch-depth to be an optional input without a default, got %+v", proposal.Inputs)
+	}
+
+	if !strings.Contains(proposal.ActionYml, "fetch-depth:\n    description: The fetch-depth input of actions/checkout.\n    required: false\n  submodules:") {
+		t.Errorf("Expected the action.yml to have an optional fetch-depth input:\n%s", proposal.ActionYml)
+	}
+
+	if !strings.Contains(proposal.Replacements[0].Snippet, "fetch-depth: 0") {
+		t.Errorf("Expected o/a to pass fetch-depth:\n%s", proposal.Replacements[0].Snippet)
+	}
+
+	if strings.Contains(proposal.Replacements[1].Snippet, "fetch-depth") {
+		t.Errorf("Expected o/b not to pass fetch-depth:\n%s", proposal.Replacements[1].Snippet)
+	}
+}
+
+func TestProposeCompositeActions(t *testing.T) {
+	// Arrange - run steps and reusable workflows can't be wrapped, and the same action can have several clusters
+	clusters := []models.ActionCluster{
+		newSetupNodeCluster(),
+		{Uses: BuiltInStep, Repos: []string{"owner/repo1", "owner/repo2"}},
+		{Uses: "owner/workflows/.github/workflows/deploy.yml", Repos: []string{"owner/repo1", "owner/repo2"}},
+		newSetupNodeCluster(),
+	}
+
+	// Act
+	proposals := ProposeCompositeActions(clusters, "")
+
+	// Assert
+	if len(proposals) != 2 || proposals[0].Name != "setup-node" || proposals[1].Name != "setup-node-2" {
+		t.Fatalf("Unexpected proposals %+v", proposals)
+	}
+
+	if proposals[1].Location != "owner/shared-actions/setup-node-2@v1" {
+		t.Errorf("Expected the default repository of the owner, got %s", proposals[1].Location)
+	}
+}
+
+func TestGetDefaultActionRepo(t *testing.T) {
+	tests := []struct {
+		name     string
+		repos    []string
+		expected string
+	}{
+		{"single owner", []string{"owner/repo1", "owner/repo2"}, "owner/shared-actions"},
+		{"different owners", []string{"owner1/repo1", "owner2/repo2"}, "OWNER/shared-actions"},
+		{"local checkouts", []string{"/tmp/repo1"}, "OWNER/shared-actions"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if result := getDefaultActionRepo(tt.repos); result != tt.expected {
+				t.Errorf("getDefaultActionRepo() = %q, expected %q", result, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,217 @@
+package workflows
+
+import (
+	"context"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/OctopusSolutionsEngineering/DuplicationCostCalculator/internal/domain/formatting"
+	"github.com/OctopusSolutionsEngineering/DuplicationCostCalculator/internal/domain/models"
+	"github.com/OctopusSolutionsEngineering/DuplicationCostCalculator/internal/infrastructure/githubapi"
+	"github.com/google/go-github/v57/github"
+	"github.com/samber/lo"
+)
+
+// AlignmentBranch is the branch pull requests that align action versions are opened from. Every run uses the
+// same branch, so an open pull request is updated rather than duplicated.
+const AlignmentBranch = "dupcost/align-action-versions"
+
+const AlignmentTitle = "Align GitHub Actions versions"
+
+// AlignmentOptions choose the actions that are aligned, and the versions they are aligned to.
+type AlignmentOptions struct {
+	// Target is TargetLatest or TargetMajority.
+	Target string
+	// Versions maps actions to the version they are aligned to, overriding the target.
+	Versions map[string]string
+	// Actions limits the drifted actions that are aligned. Every drifted action is aligned if it is empty.
+	Actions []string
+}
+
+// WorkflowChange is a workflow file with aligned action versions.
+type WorkflowChange struct {
+	File     string
+	Before   string
+	After    string
+	Rewrites []ActionRewrite
+}
+
+// RepoAlignment is the changes to the workflows of a repository that align its action versions.
+type RepoAlignment struct {
+	Repo    string
+	Changes []WorkflowChange
+}
+
+// GetAlignmentTargets returns the version each action is aligned to. These are the drifted actions in the report,
+// limited to the selected actions, and the actions with an explicit version.
+func GetAlignmentTargets(allRepoActions []RepoActions, report models.Report, options AlignmentOptions) map[string]string {
+	drifted := GetDriftedActions(report)
+	if len(options.Actions) != 0 {
+		drifted = lo.Intersect(drifted, options.Actions)
+	}
+
+	targets := GetTargetVersions(ConvertRepoActionsToActionsMap(allRepoActions), drifted, options.Target, report.RecommendedVersions)
+	for uses, version := range options.Versions {
+		targets[uses] = version
+	}
+
+	return targets
+}
+
+// PlanAlignment returns the changes to the workflows of each repository that align the actions to the target
+// versions, sorted by repository. Repositories with nothing to change are not included.
+func PlanAlignment(allRepoActions []RepoActions, targets map[string]string) []RepoAlignment {
+	alignments := []RepoAlignment{}
+
+	for _, repoActions := range allRepoActions {
+		changes := []WorkflowChange{}
+		for i, workflow := range repoActions.Workflows {
+			// A workflow can't be changed if the file it was loaded from isn't known
+			if i >= len(repoActions.WorkflowFiles) {
+				break
+			}
+
+			after, rewrites, err := RewriteActionVersions(workflow, targets)
+			if err != nil || len(rewrites) == 0 {
+				continue
+			}
+
+			changes = append(changes, WorkflowChange{
+				File:     repoActions.WorkflowFiles[i],
+				Before:   workflow,
+				After:    after,
+				Rewrites: rewrites,
+			})
+		}
+
+		if len(changes) != 0 {
+			alignments = append(alignments, RepoAlignment{Repo: repoActions.Repo, Changes: changes})
+		}
+	}
+
+	slices.SortFunc(alignments, func(a, b RepoAlignment) int {
+		return strings.Compare(a.Repo, b.Repo)
+	})
+
+	return alignments
+}
+
+// GetPullRequestBody explains which actions a pull request aligns, and the version each is aligned to.
+func GetPullRequestBody(alignment RepoAlignment) string {
+	fromVersions := map[string][]string{}
+	toVersions := map[string]string{}
+	for _, change := range alignment.Changes {
+		for _, rewrite := range change.Rewrites {
+			fromVersions[rewrite.Uses] = append(fromVersions[rewrite.Uses], rewrite.FromVersion)
+			toVersions[rewrite.Uses] = rewrite.ToVersion
+		}
+	}
+
+	builder := strings.Builder{}
+	builder.WriteString("This pull request aligns the versions of GitHub Actions that have drifted from the versions used by ")
+	builder.WriteString("other repositories, so a change to an action only has to be made once across every repository.\n\n")
+	builder.WriteString("| Action | Current versions | Aligned version |\n")
+	builder.WriteString("| --- | --- | --- |\n")
+	for _, uses := range slices.Sorted(maps.Keys(toVersions)) {
+		builder.WriteString("| `" + uses + "` | " + strings.Join(sortedUnique(fromVersions[uses]), ", ") + " | " + toVersions[uses] + " |\n")
+	}
+
+	builder.WriteString("\nChanged workflows:\n\n")
+	for _, change := range alignment.Changes {
+		builder.WriteString("- `" + getWorkflowPath(change.File) + "`\n")
+	}
+
+	builder.WriteString("\nThis pull request is updated when the versions are aligned again, so changes made to its branch ")
+	builder.WriteString("may be replaced.\n")
+
+	return builder.String()
+}
+
+// AlignActionVersions loads the workflows of the repositories, and proposes aligning the versions of the drifted
+// actions with a pull request in each repository that uses them. Running it again updates the pull requests that
+// are still open. If dryRun is true, the changes are planned without changing the repositories.
+// It returns the version each action is aligned to, and the result for each repository, sorted by repository.
+func AlignActionVersions(ctx context.Context, client *github.Client, repos []string, options AlignmentOptions, dryRun bool) (map[string]string, []models.PullRequestResult) {
+	// Repositories often call the same actions, so each version of an action is only loaded once
+	source := NewActionCachingSource(githubapi.NewWorkflowSource(client))
+	allRepoActions := lo.Map(repos, func(item string, index int) RepoActions {
+		return LoadRepoActions(ctx, source, item)
+	})
+
+	// The tags of the actions are needed to align actions to their latest version
+	parsedRepoActions, repoActions := ParseRepoActions(allRepoActions)
+	report := GenerateReportFromActionsMap(parsedRepoActions, repoActions, ReportOptions{
+		ActionTags: LoadActionTags(ctx, source, GetActionRepos(repoActions)),
+	})
+
+	targets := GetAlignmentTargets(allRepoActions, report, options)
+
+	results := []models.PullRequestResult{}
+	for _, repoActions := range allRepoActions {
+		if len(repoActions.Workflows) == 0 && len(repoActions.Errors) != 0 {
+			results = append(results, models.PullRequestResult{
+				Repo:   repoActions.Repo,
+				Status: models.PullRequestFailed,
+				Files:  []string{},
+				Error:  repoActions.Errors[0].Message,
+			})
+		}
+	}
+
+	for _, alignment := range PlanAlignment(allRepoActions, targets) {
+		results = append(results, OpenAlignmentPullRequest(ctx, client, alignment, dryRun))
+	}
+
+	slices.SortFunc(results, func(a, b models.PullRequestResult) int {
+		return strings.Compare(a.Repo, b.Repo)
+	})
+
+	return targets, results
+}
+
+// OpenAlignmentPullRequest commits the changed workflows of a repository to the alignment branch, and opens a pull
+// request, or updates the open one. If dryRun is true, only the diff of the changes is returned.
+func OpenAlignmentPullRequest(ctx context.Context, client *github.Client, alignment RepoAlignment, dryRun bool) models.PullRequestResult {
+	files := map[string]string{}
+	diff := strings.Builder{}
+	for _, change := range alignment.Changes {
+		path := getWorkflowPath(change.File)
+		files[path] = change.After
+		diff.WriteString(formatting.UnifiedDiff("a/"+path, "b/"+path, change.Before, change.After))
+	}
+
+	result := models.PullRequestResult{
+		Repo:   alignment.Repo,
+		Status: models.PullRequestPlanned,
+		Files:  slices.Sorted(maps.Keys(files)),
+		Diff:   diff.String(),
+	}
+
+	if dryRun {
+		return result
+	}
+
+	pullRequest, created, err := githubapi.CreateOrUpdatePullRequest(ctx, client, alignment.Repo, githubapi.PullRequestChange{
+		Branch:        AlignmentBranch,
+		Title:         AlignmentTitle,
+		Body:          GetPullRequestBody(alignment),
+		CommitMessage: AlignmentTitle,
+		Files:         files,
+	})
+	if err != nil {
+		result.Status = models.PullRequestFailed
+		result.Error = err.Error()
+		return result
+	}
+
+	result.Status = lo.Ternary(created, models.PullRequestCreated, models.PullRequestUpdated)
+	result.Number = pullRequest.GetNumber()
+	result.Url = pullRequest.GetHTMLURL()
+
+	return result
+}
+
+func getWorkflowPath(file string) string {
+	return ".github/workflows/" + file
+}
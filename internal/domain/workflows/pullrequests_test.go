@@ -0,0 +1,139 @@
+package workflows
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/OctopusSolutionsEngineering/DuplicationCostCalculator/internal/domain/models"
+	"github.com/OctopusSolutionsEngineering/DuplicationCostCalculator/internal/infrastructure/fakegithub"
+)
+
+func newAlignmentServer() *fakegithub.Server {
+	return fakegithub.NewServer().
+		AddRepo("owner/repo1", map[string]string{".github/workflows/build.yml": checkoutAndCacheV4Workflow}).
+		AddRepo("owner/repo2", map[string]string{
+			".github/workflows/build.yml": checkoutAndCacheV3Workflow,
+			".github/workflows/lint.yml":  checkoutV3Workflow,
+		}).
+		AddRepo("owner/repo3", map[string]string{".github/workflows/build.yml": checkoutAndCacheV4Workflow})
+}
+
+func TestPlanAlignment(t *testing.T) {
+	// Arrange
+	allRepoActions := []RepoActions{
+		{Repo: "owner/repo2", Workflows: []string{checkoutAndCacheV3Workflow, checkoutV3Workflow}, WorkflowFiles: []string{"build.yml", "lint.yml"}},
+		{Repo: "owner/repo1", Workflows: []string{checkoutAndCacheV4Workflow}, WorkflowFiles: []string{"build.yml"}},
+		{Repo: "owner/repo3", Workflows: []string{checkoutAndCacheV3Workflow}},
+	}
+
+	// Act
+	alignments := PlanAlignment(allRepoActions, map[string]string{"actions/checkout": "v4", "actions/cache": "v4"})
+
+	// Assert - repo1 is already aligned, and the workflows of repo3 can't be changed without their file names
+	if len(alignments) != 1 || alignments[0].Repo != "owner/repo2" || len(alignments[0].Changes) != 2 {
+		t.Fatalf("Unexpected alignments %+v", alignments)
+	}
+
+	if change := alignments[0].Changes[0]; change.File != "build.yml" || change.After != checkoutAndCacheV4Workflow || len(change.Rewrites) != 2 {
+		t.Errorf("Unexpected change %+v", change)
+	}
+}
+
+func TestGetPullRequestBody(t *testing.T) {
+	alignment := RepoAlignment{
+		Repo: "owner/repo",
+		Changes: []WorkflowChange{
+			{File: "build.yml", Rewrites: []ActionRewrite{{Uses: "actions/checkout", FromVersion: "v3", ToVersion: "v4"}, {Uses: "actions/cache", FromVersion: "v3", ToVersion: "v4"}}},
+			{File: "lint.yml", Rewrites: []ActionRewrite{{Uses: "actions/checkout", FromVersion: "v2", ToVersion: "v4"}}},
+		},
+	}
+
+	body := GetPullRequestBody(alignment)
+
+	for _, expected := range []string{
+		"| `actions/cache` | v3 | v4 |\n| `actions/checkout` | v2, v3 | v4 |",
+		"- `.github/workflows/build.yml`\n- `.github/workflows/lint.yml`",
+	} {
+		if !strings.Contains(body, expected) {
+			t.Errorf("Expected the body to contain %q, got\n%s", expected, body)
+		}
+	}
+}
+
+func TestAlignActionVersions(t *testing.T) {
+	// Arrange
+	server := newAlignmentServer()
+	defer server.Close()
+	repos := []string{"owner/repo1", "owner/repo2", "owner/repo3"}
+
+	// Act
+	targets, results := AlignActionVersions(context.Background(), server.Client(), repos, AlignmentOptions{Target: TargetMajority}, false)
+
+	// Assert - only repo2 drifts from the majority, so only it gets a pull request
+	if targets["actions/checkout"] != "v4" || targets["actions/cache"] != "v4" {
+		t.Errorf("Unexpected targets %v", targets)
+	}
+
+	if len(results) != 1 || results[0].Repo != "owner/repo2" || results[0].Status != models.PullRequestCreated || results[0].Url == "" {
+		t.Fatalf("Unexpected results %+v", results)
+	}
+
+	files := server.GetFiles("owner/repo2", AlignmentBranch)
+	if files[".github/workflows/build.yml"] != checkoutAndCacheV4Workflow || strings.Contains(files[".github/workflows/lint.yml"], "@v3") {
+		t.Errorf("Unexpected files on the branch %v", files)
+	}
+
+	pullRequests := server.GetPullRequests("owner/repo2")
+	if len(pullRequests) != 1 || pullRequests[0].Title != AlignmentTitle || !strings.Contains(pullRequests[0].Body, "`actions/checkout` | v3 | v4") {
+		t.Errorf("Unexpected pull requests %+v", pullRequests)
+	}
+}
+
+func TestAlignActionVersions_RunTwice(t *testing.T) {
+	// Arrange
+	server := newAlignmentServer()
+	defer server.Close()
+	repos := []string{"owner/repo1", "owner/repo2", "owner/repo3"}
+	AlignActionVersions(context.Background(), server.Client(), repos, AlignmentOptions{Target: TargetMajority}, false)
+
+	// Act - the second run also sets the version of checkout
+	_, results := AlignActionVersions(context.Background(), server.Client(), repos, AlignmentOptions{Target: TargetMajority, Versions: map[string]string{"actions/checkout": "v4.2.0"}}, false)
+
+	// Assert - the pull request is updated instead of duplicated
+	updated := map[string]bool{}
+	for _, result := range results {
+		updated[result.Repo] = result.Status == models.PullRequestUpdated
+	}
+
+	if !updated["owner/repo2"] {
+		t.Errorf("Expected the pull request in repo2 to be updated, got %+v", results)
+	}
+
+	if pullRequests := server.GetPullRequests("owner/repo2"); len(pullRequests) != 1 || !strings.Contains(pullRequests[0].Body, "v4.2.0") {
+		t.Errorf("Expected a single updated pull request, got %+v", pullRequests)
+	}
+}
+
+func TestAlignActionVersions_DryRun(t *testing.T) {
+	// Arrange
+	server := newAlignmentServer()
+	defer server.Close()
+
+	// Act
+	_, results := AlignActionVersions(context.Background(), server.Client(), []string{"owner/repo1", "owner/repo2", "owner/missing"}, AlignmentOptions{Target: TargetLatest}, true)
+
+	// Assert - the changes are planned without a branch or pull request, and the missing repo fails
+	if len(results) != 2 || results[0].Repo != "owner/missing" || results[0].Status != models.PullRequestFailed {
+		t.Fatalf("Unexpected results %+v", results)
+	}
+
+	planned := results[1]
+	if planned.Status != models.PullRequestPlanned || len(planned.Files) != 2 || !strings.Contains(planned.Diff, "-      - uses: actions/checkout@v3\n+      - uses: actions/checkout@v4\n") {
+		t.Errorf("Unexpected planned result %+v", planned)
+	}
+
+	if server.GetBranch("owner/repo2", AlignmentBranch) != "" || len(server.GetPullRequests("owner/repo2")) != 0 {
+		t.Error("Expected a dry run not to change the repository")
+	}
+}
@@ -0,0 +1,158 @@
+package workflows
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/OctopusSolutionsEngineering/DuplicationCostCalculator/internal/domain/models"
+)
+
+func TestParseWorkflowReusableWorkflowCall(t *testing.T) {
+	workflowYAML := `
+name: CI Pipeline
+on: [push]
+
+jobs:
+  build:
+    uses: my-org/shared/.github/workflows/build.yml@v1
+    with:
+      go-version: '1.22'
+    secrets:
+      token: ${{ secrets.TOKEN }}
+  deploy:
+    needs: build
+    uses: ./.github/workflows/deploy.yml
+    secrets: inherit
+  test:
+    runs-on: ubuntu-latest
+    steps:
+      - uses: actions/checkout@v4
+`
+
+	actions := ParseWorkflow(workflowYAML, 1)
+
+	if len(actions) != 3 {
+		t.Fatalf("Expected 3 actions, got %d", len(actions))
+	}
+
+	build := actions[0]
+	if !build.ReusableWorkflow {
+		t.Error("Expected the build job to be a reusable workflow call")
+	}
+	if build.Uses != "my-org/shared/.github/workflows/build.yml" || build.UsesVersion != "v1" {
+		t.Errorf("Unexpected uses %q and version %q", build.Uses, build.UsesVersion)
+	}
+	if build.With["go-version"] != "1.22" {
+		t.Errorf("Expected go-version input to be '1.22', got '%s'", build.With["go-version"])
+	}
+	if build.Secrets["token"] != "${{ secrets.TOKEN }}" {
+		t.Errorf("Expected token secret to be captured, got '%s'", build.Secrets["token"])
+	}
+	if _, ok := build.Settings["secrets"]; ok {
+		t.Error("Expected secrets map to be excluded from settings")
+	}
+
+	deploy := actions[1]
+	if !deploy.ReusableWorkflow || deploy.Uses != "./.github/workflows/deploy.yml" || deploy.UsesVersion != "latest" {
+		t.Errorf("Unexpected local reusable workflow call: %+v", deploy)
+	}
+	if deploy.Settings["secrets"] != "inherit" {
+		t.Errorf("Expected inherited secrets to be kept in settings, got '%s'", deploy.Settings["secrets"])
+	}
+	if deploy.Settings["needs"] != "build" {
+		t.Errorf("Expected needs setting to be 'build', got '%s'", deploy.Settings["needs"])
+	}
+
+	if actions[2].ReusableWorkflow || actions[2].Uses != "actions/checkout" {
+		t.Errorf("Expected a regular checkout step, got %+v", actions[2])
+	}
+
+	if actions[0].Id == actions[1].Id || actions[1].Id == actions[2].Id {
+		t.Error("Expected unique action IDs")
+	}
+}
+
+func TestGenerateReportFromWorkflowsReusableWorkflowDrift(t *testing.T) {
+	workflow1 := `
+jobs:
+  build:
+    uses: my-org/shared/.github/workflows/build.yml@v1
+    with:
+      go-version: '1.22'
+      run-tests: true
+      upload-artifacts: true
+      artifact-name: application-package
+`
+
+	workflow2 := `
+jobs:
+  build:
+    uses: my-org/shared/.github/workflows/build.yml@v2
+    with:
+      go-version: '1.22'
+      run-tests: true
+      upload-artifacts: true
+      artifact-name: application-package
+`
+
+	workflow3 := `
+jobs:
+  build:
+    runs-on: ubuntu-latest
+    steps:
+      - uses: actions/checkout@v4
+`
+
+	report := GenerateReportFromWorkflows(map[string][]string{
+		"repo1": {workflow1},
+		"repo2": {workflow2},
+		"repo3": {workflow3},
+	}, map[string][]string{}, map[string][]string{})
+
+	comparison := report.Comparisons["repo1"]["repo2"]
+	if comparison.StepsWithDifferentVersionsCount != 2 {
+		t.Errorf("Expected 2 steps with different versions, got %d", comparison.StepsWithDifferentVersionsCount)
+	}
+	if !slices.Contains(comparison.StepsWithDifferentVersions, "my-org/shared/.github/workflows/build.yml") {
+		t.Errorf("Expected the shared workflow to be reported as drifted, got %v", comparison.StepsWithDifferentVersions)
+	}
+	if comparison.StepsWithSimilarConfigCount != 2 {
+		t.Errorf("Expected the callers to have similar inputs, got %d", comparison.StepsWithSimilarConfigCount)
+	}
+
+	if !slices.Equal(report.SharedWorkflows["repo1"], []string{"my-org/shared/.github/workflows/build.yml@v1"}) {
+		t.Errorf("Unexpected shared workflows for repo1: %v", report.SharedWorkflows["repo1"])
+	}
+	if len(report.SharedWorkflows["repo3"]) != 0 {
+		t.Errorf("Expected no shared workflows for repo3, got %v", report.SharedWorkflows["repo3"])
+	}
+	if report.NumberOfReposUsingSharedWorkflows != 2 {
+		t.Errorf("Expected 2 repos using shared workflows, got %d", report.NumberOfReposUsingSharedWorkflows)
+	}
+}
+
+func TestGetSharedWorkflowsFromActionsListIgnoresLocalWorkflows(t *testing.T) {
+	workflowYAML := `
+jobs:
+  deploy:
+    uses: ./.github/workflows/deploy.yml
+  build:
+    uses: my-org/shared/.github/workflows/build.yml@v1
+  build2:
+    uses: my-org/shared/.github/workflows/build.yml@v1
+`
+
+	shared := GetSharedWorkflowsFromActionsList([][]models.Action{ParseWorkflow(workflowYAML, 1)})
+
+	if !slices.Equal(shared, []string{"my-org/shared/.github/workflows/build.yml@v1"}) {
+		t.Errorf("Unexpected shared workflows: %v", shared)
+	}
+}
+
+func TestGetSharedWorkflowsFromActionsListNil(t *testing.T) {
+	shared := GetSharedWorkflowsFromActionsList(nil)
+
+	if shared == nil || len(shared) != 0 {
+		t.Errorf("Expected an empty slice, got %v", shared)
+	}
+}
@@ -0,0 +1,197 @@
+package workflows
+
+import (
+	"cmp"
+	"math"
+	"slices"
+	"strings"
+
+	"github.com/OctopusSolutionsEngineering/DuplicationCostCalculator/internal/domain/models"
+	"github.com/OctopusSolutionsEngineering/DuplicationCostCalculator/internal/domain/parsing"
+	"github.com/samber/lo"
+)
+
+// SimilarScriptThreshold is the minimum similarity, between 0 and 1, for two scripts to be reported as similar.
+const SimilarScriptThreshold = 0.7
+
+// MinScriptTokens is the number of tokens a script needs before it is compared. Trivial scripts like
+// "npm ci" are expected to be repeated and are not worth reporting.
+const MinScriptTokens = 4
+
+// ScriptShingleSize is the number of consecutive tokens that are compared as a single unit.
+const ScriptShingleSize = 3
+
+// MaxScriptSnippetLength is the maximum number of characters of a script included in a report.
+const MaxScriptSnippetLength = 500
+
+// FindSimilarScripts returns the unique pairs of run scripts in the two lists of actions that are similar
+// once whitespace, comments and expression formatting are ignored.
+func FindSimilarScripts(actions1 []models.Action, actions2 []models.Action) []models.SimilarScript {
+	scripts2 := GetScriptShingles(actions2)
+	return FindSimilarScriptsFromShingles(actions1, GetScriptShingles(actions1), actions2, scripts2, NewScriptIndex(scripts2))
+}
+
+// FindSimilarScriptsFromShingles is FindSimilarScripts where the shingles of each list of actions have been
+// calculated with GetScriptShingles, and the shingles of the second list indexed with NewScriptIndex. Only the
+// candidates found in the index are compared, rather than every pair of scripts.
+func FindSimilarScriptsFromShingles(actions1 []models.Action, scripts1 [][]string, actions2 []models.Action, scripts2 [][]string, index2 ScriptIndex) []models.SimilarScript {
+	result := []models.SimilarScript{}
+	seen := map[models.SimilarScript]bool{}
+
+	for i, action1 := range actions1 {
+		shingles1 := scripts1[i]
+		if shingles1 == nil {
+			continue
+		}
+
+		for _, j := range index2.GetCandidates(shingles1) {
+			action2 := actions2[j]
+			if IsSharedCompositeStep(action1, action2) {
+				continue
+			}
+
+			similarity := GetScriptSimilarity(shingles1, scripts2[j])
+			if similarity < SimilarScriptThreshold {
+				continue
+			}
+
+			similarScript := models.SimilarScript{
+				Script1:    GetScriptSnippet(action1.Run),
+				Script2:    GetScriptSnippet(action2.Run),
+				Similarity: math.Round(similarity*100) / 100,
+			}
+
+			if !seen[similarScript] {
+				seen[similarScript] = true
+				result = append(result, similarScript)
+			}
+		}
+	}
+
+	return result
+}
+
+// ScriptIndex finds the scripts of a repository that could be similar to a script, without comparing every pair
+// of scripts. Each script is indexed by the shingles in its prefix, which are its least common shingles in the
+// repository. Two scripts with a similarity of at least SimilarScriptThreshold share more than the rest of the
+// shingles of either script, so they always share a shingle in their prefixes.
+type ScriptIndex struct {
+	counts   map[string]int
+	prefixes map[string][]int
+}
+
+// NewScriptIndex indexes the shingles of the scripts calculated with GetScriptShingles.
+func NewScriptIndex(scripts [][]string) ScriptIndex {
+	index := ScriptIndex{counts: map[string]int{}, prefixes: map[string][]int{}}
+	for _, shingles := range scripts {
+		for _, shingle := range shingles {
+			index.counts[shingle]++
+		}
+	}
+
+	for i, shingles := range scripts {
+		for _, shingle := range index.GetPrefix(shingles) {
+			index.prefixes[shingle] = append(index.prefixes[shingle], i)
+		}
+	}
+
+	return index
+}
+
+// GetPrefix returns the least common shingles of a script, which a similar script in the index shares at least one
+// of. Shingles that are rare in the repository are shared by few of its scripts, so there are few candidates.
+func (index ScriptIndex) GetPrefix(shingles []string) []string {
+	if len(shingles) == 0 {
+		return []string{}
+	}
+
+	ordered := slices.SortedFunc(slices.Values(shingles), func(a string, b string) int {
+		return cmp.Or(cmp.Compare(index.counts[a], index.counts[b]), strings.Compare(a, b))
+	})
+
+	// The small tolerance stops floating point error rounding the shared shingles up, which would shorten the prefix
+	shared := int(math.Ceil(SimilarScriptThreshold*float64(len(ordered)) - 1e-9))
+
+	return ordered[:len(ordered)-shared+1]
+}
+
+// GetCandidates returns the indexes, in order, of the scripts that share a shingle in their prefixes with the script.
+func (index ScriptIndex) GetCandidates(shingles []string) []int {
+	candidates := lo.Uniq(lo.FlatMap(index.GetPrefix(shingles), func(item string, i int) []int {
+		return index.prefixes[item]
+	}))
+	slices.Sort(candidates)
+
+	return candidates
+}
+
+// GetScriptShingles returns the shingles of the script of each action, in the same order as the actions.
+// Actions without a script long enough to compare have nil shingles.
+func GetScriptShingles(actions []models.Action) [][]string {
+	return lo.Map(actions, func(action models.Action, index int) []string {
+		if action.Run == "" {
+			return nil
+		}
+
+		tokens := parsing.TokenizeScript(action.Run)
+		if len(tokens) < MinScriptTokens {
+			return nil
+		}
+
+		return GetShingles(tokens, ScriptShingleSize)
+	})
+}
+
+// GetShingles returns the unique sequences of size consecutive tokens, sorted so they can be compared
+// with GetScriptSimilarity.
+func GetShingles(tokens []string, size int) []string {
+	if len(tokens) <= size {
+		return []string{strings.Join(tokens, " ")}
+	}
+
+	shingles := []string{}
+	for i := 0; i+size <= len(tokens); i++ {
+		shingles = append(shingles, strings.Join(tokens[i:i+size], " "))
+	}
+
+	slices.Sort(shingles)
+
+	return slices.Compact(shingles)
+}
+
+// GetScriptSimilarity returns the Jaccard similarity of two sorted sets of shingles, where 1 means the scripts
+// are identical.
+func GetScriptSimilarity(shingles1 []string, shingles2 []string) float64 {
+	intersection := 0
+	for i, j := 0, 0; i < len(shingles1) && j < len(shingles2); {
+		switch strings.Compare(shingles1[i], shingles2[j]) {
+		case 0:
+			intersection++
+			i++
+			j++
+		case -1:
+			i++
+		default:
+			j++
+		}
+	}
+
+	union := len(shingles1) + len(shingles2) - intersection
+	if union == 0 {
+		return 0
+	}
+
+	return float64(intersection) / float64(union)
+}
+
+// GetScriptSnippet returns the script to display in a report, truncated to MaxScriptSnippetLength characters.
+func GetScriptSnippet(script string) string {
+	snippet := strings.TrimSpace(script)
+	runes := []rune(snippet)
+
+	if len(runes) > MaxScriptSnippetLength {
+		return string(runes[:MaxScriptSnippetLength]) + "..."
+	}
+
+	return snippet
+}
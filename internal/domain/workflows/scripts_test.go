@@ -0,0 +1,253 @@
+package workflows
+
+import (
+	"math"
+	"math/rand/v2"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/OctopusSolutionsEngineering/DuplicationCostCalculator/internal/domain/models"
+)
+
+const buildScript = `
+# Build and push the image
+docker build -t ghcr.io/my-org/app:${{ github.sha }} .
+docker push ghcr.io/my-org/app:${{ github.sha }}
+echo "Pushed image"
+`
+
+func TestFindSimilarScripts(t *testing.T) {
+	tests := []struct {
+		name     string
+		script1  string
+		script2  string
+		expected int
+	}{
+		{
+			name:     "identical scripts",
+			script1:  buildScript,
+			script2:  buildScript,
+			expected: 1,
+		},
+		{
+			name:     "formatting and comments differ",
+			script1:  buildScript,
+			script2:  "docker build -t ghcr.io/my-org/app:${{github.sha}} .  # build\ndocker   push ghcr.io/my-org/app:${{github.sha}}\necho \"Pushed image\"",
+			expected: 1,
+		},
+		{
+			name:     "an extra command",
+			script1:  buildScript,
+			script2:  buildScript + "echo done\n",
+			expected: 1,
+		},
+		{
+			name:     "different scripts",
+			script1:  buildScript,
+			script2:  "go test ./...\ngo vet ./...\ngo build ./...",
+			expected: 0,
+		},
+		{
+			name:     "trivial scripts are ignored",
+			script1:  "npm ci",
+			script2:  "npm ci",
+			expected: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actions1 := []models.Action{{Id: "1-1", Run: tt.script1}, {Id: "1-2", Uses: "actions/checkout", UsesVersion: "v4"}}
+			actions2 := []models.Action{{Id: "1-1", Uses: "actions/checkout", UsesVersion: "v4"}, {Id: "1-2", Run: tt.script2}}
+
+			result := FindSimilarScripts(actions1, actions2)
+
+			if len(result) != tt.expected {
+				t.Fatalf("Expected %d similar scripts, got %d: %+v", tt.expected, len(result), result)
+			}
+
+			if tt.expected > 0 {
+				if result[0].Script1 != strings.TrimSpace(tt.script1) || result[0].Script2 != strings.TrimSpace(tt.script2) {
+					t.Errorf("Expected the snippets to be the original scripts, got %+v", result[0])
+				}
+
+				if result[0].Similarity < SimilarScriptThreshold || result[0].Similarity > 1 {
+					t.Errorf("Unexpected similarity %v", result[0].Similarity)
+				}
+			}
+		})
+	}
+}
+
+func TestFindSimilarScripts_Unique(t *testing.T) {
+	actions1 := []models.Action{{Id: "1-1", Run: buildScript}, {Id: "2-1", Run: buildScript}}
+	actions2 := []models.Action{{Id: "1-1", Run: buildScript}}
+
+	result := FindSimilarScripts(actions1, actions2)
+
+	if len(result) != 1 || result[0].Similarity != 1 {
+		t.Errorf("Expected a single identical script, got %+v", result)
+	}
+}
+
+func TestFindSimilarScriptsMatchesEveryPair(t *testing.T) {
+	// Arrange - the second scripts are the first with a command changed, so many pairs are close to the threshold
+	random := rand.New(rand.NewPCG(1, 2))
+	newCommand := func() string {
+		return "step" + strconv.Itoa(random.IntN(20)) + " --flag " + strconv.Itoa(random.IntN(3))
+	}
+	actions1 := []models.Action{}
+	actions2 := []models.Action{}
+	for i := 0; i < 100; i++ {
+		script := []string{}
+		for j := 0; j < 3+random.IntN(8); j++ {
+			script = append(script, newCommand())
+		}
+		actions1 = append(actions1, models.Action{Id: strconv.Itoa(i), Run: strings.Join(script, "\n")})
+
+		script[random.IntN(len(script))] = newCommand()
+		actions2 = append(actions2, models.Action{Id: strconv.Itoa(i), Run: strings.Join(script, "\n")})
+	}
+
+	// Act
+	result := FindSimilarScripts(actions1, actions2)
+
+	// Assert - the same scripts are found as when every pair is compared
+	scripts1 := GetScriptShingles(actions1)
+	scripts2 := GetScriptShingles(actions2)
+	expected := map[models.SimilarScript]bool{}
+	for i := range actions1 {
+		for j := range actions2 {
+			if scripts1[i] == nil || scripts2[j] == nil {
+				continue
+			}
+
+			if similarity := GetScriptSimilarity(scripts1[i], scripts2[j]); similarity >= SimilarScriptThreshold {
+				expected[models.SimilarScript{
+					Script1:    GetScriptSnippet(actions1[i].Run),
+					Script2:    GetScriptSnippet(actions2[j].Run),
+					Similarity: math.Round(similarity*100) / 100,
+				}] = true
+			}
+		}
+	}
+
+	if len(expected) == 0 || len(result) != len(expected) {
+		t.Fatalf("Expected %d similar scripts, got %d", len(expected), len(result))
+	}
+
+	for _, item := range result {
+		if !expected[item] {
+			t.Errorf("Unexpected similar script %+v", item)
+		}
+	}
+}
+
+func TestScriptIndexGetPrefix(t *testing.T) {
+	tests := []struct {
+		shingles int
+		expected int
+	}{
+		{0, 0},
+		{1, 1},
+		{3, 1},
+		{4, 2},
+		{10, 4},
+	}
+
+	for _, tt := range tests {
+		shingles := make([]string, tt.shingles)
+		if result := NewScriptIndex(nil).GetPrefix(shingles); len(result) != tt.expected {
+			t.Errorf("Expected a prefix of %d shingles for %d shingles, got %d", tt.expected, tt.shingles, len(result))
+		}
+	}
+}
+
+func TestScriptIndexGetCandidates(t *testing.T) {
+	// Arrange - the scripts share half their shingles, which are too common to find candidates with
+	common := []string{"c1", "c2", "c3", "c4", "c5"}
+	newScript := func(prefix string) []string {
+		return slices.Sorted(slices.Values(append(slices.Clone(common), prefix+"1", prefix+"2", prefix+"3", prefix+"4", prefix+"5")))
+	}
+	index := NewScriptIndex([][]string{newScript("a"), newScript("b"), nil, newScript("d")})
+
+	// Act
+	candidates := index.GetCandidates(newScript("b"))
+
+	// Assert
+	if !slices.Equal(candidates, []int{1}) {
+		t.Errorf("Expected only the script with the same rare shingles to be a candidate, got %v", candidates)
+	}
+
+	if candidates := index.GetCandidates(newScript("x")); len(candidates) != 0 {
+		t.Errorf("Expected no candidates for a script that only shares common shingles, got %v", candidates)
+	}
+}
+
+func TestGetShingles(t *testing.T) {
+	if result := GetShingles([]string{"a", "b"}, 3); !slices.Equal(result, []string{"a b"}) {
+		t.Errorf("Expected short token lists to be a single shingle, got %q", result)
+	}
+
+	if result := GetShingles([]string{"a", "b", "c", "a", "b", "c"}, 3); !slices.Equal(result, []string{"a b c", "b c a", "c a b"}) {
+		t.Errorf("Unexpected shingles %q", result)
+	}
+}
+
+func TestGetScriptSimilarity(t *testing.T) {
+	if result := GetScriptSimilarity([]string{}, []string{}); result != 0 {
+		t.Errorf("Expected empty shingles to have no similarity, got %v", result)
+	}
+
+	if result := GetScriptSimilarity([]string{"a", "b"}, []string{"b", "c"}); result != 1.0/3 {
+		t.Errorf("Expected a similarity of 1/3, got %v", result)
+	}
+}
+
+func TestGetScriptSnippet(t *testing.T) {
+	long := strings.Repeat("x", MaxScriptSnippetLength+10)
+
+	if result := GetScriptSnippet(long); len(result) != MaxScriptSnippetLength+3 || !strings.HasSuffix(result, "...") {
+		t.Errorf("Expected the snippet to be truncated, got %d characters", len(result))
+	}
+
+	if result := GetScriptSnippet("  npm ci\n"); result != "npm ci" {
+		t.Errorf("Expected the snippet to be trimmed, got %q", result)
+	}
+}
+
+func TestGenerateReportFromWorkflowsNormalizedScripts(t *testing.T) {
+	workflow1 := `
+jobs:
+  build:
+    steps:
+      - run: |
+          # Build and push the image
+          docker build -t ghcr.io/my-org/app:${{ github.sha }} .
+          docker push ghcr.io/my-org/app:${{ github.sha }}
+`
+	workflow2 := `
+jobs:
+  publish:
+    steps:
+      - run: |
+          docker build -t ghcr.io/my-org/app:${{github.sha}} .
+          docker push   ghcr.io/my-org/app:${{github.sha}}
+`
+
+	report := GenerateReportFromWorkflows(map[string][]string{
+		"repo1": {workflow1},
+		"repo2": {workflow2},
+	}, nil, nil)
+
+	measurements := report.Comparisons["repo1"]["repo2"]
+	if measurements.SimilarScriptsCount != 1 || len(measurements.SimilarScripts) != 1 {
+		t.Fatalf("Expected 1 similar script, got %+v", measurements.SimilarScripts)
+	}
+
+	if measurements.SimilarScripts[0].Similarity != 1 {
+		t.Errorf("Expected the normalized scripts to be identical, got %v", measurements.SimilarScripts[0].Similarity)
+	}
+}
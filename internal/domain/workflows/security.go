@@ -0,0 +1,206 @@
+package workflows
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/OctopusSolutionsEngineering/DuplicationCostCalculator/internal/domain/models"
+	"github.com/OctopusSolutionsEngineering/DuplicationCostCalculator/internal/domain/parsing"
+	"github.com/samber/lo"
+	"gopkg.in/yaml.v3"
+)
+
+// firstPartyOwners own the actions that are maintained by GitHub.
+var firstPartyOwners = []string{"actions", "github"}
+
+// AuditActionReferences returns the actions and reusable workflows in the workflows of each repository that are not
+// pinned to a full commit SHA, sorted by repository, workflow, and line. tags maps the repository of each action to
+// its tags, and is used to tell tags that don't look like versions apart from branches. owners maps the repositories
+// whose names don't include their owner, like local checkouts, to their owner.
+func AuditActionReferences(allRepoActions []RepoActions, tags map[string][]models.Tag, owners map[string]string) []models.SecurityFinding {
+	findings := []models.SecurityFinding{}
+
+	for _, repo := range allRepoActions {
+		owner := getRepoOwner(repo.Repo, owners)
+
+		for i, workflow := range repo.Workflows {
+			workflowName := ""
+			if i < len(repo.WorkflowFiles) {
+				workflowName = repo.WorkflowFiles[i]
+			}
+
+			// Workflows that can't be parsed are already reported in the repository's status
+			workflowFindings, err := AuditWorkflow(repo.Repo, owner, workflowName, workflow, tags)
+			if err != nil {
+				continue
+			}
+
+			findings = append(findings, workflowFindings...)
+		}
+	}
+
+	slices.SortStableFunc(findings, func(a, b models.SecurityFinding) int {
+		return cmp.Or(
+			cmp.Compare(a.Repo, b.Repo),
+			cmp.Compare(a.Workflow, b.Workflow),
+			cmp.Compare(a.Line, b.Line))
+	})
+
+	return findings
+}
+
+// AuditWorkflow returns the "uses" values in a workflow of the repository that are not pinned to a full commit SHA.
+// The raw workflow is parsed, rather than the actions, so that a missing version can be told apart from "@latest",
+// and each finding has the line it is on. Local actions and Docker images are not audited.
+func AuditWorkflow(repo string, owner string, workflowName string, workflow string, tags map[string][]models.Tag) ([]models.SecurityFinding, error) {
+	var document yaml.Node
+	if err := yaml.Unmarshal([]byte(workflow), &document); err != nil {
+		return nil, err
+	}
+
+	findings := []models.SecurityFinding{}
+
+	for _, node := range findUsesNodes(&document) {
+		finding, ok := AuditActionReference(repo, owner, node.Value, tags)
+		if !ok {
+			continue
+		}
+
+		finding.Workflow = workflowName
+		finding.Line = node.Line
+		findings = append(findings, finding)
+	}
+
+	return findings, nil
+}
+
+// AuditActionReference classifies the "uses" value of a step or job in the repository, which belongs to the owner.
+// The second return value is false if the reference is pinned to a full commit SHA, or is not audited.
+//
+// Actions without a version or pinned to a branch run whatever is pushed next, so they are high severity. Tags like
+// v4 or latest are moved to each new release, so they are medium severity. Tags like v4.1.0 are not expected to
+// move, but can be, so they are low severity. Actions owned by a third party are one severity higher, because the
+// repository's owner has no control over them.
+func AuditActionReference(repo string, owner string, uses string, tags map[string][]models.Tag) (models.SecurityFinding, bool) {
+	if uses == "" || parsing.IsLocalReference(uses) || strings.HasPrefix(uses, "docker://") {
+		return models.SecurityFinding{}, false
+	}
+
+	actionId, version := parsing.GetActionIdAndVersion(uses)
+	if !strings.Contains(uses, "@") {
+		version = ""
+	}
+
+	if parsing.IsSha(version) {
+		return models.SecurityFinding{}, false
+	}
+
+	actionRepo, _ := parsing.SplitActionPath(actionId)
+	kind := getReferenceKind(version, tags[actionRepo])
+	thirdParty := isThirdPartyAction(owner, actionRepo)
+
+	severity := getReferenceSeverity(kind)
+	if thirdParty {
+		severity = raiseSecuritySeverity(severity)
+	}
+
+	return models.SecurityFinding{
+		Repo:       repo,
+		Uses:       actionId,
+		Version:    version,
+		Kind:       kind,
+		Severity:   severity,
+		ThirdParty: thirdParty,
+		Message:    getReferenceMessage(actionId, version, kind),
+	}, true
+}
+
+// getReferenceKind classifies a version that is not a commit SHA. Semantic versions are assumed to be tags, and
+// other versions are branches unless they are one of the action's tags. "latest" is used for a missing version, so
+// unless the action has a latest tag, it is not a version either.
+func getReferenceKind(version string, tags []models.Tag) string {
+	if version == "" {
+		return models.ReferenceUnversioned
+	}
+
+	if semVer, ok := parsing.ParseSemVer(version); ok {
+		if semVer.Precision < 3 {
+			return models.ReferenceFloatingTag
+		}
+		return models.ReferenceTag
+	}
+
+	if lo.ContainsBy(tags, func(item models.Tag) bool { return item.Name == version }) {
+		return models.ReferenceFloatingTag
+	}
+
+	if strings.EqualFold(version, "latest") {
+		return models.ReferenceUnversioned
+	}
+
+	return models.ReferenceBranch
+}
+
+func getReferenceSeverity(kind string) string {
+	switch kind {
+	case models.ReferenceTag:
+		return models.SecuritySeverityLow
+	case models.ReferenceFloatingTag:
+		return models.SecuritySeverityMedium
+	default:
+		return models.SecuritySeverityHigh
+	}
+}
+
+// raiseSecuritySeverity returns the next most severe severity, up to critical.
+func raiseSecuritySeverity(severity string) string {
+	index := slices.Index(models.SecuritySeverities, severity)
+	if index < 0 || index == len(models.SecuritySeverities)-1 {
+		return severity
+	}
+
+	return models.SecuritySeverities[index+1]
+}
+
+// getRepoOwner returns the owner of the repository from owners, or from the repository's name if it is not in owners.
+func getRepoOwner(repo string, owners map[string]string) string {
+	if owner, ok := owners[repo]; ok {
+		return owner
+	}
+
+	owner, _ := parsing.SplitRepoNoErr(repo)
+	return owner
+}
+
+// isThirdPartyAction returns true if the action's repository is not owned by GitHub or by the owner of the repository
+// that calls it. Every action that is not owned by GitHub is third party if the owner is not known.
+func isThirdPartyAction(owner string, actionRepo string) bool {
+	actionOwner, _ := parsing.SplitRepoNoErr(actionRepo)
+	if actionOwner == "" {
+		return true
+	}
+
+	if lo.ContainsBy(firstPartyOwners, func(item string) bool { return strings.EqualFold(item, actionOwner) }) {
+		return false
+	}
+
+	return owner == "" || !strings.EqualFold(actionOwner, owner)
+}
+
+func getReferenceMessage(actionId string, version string, kind string) string {
+	switch kind {
+	case models.ReferenceUnversioned:
+		if version != "" {
+			return fmt.Sprintf("%s is pinned to %s, which is not a version of the action", actionId, version)
+		}
+		return fmt.Sprintf("%s has no version, so it runs the latest commit of the default branch", actionId)
+	case models.ReferenceBranch:
+		return fmt.Sprintf("%s is pinned to the %s branch, so it runs whatever is pushed to the branch", actionId, version)
+	case models.ReferenceFloatingTag:
+		return fmt.Sprintf("%s is pinned to the %s tag, which is moved to each new release", actionId, version)
+	default:
+		return fmt.Sprintf("%s is pinned to the %s tag, which can be moved to a different commit", actionId, version)
+	}
+}
@@ -0,0 +1,212 @@
+package workflows
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/OctopusSolutionsEngineering/DuplicationCostCalculator/internal/domain/models"
+	"github.com/OctopusSolutionsEngineering/DuplicationCostCalculator/internal/infrastructure/memory"
+	"github.com/samber/lo"
+)
+
+const unpinnedWorkflow = `
+jobs:
+  build:
+    steps:
+      - uses: actions/checkout@v4
+      - uses: actions/setup-node@v4.1.0
+      - uses: actions/cache@8e5a1d0c4f6c8b7d2e3f4a5b6c7d8e9f0a1b2c3d
+      - uses: azure/login@main
+      - uses: owner/internal-action
+      - uses: ./local-action
+      - uses: docker://alpine:3.19
+  deploy:
+    uses: other/workflows/.github/workflows/deploy.yml@v1
+`
+
+func TestAuditActionReference(t *testing.T) {
+	tags := map[string][]models.Tag{
+		"actions/checkout": {{Name: "stable", Commit: "abc"}},
+	}
+
+	tests := []struct {
+		uses               string
+		expectedOk         bool
+		expectedVersion    string
+		expectedKind       string
+		expectedSeverity   string
+		expectedThirdParty bool
+	}{
+		{"actions/checkout", true, "", models.ReferenceUnversioned, models.SecuritySeverityHigh, false},
+		{"actions/checkout@latest", true, "latest", models.ReferenceUnversioned, models.SecuritySeverityHigh, false},
+		{"actions/checkout@main", true, "main", models.ReferenceBranch, models.SecuritySeverityHigh, false},
+		{"actions/checkout@stable", true, "stable", models.ReferenceFloatingTag, models.SecuritySeverityMedium, false},
+		{"actions/checkout@v4", true, "v4", models.ReferenceFloatingTag, models.SecuritySeverityMedium, false},
+		{"actions/checkout@v4.1", true, "v4.1", models.ReferenceFloatingTag, models.SecuritySeverityMedium, false},
+		{"actions/checkout@v4.1.0", true, "v4.1.0", models.ReferenceTag, models.SecuritySeverityLow, false},
+		{"github/codeql-action/init@v3", true, "v3", models.ReferenceFloatingTag, models.SecuritySeverityMedium, false},
+		{"owner/internal-action@main", true, "main", models.ReferenceBranch, models.SecuritySeverityHigh, false},
+		{"Owner/internal-action@v1.0.0", true, "v1.0.0", models.ReferenceTag, models.SecuritySeverityLow, false},
+		{"azure/login@v4.1.0", true, "v4.1.0", models.ReferenceTag, models.SecuritySeverityMedium, true},
+		{"azure/login@v2", true, "v2", models.ReferenceFloatingTag, models.SecuritySeverityHigh, true},
+		{"azure/login@main", true, "main", models.ReferenceBranch, models.SecuritySeverityCritical, true},
+		{"azure/login", true, "", models.ReferenceUnversioned, models.SecuritySeverityCritical, true},
+		{"azure/login@8e5a1d0c4f6c8b7d2e3f4a5b6c7d8e9f0a1b2c3d", false, "", "", "", false},
+		{"./local-action", false, "", "", "", false},
+		{"docker://alpine:3.19", false, "", "", "", false},
+		{"", false, "", "", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.uses, func(t *testing.T) {
+			// Act
+			finding, ok := AuditActionReference("owner/repo", "owner", tt.uses, tags)
+
+			// Assert
+			if ok != tt.expectedOk {
+				t.Fatalf("AuditActionReference(%q) ok = %v, expected %v", tt.uses, ok, tt.expectedOk)
+			}
+
+			if !ok {
+				return
+			}
+
+			if finding.Version != tt.expectedVersion || finding.Kind != tt.expectedKind || finding.Severity != tt.expectedSeverity || finding.ThirdParty != tt.expectedThirdParty {
+				t.Errorf("AuditActionReference(%q) = %+v, expected version %q, kind %q, severity %q, third party %v",
+					tt.uses, finding, tt.expectedVersion, tt.expectedKind, tt.expectedSeverity, tt.expectedThirdParty)
+			}
+
+			if finding.Message == "" {
+				t.Errorf("Expected a message for %q", tt.uses)
+			}
+		})
+	}
+}
+
+func TestAuditActionReferenceLatestTag(t *testing.T) {
+	// An action with a latest tag is pinned to a floating tag, rather than having no version
+	tags := map[string][]models.Tag{"owner/action": {{Name: "latest", Commit: "abc"}}}
+
+	if finding, _ := AuditActionReference("owner/repo", "owner", "owner/action@latest", tags); finding.Kind != models.ReferenceFloatingTag {
+		t.Errorf("Expected a floating tag, got %+v", finding)
+	}
+
+	if finding, _ := AuditActionReference("owner/repo", "owner", "owner/action@latest", nil); finding.Kind != models.ReferenceUnversioned || !strings.Contains(finding.Message, "not a version") {
+		t.Errorf("Expected no version, got %+v", finding)
+	}
+}
+
+func TestAuditActionReferencesOwners(t *testing.T) {
+	// Arrange - local checkouts are named by their path, so the owner comes from owners
+	allRepoActions := []RepoActions{
+		{Repo: "checkouts/repo1", Workflows: []string{"jobs:\n  build:\n    steps:\n      - uses: my-org/internal-action@main\n"}},
+		{Repo: "checkouts/repo2", Workflows: []string{"jobs:\n  build:\n    steps:\n      - uses: my-org/internal-action@main\n"}},
+		{Repo: "my-org/repo3", Workflows: []string{"jobs:\n  build:\n    steps:\n      - uses: my-org/internal-action@main\n"}},
+	}
+	owners := map[string]string{"checkouts/repo1": "my-org", "checkouts/repo2": ""}
+
+	// Act
+	findings := AuditActionReferences(allRepoActions, nil, owners)
+
+	// Assert
+	expected := map[string]bool{"checkouts/repo1": false, "checkouts/repo2": true, "my-org/repo3": false}
+	if len(findings) != len(expected) {
+		t.Fatalf("Expected %d findings, got %+v", len(expected), findings)
+	}
+
+	for _, finding := range findings {
+		if finding.ThirdParty != expected[finding.Repo] {
+			t.Errorf("Expected the action called by %s to have third party %v, got %+v", finding.Repo, expected[finding.Repo], finding)
+		}
+	}
+}
+
+func TestAuditWorkflow(t *testing.T) {
+	// Act
+	findings, err := AuditWorkflow("owner/repo", "owner", "build.yml", unpinnedWorkflow, nil)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Failed to audit the workflow: %v", err)
+	}
+
+	expected := []models.SecurityFinding{
+		{Workflow: "build.yml", Line: 5, Uses: "actions/checkout", Version: "v4", Kind: models.ReferenceFloatingTag},
+		{Workflow: "build.yml", Line: 6, Uses: "actions/setup-node", Version: "v4.1.0", Kind: models.ReferenceTag},
+		{Workflow: "build.yml", Line: 8, Uses: "azure/login", Version: "main", Kind: models.ReferenceBranch},
+		{Workflow: "build.yml", Line: 9, Uses: "owner/internal-action", Version: "", Kind: models.ReferenceUnversioned},
+		{Workflow: "build.yml", Line: 13, Uses: "other/workflows/.github/workflows/deploy.yml", Version: "v1", Kind: models.ReferenceFloatingTag},
+	}
+
+	if len(findings) != len(expected) {
+		t.Fatalf("Expected %d findings, got %+v", len(expected), findings)
+	}
+
+	for i, item := range expected {
+		finding := findings[i]
+		if finding.Repo != "owner/repo" || finding.Workflow != item.Workflow || finding.Line != item.Line || finding.Uses != item.Uses || finding.Version != item.Version || finding.Kind != item.Kind {
+			t.Errorf("Finding %d = %+v, expected %+v", i, finding, item)
+		}
+	}
+}
+
+func TestAuditWorkflowInvalid(t *testing.T) {
+	if _, err := AuditWorkflow("owner/repo", "owner", "build.yml", "jobs: [", nil); err == nil {
+		t.Errorf("Expected an error for an invalid workflow")
+	}
+}
+
+func TestGenerateReportSecurityFindings(t *testing.T) {
+	// Arrange
+	source := memory.NewWorkflowSource().
+		AddWorkflow("owner/repo2", "build.yml", checkoutV4Workflow).
+		AddWorkflow("owner/repo1", "deploy.yml", unpinnedWorkflow).
+		AddWorkflow("owner/repo1", "build.yml", checkoutV3Workflow)
+
+	// Act
+	report := GenerateReportFromSource(context.Background(), source, []string{"owner/repo1", "owner/repo2"})
+
+	// Assert
+	locations := lo.Map(report.SecurityFindings, func(item models.SecurityFinding, index int) string {
+		return item.Repo + " " + item.Workflow + " " + item.Uses
+	})
+	expected := []string{
+		"owner/repo1 build.yml actions/checkout",
+		"owner/repo1 deploy.yml actions/checkout",
+		"owner/repo1 deploy.yml actions/setup-node",
+		"owner/repo1 deploy.yml azure/login",
+		"owner/repo1 deploy.yml owner/internal-action",
+		"owner/repo1 deploy.yml other/workflows/.github/workflows/deploy.yml",
+		"owner/repo2 build.yml actions/checkout",
+	}
+
+	if len(locations) != len(expected) {
+		t.Fatalf("Expected findings %v, got %v", expected, locations)
+	}
+
+	for i := range expected {
+		if locations[i] != expected[i] {
+			t.Errorf("Expected findings %v, got %v", expected, locations)
+			break
+		}
+	}
+}
+
+func TestRaiseSecuritySeverity(t *testing.T) {
+	tests := []struct {
+		severity string
+		expected string
+	}{
+		{models.SecuritySeverityLow, models.SecuritySeverityMedium},
+		{models.SecuritySeverityMedium, models.SecuritySeverityHigh},
+		{models.SecuritySeverityHigh, models.SecuritySeverityCritical},
+		{models.SecuritySeverityCritical, models.SecuritySeverityCritical},
+	}
+
+	for _, tt := range tests {
+		if result := raiseSecuritySeverity(tt.severity); result != tt.expected {
+			t.Errorf("raiseSecuritySeverity(%q) = %q, expected %q", tt.severity, result, tt.expected)
+		}
+	}
+}
@@ -0,0 +1,111 @@
+package workflows
+
+import (
+	"context"
+	"sync"
+
+	"github.com/OctopusSolutionsEngineering/DuplicationCostCalculator/internal/domain/models"
+)
+
+// MaxConcurrentLoads is the number of repositories that are loaded from a source at the same time. The GitHub
+// client limits the number of concurrent requests itself, so this only needs to keep it busy, while stopping a large
+// organization from starting a goroutine for every repository.
+const MaxConcurrentLoads = 16
+
+// WorkflowSource provides access to the workflows, contributors, and advisories of repositories.
+// Implementations exist for GitHub, local repository checkouts, and in-memory fixtures.
+// Methods that load data stop when the context is done, and return what they have loaded.
+type WorkflowSource interface {
+	// RepoName returns the normalized name used to identify the repository in a report.
+	RepoName(repo string) string
+	// FindWorkflows returns the file names of the workflows defined in the repository.
+	FindWorkflows(ctx context.Context, repo string) []string
+	// WorkflowToString returns the content of a workflow file.
+	WorkflowToString(ctx context.Context, repo string, workflow string) string
+	// FindContributorsToWorkflow returns the names of the people who have contributed to a workflow file.
+	FindContributorsToWorkflow(ctx context.Context, repo string, workflow string) []string
+	// ActionToString returns the content of the action.yml or action.yaml file in the directory path of
+	// the repository. An empty ref means the default branch.
+	ActionToString(ctx context.Context, repo string, path string, ref string) string
+	// FindTags returns the tags of a repository. It is used to resolve the versions of actions.
+	FindTags(ctx context.Context, repo string) []models.Tag
+	// GetWorkflowAdvisories returns the IDs of the security advisories for the repository.
+	GetWorkflowAdvisories(ctx context.Context, repo string) []string
+	// GetErrors returns the errors found while loading the repository, so a repository that could not be
+	// loaded can be told apart from a repository without workflows.
+	GetErrors(repo string) []models.RepoError
+	// GetRateLimit returns the API budget remaining after the repositories were loaded, or nil if the
+	// source is not rate limited.
+	GetRateLimit() *models.RateLimitBudget
+}
+
+// actionCachingSource is a source that loads each version of a remote action once, however many repositories call
+// it. Local actions are different in each repository, so they are not cached.
+type actionCachingSource struct {
+	WorkflowSource
+	mutex   sync.Mutex
+	actions map[string]*cachedAction
+}
+
+type cachedAction struct {
+	once    sync.Once
+	content string
+}
+
+// NewActionCachingSource returns a source that shares the remote actions loaded by the repositories of an analysis.
+// Repositories that are loaded at the same time wait for the first to load the action, rather than loading it again.
+func NewActionCachingSource(source WorkflowSource) WorkflowSource {
+	return &actionCachingSource{
+		WorkflowSource: source,
+		actions:        map[string]*cachedAction{},
+	}
+}
+
+func (s *actionCachingSource) ActionToString(ctx context.Context, repo string, path string, ref string) string {
+	if ref == "" {
+		return s.WorkflowSource.ActionToString(ctx, repo, path, ref)
+	}
+
+	key := repo + "/" + path + "@" + ref
+
+	s.mutex.Lock()
+	action, ok := s.actions[key]
+	if !ok {
+		action = &cachedAction{}
+		s.actions[key] = action
+	}
+	s.mutex.Unlock()
+
+	action.once.Do(func() {
+		action.content = s.WorkflowSource.ActionToString(ctx, repo, path, ref)
+	})
+
+	return action.content
+}
+
+// loadConcurrently calls load for each of the items, with at most MaxConcurrentLoads calls at the same time, and
+// sends the results to the returned channel. Once the context is done, no more items are loaded. The channel is
+// buffered so the goroutines can finish after the context is done.
+func loadConcurrently[T any, R any](ctx context.Context, items []T, load func(item T) R) <-chan R {
+	results := make(chan R, len(items))
+	pending := make(chan T, len(items))
+
+	for _, item := range items {
+		pending <- item
+	}
+	close(pending)
+
+	for range min(MaxConcurrentLoads, len(items)) {
+		go func() {
+			for item := range pending {
+				if ctx.Err() != nil {
+					return
+				}
+
+				results <- load(item)
+			}
+		}()
+	}
+
+	return results
+}
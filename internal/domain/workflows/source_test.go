@@ -0,0 +1,272 @@
+package workflows
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/OctopusSolutionsEngineering/DuplicationCostCalculator/internal/domain/models"
+	"github.com/OctopusSolutionsEngineering/DuplicationCostCalculator/internal/infrastructure/memory"
+)
+
+func TestGenerateReportFromSource(t *testing.T) {
+	workflow1 := `
+name: Build
+jobs:
+  build:
+    steps:
+      - uses: actions/checkout@v3
+`
+
+	workflow2 := `
+name: Build
+jobs:
+  build:
+    steps:
+      - uses: actions/checkout@v4
+`
+
+	source := memory.NewWorkflowSource().
+		AddWorkflow("owner/repo1", "build.yml", workflow1, "Alice").
+		AddWorkflow("owner/repo2", "build.yml", workflow2, "Bob", "Alice")
+	source.Advisories["owner/repo1"] = []string{"GHSA-1234"}
+
+	report := GenerateReportFromSource(context.Background(), source, []string{"owner/repo1", "owner/repo2"})
+
+	if report.NumberOfRepos != 2 {
+		t.Errorf("Expected 2 repos, got %d", report.NumberOfRepos)
+	}
+
+	if report.Comparisons["owner/repo1"]["owner/repo2"].StepsWithDifferentVersionsCount != 2 {
+		t.Errorf("Expected 2 steps with different versions, got %d", report.Comparisons["owner/repo1"]["owner/repo2"].StepsWithDifferentVersionsCount)
+	}
+
+	if len(report.WorkflowAdvisories["owner/repo1"]) != 1 {
+		t.Errorf("Expected 1 advisory for repo1, got %d", len(report.WorkflowAdvisories["owner/repo1"]))
+	}
+
+	slices.Sort(report.UniqueContributors)
+	if !slices.Equal(report.UniqueContributors, []string{"Alice", "Bob"}) {
+		t.Errorf("Expected unique contributors [Alice Bob], got %v", report.UniqueContributors)
+	}
+}
+
+func TestGenerateReportFromSourceNoRepos(t *testing.T) {
+	report := GenerateReportFromSource(context.Background(), memory.NewWorkflowSource(), []string{})
+
+	if report.NumberOfRepos != 0 {
+		t.Errorf("Expected 0 repos, got %d", report.NumberOfRepos)
+	}
+}
+
+func TestLoadRepoActions(t *testing.T) {
+	source := memory.NewWorkflowSource().
+		AddWorkflow("owner/repo", "build.yml", "name: Build", "Alice").
+		AddWorkflow("owner/repo", "empty.yml", "", "Alice", "Bob")
+
+	repoActions := LoadRepoActions(context.Background(), source, "owner/repo")
+
+	if repoActions.Repo != "owner/repo" {
+		t.Errorf("Expected repo name 'owner/repo', got '%s'", repoActions.Repo)
+	}
+
+	// Empty workflows are ignored, but their contributors are still counted
+	if len(repoActions.Workflows) != 1 {
+		t.Errorf("Expected 1 workflow, got %d", len(repoActions.Workflows))
+	}
+
+	if len(repoActions.Contributors) != 2 {
+		t.Errorf("Expected 2 contributors, got %v", repoActions.Contributors)
+	}
+}
+
+func TestGenerateReportFromSourceRateLimit(t *testing.T) {
+	// Arrange
+	source := memory.NewWorkflowSource().
+		AddWorkflow("owner/repo1", "build.yml", "jobs: {}").
+		AddWorkflow("owner/repo2", "build.yml", "jobs: {}")
+	source.RateLimit = &models.RateLimitBudget{Limit: 5000, Remaining: 4321, Requests: 20, Retries: 2}
+
+	// Act
+	report := GenerateReportFromSource(context.Background(), source, []string{"owner/repo1", "owner/repo2"})
+
+	// Assert
+	if report.Metadata.RateLimit == nil || report.Metadata.RateLimit.Remaining != 4321 || report.Metadata.RateLimit.Retries != 2 {
+		t.Errorf("Expected the rate limit of the source to be reported, got %+v", report.Metadata.RateLimit)
+	}
+}
+
+func TestGenerateReportFromSourceWithProgress(t *testing.T) {
+	// Arrange
+	source := memory.NewWorkflowSource().
+		AddWorkflow("owner/repo1", "build.yml", "jobs: {}").
+		AddWorkflow("owner/repo2", "build.yml", "jobs: {}")
+
+	loaded := []string{}
+	counts := []int{}
+
+	// Act
+	report := GenerateReportFromSourceWithProgress(context.Background(), source, []string{"owner/repo1", "owner/repo2", "owner/repo3"}, func(repoActions RepoActions, completed int, total int) {
+		loaded = append(loaded, repoActions.Repo)
+		counts = append(counts, completed)
+
+		if total != 3 {
+			t.Errorf("Expected a total of 3, got %d", total)
+		}
+	})
+
+	// Assert
+	slices.Sort(loaded)
+	if !slices.Equal(loaded, []string{"owner/repo1", "owner/repo2", "owner/repo3"}) {
+		t.Errorf("Expected progress for every repo, got %v", loaded)
+	}
+
+	if !slices.Equal(counts, []int{1, 2, 3}) {
+		t.Errorf("Expected the completed count to increase, got %v", counts)
+	}
+
+	if report.NumberOfRepos != 2 {
+		t.Errorf("Expected the report to include the repos with workflows, got %d", report.NumberOfRepos)
+	}
+}
+
+// blockingSource is a source that doesn't return the workflows of a repository until the context is done.
+type blockingSource struct {
+	*memory.WorkflowSource
+	blockedRepo string
+}
+
+func (s blockingSource) FindWorkflows(ctx context.Context, repo string) []string {
+	if repo == s.blockedRepo {
+		<-ctx.Done()
+		return []string{}
+	}
+
+	return s.WorkflowSource.FindWorkflows(ctx, repo)
+}
+
+func TestGenerateReportFromSourceCancelled(t *testing.T) {
+	tests := []struct {
+		name           string
+		newContext     func() (context.Context, context.CancelFunc)
+		expectedReason string
+	}{
+		{"cancelled", func() (context.Context, context.CancelFunc) {
+			return context.WithCancel(context.Background())
+		}, "cancelled"},
+		{"timed out", func() (context.Context, context.CancelFunc) {
+			return context.WithTimeout(context.Background(), 50*time.Millisecond)
+		}, "timed out"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			source := blockingSource{
+				WorkflowSource: memory.NewWorkflowSource().
+					AddWorkflow("owner/repo1", "build.yml", "jobs: {}").
+					AddWorkflow("owner/repo2", "build.yml", "jobs: {}").
+					AddWorkflow("owner/slow", "build.yml", "jobs: {}"),
+				blockedRepo: "owner/slow",
+			}
+
+			ctx, cancel := tt.newContext()
+			defer cancel()
+
+			// Act - the analysis is cancelled once the other repositories have loaded
+			report := GenerateReportFromSourceWithProgress(ctx, source, []string{"owner/repo1", "owner/repo2", "owner/slow"}, func(repoActions RepoActions, completed int, total int) {
+				if completed == 2 && tt.expectedReason == "cancelled" {
+					cancel()
+				}
+			})
+
+			// Assert
+			if !report.Metadata.Incomplete || report.Metadata.IncompleteReason != tt.expectedReason {
+				t.Errorf("Expected the report to be flagged as %s, got %+v", tt.expectedReason, report.Metadata)
+			}
+
+			if report.NumberOfRepos != 2 {
+				t.Errorf("Expected the report to include the loaded repos, got %d", report.NumberOfRepos)
+			}
+
+			for _, repo := range []string{"owner/repo1", "owner/repo2"} {
+				if status := report.RepoStatus[repo]; status.Status != models.RepoStatusOk {
+					t.Errorf("Expected %s to be loaded, got %+v", repo, status)
+				}
+			}
+
+			slow := report.RepoStatus["owner/slow"]
+			if slow.Status != models.RepoStatusFailed || len(slow.Errors) != 1 || slow.Errors[0].Category != models.ErrorCategoryCancelled {
+				t.Errorf("Expected the blocked repo to be cancelled, got %+v", slow)
+			}
+		})
+	}
+}
+
+func TestLoadRepoActionsCancelled(t *testing.T) {
+	// Arrange
+	source := memory.NewWorkflowSource().AddWorkflow("owner/repo", "build.yml", "jobs: {}", "Alice")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	// Act
+	repoActions := LoadRepoActions(ctx, source, "owner/repo")
+
+	// Assert
+	if len(repoActions.Workflows) != 0 || len(repoActions.Contributors) != 0 {
+		t.Errorf("Expected nothing to be loaded after the context is done, got %+v", repoActions)
+	}
+
+	if len(repoActions.Errors) != 1 || repoActions.Errors[0].Category != models.ErrorCategoryCancelled {
+		t.Errorf("Expected a cancelled error, got %+v", repoActions.Errors)
+	}
+}
+
+// concurrencySource is a source that records the most repositories it is asked to load at the same time.
+type concurrencySource struct {
+	*memory.WorkflowSource
+	inFlight    atomic.Int32
+	maxInFlight atomic.Int32
+}
+
+func (s *concurrencySource) FindWorkflows(ctx context.Context, repo string) []string {
+	current := s.inFlight.Add(1)
+	defer s.inFlight.Add(-1)
+
+	for {
+		observed := s.maxInFlight.Load()
+		if current <= observed || s.maxInFlight.CompareAndSwap(observed, current) {
+			break
+		}
+	}
+
+	time.Sleep(time.Millisecond)
+	return s.WorkflowSource.FindWorkflows(ctx, repo)
+}
+
+func TestGenerateReportFromSourceBoundedConcurrency(t *testing.T) {
+	// Arrange
+	source := &concurrencySource{WorkflowSource: memory.NewWorkflowSource()}
+	repos := []string{}
+	for i := range MaxConcurrentLoads * 4 {
+		repo := fmt.Sprintf("owner/repo%d", i)
+		repos = append(repos, repo)
+		source.AddWorkflow(repo, "build.yml", "jobs: {}")
+	}
+
+	// Act
+	report := GenerateReportFromSource(context.Background(), source, repos)
+
+	// Assert
+	if len(report.RepoStatus) != len(repos) {
+		t.Errorf("Expected every repository to be loaded, got %d", len(report.RepoStatus))
+	}
+
+	if source.maxInFlight.Load() > MaxConcurrentLoads {
+		t.Errorf("Expected at most %d repositories to be loaded at once, got %d", MaxConcurrentLoads, source.maxInFlight.Load())
+	}
+}
@@ -0,0 +1,89 @@
+package workflows
+
+import (
+	"maps"
+	"slices"
+
+	"github.com/OctopusSolutionsEngineering/DuplicationCostCalculator/internal/domain/models"
+	"github.com/OctopusSolutionsEngineering/DuplicationCostCalculator/internal/domain/parsing"
+	"github.com/samber/lo"
+)
+
+// GetLatestStableVersion returns the name of the highest semantic version tag that is not a prerelease,
+// or an empty string if there is no such tag. When tags like "v4" and "v4.0.0" represent the same
+// version, the more specific tag is returned.
+func GetLatestStableVersion(tags []models.Tag) string {
+	latest := ""
+	var latestSemVer parsing.SemVer
+
+	for _, tag := range tags {
+		semVer, ok := parsing.ParseSemVer(tag.Name)
+		if !ok || semVer.Prerelease != "" {
+			continue
+		}
+
+		comparison := semVer.Compare(latestSemVer)
+		if latest == "" || comparison > 0 || (comparison == 0 && semVer.Precision > latestSemVer.Precision) {
+			latest = tag.Name
+			latestSemVer = semVer
+		}
+	}
+
+	return latest
+}
+
+// GetRecommendedVersions returns the latest stable version of each action that has tags. This is the
+// version that repos with drift should be aligned to.
+func GetRecommendedVersions(tags map[string][]models.Tag, repoActions map[string][][]models.Action) map[string]string {
+	allActions := lo.Flatten(lo.Flatten(lo.Values(repoActions)))
+
+	recommended := map[string]string{}
+	for _, action := range allActions {
+		actionRepo, _ := parsing.SplitActionPath(action.Uses)
+		if latest := GetLatestStableVersion(tags[actionRepo]); latest != "" {
+			recommended[action.Uses] = latest
+		}
+	}
+
+	return recommended
+}
+
+// FindStaleActions returns the actions in the list that are behind the latest stable version of the action.
+// Actions pinned to branches, or to SHAs that could not be resolved to a tag, can not be measured and are ignored.
+// MinorsBehind is only calculated for actions on the latest major version.
+func FindStaleActions(actionsList [][]models.Action, recommendedVersions map[string]string) []models.ActionStaleness {
+	stale := map[string]models.ActionStaleness{}
+
+	for _, action := range lo.Flatten(actionsList) {
+		latest, ok := recommendedVersions[action.Uses]
+		if !ok {
+			continue
+		}
+
+		latestSemVer, _ := parsing.ParseSemVer(latest)
+		currentSemVer, ok := parsing.ParseSemVer(GetEffectiveVersion(action))
+		if !ok {
+			continue
+		}
+
+		staleness := models.ActionStaleness{
+			Uses:           action.Uses,
+			CurrentVersion: action.UsesVersion,
+			LatestVersion:  latest,
+			MajorsBehind:   max(latestSemVer.Major-currentSemVer.Major, 0),
+		}
+
+		// Versions like "v4" float to the latest minor release, so they are only behind by majors
+		if staleness.MajorsBehind == 0 && currentSemVer.Precision >= 2 {
+			staleness.MinorsBehind = max(latestSemVer.Minor-currentSemVer.Minor, 0)
+		}
+
+		if staleness.MajorsBehind > 0 || staleness.MinorsBehind > 0 {
+			stale[action.Uses+"@"+action.UsesVersion] = staleness
+		}
+	}
+
+	return lo.Map(slices.Sorted(maps.Keys(stale)), func(item string, index int) models.ActionStaleness {
+		return stale[item]
+	})
+}
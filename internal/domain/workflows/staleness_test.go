@@ -0,0 +1,134 @@
+package workflows
+
+import (
+	"context"
+	"testing"
+
+	"github.com/OctopusSolutionsEngineering/DuplicationCostCalculator/internal/domain/models"
+	"github.com/OctopusSolutionsEngineering/DuplicationCostCalculator/internal/infrastructure/memory"
+)
+
+func TestGetLatestStableVersion(t *testing.T) {
+	tests := []struct {
+		name     string
+		tags     []string
+		expected string
+	}{
+		{"no tags", []string{}, ""},
+		{"only branches", []string{"main", "stable"}, ""},
+		{"highest version", []string{"v3.0.0", "v4.1.0", "v4.0.2"}, "v4.1.0"},
+		{"prereleases are ignored", []string{"v4.1.0", "v5.0.0-beta.1"}, "v4.1.0"},
+		{"most specific tag", []string{"v4", "v4.1", "v4.1.0"}, "v4.1.0"},
+		{"major tag ahead of full tags", []string{"v4.1.0", "v5"}, "v5"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tags := []models.Tag{}
+			for _, name := range tt.tags {
+				tags = append(tags, models.Tag{Name: name})
+			}
+
+			if result := GetLatestStableVersion(tags); result != tt.expected {
+				t.Errorf("GetLatestStableVersion(%v) = %q, expected %q", tt.tags, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestGetRecommendedVersions(t *testing.T) {
+	repoActions := map[string][][]models.Action{
+		"repo1": {{
+			{Uses: "actions/checkout", UsesVersion: "v3"},
+			{Uses: "my-org/actions/deploy", UsesVersion: "v1"},
+			{Uses: "actions/setup-go", UsesVersion: "v5"},
+		}},
+	}
+
+	tags := map[string][]models.Tag{
+		"actions/checkout": {{Name: "v3.6.0"}, {Name: "v4.1.1"}},
+		"my-org/actions":   {{Name: "v2.0.0"}},
+	}
+
+	recommended := GetRecommendedVersions(tags, repoActions)
+
+	if len(recommended) != 2 {
+		t.Fatalf("Expected 2 recommended versions, got %v", recommended)
+	}
+
+	if recommended["actions/checkout"] != "v4.1.1" || recommended["my-org/actions/deploy"] != "v2.0.0" {
+		t.Errorf("Unexpected recommended versions: %v", recommended)
+	}
+}
+
+func TestFindStaleActions(t *testing.T) {
+	actionsList := [][]models.Action{{
+		{Uses: "actions/checkout", UsesVersion: "v3"},
+		{Uses: "actions/checkout", UsesVersion: "v3"},
+		{Uses: "actions/setup-go", UsesVersion: "v5.0.0"},
+		{Uses: "actions/setup-node", UsesVersion: "v4"},
+		{Uses: "actions/cache", UsesVersion: "main"},
+		{Uses: "actions/upload-artifact", UsesVersion: checkoutSha, ResolvedVersion: "v3.1.0"},
+		{Uses: "actions/unknown", UsesVersion: "v1"},
+	}}
+
+	recommended := map[string]string{
+		"actions/checkout":        "v4.1.1",
+		"actions/setup-go":        "v5.2.0",
+		"actions/setup-node":      "v4.3.0",
+		"actions/cache":           "v4.0.0",
+		"actions/upload-artifact": "v4.0.0",
+	}
+
+	stale := FindStaleActions(actionsList, recommended)
+
+	if len(stale) != 3 {
+		t.Fatalf("Expected 3 stale actions, got %d: %+v", len(stale), stale)
+	}
+
+	expected := []models.ActionStaleness{
+		{Uses: "actions/checkout", CurrentVersion: "v3", LatestVersion: "v4.1.1", MajorsBehind: 1},
+		{Uses: "actions/setup-go", CurrentVersion: "v5.0.0", LatestVersion: "v5.2.0", MinorsBehind: 2},
+		{Uses: "actions/upload-artifact", CurrentVersion: checkoutSha, LatestVersion: "v4.0.0", MajorsBehind: 1},
+	}
+
+	for i := range expected {
+		if stale[i] != expected[i] {
+			t.Errorf("Expected %+v, got %+v", expected[i], stale[i])
+		}
+	}
+}
+
+func TestGenerateReportFromSourceStaleActions(t *testing.T) {
+	workflow1 := `
+jobs:
+  build:
+    steps:
+      - uses: actions/checkout@v3
+`
+	workflow2 := `
+jobs:
+  build:
+    steps:
+      - uses: actions/checkout@v4.1.1
+`
+
+	source := memory.NewWorkflowSource().
+		AddWorkflow("owner/repo1", "build.yml", workflow1).
+		AddWorkflow("owner/repo2", "build.yml", workflow2)
+	source.Tags["actions/checkout"] = []models.Tag{{Name: "v3.6.0"}, {Name: "v4.1.1"}}
+
+	report := GenerateReportFromSource(context.Background(), source, []string{"owner/repo1", "owner/repo2"})
+
+	if report.RecommendedVersions["actions/checkout"] != "v4.1.1" {
+		t.Errorf("Expected v4.1.1 to be recommended, got %q", report.RecommendedVersions["actions/checkout"])
+	}
+
+	if len(report.StaleActions["owner/repo1"]) != 1 || report.StaleActions["owner/repo1"][0].MajorsBehind != 1 {
+		t.Errorf("Expected repo1 to be one major behind, got %+v", report.StaleActions["owner/repo1"])
+	}
+
+	if len(report.StaleActions["owner/repo2"]) != 0 {
+		t.Errorf("Expected repo2 to be up to date, got %+v", report.StaleActions["owner/repo2"])
+	}
+}
@@ -0,0 +1,44 @@
+package workflows
+
+import (
+	"github.com/OctopusSolutionsEngineering/DuplicationCostCalculator/internal/domain/models"
+	"github.com/samber/lo"
+)
+
+// GetRepoStatuses returns the status of every repository, including those that could not be loaded or have
+// no workflows, and so do not appear in the comparisons. The workflows that could not be parsed are only included
+// once the repositories have been parsed with ParseRepoActions.
+func GetRepoStatuses(allRepoActions []RepoActions) map[string]models.RepoStatus {
+	return lo.SliceToMap(allRepoActions, func(item RepoActions) (string, models.RepoStatus) {
+		return item.Repo, GetRepoStatus(len(item.Workflows), item.Errors)
+	})
+}
+
+// GetRepoStatus summarizes the errors found while loading a repository with the given number of workflows.
+func GetRepoStatus(workflowCount int, errors []models.RepoError) models.RepoStatus {
+	status := models.RepoStatusOk
+
+	switch {
+	case len(errors) == 0 && workflowCount == 0:
+		status = models.RepoStatusNoWorkflows
+	case len(errors) != 0 && workflowCount == 0:
+		status = models.RepoStatusFailed
+	case len(errors) != 0:
+		status = models.RepoStatusPartial
+	}
+
+	return models.RepoStatus{
+		Status: status,
+		Errors: errors,
+	}
+}
+
+// NewParseError records that a workflow is not valid YAML. These workflows are otherwise silently ignored when
+// the steps are parsed.
+func NewParseError(workflowName string, err error) models.RepoError {
+	return models.RepoError{
+		Category:  models.ErrorCategoryParseError,
+		Operation: "parse workflow " + workflowName,
+		Message:   err.Error(),
+	}
+}
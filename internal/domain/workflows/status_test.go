@@ -0,0 +1,166 @@
+package workflows
+
+import (
+	"context"
+	"encoding/base64"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/OctopusSolutionsEngineering/DuplicationCostCalculator/internal/domain/models"
+	"github.com/OctopusSolutionsEngineering/DuplicationCostCalculator/internal/infrastructure/githubapi"
+	"github.com/OctopusSolutionsEngineering/DuplicationCostCalculator/internal/infrastructure/memory"
+	"github.com/google/go-github/v57/github"
+	"github.com/migueleliasweb/go-github-mock/src/mock"
+)
+
+func TestGetRepoStatus(t *testing.T) {
+	repoError := models.RepoError{Category: models.ErrorCategoryForbidden, Operation: "list workflows"}
+
+	tests := []struct {
+		name          string
+		workflowCount int
+		errors        []models.RepoError
+		expected      string
+	}{
+		{"workflows without errors", 2, []models.RepoError{}, models.RepoStatusOk},
+		{"no workflows without errors", 0, []models.RepoError{}, models.RepoStatusNoWorkflows},
+		{"no workflows with errors", 0, []models.RepoError{repoError}, models.RepoStatusFailed},
+		{"workflows with errors", 2, []models.RepoError{repoError}, models.RepoStatusPartial},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status := GetRepoStatus(tt.workflowCount, tt.errors)
+
+			if status.Status != tt.expected {
+				t.Errorf("Expected status %s, got %s", tt.expected, status.Status)
+			}
+
+			if len(status.Errors) != len(tt.errors) {
+				t.Errorf("Expected %d errors, got %d", len(tt.errors), len(status.Errors))
+			}
+		})
+	}
+}
+
+func TestParseRepoActionsParseErrors(t *testing.T) {
+	// Arrange
+	repo := RepoActions{
+		Repo:          "owner/repo",
+		Workflows:     []string{"jobs: {}", "jobs: [unclosed", "name: Build\n  bad: indent"},
+		WorkflowFiles: []string{"good.yml", "broken.yml"},
+	}
+
+	// Act
+	parsed, _ := ParseRepoActions([]RepoActions{repo})
+
+	// Assert
+	errors := parsed[0].Errors
+	if len(errors) != 2 {
+		t.Fatalf("Expected 2 parse errors, got %+v", errors)
+	}
+
+	if errors[0].Category != models.ErrorCategoryParseError || errors[0].Operation != "parse workflow broken.yml" {
+		t.Errorf("Unexpected error %+v", errors[0])
+	}
+
+	// Workflows without a file name are identified by their position
+	if errors[1].Operation != "parse workflow #3" {
+		t.Errorf("Expected the workflow to be identified by position, got %s", errors[1].Operation)
+	}
+
+	// The errors are added to a copy, so the repository that was loaded is unchanged
+	if len(repo.Errors) != 0 {
+		t.Errorf("Expected the loaded repository to be unchanged, got %+v", repo.Errors)
+	}
+}
+
+func TestGenerateReportFromSourceRepoStatus(t *testing.T) {
+	// Arrange
+	source := memory.NewWorkflowSource().
+		AddWorkflow("owner/repo1", "build.yml", deployWorkflow).
+		AddWorkflow("owner/repo2", "build.yml", deployWorkflow).
+		AddWorkflow("owner/repo2", "broken.yml", "jobs: [unclosed")
+	source.Errors["owner/private"] = []models.RepoError{{
+		Category:  models.ErrorCategoryForbidden,
+		Operation: "list workflows",
+		Message:   "Resource not accessible by integration",
+	}}
+
+	// Act
+	report := GenerateReportFromSource(context.Background(), source, []string{"owner/repo1", "owner/repo2", "owner/empty", "owner/private"})
+
+	// Assert
+	expected := map[string]string{
+		"owner/repo1":   models.RepoStatusOk,
+		"owner/repo2":   models.RepoStatusPartial,
+		"owner/empty":   models.RepoStatusNoWorkflows,
+		"owner/private": models.RepoStatusFailed,
+	}
+
+	if len(report.RepoStatus) != len(expected) {
+		t.Fatalf("Expected %d statuses, got %+v", len(expected), report.RepoStatus)
+	}
+
+	for repo, status := range expected {
+		if report.RepoStatus[repo].Status != status {
+			t.Errorf("Expected %s to have status %s, got %+v", repo, status, report.RepoStatus[repo])
+		}
+	}
+
+	if errors := report.RepoStatus["owner/private"].Errors; len(errors) != 1 || errors[0].Category != models.ErrorCategoryForbidden {
+		t.Errorf("Expected the forbidden error to be reported, got %+v", errors)
+	}
+}
+
+func TestLoadRepoActionsForbiddenAdvisories(t *testing.T) {
+	// Arrange
+	encodedContent := base64.StdEncoding.EncodeToString([]byte(deployWorkflow))
+	mockedHTTPClient := mock.NewMockedHTTPClient(
+		mock.WithRequestMatchHandler(
+			mock.GetReposContentsByOwnerByRepoByPath,
+			http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if strings.HasSuffix(r.URL.Path, "/build.yml") {
+					w.Write(mock.MustMarshal(github.RepositoryContent{
+						Name:     github.String("build.yml"),
+						Type:     github.String("file"),
+						Content:  &encodedContent,
+						Encoding: github.String("base64"),
+					}))
+					return
+				}
+
+				w.Write(mock.MustMarshal([]github.RepositoryContent{{
+					Name: github.String("build.yml"),
+					Type: github.String("file"),
+				}}))
+			}),
+		),
+		mock.WithRequestMatch(
+			mock.GetReposCommitsByOwnerByRepo,
+			[]github.RepositoryCommit{},
+		),
+		mock.WithRequestMatchHandler(
+			mock.GetReposSecurityAdvisoriesByOwnerByRepo,
+			http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				mock.WriteError(w, http.StatusForbidden, "Resource not accessible by integration")
+			}),
+		),
+	)
+
+	source := githubapi.NewWorkflowSource(github.NewClient(mockedHTTPClient))
+
+	// Act
+	repoActions := LoadRepoActions(context.Background(), source, "owner/repo")
+	statuses := GetRepoStatuses([]RepoActions{repoActions})
+
+	// Assert
+	if len(repoActions.Workflows) != 1 {
+		t.Fatalf("Expected the workflow to be loaded, got %+v", repoActions)
+	}
+
+	if status := statuses["owner/repo"]; status.Status != models.RepoStatusOk || len(status.Errors) != 0 {
+		t.Errorf("Expected advisories that can't be read not to affect the status, got %+v", status)
+	}
+}
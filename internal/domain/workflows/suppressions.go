@@ -0,0 +1,222 @@
+package workflows
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/OctopusSolutionsEngineering/DuplicationCostCalculator/internal/domain/models"
+	"github.com/samber/lo"
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultSuppressionFile is the name of the suppression file that is used if it exists in the working directory.
+const DefaultSuppressionFile = ".dupcost-suppressions.yml"
+
+// ParseSuppressions parses a suppression file, which is YAML or JSON, and checks each suppression is valid.
+func ParseSuppressions(content []byte) ([]models.Suppression, error) {
+	var file models.SuppressionFile
+	if err := yaml.Unmarshal(content, &file); err != nil {
+		return nil, err
+	}
+
+	var suppressionErrors []error
+	for i, suppression := range file.Suppressions {
+		if err := ValidateSuppression(suppression); err != nil {
+			suppressionErrors = append(suppressionErrors, fmt.Errorf("suppression %d: %w", i+1, err))
+		}
+	}
+
+	if len(suppressionErrors) != 0 {
+		return nil, errors.Join(suppressionErrors...)
+	}
+
+	return file.Suppressions, nil
+}
+
+// ValidateSuppression returns an error if the suppression does not limit what it accepts, does not expire, or
+// has no reason.
+func ValidateSuppression(suppression models.Suppression) error {
+	if strings.Contains(suppression.Action, "@") {
+		return fmt.Errorf("the action %q must not include a version", suppression.Action)
+	}
+
+	if len(suppression.Repos) > 2 {
+		return errors.New("a suppression can have at most two repos")
+	}
+
+	if suppression.Action == "" && len(suppression.Repos) == 0 {
+		return errors.New("an action or repos are required")
+	}
+
+	if _, err := time.Parse(time.DateOnly, suppression.Expires); err != nil {
+		return fmt.Errorf("the expiry date %q must be a date like 2025-12-31", suppression.Expires)
+	}
+
+	if strings.TrimSpace(suppression.Reason) == "" {
+		return errors.New("a reason is required")
+	}
+
+	return nil
+}
+
+// GetActiveSuppressions splits the suppressions into those that apply at the time, and those that have expired.
+// A suppression applies until the end of its expiry date, in UTC.
+func GetActiveSuppressions(suppressions []models.Suppression, now time.Time) ([]models.Suppression, []models.Suppression) {
+	return lo.FilterReject(suppressions, func(item models.Suppression, index int) bool {
+		expires, err := time.Parse(time.DateOnly, item.Expires)
+		return err == nil && now.Before(expires.AddDate(0, 0, 1))
+	})
+}
+
+// MatchSuppression returns the first suppression that accepts the drift and duplication of the action between
+// the two repositories.
+func MatchSuppression(suppressions []models.Suppression, repo1 string, repo2 string, uses string) (models.Suppression, bool) {
+	return lo.Find(suppressions, func(item models.Suppression) bool {
+		if item.Action != "" && !strings.EqualFold(item.Action, uses) {
+			return false
+		}
+
+		switch len(item.Repos) {
+		case 1:
+			return strings.EqualFold(item.Repos[0], repo1) || strings.EqualFold(item.Repos[0], repo2)
+		case 2:
+			return (strings.EqualFold(item.Repos[0], repo1) && strings.EqualFold(item.Repos[1], repo2)) ||
+				(strings.EqualFold(item.Repos[0], repo2) && strings.EqualFold(item.Repos[1], repo1))
+		}
+
+		return true
+	})
+}
+
+// SuppressedRepoActions is the indexed actions of a repository, split by the suppressions that accept an action in
+// every repository. These don't depend on the other repository of a pair, so they are applied once per repository.
+type SuppressedRepoActions struct {
+	Kept       IndexedRepoActions
+	Suppressed IndexedRepoActions
+}
+
+// SuppressRepoActions splits the indexed actions of a repository into those that are accepted by a suppression
+// without repos, and those that are compared.
+func SuppressRepoActions(suppressions []models.Suppression, indexed IndexedRepoActions) SuppressedRepoActions {
+	repoIndependent := lo.Filter(suppressions, func(item models.Suppression, index int) bool {
+		return len(item.Repos) == 0
+	})
+
+	suppressedUses := lo.Filter(lo.Keys(indexed.ActionsByUses), func(uses string, index int) bool {
+		_, ok := MatchSuppression(repoIndependent, "", "", uses)
+		return ok
+	})
+
+	if len(suppressedUses) == 0 {
+		return SuppressedRepoActions{Kept: indexed}
+	}
+
+	isSuppressed := lo.Keyify(suppressedUses)
+
+	return SuppressedRepoActions{
+		Kept: FilterIndexedRepoActions(indexed, func(uses string) bool {
+			_, ok := isSuppressed[uses]
+			return !ok
+		}),
+		Suppressed: FilterIndexedRepoActions(indexed, func(uses string) bool {
+			_, ok := isSuppressed[uses]
+			return ok
+		}),
+	}
+}
+
+// SuppressPair removes the actions that are accepted by a suppression from the indexed actions of two
+// repositories, and returns the drift and duplication of the removed actions. The actions accepted in every
+// repository have already been removed by SuppressRepoActions, so only the suppressions that depend on the
+// repositories are applied here.
+func SuppressPair(suppressions []models.Suppression, repo1 string, actions1 SuppressedRepoActions, repo2 string, actions2 SuppressedRepoActions, cache DriftSeverityCache) (IndexedRepoActions, IndexedRepoActions, []models.SuppressedFinding) {
+	if len(suppressions) == 0 {
+		return actions1.Kept, actions2.Kept, []models.SuppressedFinding{}
+	}
+
+	suppressionsByUses := map[string]models.Suppression{}
+	for _, indexed := range []IndexedRepoActions{actions1.Kept, actions1.Suppressed, actions2.Kept, actions2.Suppressed} {
+		for uses := range indexed.ActionsByUses {
+			if _, ok := suppressionsByUses[uses]; ok {
+				continue
+			}
+
+			if suppression, ok := MatchSuppression(suppressions, repo1, repo2, uses); ok {
+				suppressionsByUses[uses] = suppression
+			}
+		}
+	}
+
+	if len(suppressionsByUses) == 0 {
+		return actions1.Kept, actions2.Kept, []models.SuppressedFinding{}
+	}
+
+	// Compare the suppressed actions to find the drift and duplication that was accepted
+	similarConfig, versionDrift := compareSuppressedActions(actions1.Suppressed, actions2.Suppressed, cache)
+
+	kept1 := actions1.Kept
+	kept2 := actions2.Kept
+
+	isPairSuppressed := func(uses string) bool {
+		_, ok := suppressionsByUses[uses]
+		return ok
+	}
+
+	isPairKept := func(uses string) bool {
+		return !isPairSuppressed(uses)
+	}
+
+	if lo.SomeBy(lo.Keys(suppressionsByUses), func(uses string) bool {
+		return len(kept1.ActionsByUses[uses]) != 0 || len(kept2.ActionsByUses[uses]) != 0
+	}) {
+		pairSimilarConfig, pairVersionDrift := compareSuppressedActions(FilterIndexedRepoActions(kept1, isPairSuppressed), FilterIndexedRepoActions(kept2, isPairSuppressed), cache)
+		similarConfig = append(similarConfig, pairSimilarConfig...)
+		versionDrift = append(versionDrift, pairVersionDrift...)
+
+		kept1 = FilterIndexedRepoActions(kept1, isPairKept)
+		kept2 = FilterIndexedRepoActions(kept2, isPairKept)
+	}
+
+	findings := []models.SuppressedFinding{}
+	for _, uses := range sortedUnique(lo.Keys(suppressionsByUses)) {
+		name := uses
+		if name == "" {
+			name = BuiltInStep
+		}
+
+		drift := lo.Filter(versionDrift, func(item models.VersionDrift, index int) bool {
+			return item.Uses == uses
+		})
+
+		if len(drift) == 0 && !slices.Contains(similarConfig, name) {
+			continue
+		}
+
+		findings = append(findings, models.SuppressedFinding{
+			Repo1:         repo1,
+			Repo2:         repo2,
+			Uses:          name,
+			VersionDrift:  drift,
+			SimilarConfig: slices.Contains(similarConfig, name),
+			Suppression:   suppressionsByUses[uses],
+		})
+	}
+
+	return kept1, kept2, findings
+}
+
+// compareSuppressedActions returns the names of the actions with similar configuration, and the version drift,
+// between the suppressed actions of two repositories.
+func compareSuppressedActions(suppressed1 IndexedRepoActions, suppressed2 IndexedRepoActions, cache DriftSeverityCache) ([]string, []models.VersionDrift) {
+	if len(suppressed1.Actions) == 0 || len(suppressed2.Actions) == 0 {
+		return []string{}, []models.VersionDrift{}
+	}
+
+	_, _, similarConfig, _ := GetActionsWithVersionDriftAndDuplicationIndexed(suppressed1, suppressed2)
+	versionDrift := FindVersionDriftIndexed(suppressed1.UniqueVersions, suppressed2.UniqueVersionsByUses, cache)
+
+	return similarConfig, versionDrift
+}
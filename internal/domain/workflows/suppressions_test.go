@@ -0,0 +1,163 @@
+package workflows
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/OctopusSolutionsEngineering/DuplicationCostCalculator/internal/domain/models"
+	"github.com/OctopusSolutionsEngineering/DuplicationCostCalculator/internal/infrastructure/memory"
+)
+
+const setupNodeV3Workflow = `
+jobs:
+  build:
+    steps:
+      - uses: actions/checkout@v4
+      - uses: actions/setup-node@v3
+`
+
+const setupNodeV4Workflow = `
+jobs:
+  build:
+    steps:
+      - uses: actions/checkout@v4
+      - uses: actions/setup-node@v4
+`
+
+func TestParseSuppressions(t *testing.T) {
+	tests := []struct {
+		name          string
+		content       string
+		expectedCount int
+		expectedErr   bool
+	}{
+		{"yaml", `
+suppressions:
+  - action: actions/setup-node
+    repos: [owner/legacy]
+    expires: 2025-12-31
+    reason: Migrating to Node 20
+  - repos: [owner/repo1, owner/repo2]
+    expires: "2025-06-30"
+    reason: Being merged
+`, 2, false},
+		{"json", `{"suppressions": [{"action": "actions/cache", "expires": "2025-12-31", "reason": "Pinned"}]}`, 1, false},
+		{"empty", ``, 0, false},
+		{"version", `{"suppressions": [{"action": "actions/cache@v3", "expires": "2025-12-31", "reason": "Pinned"}]}`, 0, true},
+		{"no action or repos", `{"suppressions": [{"expires": "2025-12-31", "reason": "Everything"}]}`, 0, true},
+		{"too many repos", `{"suppressions": [{"repos": ["a/b", "c/d", "e/f"], "expires": "2025-12-31", "reason": "Pinned"}]}`, 0, true},
+		{"no expiry", `{"suppressions": [{"action": "actions/cache", "reason": "Pinned"}]}`, 0, true},
+		{"invalid expiry", `{"suppressions": [{"action": "actions/cache", "expires": "next year", "reason": "Pinned"}]}`, 0, true},
+		{"no reason", `{"suppressions": [{"action": "actions/cache", "expires": "2025-12-31"}]}`, 0, true},
+		{"malformed", `suppressions: [`, 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			suppressions, err := ParseSuppressions([]byte(tt.content))
+
+			if (err != nil) != tt.expectedErr || len(suppressions) != tt.expectedCount {
+				t.Errorf("ParseSuppressions() = %+v, %v, expected %d suppressions and error %v", suppressions, err, tt.expectedCount, tt.expectedErr)
+			}
+		})
+	}
+}
+
+func TestGetActiveSuppressions(t *testing.T) {
+	suppressions := []models.Suppression{
+		{Action: "actions/cache", Expires: "2025-06-01"},
+		{Action: "actions/checkout", Expires: "2025-05-31"},
+		{Action: "actions/setup-node", Expires: "2025-05-30"},
+	}
+
+	active, expired := GetActiveSuppressions(suppressions, time.Date(2025, 5, 31, 23, 59, 0, 0, time.UTC))
+
+	// A suppression applies until the end of its expiry date
+	if len(active) != 2 || active[0].Action != "actions/cache" || active[1].Action != "actions/checkout" {
+		t.Errorf("Unexpected active suppressions %+v", active)
+	}
+
+	if len(expired) != 1 || expired[0].Action != "actions/setup-node" {
+		t.Errorf("Unexpected expired suppressions %+v", expired)
+	}
+}
+
+func TestMatchSuppression(t *testing.T) {
+	tests := []struct {
+		name        string
+		suppression models.Suppression
+		expected    bool
+	}{
+		{"action", models.Suppression{Action: "actions/setup-node"}, true},
+		{"action case", models.Suppression{Action: "Actions/Setup-Node"}, true},
+		{"other action", models.Suppression{Action: "actions/cache"}, false},
+		{"repo and action", models.Suppression{Action: "actions/setup-node", Repos: []string{"owner/legacy"}}, true},
+		{"other repo and action", models.Suppression{Action: "actions/setup-node", Repos: []string{"owner/repo3"}}, false},
+		{"repo", models.Suppression{Repos: []string{"owner/repo1"}}, true},
+		{"pair", models.Suppression{Repos: []string{"owner/legacy", "owner/repo1"}}, true},
+		{"other pair", models.Suppression{Repos: []string{"owner/legacy", "owner/repo3"}}, false},
+		{"pair and action", models.Suppression{Action: "actions/setup-node", Repos: []string{"owner/repo1", "owner/legacy"}}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, ok := MatchSuppression([]models.Suppression{tt.suppression}, "owner/legacy", "owner/repo1", "actions/setup-node")
+
+			if ok != tt.expected {
+				t.Errorf("MatchSuppression() = %v, expected %v", ok, tt.expected)
+			}
+		})
+	}
+}
+
+func TestGenerateReportSuppressions(t *testing.T) {
+	// Arrange - the legacy repo is pinned to an older version of setup-node while it is migrated
+	source := memory.NewWorkflowSource().
+		AddWorkflow("owner/legacy", "build.yml", setupNodeV3Workflow).
+		AddWorkflow("owner/repo1", "build.yml", setupNodeV4Workflow).
+		AddWorkflow("owner/repo2", "build.yml", setupNodeV4Workflow)
+	repos := []string{"owner/legacy", "owner/repo1", "owner/repo2"}
+
+	tests := []struct {
+		name                 string
+		suppressions         []models.Suppression
+		expectedRepos        int
+		expectedSuppressed   int
+		expectedExpiredCount int
+	}{
+		{"no suppressions", nil, 3, 0, 0},
+		{"repo and action", []models.Suppression{{Action: "actions/setup-node", Repos: []string{"owner/legacy"}, Expires: "2999-12-31", Reason: "Migrating"}}, 0, 2, 0},
+		{"pair", []models.Suppression{{Repos: []string{"owner/legacy", "owner/repo1"}, Expires: "2999-12-31", Reason: "Migrating"}}, 2, 1, 0},
+		{"expired", []models.Suppression{{Action: "actions/setup-node", Expires: "2000-01-01", Reason: "Migrating"}}, 3, 0, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			report := GenerateReportFromSourceWithOptions(context.Background(), source, repos, nil, ReportOptions{Suppressions: tt.suppressions})
+
+			// Assert
+			if report.NumberOfReposWithDuplicationOrDrift != tt.expectedRepos {
+				t.Errorf("NumberOfReposWithDuplicationOrDrift = %d, expected %d", report.NumberOfReposWithDuplicationOrDrift, tt.expectedRepos)
+			}
+
+			if len(report.Suppressed) != tt.expectedSuppressed || len(report.ExpiredSuppressions) != tt.expectedExpiredCount {
+				t.Fatalf("Unexpected suppressed findings %+v and expired suppressions %+v", report.Suppressed, report.ExpiredSuppressions)
+			}
+
+			if tt.expectedSuppressed == 0 {
+				return
+			}
+
+			finding := report.Suppressed[0]
+			if finding.Repo1 != "owner/legacy" || finding.Repo2 != "owner/repo1" || finding.Uses != "actions/setup-node" || len(finding.VersionDrift) == 0 || finding.Suppression.Reason != "Migrating" {
+				t.Errorf("Unexpected suppressed finding %+v", finding)
+			}
+
+			if len(report.Comparisons["owner/legacy"]["owner/repo1"].VersionDrift) != 0 {
+				t.Errorf("Expected the suppressed drift to be excluded from the comparison, got %+v", report.Comparisons["owner/legacy"]["owner/repo1"])
+			}
+		})
+	}
+}
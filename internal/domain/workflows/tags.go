@@ -0,0 +1,74 @@
+package workflows
+
+import (
+	"context"
+	"strings"
+
+	"github.com/OctopusSolutionsEngineering/DuplicationCostCalculator/internal/domain/models"
+	"github.com/OctopusSolutionsEngineering/DuplicationCostCalculator/internal/domain/parsing"
+	"github.com/samber/lo"
+)
+
+type actionTags struct {
+	Repo string
+	Tags []models.Tag
+}
+
+// LoadActionTags loads the tags of each of the action repositories from the source. If the context is done,
+// the tags loaded so far are returned.
+func LoadActionTags(ctx context.Context, source WorkflowSource, actionRepos []string) map[string][]models.Tag {
+	tags := map[string][]models.Tag{}
+
+	if ctx.Err() != nil {
+		return tags
+	}
+
+	result := loadConcurrently(ctx, actionRepos, func(actionRepo string) actionTags {
+		return actionTags{
+			Repo: actionRepo,
+			Tags: source.FindTags(ctx, actionRepo),
+		}
+	})
+
+	for i := 0; i < len(actionRepos); i++ {
+		select {
+		case repoTags := <-result:
+			tags[repoTags.Repo] = repoTags.Tags
+		case <-ctx.Done():
+			return tags
+		}
+	}
+
+	return tags
+}
+
+// GetActionRepos returns the repositories of the actions that are referenced with a version. The tags
+// of these repositories are used to resolve SHA pinned versions and to find the latest version of each action.
+func GetActionRepos(repoActions map[string][][]models.Action) []string {
+	allActions := lo.Flatten(lo.Flatten(lo.Values(repoActions)))
+
+	return lo.Uniq(lo.FilterMap(allActions, func(item models.Action, index int) (string, bool) {
+		if parsing.IsLocalReference(item.Uses) || strings.HasPrefix(item.Uses, "docker://") || parsing.GetRefType(item.UsesVersion) == parsing.RefTypeNone {
+			return "", false
+		}
+		actionRepo, _ := parsing.SplitActionPath(item.Uses)
+		return actionRepo, actionRepo != ""
+	}))
+}
+
+// ResolveShaPinnedVersions sets the ResolvedVersion of each SHA pinned action to the tag that the SHA points to.
+// tags maps the repository of each action to its tags.
+func ResolveShaPinnedVersions(repoActions map[string][][]models.Action, tags map[string][]models.Tag) {
+	for _, workflows := range repoActions {
+		for _, actions := range workflows {
+			for i := range actions {
+				if !parsing.IsSha(actions[i].UsesVersion) {
+					continue
+				}
+
+				actionRepo, _ := parsing.SplitActionPath(actions[i].Uses)
+				actions[i].ResolvedVersion = ResolveShaToTag(actions[i].UsesVersion, tags[actionRepo])
+			}
+		}
+	}
+}
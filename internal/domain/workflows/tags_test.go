@@ -0,0 +1,116 @@
+package workflows
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/OctopusSolutionsEngineering/DuplicationCostCalculator/internal/domain/models"
+	"github.com/OctopusSolutionsEngineering/DuplicationCostCalculator/internal/infrastructure/memory"
+)
+
+const checkoutSha = "b4ffde65f46336ab88eb53be808477a3936bae11"
+
+func TestGetActionRepos(t *testing.T) {
+	repoActions := map[string][][]models.Action{
+		"repo1": {{
+			{Uses: "actions/checkout", UsesVersion: checkoutSha},
+			{Uses: "actions/setup-go", UsesVersion: "v5"},
+			{Uses: "./.github/actions/setup", UsesVersion: "latest"},
+			{Uses: "", UsesVersion: "latest"},
+		}},
+		"repo2": {{
+			{Uses: "actions/checkout", UsesVersion: "v4"},
+			{Uses: "my-org/actions/deploy", UsesVersion: "main"},
+			{Uses: "docker://alpine:3.8", UsesVersion: "latest"},
+		}},
+	}
+
+	actionRepos := GetActionRepos(repoActions)
+	slices.Sort(actionRepos)
+
+	if !slices.Equal(actionRepos, []string{"actions/checkout", "actions/setup-go", "my-org/actions"}) {
+		t.Errorf("Unexpected action repos: %v", actionRepos)
+	}
+}
+
+func TestResolveShaPinnedVersions(t *testing.T) {
+	repoActions := map[string][][]models.Action{
+		"repo1": {{
+			{Uses: "actions/checkout", UsesVersion: checkoutSha},
+			{Uses: "actions/setup-go", UsesVersion: "v5"},
+		}},
+	}
+
+	ResolveShaPinnedVersions(repoActions, map[string][]models.Tag{
+		"actions/checkout": {{Name: "v4.1.1", Commit: checkoutSha}},
+	})
+
+	if repoActions["repo1"][0][0].ResolvedVersion != "v4.1.1" {
+		t.Errorf("Expected the SHA to resolve to v4.1.1, got %q", repoActions["repo1"][0][0].ResolvedVersion)
+	}
+
+	if repoActions["repo1"][0][1].ResolvedVersion != "" {
+		t.Errorf("Expected tag versions to be left unresolved, got %q", repoActions["repo1"][0][1].ResolvedVersion)
+	}
+}
+
+func TestGenerateReportFromSourceResolvesShaPins(t *testing.T) {
+	shaPinned := `
+jobs:
+  build:
+    steps:
+      - uses: actions/checkout@` + checkoutSha + `
+`
+	tagPinned := `
+jobs:
+  build:
+    steps:
+      - uses: actions/checkout@v4.1.1
+`
+	oldTag := `
+jobs:
+  build:
+    steps:
+      - uses: actions/checkout@v4.0.0
+`
+
+	source := memory.NewWorkflowSource().
+		AddWorkflow("owner/repo1", "build.yml", shaPinned).
+		AddWorkflow("owner/repo2", "build.yml", tagPinned).
+		AddWorkflow("owner/repo3", "build.yml", oldTag)
+	source.Tags["actions/checkout"] = []models.Tag{
+		{Name: "v4", Commit: checkoutSha},
+		{Name: "v4.1.1", Commit: checkoutSha},
+	}
+
+	report := GenerateReportFromSource(context.Background(), source, []string{"owner/repo1", "owner/repo2", "owner/repo3"})
+
+	if count := report.Comparisons["owner/repo1"]["owner/repo2"].StepsWithDifferentVersionsCount; count != 0 {
+		t.Errorf("Expected a SHA pin and the tag it points to not to drift, got %d drifted steps", count)
+	}
+
+	comparison := report.Comparisons["owner/repo1"]["owner/repo3"]
+	if comparison.HighestDriftSeverity != DriftSeverityMinor {
+		t.Errorf("Expected minor drift between v4.1.1 and v4.0.0, got %q", comparison.HighestDriftSeverity)
+	}
+
+	if len(comparison.VersionDrift) != 1 || comparison.VersionDrift[0].Version1 != checkoutSha || comparison.VersionDrift[0].Version2 != "v4.0.0" {
+		t.Errorf("Unexpected version drift: %+v", comparison.VersionDrift)
+	}
+}
+
+func TestLoadActionTags(t *testing.T) {
+	source := memory.NewWorkflowSource()
+	source.Tags["actions/checkout"] = []models.Tag{{Name: "v4", Commit: checkoutSha}}
+
+	tags := LoadActionTags(context.Background(), source, []string{"actions/checkout", "actions/missing"})
+
+	if len(tags) != 2 {
+		t.Fatalf("Expected tags for 2 repos, got %d", len(tags))
+	}
+
+	if len(tags["actions/checkout"]) != 1 || len(tags["actions/missing"]) != 0 {
+		t.Errorf("Unexpected tags: %v", tags)
+	}
+}
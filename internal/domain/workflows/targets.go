@@ -0,0 +1,99 @@
+package workflows
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/OctopusSolutionsEngineering/DuplicationCostCalculator/internal/domain/models"
+	"github.com/OctopusSolutionsEngineering/DuplicationCostCalculator/internal/domain/parsing"
+	"github.com/OctopusSolutionsEngineering/DuplicationCostCalculator/internal/infrastructure/githubapi"
+	"github.com/google/go-github/v57/github"
+	"github.com/samber/lo"
+)
+
+// RepositoryLister lists the repositories owned by an organization or user.
+type RepositoryLister interface {
+	ListOrgRepositories(ctx context.Context, org string) ([]models.Repository, error)
+	ListUserRepositories(ctx context.Context, user string) ([]models.Repository, error)
+}
+
+// ExpandTargets replaces any "org:<name>" or "user:<name>" targets with the GitHub repositories they own.
+func ExpandTargets(ctx context.Context, client *github.Client, targets []string, filter models.RepositoryFilter) ([]string, error) {
+	return ExpandTargetsFromLister(ctx, githubapi.NewWorkflowSource(client), targets, filter)
+}
+
+// ExpandTargetsFromLister replaces any "org:<name>" or "user:<name>" targets with the repositories they own that
+// match the filter. Other targets are treated as individual repositories and are not filtered. Duplicate
+// repositories are removed.
+// An error is returned if the repositories of any target could not be listed, like when the organization does not
+// exist, so a mistyped target is not mistaken for an owner without repositories. The error of each target wraps the
+// error of the lister.
+func ExpandTargetsFromLister(ctx context.Context, lister RepositoryLister, targets []string, filter models.RepositoryFilter) ([]string, error) {
+	var targetErrors []error
+
+	repos := lo.Uniq(lo.FlatMap(targets, func(target string, index int) []string {
+		kind, name, ok := parsing.ParseTarget(target)
+		if !ok {
+			return []string{target}
+		}
+
+		var repositories []models.Repository
+		var err error
+		if kind == parsing.TargetOrg {
+			repositories, err = lister.ListOrgRepositories(ctx, name)
+		} else {
+			repositories, err = lister.ListUserRepositories(ctx, name)
+		}
+
+		if err != nil {
+			targetErrors = append(targetErrors, fmt.Errorf("listing the repositories of %s: %w", target, err))
+			return []string{}
+		}
+
+		return lo.Map(FilterRepositories(repositories, filter), func(item models.Repository, index int) string {
+			return item.FullName
+		})
+	}))
+
+	return repos, errors.Join(targetErrors...)
+}
+
+// FilterRepositories returns the repositories that match the filter.
+func FilterRepositories(repositories []models.Repository, filter models.RepositoryFilter) []models.Repository {
+	return lo.Filter(repositories, func(item models.Repository, index int) bool {
+		return MatchesRepositoryFilter(item, filter)
+	})
+}
+
+// MatchesRepositoryFilter returns true if the repository matches every part of the filter.
+func MatchesRepositoryFilter(repository models.Repository, filter models.RepositoryFilter) bool {
+	if filter.ExcludeArchived && repository.Archived {
+		return false
+	}
+
+	if filter.ExcludeForks && repository.Fork {
+		return false
+	}
+
+	if filter.Language != "" && !strings.EqualFold(filter.Language, repository.Language) {
+		return false
+	}
+
+	if filter.Name != "" {
+		// An invalid pattern matches nothing
+		matched, err := path.Match(strings.ToLower(filter.Name), strings.ToLower(repository.Name))
+		if err != nil || !matched {
+			return false
+		}
+	}
+
+	return lo.EveryBy(filter.Topics, func(topic string) bool {
+		return slices.ContainsFunc(repository.Topics, func(repositoryTopic string) bool {
+			return strings.EqualFold(topic, repositoryTopic)
+		})
+	})
+}
@@ -0,0 +1,133 @@
+package workflows
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/OctopusSolutionsEngineering/DuplicationCostCalculator/internal/domain/models"
+	"github.com/OctopusSolutionsEngineering/DuplicationCostCalculator/internal/infrastructure/memory"
+)
+
+func newRepositoryLister() *memory.WorkflowSource {
+	return memory.NewWorkflowSource().
+		AddRepository("my-org", models.Repository{FullName: "my-org/service-api", Name: "service-api", Language: "Go", Topics: []string{"platform", "api"}}).
+		AddRepository("my-org", models.Repository{FullName: "my-org/service-web", Name: "service-web", Language: "TypeScript", Topics: []string{"platform"}}).
+		AddRepository("my-org", models.Repository{FullName: "my-org/legacy", Name: "legacy", Language: "Go", Archived: true}).
+		AddRepository("my-org", models.Repository{FullName: "my-org/checkout", Name: "checkout", Language: "Go", Fork: true}).
+		AddRepository("octocat", models.Repository{FullName: "octocat/hello-world", Name: "hello-world"})
+}
+
+func TestExpandTargetsFromLister(t *testing.T) {
+	tests := []struct {
+		name     string
+		targets  []string
+		filter   models.RepositoryFilter
+		expected []string
+	}{
+		{
+			name:     "repositories are unchanged",
+			targets:  []string{"owner/repo1", "owner/repo2"},
+			expected: []string{"owner/repo1", "owner/repo2"},
+		},
+		{
+			name:     "organization",
+			targets:  []string{"org:my-org"},
+			expected: []string{"my-org/service-api", "my-org/service-web", "my-org/legacy", "my-org/checkout"},
+		},
+		{
+			name:     "user mixed with repositories",
+			targets:  []string{"user:octocat", "owner/repo1"},
+			expected: []string{"octocat/hello-world", "owner/repo1"},
+		},
+		{
+			name:     "duplicates are removed",
+			targets:  []string{"my-org/legacy", "org:my-org"},
+			filter:   models.RepositoryFilter{Language: "go"},
+			expected: []string{"my-org/legacy", "my-org/service-api", "my-org/checkout"},
+		},
+		{
+			name:     "archived and forks excluded",
+			targets:  []string{"org:my-org"},
+			filter:   models.RepositoryFilter{ExcludeArchived: true, ExcludeForks: true},
+			expected: []string{"my-org/service-api", "my-org/service-web"},
+		},
+		{
+			name:     "name glob",
+			targets:  []string{"org:my-org"},
+			filter:   models.RepositoryFilter{Name: "Service-*"},
+			expected: []string{"my-org/service-api", "my-org/service-web"},
+		},
+		{
+			name:     "all topics are required",
+			targets:  []string{"org:my-org"},
+			filter:   models.RepositoryFilter{Topics: []string{"platform", "API"}},
+			expected: []string{"my-org/service-api"},
+		},
+		{
+			name:     "invalid glob matches nothing",
+			targets:  []string{"org:my-org"},
+			filter:   models.RepositoryFilter{Name: "[service"},
+			expected: []string{},
+		},
+		{
+			name:     "unknown organization",
+			targets:  []string{"org:missing"},
+			expected: []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := ExpandTargetsFromLister(context.Background(), newRepositoryLister(), tt.targets, tt.filter)
+			if err != nil {
+				t.Fatalf("Unexpected error %v", err)
+			}
+
+			if !slices.Equal(result, tt.expected) {
+				t.Errorf("ExpandTargetsFromLister(%v) = %v, expected %v", tt.targets, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestExpandTargetsFromListerError(t *testing.T) {
+	// Arrange
+	notFound := errors.New("404 Not Found")
+	lister := newRepositoryLister()
+	lister.ListErrors["typo"] = notFound
+
+	// Act
+	result, err := ExpandTargetsFromLister(context.Background(), lister, []string{"org:typo", "user:octocat"}, models.RepositoryFilter{})
+
+	// Assert - the other targets are still expanded
+	if !errors.Is(err, notFound) || !strings.Contains(err.Error(), "org:typo") {
+		t.Errorf("Expected the error of the target, got %v", err)
+	}
+
+	if !slices.Equal(result, []string{"octocat/hello-world"}) {
+		t.Errorf("Unexpected repositories %v", result)
+	}
+}
+
+func TestMatchesRepositoryFilter(t *testing.T) {
+	repository := models.Repository{FullName: "my-org/service-api", Name: "service-api", Language: "Go", Topics: []string{"platform"}}
+
+	if !MatchesRepositoryFilter(repository, models.RepositoryFilter{}) {
+		t.Error("Expected an empty filter to match every repository")
+	}
+
+	if MatchesRepositoryFilter(repository, models.RepositoryFilter{Language: "Python"}) {
+		t.Error("Expected a different language not to match")
+	}
+
+	if MatchesRepositoryFilter(repository, models.RepositoryFilter{Topics: []string{"frontend"}}) {
+		t.Error("Expected a missing topic not to match")
+	}
+
+	if MatchesRepositoryFilter(repository, models.RepositoryFilter{Name: "my-org/*"}) {
+		t.Error("Expected the glob to be matched against the name without the owner")
+	}
+}
@@ -0,0 +1,114 @@
+package workflows
+
+import (
+	"fmt"
+
+	"github.com/OctopusSolutionsEngineering/DuplicationCostCalculator/internal/domain/models"
+	"github.com/samber/lo"
+)
+
+// CheckThresholds returns each threshold the report exceeds. New drift is found by comparing the report to the
+// baseline, which is only required when the thresholds include NoNewDrift. Unlike DiffReports, drift involving a
+// repository that is not in the baseline is new, so a repository can't be added with drift without failing.
+func CheckThresholds(report models.Report, thresholds models.Thresholds, baseline *models.Report) []models.ThresholdViolation {
+	violations := []models.ThresholdViolation{}
+
+	if thresholds.MaxReposWithDuplicationOrDrift != nil && report.NumberOfReposWithDuplicationOrDrift > *thresholds.MaxReposWithDuplicationOrDrift {
+		violations = append(violations, models.ThresholdViolation{
+			Rule:    models.ThresholdMaxReposWithDuplicationOrDrift,
+			Message: fmt.Sprintf("%d repositories have duplication or drift, more than the maximum of %d", report.NumberOfReposWithDuplicationOrDrift, *thresholds.MaxReposWithDuplicationOrDrift),
+			Details: []string{},
+		})
+	}
+
+	if thresholds.MaxDriftedActionsPerPair != nil {
+		details := []string{}
+		for _, pair := range getDriftedActionsPerPair(report) {
+			if len(pair.actions) > *thresholds.MaxDriftedActionsPerPair {
+				details = append(details, fmt.Sprintf("%s vs %s: %d actions", pair.repo1, pair.repo2, len(pair.actions)))
+			}
+		}
+
+		if len(details) != 0 {
+			violations = append(violations, models.ThresholdViolation{
+				Rule:    models.ThresholdMaxDriftedActionsPerPair,
+				Message: fmt.Sprintf("%d pairs of repositories use more than %d actions with different versions", len(details), *thresholds.MaxDriftedActionsPerPair),
+				Details: details,
+			})
+		}
+	}
+
+	if thresholds.MaxCost != nil && report.Cost.Total > *thresholds.MaxCost {
+		violations = append(violations, models.ThresholdViolation{
+			Rule:    models.ThresholdMaxCost,
+			Message: fmt.Sprintf("The cost of a consistent change is $%.2f, more than the maximum of $%.2f", report.Cost.Total, *thresholds.MaxCost),
+			Details: []string{},
+		})
+	}
+
+	if thresholds.NoNewDrift && baseline != nil {
+		introduced := getMissingDrift(getDriftChanges(report, getReportRepos(report)), getDriftChanges(*baseline, getReportRepos(*baseline)))
+		if len(introduced) != 0 {
+			violations = append(violations, models.ThresholdViolation{
+				Rule:    models.ThresholdNoNewDrift,
+				Message: fmt.Sprintf("%d version drifts were introduced since the baseline report", len(introduced)),
+				Details: lo.Map(introduced, func(item models.DriftChange, index int) string {
+					return fmt.Sprintf("%s vs %s: %s %s vs %s (%s)", item.Repo1, item.Repo2, item.Uses, item.Version1, item.Version2, item.Severity)
+				}),
+			})
+		}
+	}
+
+	if thresholds.RequireShaPins {
+		unpinned := lo.Filter(report.SecurityFindings, func(item models.SecurityFinding, index int) bool {
+			return item.ThirdParty
+		})
+		if len(unpinned) != 0 {
+			violations = append(violations, models.ThresholdViolation{
+				Rule:    models.ThresholdRequireShaPins,
+				Message: fmt.Sprintf("%d third party actions are not pinned to a full commit SHA", len(unpinned)),
+				Details: lo.Map(unpinned, func(item models.SecurityFinding, index int) string {
+					return fmt.Sprintf("%s %s:%d %s (%s, %s)", item.Repo, item.Workflow, item.Line, formatUses(item.Uses, item.Version), item.Kind, item.Severity)
+				}),
+			})
+		}
+	}
+
+	return violations
+}
+
+// formatUses returns the "uses" value of an action with the version, if it has one.
+func formatUses(uses string, version string) string {
+	if version == "" {
+		return uses
+	}
+
+	return uses + "@" + version
+}
+
+type driftedActions struct {
+	repo1   string
+	repo2   string
+	actions []string
+}
+
+// getDriftedActionsPerPair returns the unique actions used with different versions by each pair of repositories.
+func getDriftedActionsPerPair(report models.Report) []driftedActions {
+	grouped := lo.GroupBy(getDriftChanges(report, getReportRepos(report)), func(item models.DriftChange) string {
+		return item.Repo1 + "\n" + item.Repo2
+	})
+
+	pairs := []driftedActions{}
+	for _, key := range sortedUnique(lo.Keys(grouped)) {
+		drift := grouped[key]
+		pairs = append(pairs, driftedActions{
+			repo1: drift[0].Repo1,
+			repo2: drift[0].Repo2,
+			actions: sortedUnique(lo.Map(drift, func(item models.DriftChange, index int) string {
+				return item.Uses
+			})),
+		})
+	}
+
+	return pairs
+}
@@ -0,0 +1,193 @@
+package workflows
+
+import (
+	"context"
+	"testing"
+
+	"github.com/OctopusSolutionsEngineering/DuplicationCostCalculator/internal/domain/models"
+	"github.com/OctopusSolutionsEngineering/DuplicationCostCalculator/internal/infrastructure/memory"
+	"github.com/samber/lo"
+)
+
+const checkoutAndCacheV3Workflow = `
+jobs:
+  build:
+    steps:
+      - uses: actions/checkout@v3
+      - uses: actions/cache@v3
+`
+
+const checkoutAndCacheV4Workflow = `
+jobs:
+  build:
+    steps:
+      - uses: actions/checkout@v4
+      - uses: actions/cache@v4
+`
+
+func TestCheckThresholds(t *testing.T) {
+	// Arrange - repo1 and repo2 drift on two actions, and repo3 matches repo1
+	report := GenerateReportFromSource(context.Background(), memory.NewWorkflowSource().
+		AddWorkflow("owner/repo1", "build.yml", checkoutAndCacheV4Workflow).
+		AddWorkflow("owner/repo2", "build.yml", checkoutAndCacheV3Workflow).
+		AddWorkflow("owner/repo3", "build.yml", checkoutAndCacheV4Workflow), []string{"owner/repo1", "owner/repo2", "owner/repo3"})
+	report.Cost = CalculateCost(report, DefaultCostModel())
+
+	tests := []struct {
+		name          string
+		thresholds    models.Thresholds
+		expectedRules []string
+	}{
+		{"no thresholds", models.Thresholds{}, []string{}},
+		{"within thresholds", models.Thresholds{
+			MaxReposWithDuplicationOrDrift: lo.ToPtr(report.NumberOfReposWithDuplicationOrDrift),
+			MaxDriftedActionsPerPair:       lo.ToPtr(2),
+			MaxCost:                        lo.ToPtr(report.Cost.Total),
+		}, []string{}},
+		{"too many repos", models.Thresholds{MaxReposWithDuplicationOrDrift: lo.ToPtr(0)}, []string{models.ThresholdMaxReposWithDuplicationOrDrift}},
+		{"too much drift per pair", models.Thresholds{MaxDriftedActionsPerPair: lo.ToPtr(1)}, []string{models.ThresholdMaxDriftedActionsPerPair}},
+		{"too expensive", models.Thresholds{MaxCost: lo.ToPtr(1.0)}, []string{models.ThresholdMaxCost}},
+		{"every threshold", models.Thresholds{
+			MaxReposWithDuplicationOrDrift: lo.ToPtr(0),
+			MaxDriftedActionsPerPair:       lo.ToPtr(0),
+			MaxCost:                        lo.ToPtr(0.0),
+		}, []string{models.ThresholdMaxReposWithDuplicationOrDrift, models.ThresholdMaxDriftedActionsPerPair, models.ThresholdMaxCost}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			violations := CheckThresholds(report, tt.thresholds, nil)
+
+			// Assert
+			rules := lo.Map(violations, func(item models.ThresholdViolation, index int) string { return item.Rule })
+			if len(rules) != len(tt.expectedRules) || len(lo.Without(rules, tt.expectedRules...)) != 0 {
+				t.Errorf("Violated rules = %v, expected %v", rules, tt.expectedRules)
+			}
+		})
+	}
+}
+
+func TestCheckThresholdsDriftPerPair(t *testing.T) {
+	report := GenerateReportFromSource(context.Background(), memory.NewWorkflowSource().
+		AddWorkflow("owner/repo1", "build.yml", checkoutAndCacheV4Workflow).
+		AddWorkflow("owner/repo2", "build.yml", checkoutAndCacheV3Workflow).
+		AddWorkflow("owner/repo3", "build.yml", checkoutV3Workflow), []string{"owner/repo1", "owner/repo2", "owner/repo3"})
+
+	violations := CheckThresholds(report, models.Thresholds{MaxDriftedActionsPerPair: lo.ToPtr(1)}, nil)
+
+	// Only repo1 and repo2 drift on more than one action
+	if len(violations) != 1 || len(violations[0].Details) != 1 || violations[0].Details[0] != "owner/repo1 vs owner/repo2: 2 actions" {
+		t.Errorf("Unexpected violations %+v", violations)
+	}
+}
+
+func TestCheckThresholdsNoNewDrift(t *testing.T) {
+	// Arrange
+	baseline := GenerateReportFromSource(context.Background(), memory.NewWorkflowSource().
+		AddWorkflow("owner/repo1", "build.yml", checkoutV4Workflow).
+		AddWorkflow("owner/repo2", "build.yml", checkoutV3Workflow).
+		AddWorkflow("owner/repo3", "build.yml", checkoutV4Workflow), []string{"owner/repo1", "owner/repo2", "owner/repo3"})
+
+	tests := []struct {
+		name               string
+		repo3Workflow      string
+		repo4Workflow      string
+		expectedViolations int
+	}{
+		{"same drift", checkoutV4Workflow, "", 0},
+		{"new drift", checkoutV3Workflow, "", 1},
+		{"new repo without drift", checkoutV4Workflow, `
+jobs:
+  build:
+    steps:
+      - uses: actions/setup-go@v5
+`, 0},
+		{"new repo with drift", checkoutV4Workflow, checkoutV3Workflow, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			source := memory.NewWorkflowSource().
+				AddWorkflow("owner/repo1", "build.yml", checkoutV4Workflow).
+				AddWorkflow("owner/repo2", "build.yml", checkoutV3Workflow).
+				AddWorkflow("owner/repo3", "build.yml", tt.repo3Workflow)
+			repos := []string{"owner/repo1", "owner/repo2", "owner/repo3"}
+
+			// Drift involving a repository that is not in the baseline is new
+			if tt.repo4Workflow != "" {
+				source.AddWorkflow("owner/repo4", "build.yml", tt.repo4Workflow)
+				repos = append(repos, "owner/repo4")
+			}
+
+			report := GenerateReportFromSource(context.Background(), source, repos)
+
+			// Act
+			violations := CheckThresholds(report, models.Thresholds{NoNewDrift: true}, &baseline)
+
+			// Assert
+			if len(violations) != tt.expectedViolations {
+				t.Fatalf("Expected %d violations, got %+v", tt.expectedViolations, violations)
+			}
+
+			if tt.expectedViolations != 0 && (violations[0].Rule != models.ThresholdNoNewDrift || len(violations[0].Details) == 0) {
+				t.Errorf("Unexpected violation %+v", violations[0])
+			}
+		})
+	}
+}
+
+func TestCheckThresholdsRequireShaPins(t *testing.T) {
+	tests := []struct {
+		name            string
+		workflow        string
+		expectedDetails []string
+	}{
+		{"first party tags", checkoutV4Workflow, []string{}},
+		{"third party sha", `
+jobs:
+  build:
+    steps:
+      - uses: azure/login@8e5a1d0c4f6c8b7d2e3f4a5b6c7d8e9f0a1b2c3d
+`, []string{}},
+		{"third party branch and tag", `
+jobs:
+  build:
+    steps:
+      - uses: azure/login@main
+      - uses: hashicorp/setup-terraform@v3.1.0
+      - uses: owner/internal-action
+`, []string{
+			"owner/repo1 build.yml:5 azure/login@main (branch, critical)",
+			"owner/repo1 build.yml:6 hashicorp/setup-terraform@v3.1.0 (tag, medium)",
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			report := GenerateReportFromSource(context.Background(), memory.NewWorkflowSource().
+				AddWorkflow("owner/repo1", "build.yml", tt.workflow).
+				AddWorkflow("owner/repo2", "build.yml", checkoutV4Workflow), []string{"owner/repo1", "owner/repo2"})
+
+			// Act
+			violations := CheckThresholds(report, models.Thresholds{RequireShaPins: true}, nil)
+
+			// Assert
+			if len(tt.expectedDetails) == 0 {
+				if len(violations) != 0 {
+					t.Errorf("Expected no violations, got %+v", violations)
+				}
+				return
+			}
+
+			if len(violations) != 1 || violations[0].Rule != models.ThresholdRequireShaPins {
+				t.Fatalf("Expected a %s violation, got %+v", models.ThresholdRequireShaPins, violations)
+			}
+
+			if len(violations[0].Details) != len(tt.expectedDetails) || len(lo.Without(violations[0].Details, tt.expectedDetails...)) != 0 {
+				t.Errorf("Details = %v, expected %v", violations[0].Details, tt.expectedDetails)
+			}
+		})
+	}
+}
@@ -1,10 +1,13 @@
 package workflows
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"maps"
 	"slices"
 	"strings"
+	"time"
 
 	"github.com/OctopusSolutionsEngineering/DuplicationCostCalculator/internal/domain/collections"
 	"github.com/OctopusSolutionsEngineering/DuplicationCostCalculator/internal/domain/models"
@@ -18,61 +21,215 @@ import (
 const HighSimilarity = 30
 const BuiltInStep = "(built-in step)"
 
+// ReportOptions holds the additional information used when comparing workflows.
+type ReportOptions struct {
+	// ActionTags maps the repository of an action to its tags. It is used to resolve SHA pinned versions
+	// and to find the latest version of each action.
+	ActionTags map[string][]models.Tag
+	// CostModel is used to calculate the cost of the report. The default model is used if it is nil.
+	CostModel *models.CostModel
+	// Suppressions accept drift and duplication, which is then excluded from the comparisons and counts.
+	Suppressions []models.Suppression
+	// Owners maps the repositories whose names don't include their owner, like local checkouts, to their owner. The
+	// actions of the owner are not third party actions.
+	Owners map[string]string
+}
+
 type RepoActions struct {
-	Repo               string
-	Workflows          []string
+	Repo      string
+	Workflows []string
+	// WorkflowFiles are the names of the workflow files, in the same order as Workflows. It may be empty
+	// if the names are not known.
+	WorkflowFiles []string
+	// CompositeActions maps the "uses" value of steps that call composite actions to the content of
+	// the composite action's action.yml file.
+	CompositeActions   map[string]string
 	Contributors       []string
 	WorkflowAdvisories []string
+	// Errors are the errors found while loading the repository.
+	Errors []models.RepoError
+}
+
+// ProgressFunc is called as each repository is loaded, with the number of repositories loaded so far.
+type ProgressFunc func(repoActions RepoActions, completed int, total int)
+
+// GenerateReport compares the workflows in the supplied GitHub repositories.
+func GenerateReport(ctx context.Context, client *github.Client, repos []string) models.Report {
+	return GenerateReportWithProgress(ctx, client, repos, nil)
+}
+
+// GenerateReportWithProgress is GenerateReport, calling progress as each repository is loaded.
+func GenerateReportWithProgress(ctx context.Context, client *github.Client, repos []string, progress ProgressFunc) models.Report {
+	return GenerateReportFromSourceWithProgress(ctx, githubapi.NewWorkflowSource(client), repos, progress)
+}
+
+// GenerateReportFromSource compares the workflows in the supplied repositories, loading the
+// workflows, contributors, and advisories from the source.
+func GenerateReportFromSource(ctx context.Context, source WorkflowSource, repos []string) models.Report {
+	return GenerateReportFromSourceWithProgress(ctx, source, repos, nil)
+}
+
+// GenerateReportFromSourceWithProgress is GenerateReportFromSource, calling progress, if it is not nil,
+// as each repository is loaded.
+// If the context is cancelled or times out, no more data is fetched, and the report compares the repositories
+// loaded so far. The report is flagged as incomplete, and the repositories that were not loaded are reported
+// as failed with a "cancelled" error.
+func GenerateReportFromSourceWithProgress(ctx context.Context, source WorkflowSource, repos []string, progress ProgressFunc) models.Report {
+	return GenerateReportFromSourceWithOptions(ctx, source, repos, progress, ReportOptions{})
 }
 
-func GenerateReport(client *github.Client, repos []string) models.Report {
-	result := make(chan RepoActions)
-	workflowsContent := map[string][]string{}
-	workflowsContributors := map[string][]string{}
-	repoAdvisories := map[string][]string{}
+// GenerateReportFromSourceWithOptions is GenerateReportFromSourceWithProgress, comparing the workflows with the
+// options. The action tags are loaded from the source.
+func GenerateReportFromSourceWithOptions(ctx context.Context, source WorkflowSource, repos []string, progress ProgressFunc, options ReportOptions) models.Report {
+	allRepoActions := map[string]RepoActions{}
 
+	// Repositories often call the same actions, so each version of an action is only loaded once
+	source = NewActionCachingSource(source)
+
+	result := loadConcurrently(ctx, repos, func(repo string) RepoActions {
+		return LoadRepoActions(ctx, source, repo)
+	})
+
+	// Wait for all the goroutines to finish, or the context to be done
+	pending := map[string]bool{}
 	for _, repo := range repos {
-		// Get the workflows in a goroutine
-		go func(client *github.Client, repo string) {
-
-			advisories := githubapi.GetWorkflowAdvisories(client, repo)
-			workflowFiles := githubapi.FindWorkflows(client, repo)
-			workflows := lo.FilterMap(workflowFiles, func(item string, index int) (string, bool) {
-				workflowStr := githubapi.WorkflowToString(client, repo, item)
-				return workflowStr, workflowStr != ""
-			})
-			contributors := lo.Uniq(lo.FlatMap(workflowFiles, func(item string, index int) []string {
-				return githubapi.FindContributorsToWorkflow(client, repo, item)
-			}))
-
-			// Split repo into owner and name
-			owner, repoName := parsing.SplitRepoNoErr(repo)
-
-			result <- RepoActions{
-				Repo:               owner + "/" + repoName,
-				Workflows:          workflows,
-				Contributors:       contributors,
-				WorkflowAdvisories: advisories,
-			}
-		}(client, repo)
+		pending[source.RepoName(repo)] = true
 	}
 
-	// Wait for all the goroutines to finish
+waitForRepos:
 	for i := 0; i < len(repos); i++ {
-		repoActions := <-result
-		workflowsContent[repoActions.Repo] = repoActions.Workflows
-		workflowsContributors[repoActions.Repo] = repoActions.Contributors
-		repoAdvisories[repoActions.Repo] = repoActions.WorkflowAdvisories
+		select {
+		case repoActions := <-result:
+			allRepoActions[repoActions.Repo] = repoActions
+			delete(pending, repoActions.Repo)
+
+			if progress != nil {
+				progress(repoActions, i+1, len(repos))
+			}
+		case <-ctx.Done():
+			break waitForRepos
+		}
 	}
 
-	report := GenerateReportFromWorkflows(workflowsContent, workflowsContributors, repoAdvisories)
+	for repo := range pending {
+		allRepoActions[repo] = RepoActions{
+			Repo:   repo,
+			Errors: []models.RepoError{NewCancelledError(ctx.Err())},
+		}
+	}
+
+	// Look up the tags of the actions to resolve SHA pinned versions and find the latest version of each action
+	parsedRepoActions, repoActions := ParseRepoActions(lo.Values(allRepoActions))
+
+	options.ActionTags = LoadActionTags(ctx, source, GetActionRepos(repoActions))
+
+	report := GenerateReportFromActionsMap(parsedRepoActions, repoActions, options)
+	report.Metadata.RateLimit = source.GetRateLimit()
+
+	if err := ctx.Err(); err != nil {
+		report.Metadata.Incomplete = true
+		report.Metadata.IncompleteReason = GetIncompleteReason(err)
+	}
 
 	return report
 }
 
+// GetIncompleteReason describes why an analysis stopped early.
+func GetIncompleteReason(err error) string {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return "timed out"
+	}
+
+	return "cancelled"
+}
+
+// NewCancelledError records that a repository was not completely loaded because the analysis was stopped.
+func NewCancelledError(err error) models.RepoError {
+	return models.RepoError{
+		Category:  models.ErrorCategoryCancelled,
+		Operation: "load repository",
+		Message:   err.Error(),
+	}
+}
+
+// LoadRepoActions loads the workflows, contributors, and advisories for a single repository.
+// Once the context is done, nothing more is fetched, and the repository includes what was loaded so far.
+func LoadRepoActions(ctx context.Context, source WorkflowSource, repo string) RepoActions {
+	advisories := source.GetWorkflowAdvisories(ctx, repo)
+	workflowFiles := source.FindWorkflows(ctx, repo)
+	workflows := []string{}
+	loadedWorkflowFiles := []string{}
+	for _, workflowFile := range workflowFiles {
+		if ctx.Err() != nil {
+			break
+		}
+
+		workflowStr := source.WorkflowToString(ctx, repo, workflowFile)
+		if workflowStr != "" {
+			workflows = append(workflows, workflowStr)
+			loadedWorkflowFiles = append(loadedWorkflowFiles, workflowFile)
+		}
+	}
+	contributors := []string{}
+	for _, workflowFile := range workflowFiles {
+		if ctx.Err() != nil {
+			break
+		}
+
+		contributors = append(contributors, source.FindContributorsToWorkflow(ctx, repo, workflowFile)...)
+	}
+
+	compositeActions := LoadCompositeActions(ctx, source, repo, workflows)
+
+	// Sources that don't fetch over the network may not report an error when they are stopped, so the
+	// repository is flagged here, otherwise it would look like it was completely loaded
+	repoErrors := source.GetErrors(repo)
+	if err := ctx.Err(); err != nil && !lo.SomeBy(repoErrors, func(item models.RepoError) bool {
+		return item.Category == models.ErrorCategoryCancelled
+	}) {
+		repoErrors = append(repoErrors, NewCancelledError(err))
+	}
+
+	return RepoActions{
+		Repo:               source.RepoName(repo),
+		Workflows:          workflows,
+		WorkflowFiles:      loadedWorkflowFiles,
+		CompositeActions:   compositeActions,
+		Contributors:       lo.Uniq(contributors),
+		WorkflowAdvisories: advisories,
+		Errors:             repoErrors,
+	}
+}
+
 func GenerateReportFromWorkflows(workflows map[string][]string, contributors map[string][]string, repoAdvisories map[string][]string) models.Report {
+	return GenerateReportFromRepoActions(lo.MapToSlice(workflows, func(repo string, workflowFiles []string) RepoActions {
+		return RepoActions{
+			Repo:               repo,
+			Workflows:          workflowFiles,
+			Contributors:       contributors[repo],
+			WorkflowAdvisories: repoAdvisories[repo],
+		}
+	}), ReportOptions{})
+}
+
+// GenerateReportFromRepoActions compares the workflows that have been loaded for each repository.
+func GenerateReportFromRepoActions(allRepoActions []RepoActions, options ReportOptions) models.Report {
+	parsedRepoActions, repoActions := ParseRepoActions(allRepoActions)
+	return GenerateReportFromActionsMap(parsedRepoActions, repoActions, options)
+}
 
-	repoActions := ConvertWorkflowToActionsMap(workflows)
+// GenerateReportFromActionsMap is GenerateReportFromRepoActions where the workflows have already been parsed
+// with ParseRepoActions.
+func GenerateReportFromActionsMap(allRepoActions []RepoActions, repoActions map[string][][]models.Action, options ReportOptions) models.Report {
+	ResolveShaPinnedVersions(repoActions, options.ActionTags)
+
+	contributors := lo.SliceToMap(allRepoActions, func(item RepoActions) (string, []string) {
+		return item.Repo, item.Contributors
+	})
+
+	repoAdvisories := lo.SliceToMap(allRepoActions, func(item RepoActions) (string, []string) {
+		return item.Repo, item.WorkflowAdvisories
+	})
 
 	repoNames := maps.Keys(repoActions)
 	sortedRepoNames := slices.Sorted(repoNames)
@@ -82,26 +239,54 @@ func GenerateReportFromWorkflows(workflows map[string][]string, contributors map
 		Contributors:       map[string][]string{},
 		WorkflowAdvisories: map[string][]string{},
 		ActionAuthors:      map[string][]string{},
+		SharedWorkflows:    map[string][]string{},
+		StaleActions:       map[string][]models.ActionStaleness{},
+		RepoStatus:         GetRepoStatuses(allRepoActions),
 		NumberOfRepos:      len(sortedRepoNames),
 	}
 
+	suppressions, expiredSuppressions := GetActiveSuppressions(options.Suppressions, time.Now())
+	report.Suppressed = []models.SuppressedFinding{}
+	report.ExpiredSuppressions = expiredSuppressions
+
+	report.RecommendedVersions = GetRecommendedVersions(options.ActionTags, repoActions)
+	report.Clusters = ClusterActionsWithSuppressions(repoActions, suppressions)
+	report.SecurityFindings = AuditActionReferences(allRepoActions, options.ActionTags, options.Owners)
+
+	driftSeverityCache := DriftSeverityCache{}
+
+	// Index the actions once per repository, rather than once for each pair of repositories. The suppressions that
+	// accept an action in every repository are also applied once per repository.
+	indexedRepoActions := lo.MapValues(repoActions, func(value [][]models.Action, key string) SuppressedRepoActions {
+		return SuppressRepoActions(suppressions, IndexRepoActions(value))
+	})
+
 	for i := 0; i < len(sortedRepoNames); i++ {
 		repo1 := sortedRepoNames[i]
 		actionsList1 := repoActions[repo1]
+		indexed1 := indexedRepoActions[repo1]
 		report.Contributors[repo1] = contributors[repo1]
 		report.WorkflowAdvisories[repo1] = repoAdvisories[repo1]
 		report.ActionAuthors[repo1] = GetActionAuthorsFromActionsList(actionsList1)
+		report.SharedWorkflows[repo1] = GetSharedWorkflowsFromActionsList(actionsList1)
+		report.StaleActions[repo1] = FindStaleActions(actionsList1, report.RecommendedVersions)
 
 		for j := i + 1; j < len(sortedRepoNames); j++ {
 			repo2 := sortedRepoNames[j]
-			actionsList2 := repoActions[repo2]
 
-			stepsWithDifferentVersions, diffVersionsIds, stepsWithSimilarConfig, similarConfigIds := GetActionsWithVersionDriftAndDuplication(actionsList1, actionsList2)
+			// Accepted drift and duplication is reported separately, and is not compared
+			pairIndexed1, pairIndexed2, suppressed := SuppressPair(suppressions, repo1, indexed1, repo2, indexedRepoActions[repo2], driftSeverityCache)
+			report.Suppressed = append(report.Suppressed, suppressed...)
+
+			stepsWithDifferentVersions, diffVersionsIds, stepsWithSimilarConfig, similarConfigIds := GetActionsWithVersionDriftAndDuplicationIndexed(pairIndexed1, pairIndexed2)
 
 			// An overall number of the steps that would have to be updated to ensure consistency between the workflows
 			// This includes those that have version drift and those that have similar config
 			uniqueActions := lo.Uniq(append(similarConfigIds, diffVersionsIds...))
 
+			versionDrift := FindVersionDriftIndexed(pairIndexed1.UniqueVersions, pairIndexed2.UniqueVersionsByUses, driftSeverityCache)
+			similarScripts := FindSimilarScriptsFromShingles(pairIndexed1.Actions, pairIndexed1.ScriptShingles, pairIndexed2.Actions, pairIndexed2.ScriptShingles, pairIndexed2.ScriptIndex)
+
 			if _, ok := report.Comparisons[repo1]; !ok {
 				report.Comparisons[repo1] = make(map[string]models.RepoMeasurements)
 			}
@@ -116,6 +301,10 @@ func GenerateReportFromWorkflows(workflows map[string][]string, contributors map
 				StepsWithSimilarConfig:           stepsWithSimilarConfig,
 				StepsWithSimilarConfigCount:      len(similarConfigIds),
 				StepsThatIndicateDuplicationRisk: len(uniqueActions),
+				VersionDrift:                     versionDrift,
+				HighestDriftSeverity:             GetHighestDriftSeverity(versionDrift),
+				SimilarScripts:                   similarScripts,
+				SimilarScriptsCount:              len(similarScripts),
 			}
 
 			// The measurements for repo2 compared to repo1 are the same as repo1 compared to repo2,
@@ -126,22 +315,32 @@ func GenerateReportFromWorkflows(workflows map[string][]string, contributors map
 
 	// Count the number of repositories that have duplication or drift
 	report.NumberOfReposWithDuplicationOrDrift = CountReposWithDuplicationOrDrift(report.Comparisons)
+	report.WeightedNumberOfReposWithDuplicationOrDrift = CountWeightedReposWithDuplicationOrDrift(report.Comparisons)
+
+	// Count the number of repositories that already consume shared workflows
+	report.NumberOfReposUsingSharedWorkflows = len(lo.PickBy(report.SharedWorkflows, func(key string, value []string) bool {
+		return len(value) > 0
+	}))
 
 	// Get all unique contributors across all repositories
 	allContributorLists := lo.Values(report.Contributors)
 	flattenedContributors := lo.Flatten(allContributorLists)
 	report.UniqueContributors = lo.Uniq(flattenedContributors)
 
+	report.Cost = CalculateCost(report, GetCostModel(options.CostModel))
+
 	return report
 }
 
 func GetActionsWithVersionDriftAndDuplication(actionsList1 [][]models.Action, actionsList2 [][]models.Action) ([]string, []string, []string, []string) {
+	return GetActionsWithVersionDriftAndDuplicationIndexed(IndexRepoActions(actionsList1), IndexRepoActions(actionsList2))
+}
 
-	flattenedActionsList1 := lo.Flatten(actionsList1)
-	flattenedActionsList2 := lo.Flatten(actionsList2)
-
-	diffVersionsActions, diffVersions := FindActionsWithDifferentVersions(flattenedActionsList1, flattenedActionsList2)
-	similarConfigsActions, similarConfigs := FindActionsWithSimilarConfigurations(flattenedActionsList1, flattenedActionsList2)
+// GetActionsWithVersionDriftAndDuplicationIndexed is GetActionsWithVersionDriftAndDuplication for repositories
+// that have been indexed with IndexRepoActions.
+func GetActionsWithVersionDriftAndDuplicationIndexed(indexed1 IndexedRepoActions, indexed2 IndexedRepoActions) ([]string, []string, []string, []string) {
+	diffVersionsActions, diffVersions := FindActionsWithDifferentVersionsIndexed(indexed1.Actions, indexed2.ActionsByUses)
+	similarConfigsActions, similarConfigs := FindActionsWithSimilarConfigurationsIndexed(indexed1.Actions, indexed2.ActionsByUses)
 
 	// Generate a list of all the action IDs for steps with different versions and similar config
 	// This provides a complete list of steps that would have to be updated to ensure consistency between the workflows
@@ -173,40 +372,123 @@ func CountReposWithDuplicationOrDrift(comparisons map[string]map[string]models.R
 	return len(reposWithDuplicationOrDrift)
 }
 
+// CountWeightedReposWithDuplicationOrDrift counts the repositories that have duplication or drift, weighting each
+// repo by the severity of its worst comparison. Duplicated steps need the same effort as a major drift to fix.
+func CountWeightedReposWithDuplicationOrDrift(comparisons map[string]map[string]models.RepoMeasurements) float64 {
+	return lo.SumBy(lo.Values(comparisons), func(repoComparisons map[string]models.RepoMeasurements) float64 {
+		return lo.Max(lo.MapToSlice(repoComparisons, func(key string, measurement models.RepoMeasurements) float64 {
+			return GetMeasurementWeight(measurement)
+		}))
+	})
+}
+
+// GetMeasurementWeight returns the fraction of the effort of a consistent change that a comparison represents.
+func GetMeasurementWeight(measurement models.RepoMeasurements) float64 {
+	if measurement.StepsThatIndicateDuplicationRisk == 0 {
+		return 0
+	}
+
+	// Measurements created without drift details, or with duplicated steps, need the full effort
+	if measurement.StepsWithSimilarConfigCount > 0 || measurement.HighestDriftSeverity == "" {
+		return 1
+	}
+
+	return DriftSeverityWeights[measurement.HighestDriftSeverity]
+}
+
 func ConvertWorkflowToActionsMap(workflows map[string][]string) map[string][][]models.Action {
+	return ConvertRepoActionsToActionsMap(lo.MapToSlice(workflows, func(repo string, workflowFiles []string) RepoActions {
+		return RepoActions{
+			Repo:      repo,
+			Workflows: workflowFiles,
+		}
+	}))
+}
+
+// ConvertRepoActionsToActionsMap parses the workflows of each repository, expanding any composite actions.
+// The repositories are parsed in order of their names, so the action IDs are the same for every run.
+func ConvertRepoActionsToActionsMap(allRepoActions []RepoActions) map[string][][]models.Action {
+	_, repoActions := ParseRepoActions(allRepoActions)
+	return repoActions
+}
+
+// ParseRepoActions is ConvertRepoActionsToActionsMap, also returning the repositories with an error added for
+// each workflow that could not be parsed.
+func ParseRepoActions(allRepoActions []RepoActions) ([]RepoActions, map[string][][]models.Action) {
 	repoActions := make(map[string][][]models.Action)
+	parsedRepoActions := make([]RepoActions, 0, len(allRepoActions))
+
+	sortedRepoActions := slices.SortedStableFunc(slices.Values(allRepoActions), func(a RepoActions, b RepoActions) int {
+		return strings.Compare(a.Repo, b.Repo)
+	})
 
 	workflowId := 0
-	for repo, workflowFiles := range workflows {
-		for _, workflowFile := range workflowFiles {
+	for _, repo := range sortedRepoActions {
+		parseErrors := []models.RepoError{}
+
+		for i, workflowFile := range repo.Workflows {
 			workflowId++
-			actions := ParseWorkflow(workflowFile, workflowId)
-			repoActions[repo] = append(repoActions[repo], actions)
+			actions, err := ParseWorkflowWithErr(workflowFile, workflowId, repo.CompositeActions)
+
+			workflowName := fmt.Sprintf("#%d", i+1)
+			if i < len(repo.WorkflowFiles) {
+				workflowName = repo.WorkflowFiles[i]
+				for j := range actions {
+					actions[j].Workflow = workflowName
+				}
+			}
+
+			if err != nil {
+				parseErrors = append(parseErrors, NewParseError(workflowName, err))
+			}
+
+			repoActions[repo.Repo] = append(repoActions[repo.Repo], actions)
+		}
+
+		if len(parseErrors) != 0 {
+			repo.Errors = append(slices.Clone(repo.Errors), parseErrors...)
 		}
+
+		parsedRepoActions = append(parsedRepoActions, repo)
 	}
 
-	return repoActions
+	return parsedRepoActions, repoActions
 }
 
 // ParseWorkflow parses the string representation of a GitHub Actions workflow
 // and returns a slice of Action structs representing the actions used in the workflow.
 func ParseWorkflow(workflow string, workflowId int) []models.Action {
+	return ParseWorkflowWithCompositeActions(workflow, workflowId, nil)
+}
+
+// ParseWorkflowWithCompositeActions parses a workflow like ParseWorkflow, and also expands the steps
+// of any composite actions it calls. compositeActions maps the "uses" value of a step to the
+// content of the composite action's action.yml file. Expanded steps are added after the step
+// that calls the composite action, with their Source set to the "uses" value of that step.
+func ParseWorkflowWithCompositeActions(workflow string, workflowId int, compositeActions map[string]string) []models.Action {
+	actions, _ := ParseWorkflowWithErr(workflow, workflowId, compositeActions)
+	return actions
+}
+
+// ParseWorkflowWithErr is ParseWorkflowWithCompositeActions, returning the error if the workflow is not valid YAML.
+// A workflow that is valid YAML but has no jobs has no actions, and is not an error.
+func ParseWorkflowWithErr(workflow string, workflowId int, compositeActions map[string]string) ([]models.Action, error) {
 	var workflowMap map[string]interface{}
 
 	err := yaml.Unmarshal([]byte(workflow), &workflowMap)
 	if err != nil {
-		return []models.Action{}
+		return []models.Action{}, err
 	}
 
 	// Extract jobs
 	jobsInterface, ok := workflowMap["jobs"]
 	if !ok {
-		return []models.Action{}
+		return []models.Action{}, nil
 	}
 
 	jobsMap, ok := jobsInterface.(map[string]interface{})
 	if !ok {
-		return []models.Action{}
+		return []models.Action{}, nil
 	}
 
 	// 1. Get all keys into a slice
@@ -219,6 +501,28 @@ func ParseWorkflow(workflow string, workflowId int) []models.Action {
 
 	actionId := 1
 
+	var parseSteps func(stepsSlice []interface{}, source string, depth int)
+	parseSteps = func(stepsSlice []interface{}, source string, depth int) {
+		for _, stepInterface := range stepsSlice {
+			actionId++
+
+			stepMap, ok := stepInterface.(map[string]interface{})
+			if !ok {
+				continue
+			}
+
+			action := ParseStep(stepMap, fmt.Sprintf("%d-%d", workflowId, actionId))
+			action.Source = source
+			actions = append(actions, action)
+
+			// Composite actions can call other composite actions, so expand them recursively
+			uses := collections.GetStringProperty(stepMap, "uses")
+			if compositeAction, ok := compositeActions[uses]; ok && depth < MaxCompositeActionDepth {
+				parseSteps(GetCompositeActionSteps(compositeAction), uses, depth+1)
+			}
+		}
+	}
+
 	// Iterate through jobs and parse actions
 	for _, key := range keys {
 		jobMap, ok := jobsMap[key].(map[string]interface{})
@@ -226,6 +530,13 @@ func ParseWorkflow(workflow string, workflowId int) []models.Action {
 			continue
 		}
 
+		// Jobs that call a reusable workflow have a "uses" property instead of steps
+		if collections.GetStringProperty(jobMap, "uses") != "" {
+			actionId++
+			actions = append(actions, ParseReusableWorkflowCall(jobMap, fmt.Sprintf("%d-%d", workflowId, actionId)))
+			continue
+		}
+
 		// Extract steps
 		stepsInterface, ok := jobMap["steps"]
 		if !ok {
@@ -237,113 +548,171 @@ func ParseWorkflow(workflow string, workflowId int) []models.Action {
 			continue
 		}
 
-		for _, stepInterface := range stepsSlice {
-			actionId++
+		parseSteps(stepsSlice, "", 0)
+	}
 
-			stepMap, ok := stepInterface.(map[string]interface{})
-			if !ok {
-				continue
-			}
+	return actions, nil
+}
 
-			uses := collections.GetStringProperty(stepMap, "uses")
-			run := collections.GetStringProperty(stepMap, "run")
-
-			// Split uses into action and version
-			actionName, actionVersion := parsing.GetActionIdAndVersion(uses)
-
-			// Get the various settings for the action
-			env := collections.ConvertStringMap(collections.GetChildMap(stepMap, "env"))
-			with := collections.ConvertStringMap(collections.GetChildMap(stepMap, "with"))
-			settings := collections.GetOtherValues(stepMap, []string{"uses", "env", "with"})
-
-			action := models.Action{
-				Id:          fmt.Sprintf("%d-%d", workflowId, actionId),
-				Uses:        actionName,
-				UsesVersion: actionVersion,
-				Settings:    settings,
-				Env:         env,
-				With:        with,
-				Run:         run,
-			}
+// ParseStep converts a step in a workflow or composite action into an Action.
+func ParseStep(stepMap map[string]interface{}, id string) models.Action {
+	uses := collections.GetStringProperty(stepMap, "uses")
+	run := collections.GetStringProperty(stepMap, "run")
+
+	// Split uses into action and version
+	actionName, actionVersion := parsing.GetActionIdAndVersion(uses)
+
+	// Get the various settings for the action
+	env := collections.ConvertStringMap(collections.GetChildMap(stepMap, "env"))
+	with := collections.ConvertStringMap(collections.GetChildMap(stepMap, "with"))
+	settings := collections.GetOtherValues(stepMap, []string{"uses", "env", "with"})
+
+	action := models.Action{
+		Id:          id,
+		Uses:        actionName,
+		UsesVersion: actionVersion,
+		Settings:    settings,
+		Env:         env,
+		With:        with,
+		Run:         run,
+	}
 
-			action.GenerateHash()
+	action.GenerateHash()
 
-			actions = append(actions, action)
-		}
+	return action
+}
+
+// ParseReusableWorkflowCall converts a job that calls a reusable workflow into an Action.
+// The inputs passed with "with" and the secrets passed with "secrets" are captured so
+// callers of the same workflow can be compared like any other action.
+func ParseReusableWorkflowCall(jobMap map[string]interface{}, id string) models.Action {
+	uses := collections.GetStringProperty(jobMap, "uses")
+	actionName, actionVersion := parsing.GetActionIdAndVersion(uses)
+
+	with := collections.ConvertStringMap(collections.GetChildMap(jobMap, "with"))
+	secrets := collections.ConvertStringMap(collections.GetChildMap(jobMap, "secrets"))
+
+	// "secrets: inherit" is a plain string rather than a map, so it is kept with the other settings
+	excludedKeys := []string{"uses", "with"}
+	if secrets != nil {
+		excludedKeys = append(excludedKeys, "secrets")
+	}
+	settings := collections.GetOtherValues(jobMap, excludedKeys)
+
+	action := models.Action{
+		Id:               id,
+		Uses:             actionName,
+		UsesVersion:      actionVersion,
+		Settings:         settings,
+		With:             with,
+		Secrets:          secrets,
+		ReusableWorkflow: true,
 	}
 
-	return actions
+	action.GenerateHash()
+
+	return action
 }
 
+// FindActionsWithDifferentVersions returns the actions in both lists that call the same action with a different
+// version, and the unique names of those actions.
 func FindActionsWithDifferentVersions(actions1 []models.Action, actions2 []models.Action) ([]models.Action, []string) {
-	actions := []models.Action{}
-	result := []string{}
+	return FindActionsWithDifferentVersionsIndexed(actions1, IndexActionsByUses(actions2))
+}
 
-	for _, action1 := range actions1 {
-		for _, action2 := range actions2 {
+// FindActionsWithDifferentVersionsIndexed is FindActionsWithDifferentVersions where the second list of actions
+// has been indexed with IndexActionsByUses.
+func FindActionsWithDifferentVersionsIndexed(actions1 []models.Action, index2 map[string][]models.Action) ([]models.Action, []string) {
+	actions := NewActionSet()
+	result := NewStringSet()
 
+	for _, action1 := range actions1 {
+		for _, action2 := range index2[action1.Uses] {
 			if HasVersionDrift(action1, action2) {
-				if !lo.ContainsBy(actions, func(item models.Action) bool {
-					return item.Id == action1.Id
-				}) {
-					actions = append(actions, action1)
-				}
+				actions.Add(action1)
+				actions.Add(action2)
+				result.Add(action1.Uses)
+			}
+		}
+	}
 
-				if !lo.ContainsBy(actions, func(item models.Action) bool {
-					return item.Id == action2.Id
-				}) {
-					actions = append(actions, action2)
-				}
+	return actions.Items, result.Items
+}
 
-				if !slices.Contains(result, action1.Uses) {
-					result = append(result, action1.Uses)
-				}
+// FindVersionDrift returns the unique version differences between the two lists of actions, classified by severity.
+func FindVersionDrift(actions1 []models.Action, actions2 []models.Action) []models.VersionDrift {
+	return FindVersionDriftIndexed(GetUniqueVersions(actions1), IndexActionsByUses(GetUniqueVersions(actions2)), DriftSeverityCache{})
+}
+
+// FindVersionDriftIndexed is FindVersionDrift where the second list of actions has been indexed with IndexActionsByUses.
+// The cache can be shared between calls to avoid classifying the same pair of versions more than once.
+func FindVersionDriftIndexed(actions1 []models.Action, index2 map[string][]models.Action, cache DriftSeverityCache) []models.VersionDrift {
+	result := []models.VersionDrift{}
+	seen := map[models.VersionDrift]bool{}
+
+	for _, action1 := range actions1 {
+		for _, action2 := range index2[action1.Uses] {
+			severity := cache.Classify(action1, action2)
+			if severity == "" {
+				continue
+			}
+
+			drift := models.VersionDrift{
+				Uses:     action1.Uses,
+				Version1: action1.UsesVersion,
+				Version2: action2.UsesVersion,
+				Severity: severity,
+			}
+
+			if !seen[drift] {
+				seen[drift] = true
+				result = append(result, drift)
 			}
 		}
 	}
 
-	return actions, result
+	return result
 }
 
+// FindActionsWithSimilarConfigurations returns the actions in both lists that call the same action with similar
+// configuration, and the unique names of those actions.
 func FindActionsWithSimilarConfigurations(actions1 []models.Action, actions2 []models.Action) ([]models.Action, []string) {
+	return FindActionsWithSimilarConfigurationsIndexed(actions1, IndexActionsByUses(actions2))
+}
 
-	actions := []models.Action{}
-	result := []string{}
+// FindActionsWithSimilarConfigurationsIndexed is FindActionsWithSimilarConfigurations where the second list of
+// actions has been indexed with IndexActionsByUses.
+func FindActionsWithSimilarConfigurationsIndexed(actions1 []models.Action, index2 map[string][]models.Action) ([]models.Action, []string) {
+	actions := NewActionSet()
+	result := NewStringSet()
 
 	for _, action1 := range actions1 {
-		for _, action2 := range actions2 {
-			if action1.Uses == action2.Uses {
-				if action1.Hash != nil && action2.Hash != nil {
-					distance := action1.Hash.Diff(action2.Hash)
-
-					if distance <= HighSimilarity {
-						if !lo.ContainsBy(actions, func(item models.Action) bool {
-							return item.Id == action1.Id
-						}) {
-							actions = append(actions, action1)
-						}
-
-						if !lo.ContainsBy(actions, func(item models.Action) bool {
-							return item.Id == action2.Id
-						}) {
-							actions = append(actions, action2)
-						}
-
-						uses := action1.Uses
-						if uses == "" {
-							uses = BuiltInStep
-						}
-
-						if !slices.Contains(result, uses) {
-							result = append(result, uses)
-						}
-					}
+		if action1.Hash == nil {
+			continue
+		}
+
+		for _, action2 := range index2[action1.Uses] {
+			if action2.Hash == nil || IsSharedCompositeStep(action1, action2) {
+				continue
+			}
+
+			distance := action1.Hash.Diff(action2.Hash)
+
+			if distance <= HighSimilarity {
+				actions.Add(action1)
+				actions.Add(action2)
+
+				uses := action1.Uses
+				if uses == "" {
+					uses = BuiltInStep
 				}
+
+				result.Add(uses)
 			}
 		}
 	}
 
-	return actions, result
+	return actions.Items, result.Items
 }
 
 func GetActionAuthorsFromActionsList(actionsList [][]models.Action) []string {
@@ -362,3 +731,14 @@ func GetActionAuthorsFromActionsList(actionsList [][]models.Action) []string {
 		return "", false
 	}))
 }
+
+// GetSharedWorkflowsFromActionsList returns the reusable workflows from other repositories that are
+// called by the actions list. Workflows in the same repository are not shared and are ignored.
+func GetSharedWorkflowsFromActionsList(actionsList [][]models.Action) []string {
+	return lo.Uniq(lo.FilterMap(lo.Flatten(actionsList), func(item models.Action, index int) (string, bool) {
+		if !item.ReusableWorkflow || parsing.IsLocalReference(item.Uses) {
+			return "", false
+		}
+		return item.Uses + "@" + item.UsesVersion, true
+	}))
+}
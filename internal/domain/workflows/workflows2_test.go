@@ -3,6 +3,8 @@ package workflows
 import (
 	"fmt"
 	"testing"
+
+	"github.com/OctopusSolutionsEngineering/DuplicationCostCalculator/internal/domain/models"
 )
 
 func TestGenerateReportFromWorkflowsSimilarScripts(t *testing.T) {
@@ -58,3 +60,50 @@ jobs:
 		t.Error("Expected one similar steps, found " + fmt.Sprintf("%v %v", report.Comparisons["repo1"]["repo2"].StepsWithSimilarConfigCount, report.Comparisons["repo1"]["repo2"].StepsWithSimilarConfig))
 	}
 }
+
+func TestCountWeightedReposWithDuplicationOrDrift(t *testing.T) {
+	comparisons := map[string]map[string]models.RepoMeasurements{
+		"repo1": {
+			"repo2": {StepsThatIndicateDuplicationRisk: 1, StepsWithDifferentVersionsCount: 1, HighestDriftSeverity: DriftSeverityPatch},
+			"repo3": {StepsThatIndicateDuplicationRisk: 1, StepsWithDifferentVersionsCount: 1, HighestDriftSeverity: DriftSeverityMinor},
+		},
+		"repo2": {
+			"repo1": {StepsThatIndicateDuplicationRisk: 1, StepsWithDifferentVersionsCount: 1, HighestDriftSeverity: DriftSeverityPatch},
+			"repo3": {},
+		},
+		"repo3": {
+			"repo1": {StepsThatIndicateDuplicationRisk: 1, StepsWithDifferentVersionsCount: 1, HighestDriftSeverity: DriftSeverityMinor},
+			"repo2": {},
+		},
+		"repo4": {
+			"repo5": {StepsThatIndicateDuplicationRisk: 2, StepsWithSimilarConfigCount: 2, HighestDriftSeverity: DriftSeverityPatch},
+		},
+	}
+
+	// repo1 and repo3 have minor drift (0.5 each), repo2 has patch drift (0.25), and repo4 has duplication (1)
+	if result := CountWeightedReposWithDuplicationOrDrift(comparisons); result != 2.25 {
+		t.Errorf("Expected a weighted count of 2.25, got %v", result)
+	}
+}
+
+func TestGetMeasurementWeight(t *testing.T) {
+	tests := []struct {
+		name        string
+		measurement models.RepoMeasurements
+		expected    float64
+	}{
+		{"no risk", models.RepoMeasurements{}, 0},
+		{"major drift", models.RepoMeasurements{StepsThatIndicateDuplicationRisk: 1, HighestDriftSeverity: DriftSeverityMajor}, 1},
+		{"ref-type drift", models.RepoMeasurements{StepsThatIndicateDuplicationRisk: 1, HighestDriftSeverity: DriftSeverityRefType}, 0.5},
+		{"patch drift", models.RepoMeasurements{StepsThatIndicateDuplicationRisk: 1, HighestDriftSeverity: DriftSeverityPatch}, 0.25},
+		{"risk without drift details", models.RepoMeasurements{StepsThatIndicateDuplicationRisk: 1}, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if result := GetMeasurementWeight(tt.measurement); result != tt.expected {
+				t.Errorf("GetMeasurementWeight() = %v, expected %v", result, tt.expected)
+			}
+		})
+	}
+}
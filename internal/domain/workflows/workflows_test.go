@@ -454,6 +454,33 @@ jobs:
 	}
 }
 
+func TestConvertRepoActionsToActionsMapSortsRepos(t *testing.T) {
+	// Test that the action IDs do not depend on the order of the repositories
+	workflow := `
+jobs:
+  test:
+    steps:
+      - uses: actions/checkout@v4
+`
+
+	repo1 := RepoActions{Repo: "owner/repo1", Workflows: []string{workflow}}
+	repo2 := RepoActions{Repo: "owner/repo2", Workflows: []string{workflow}}
+
+	result := ConvertRepoActionsToActionsMap([]RepoActions{repo2, repo1})
+	reversed := ConvertRepoActionsToActionsMap([]RepoActions{repo1, repo2})
+
+	for _, repo := range []string{"owner/repo1", "owner/repo2"} {
+		if result[repo][0][0].Id != reversed[repo][0][0].Id {
+			t.Errorf("ConvertRepoActionsToActionsMap() gave %s action ID %q and %q for different repo orders",
+				repo, result[repo][0][0].Id, reversed[repo][0][0].Id)
+		}
+	}
+
+	if result["owner/repo1"][0][0].Id != "1-2" {
+		t.Errorf("ConvertRepoActionsToActionsMap() gave the first repo action ID %q, expected \"1-2\"", result["owner/repo1"][0][0].Id)
+	}
+}
+
 func TestConvertWorkflowToActionsMapPreservesOrder(t *testing.T) {
 	// Test that workflows are processed in order (though map iteration is random,
 	// the function should handle all workflows)
@@ -648,6 +675,19 @@ this is not valid yaml: [
 	}
 }
 
+func TestParseWorkflowWithErr(t *testing.T) {
+	_, err := ParseWorkflowWithErr("this is not valid yaml: [", 1, nil)
+	if err == nil {
+		t.Error("Expected an error for invalid YAML")
+	}
+
+	// A workflow without jobs is valid, it just has no actions
+	_, err = ParseWorkflowWithErr("name: Empty Workflow", 1, nil)
+	if err != nil {
+		t.Errorf("Unexpected error for a workflow without jobs: %v", err)
+	}
+}
+
 func TestParseWorkflowNoJobs(t *testing.T) {
 	workflowYAML := `
 name: Empty Workflow
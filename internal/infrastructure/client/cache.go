@@ -0,0 +1,184 @@
+package client
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+)
+
+// CacheHeader is added to responses that were served from the cache after GitHub reported they were not modified.
+const CacheHeader = "X-From-Cache"
+
+// CacheTransport is an http.RoundTripper that stores GitHub API responses on disk, and revalidates them with
+// If-None-Match and If-Modified-Since headers. GitHub does not count 304 Not Modified responses against the
+// rate limit, so repeated analyses of the same repositories are cheap.
+// Responses are keyed by the URL and an identity, like a hash of the access token, so one user is never sent a
+// response that was cached for another.
+type CacheTransport struct {
+	Transport http.RoundTripper
+	// Dir is the directory the responses are written to.
+	Dir string
+	// Identity identifies the credentials used to make the requests.
+	Identity string
+}
+
+// CachedResponse is a response written to the cache.
+type CachedResponse struct {
+	URL        string      `json:"url"`
+	StatusCode int         `json:"statusCode"`
+	Header     http.Header `json:"header"`
+	Body       []byte      `json:"body"`
+}
+
+// NewCacheTransport wraps the transport, which defaults to http.DefaultTransport if nil.
+func NewCacheTransport(transport http.RoundTripper, dir string, identity string) *CacheTransport {
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+
+	return &CacheTransport{
+		Transport: transport,
+		Dir:       dir,
+		Identity:  identity,
+	}
+}
+
+// GetIdentity returns an identity for the access token that can be used as a cache key without storing the token.
+func GetIdentity(token string) string {
+	hash := sha256.Sum256([]byte(token))
+	return "token:" + hex.EncodeToString(hash[:])
+}
+
+func (t *CacheTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if !isCacheable(req) {
+		return t.Transport.RoundTrip(req)
+	}
+
+	path := t.getPath(req)
+	cached, found := readCachedResponse(path)
+
+	// The request must not be modified, so the validators are added to a copy
+	if found {
+		req = req.Clone(req.Context())
+		if etag := cached.Header.Get("ETag"); etag != "" {
+			req.Header.Set("If-None-Match", etag)
+		}
+		if lastModified := cached.Header.Get("Last-Modified"); lastModified != "" {
+			req.Header.Set("If-Modified-Since", lastModified)
+		}
+	}
+
+	resp, err := t.Transport.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if found && resp.StatusCode == http.StatusNotModified {
+		resp.Body.Close()
+		return cached.toResponse(req, resp.Header), nil
+	}
+
+	if resp.StatusCode != http.StatusOK || (resp.Header.Get("ETag") == "" && resp.Header.Get("Last-Modified") == "") {
+		return resp, nil
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	// Failing to write the cache does not fail the request
+	writeCachedResponse(path, CachedResponse{
+		URL:        req.URL.String(),
+		StatusCode: resp.StatusCode,
+		Header:     resp.Header,
+		Body:       body,
+	})
+
+	return resp, nil
+}
+
+// getPath returns the file the response to the request is cached in. The identity is hashed with the URL, so
+// the files don't reveal the credentials. The Accept header is included because GitHub returns different
+// representations of the same URL, like raw file content or JSON.
+func (t *CacheTransport) getPath(req *http.Request) string {
+	hash := sha256.Sum256([]byte(t.Identity + "\n" + req.Header.Get("Accept") + "\n" + req.URL.String()))
+	key := hex.EncodeToString(hash[:])
+	return filepath.Join(t.Dir, key[:2], key+".json")
+}
+
+// toResponse builds a response from the cache, with the headers of the 304 response, like the rate limit
+// headers, replacing the cached headers.
+func (c CachedResponse) toResponse(req *http.Request, header http.Header) *http.Response {
+	mergedHeader := c.Header.Clone()
+	if mergedHeader == nil {
+		mergedHeader = http.Header{}
+	}
+	for key, values := range header {
+		mergedHeader[key] = values
+	}
+	mergedHeader.Set("Content-Length", strconv.Itoa(len(c.Body)))
+	mergedHeader.Set(CacheHeader, "1")
+
+	return &http.Response{
+		Status:        strconv.Itoa(c.StatusCode) + " " + http.StatusText(c.StatusCode),
+		StatusCode:    c.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        mergedHeader,
+		Body:          io.NopCloser(bytes.NewReader(c.Body)),
+		ContentLength: int64(len(c.Body)),
+		Request:       req,
+	}
+}
+
+func isCacheable(req *http.Request) bool {
+	return req.Method == http.MethodGet && req.Header.Get("Range") == ""
+}
+
+func readCachedResponse(path string) (CachedResponse, bool) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return CachedResponse{}, false
+	}
+
+	var cached CachedResponse
+	if err := json.Unmarshal(content, &cached); err != nil {
+		return CachedResponse{}, false
+	}
+
+	return cached, true
+}
+
+// writeCachedResponse writes the response to a temporary file and renames it, so concurrent requests never
+// read a partially written file. The files can contain private repository content, so only the owner can read them.
+func writeCachedResponse(path string, cached CachedResponse) {
+	content, err := json.Marshal(cached)
+	if err != nil {
+		return
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return
+	}
+
+	file, err := os.CreateTemp(filepath.Dir(path), ".tmp-*")
+	if err != nil {
+		return
+	}
+
+	_, writeErr := file.Write(content)
+	closeErr := file.Close()
+	if writeErr != nil || closeErr != nil || os.Rename(file.Name(), path) != nil {
+		os.Remove(file.Name())
+	}
+}
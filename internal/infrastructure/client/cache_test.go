@@ -0,0 +1,267 @@
+package client
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+
+	"github.com/OctopusSolutionsEngineering/DuplicationCostCalculator/internal/domain/configuration"
+)
+
+// newETagServer returns a server that responds with the body and an ETag, or 304 Not Modified if the
+// request has a matching If-None-Match header.
+func newETagServer(t *testing.T, body *string, notModified *atomic.Int32) *httptest.Server {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		etag := `"` + *body + `"`
+		w.Header().Set("X-RateLimit-Remaining", r.Header.Get("X-Test-Remaining"))
+
+		if r.Header.Get("If-None-Match") == etag {
+			notModified.Add(1)
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+
+		w.Header().Set("ETag", etag)
+		w.Write([]byte(*body))
+	}))
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+func getBody(t *testing.T, transport http.RoundTripper, url string, headers map[string]string) (string, *http.Response) {
+	t.Helper()
+
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		t.Fatalf("Failed to create request: %v", err)
+	}
+
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+
+	resp, err := transport.RoundTrip(req)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("Failed to read body: %v", err)
+	}
+
+	return string(body), resp
+}
+
+func TestCacheTransport_Revalidates(t *testing.T) {
+	// Arrange
+	body := "name: Build"
+	notModified := atomic.Int32{}
+	server := newETagServer(t, &body, &notModified)
+	transport := NewCacheTransport(nil, t.TempDir(), GetIdentity("token1"))
+
+	// Act
+	first, firstResp := getBody(t, transport, server.URL+"/repos/owner/repo/contents/build.yml", map[string]string{"X-Test-Remaining": "10"})
+	second, secondResp := getBody(t, transport, server.URL+"/repos/owner/repo/contents/build.yml", map[string]string{"X-Test-Remaining": "9"})
+
+	// Assert
+	if first != body || second != body {
+		t.Errorf("Expected both responses to be %q, got %q and %q", body, first, second)
+	}
+
+	if notModified.Load() != 1 {
+		t.Errorf("Expected the second request to be revalidated, got %d 304 responses", notModified.Load())
+	}
+
+	if firstResp.Header.Get(CacheHeader) != "" || secondResp.Header.Get(CacheHeader) != "1" {
+		t.Errorf("Expected only the second response to be from the cache")
+	}
+
+	if secondResp.StatusCode != http.StatusOK {
+		t.Errorf("Expected the cached response to have status 200, got %d", secondResp.StatusCode)
+	}
+
+	// The headers of the 304 response replace the cached headers
+	if remaining := secondResp.Header.Get("X-RateLimit-Remaining"); remaining != "9" {
+		t.Errorf("Expected the rate limit of the 304 response, got %s", remaining)
+	}
+}
+
+func TestCacheTransport_Modified(t *testing.T) {
+	// Arrange
+	body := "name: Build"
+	notModified := atomic.Int32{}
+	server := newETagServer(t, &body, &notModified)
+	transport := NewCacheTransport(nil, t.TempDir(), GetIdentity("token1"))
+
+	// Act
+	getBody(t, transport, server.URL, nil)
+	body = "name: Build and test"
+	second, _ := getBody(t, transport, server.URL, nil)
+	third, thirdResp := getBody(t, transport, server.URL, nil)
+
+	// Assert
+	if second != body || third != body {
+		t.Errorf("Expected the modified body %q, got %q and %q", body, second, third)
+	}
+
+	if notModified.Load() != 1 || thirdResp.Header.Get(CacheHeader) != "1" {
+		t.Errorf("Expected only the third request to be served from the cache")
+	}
+}
+
+func TestCacheTransport_SeparatesIdentities(t *testing.T) {
+	// Arrange
+	body := "private content"
+	notModified := atomic.Int32{}
+	server := newETagServer(t, &body, &notModified)
+	dir := t.TempDir()
+
+	// Act
+	getBody(t, NewCacheTransport(nil, dir, GetIdentity("token1")), server.URL, nil)
+	getBody(t, NewCacheTransport(nil, dir, GetIdentity("token2")), server.URL, nil)
+
+	// Assert
+	if notModified.Load() != 0 {
+		t.Errorf("Expected a response cached for one token not to be used for another")
+	}
+}
+
+func TestCacheTransport_SeparatesAcceptHeaders(t *testing.T) {
+	// Arrange
+	body := "name: Build"
+	notModified := atomic.Int32{}
+	server := newETagServer(t, &body, &notModified)
+	transport := NewCacheTransport(nil, t.TempDir(), GetIdentity("token1"))
+
+	// Act
+	getBody(t, transport, server.URL, map[string]string{"Accept": "application/vnd.github.raw"})
+	getBody(t, transport, server.URL, map[string]string{"Accept": "application/vnd.github+json"})
+
+	// Assert
+	if notModified.Load() != 0 {
+		t.Errorf("Expected different representations to be cached separately")
+	}
+}
+
+func TestCacheTransport_NotCached(t *testing.T) {
+	// Arrange
+	requests := atomic.Int32{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		if r.Header.Get("If-None-Match") != "" {
+			t.Errorf("Expected no conditional request for %s %s", r.Method, r.URL.Path)
+		}
+
+		switch r.URL.Path {
+		case "/missing":
+			w.Header().Set("ETag", `"missing"`)
+			w.WriteHeader(http.StatusNotFound)
+		case "/no-validators":
+			w.Write([]byte("content"))
+		default:
+			w.Header().Set("ETag", `"post"`)
+			w.WriteHeader(http.StatusCreated)
+		}
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	transport := NewCacheTransport(nil, dir, GetIdentity("token1"))
+
+	// Act
+	for i := 0; i < 2; i++ {
+		getBody(t, transport, server.URL+"/missing", nil)
+		getBody(t, transport, server.URL+"/no-validators", nil)
+
+		req, _ := http.NewRequest(http.MethodPost, server.URL+"/post", nil)
+		resp, err := transport.RoundTrip(req)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		resp.Body.Close()
+	}
+
+	// Assert
+	if requests.Load() != 6 {
+		t.Errorf("Expected every request to be sent, got %d", requests.Load())
+	}
+
+	if entries, _ := os.ReadDir(dir); len(entries) != 0 {
+		t.Errorf("Expected nothing to be cached, got %d entries", len(entries))
+	}
+}
+
+func TestCacheTransport_CorruptCache(t *testing.T) {
+	// Arrange
+	body := "name: Build"
+	notModified := atomic.Int32{}
+	server := newETagServer(t, &body, &notModified)
+	dir := t.TempDir()
+	transport := NewCacheTransport(nil, dir, GetIdentity("token1"))
+
+	req, _ := http.NewRequest(http.MethodGet, server.URL, nil)
+	path := transport.getPath(req)
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		t.Fatalf("Failed to create directory: %v", err)
+	}
+	if err := os.WriteFile(path, []byte("{not json"), 0600); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	// Act
+	result, _ := getBody(t, transport, server.URL, nil)
+
+	// Assert
+	if result != body || notModified.Load() != 0 {
+		t.Errorf("Expected a corrupt cache entry to be ignored, got %q", result)
+	}
+
+	if cached, found := readCachedResponse(path); !found || string(cached.Body) != body {
+		t.Errorf("Expected the corrupt cache entry to be replaced, got %+v", cached)
+	}
+}
+
+func TestGetIdentity(t *testing.T) {
+	if GetIdentity("token1") == GetIdentity("token2") {
+		t.Error("Expected different tokens to have different identities")
+	}
+
+	if GetIdentity("token1") != GetIdentity("token1") {
+		t.Error("Expected the identity to be stable")
+	}
+}
+
+func TestNewTransport(t *testing.T) {
+	t.Run("without cache", func(t *testing.T) {
+		t.Setenv(configuration.DUPCOST_CACHE_DIR, "")
+
+		transport, ok := NewTransport(http.DefaultTransport, "identity").(*RateLimitTransport)
+		if !ok || transport.Transport != http.DefaultTransport {
+			t.Errorf("Expected the rate limit transport to wrap the default transport, got %+v", transport)
+		}
+	})
+
+	t.Run("with cache", func(t *testing.T) {
+		dir := t.TempDir()
+		t.Setenv(configuration.DUPCOST_CACHE_DIR, dir)
+
+		transport, ok := NewTransport(http.DefaultTransport, "identity").(*RateLimitTransport)
+		if !ok {
+			t.Fatalf("Expected a rate limit transport, got %T", transport)
+		}
+
+		cache, ok := transport.Transport.(*CacheTransport)
+		if !ok || cache.Dir != dir || cache.Identity != "identity" {
+			t.Errorf("Expected the cache transport to be configured, got %+v", transport.Transport)
+		}
+	})
+}
@@ -31,6 +31,7 @@ func GetOathClient(jwt string) *github.Client {
 
 	// Create an HTTP client with the token
 	tc := oauth2.NewClient(ctx, ts)
+	tc.Transport = NewTransport(tc.Transport, GetIdentity(jwt))
 
 	// Create and return the GitHub client
 	return github.NewClient(tc)
@@ -53,7 +54,7 @@ func GetClientLocal() *github.Client {
 	}
 
 	// Create the GitHub client with the authenticated transport
-	client := github.NewClient(&http.Client{Transport: itr})
+	client := github.NewClient(&http.Client{Transport: NewTransport(itr, "app:"+appIDStr+":installation:"+installationIDStr)})
 
 	// Create GitHub client
 	return client
@@ -67,6 +68,16 @@ func GetClient(accessToken string) *github.Client {
 	return GetOathClient(accessToken)
 }
 
+// NewTransport wraps the authenticated transport with the rate limit transport, and the cache transport if a cache
+// directory has been configured. The identity separates the cached responses of different credentials.
+func NewTransport(transport http.RoundTripper, identity string) http.RoundTripper {
+	if cacheDir := configuration.GetCacheDir(); cacheDir != "" {
+		transport = NewCacheTransport(transport, cacheDir, identity)
+	}
+
+	return NewRateLimitTransport(transport)
+}
+
 func mustParseInt64(s string) int64 {
 	i, err := strconv.ParseInt(s, 10, 64)
 	if err != nil {
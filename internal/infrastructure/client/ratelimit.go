@@ -0,0 +1,325 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/OctopusSolutionsEngineering/DuplicationCostCalculator/internal/domain/models"
+)
+
+// MaxConcurrentRequests is the number of requests that are sent to the GitHub API at the same time.
+// GitHub recommends against concurrent requests, and a small pool avoids the secondary rate limits.
+const MaxConcurrentRequests = 8
+
+// MaxRetries is the number of times a request is sent again after a rate limit or server error. Only idempotent
+// requests are retried after a server or transport error, because the first attempt may have succeeded.
+const MaxRetries = 4
+
+// RetryBaseDelay is the delay before the first retry when the response does not say how long to wait.
+// The delay doubles with each retry.
+const RetryBaseDelay = time.Second
+
+// MaxRetryWait is the longest time to wait before sending a request. Requests that would have to wait longer,
+// for example because the hourly budget is used up, fail with the rate limit response instead.
+const MaxRetryWait = 2 * time.Minute
+
+// SecondaryRateLimitDelay is how long to wait after a secondary rate limit that does not include a
+// Retry-After header. GitHub asks clients to wait at least a minute.
+const SecondaryRateLimitDelay = time.Minute
+
+// RateLimitTransport is an http.RoundTripper that limits the number of concurrent requests to the GitHub API,
+// waits for the rate limit to reset when the budget is used up, and retries requests that were rate limited
+// or, if they are idempotent, failed with a server or transport error. The X-RateLimit-* headers of each response are tracked, and are available
+// from Budget.
+type RateLimitTransport struct {
+	Transport http.RoundTripper
+	// MaxRetries is the number of times a request is retried.
+	MaxRetries int
+	// BaseDelay is the delay before the first retry when the response does not include a delay.
+	BaseDelay time.Duration
+	// MaxWait is the longest time to wait before sending or retrying a request.
+	MaxWait time.Duration
+
+	slots  chan struct{}
+	mutex  sync.Mutex
+	budget models.RateLimitBudget
+	known  bool
+	// sleep waits for the duration, or until the context is done. It is replaced in tests.
+	sleep func(ctx context.Context, duration time.Duration) error
+	// now returns the current time. It is replaced in tests.
+	now func() time.Time
+}
+
+// NewRateLimitTransport wraps the transport, which defaults to http.DefaultTransport if nil.
+func NewRateLimitTransport(transport http.RoundTripper) *RateLimitTransport {
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+
+	return &RateLimitTransport{
+		Transport:  transport,
+		MaxRetries: MaxRetries,
+		BaseDelay:  RetryBaseDelay,
+		MaxWait:    MaxRetryWait,
+		slots:      make(chan struct{}, MaxConcurrentRequests),
+		sleep:      sleepContext,
+		now:        time.Now,
+	}
+}
+
+// Budget returns the rate limit reported by the most recent responses. The second value is false if no
+// response has included the rate limit headers.
+func (t *RateLimitTransport) Budget() (models.RateLimitBudget, bool) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	return t.budget, t.known
+}
+
+// RoundTrip sends the request, retrying it if it was rate limited or failed. A slot is only held while a request
+// is being sent, so requests that are waiting to be retried don't stop other requests from being sent.
+func (t *RateLimitTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+
+	// Wait for the rate limit to reset rather than sending a request that is known to fail
+	if wait := t.getExhaustedWait(); wait > 0 && wait <= t.MaxWait {
+		if err := t.sleep(ctx, wait); err != nil {
+			return nil, err
+		}
+	}
+
+	for attempt := 0; ; attempt++ {
+		attemptReq, err := getAttemptRequest(req, attempt)
+		if err != nil {
+			return nil, err
+		}
+
+		resp, err := t.send(ctx, attemptReq, attempt)
+		if resp != nil {
+			t.updateBudget(resp.Header)
+		}
+
+		retry, delay := t.getRetryDelay(req, resp, err, attempt)
+		if !retry || attempt >= t.MaxRetries || delay > t.MaxWait || (req.Body != nil && req.GetBody == nil) {
+			return resp, err
+		}
+
+		if resp != nil {
+			io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+		}
+
+		if err := t.sleep(ctx, delay); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// send sends a single attempt of the request once a slot is free.
+func (t *RateLimitTransport) send(ctx context.Context, req *http.Request, attempt int) (*http.Response, error) {
+	select {
+	case t.slots <- struct{}{}:
+		defer func() { <-t.slots }()
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+
+	t.countRequest(attempt)
+
+	return t.Transport.RoundTrip(req)
+}
+
+// getRetryDelay returns whether the request should be sent again, and how long to wait first.
+// A rate limited request was not processed, so it can always be sent again. A request that failed with a
+// server or transport error may have been processed, so only idempotent requests are sent again. Otherwise,
+// retrying a request that created a pull request would fail because the pull request already exists.
+func (t *RateLimitTransport) getRetryDelay(req *http.Request, resp *http.Response, err error, attempt int) (bool, time.Duration) {
+	backoff := t.getBackoff(attempt)
+
+	if err != nil {
+		// A cancelled request must not be retried, but other transport errors are usually transient
+		return isIdempotent(req.Method) && !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded), backoff
+	}
+
+	if retryAfter, ok := parseRetryAfter(resp.Header.Get("Retry-After"), t.now()); ok && isRateLimitStatus(resp.StatusCode) {
+		return true, retryAfter
+	}
+
+	switch {
+	case isRateLimitStatus(resp.StatusCode) && resp.Header.Get("X-RateLimit-Remaining") == "0":
+		// The primary rate limit has been used up, so wait for it to reset
+		reset, ok := parseUnixHeader(resp.Header.Get("X-RateLimit-Reset"))
+		if !ok {
+			return true, backoff
+		}
+		return true, max(reset.Sub(t.now()), 0) + time.Second
+	case resp.StatusCode == http.StatusTooManyRequests:
+		return true, backoff
+	case resp.StatusCode == http.StatusForbidden && isSecondaryRateLimit(resp):
+		return true, max(SecondaryRateLimitDelay, backoff)
+	case resp.StatusCode == http.StatusBadGateway, resp.StatusCode == http.StatusServiceUnavailable, resp.StatusCode == http.StatusGatewayTimeout:
+		return isIdempotent(req.Method), backoff
+	}
+
+	return false, 0
+}
+
+// getBackoff returns the exponential backoff for the attempt, with up to 25% jitter so concurrent
+// requests don't all retry at once.
+func (t *RateLimitTransport) getBackoff(attempt int) time.Duration {
+	delay := t.BaseDelay << attempt
+	if delay <= 0 {
+		return 0
+	}
+
+	return delay + time.Duration(rand.Int64N(int64(delay)/4+1))
+}
+
+// getExhaustedWait returns how long to wait for the rate limit to reset if the budget has been used up.
+func (t *RateLimitTransport) getExhaustedWait() time.Duration {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	if !t.known || t.budget.Remaining > 0 {
+		return 0
+	}
+
+	return t.budget.Reset.Sub(t.now())
+}
+
+func (t *RateLimitTransport) countRequest(attempt int) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	t.budget.Requests++
+	if attempt > 0 {
+		t.budget.Retries++
+	}
+}
+
+// updateBudget records the rate limit headers of a response. Responses can arrive out of order, so the
+// lowest remaining budget is kept until the limit resets.
+func (t *RateLimitTransport) updateBudget(header http.Header) {
+	limit, limitErr := strconv.Atoi(header.Get("X-RateLimit-Limit"))
+	remaining, remainingErr := strconv.Atoi(header.Get("X-RateLimit-Remaining"))
+	reset, resetOk := parseUnixHeader(header.Get("X-RateLimit-Reset"))
+	if limitErr != nil || remainingErr != nil || !resetOk {
+		return
+	}
+
+	// Search and GraphQL requests have their own, much smaller, budgets
+	if resource := header.Get("X-RateLimit-Resource"); resource != "" && resource != "core" {
+		return
+	}
+
+	used, _ := strconv.Atoi(header.Get("X-RateLimit-Used"))
+
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	if t.known && reset.Equal(t.budget.Reset) && remaining >= t.budget.Remaining {
+		return
+	}
+
+	if t.known && reset.Before(t.budget.Reset) {
+		return
+	}
+
+	t.budget.Limit = limit
+	t.budget.Remaining = remaining
+	t.budget.Used = used
+	t.budget.Reset = reset
+	t.known = true
+}
+
+// getAttemptRequest returns the request to send for the attempt. Retries need a fresh copy of the body.
+func getAttemptRequest(req *http.Request, attempt int) (*http.Request, error) {
+	if attempt == 0 || req.Body == nil || req.GetBody == nil {
+		return req, nil
+	}
+
+	body, err := req.GetBody()
+	if err != nil {
+		return nil, err
+	}
+
+	attemptReq := req.Clone(req.Context())
+	attemptReq.Body = body
+
+	return attemptReq, nil
+}
+
+// isIdempotent returns true if sending the request more than once has the same effect as sending it once.
+// PUT is idempotent in HTTP, but GitHub uses it for requests like merging a pull request, which fail when repeated.
+func isIdempotent(method string) bool {
+	switch method {
+	case "", http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodDelete:
+		return true
+	}
+
+	return false
+}
+
+func isRateLimitStatus(statusCode int) bool {
+	return statusCode == http.StatusForbidden || statusCode == http.StatusTooManyRequests
+}
+
+// isSecondaryRateLimit checks the body of a 403 response for the secondary rate limit message. The body
+// is replaced so it can still be read by the caller.
+func isSecondaryRateLimit(resp *http.Response) bool {
+	if resp.Body == nil {
+		return false
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	return err == nil && strings.Contains(strings.ToLower(string(body)), "secondary rate limit")
+}
+
+// parseRetryAfter parses a Retry-After header, which is either a number of seconds or an HTTP date.
+func parseRetryAfter(value string, now time.Time) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil {
+		return time.Duration(max(seconds, 0)) * time.Second, true
+	}
+
+	if date, err := http.ParseTime(value); err == nil {
+		return max(date.Sub(now), 0), true
+	}
+
+	return 0, false
+}
+
+func parseUnixHeader(value string) (time.Time, bool) {
+	seconds, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return time.Time{}, false
+	}
+
+	return time.Unix(seconds, 0), true
+}
+
+func sleepContext(ctx context.Context, duration time.Duration) error {
+	timer := time.NewTimer(duration)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
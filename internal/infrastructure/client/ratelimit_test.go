@@ -0,0 +1,489 @@
+package client
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+var testNow = time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+type roundTripperFunc func(req *http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+// newTestTransport returns a transport that records the delays instead of sleeping.
+func newTestTransport(responses ...func(req *http.Request) (*http.Response, error)) (*RateLimitTransport, *[]time.Duration, *int) {
+	delays := []time.Duration{}
+	calls := 0
+
+	transport := NewRateLimitTransport(roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		response := responses[min(calls, len(responses)-1)]
+		calls++
+		return response(req)
+	}))
+	transport.now = func() time.Time { return testNow }
+	transport.sleep = func(ctx context.Context, duration time.Duration) error {
+		delays = append(delays, duration)
+		return ctx.Err()
+	}
+
+	return transport, &delays, &calls
+}
+
+func newResponse(statusCode int, headers map[string]string, body string) func(req *http.Request) (*http.Response, error) {
+	return func(req *http.Request) (*http.Response, error) {
+		return &http.Response{
+			StatusCode: statusCode,
+			Header:     headerFromMap(headers),
+			Body:       io.NopCloser(strings.NewReader(body)),
+			Request:    req,
+		}, nil
+	}
+}
+
+func rateLimitHeaders(remaining int, reset time.Time) map[string]string {
+	return map[string]string{
+		"X-RateLimit-Limit":     "5000",
+		"X-RateLimit-Remaining": strconv.Itoa(remaining),
+		"X-RateLimit-Used":      strconv.Itoa(5000 - remaining),
+		"X-RateLimit-Reset":     strconv.FormatInt(reset.Unix(), 10),
+		"X-RateLimit-Resource":  "core",
+	}
+}
+
+func sendRequest(t *testing.T, transport http.RoundTripper) *http.Response {
+	t.Helper()
+
+	req, err := http.NewRequest(http.MethodGet, "https://api.github.com/repos/owner/repo", nil)
+	if err != nil {
+		t.Fatalf("Failed to create request: %v", err)
+	}
+
+	resp, err := transport.RoundTrip(req)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	return resp
+}
+
+func TestRateLimitTransport_Budget(t *testing.T) {
+	// Arrange
+	reset := testNow.Add(time.Hour)
+	transport, delays, _ := newTestTransport(newResponse(http.StatusOK, rateLimitHeaders(4990, reset), "{}"))
+
+	if _, known := transport.Budget(); known {
+		t.Fatal("Expected the budget to be unknown before any requests")
+	}
+
+	// Act
+	resp := sendRequest(t, transport)
+
+	// Assert
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("Expected status 200, got %d", resp.StatusCode)
+	}
+
+	budget, known := transport.Budget()
+	if !known {
+		t.Fatal("Expected the budget to be known")
+	}
+
+	if budget.Limit != 5000 || budget.Remaining != 4990 || budget.Used != 10 || !budget.Reset.Equal(reset) {
+		t.Errorf("Unexpected budget %+v", budget)
+	}
+
+	if budget.Requests != 1 || budget.Retries != 0 {
+		t.Errorf("Expected 1 request and no retries, got %+v", budget)
+	}
+
+	if len(*delays) != 0 {
+		t.Errorf("Expected no delays, got %v", *delays)
+	}
+}
+
+func TestRateLimitTransport_Retries(t *testing.T) {
+	reset := testNow.Add(30 * time.Second)
+	success := newResponse(http.StatusOK, nil, "{}")
+
+	tests := []struct {
+		name          string
+		first         func(req *http.Request) (*http.Response, error)
+		expectedDelay time.Duration
+		exact         bool
+	}{
+		{
+			name:          "retry after seconds",
+			first:         newResponse(http.StatusTooManyRequests, map[string]string{"Retry-After": "3"}, ""),
+			expectedDelay: 3 * time.Second,
+			exact:         true,
+		},
+		{
+			name:          "retry after date",
+			first:         newResponse(http.StatusForbidden, map[string]string{"Retry-After": testNow.Add(10 * time.Second).Format(http.TimeFormat)}, ""),
+			expectedDelay: 10 * time.Second,
+			exact:         true,
+		},
+		{
+			name:          "primary rate limit",
+			first:         newResponse(http.StatusForbidden, rateLimitHeaders(0, reset), `{"message": "API rate limit exceeded"}`),
+			expectedDelay: 31 * time.Second,
+			exact:         true,
+		},
+		{
+			name:          "secondary rate limit",
+			first:         newResponse(http.StatusForbidden, nil, `{"message": "You have exceeded a secondary rate limit."}`),
+			expectedDelay: SecondaryRateLimitDelay,
+		},
+		{
+			name:          "server error",
+			first:         newResponse(http.StatusBadGateway, nil, ""),
+			expectedDelay: RetryBaseDelay,
+		},
+		{
+			name: "transport error",
+			first: func(req *http.Request) (*http.Response, error) {
+				return nil, io.ErrUnexpectedEOF
+			},
+			expectedDelay: RetryBaseDelay,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			transport, delays, calls := newTestTransport(tt.first, success)
+
+			// Act
+			resp := sendRequest(t, transport)
+
+			// Assert
+			if resp.StatusCode != http.StatusOK {
+				t.Errorf("Expected the retry to succeed, got status %d", resp.StatusCode)
+			}
+
+			if *calls != 2 {
+				t.Errorf("Expected 2 calls, got %d", *calls)
+			}
+
+			if len(*delays) != 1 {
+				t.Fatalf("Expected 1 delay, got %v", *delays)
+			}
+
+			delay := (*delays)[0]
+			if tt.exact && delay != tt.expectedDelay {
+				t.Errorf("Expected a delay of %v, got %v", tt.expectedDelay, delay)
+			}
+
+			// Backoff includes up to 25% jitter
+			if !tt.exact && (delay < tt.expectedDelay || delay > tt.expectedDelay*5/4) {
+				t.Errorf("Expected a delay of about %v, got %v", tt.expectedDelay, delay)
+			}
+
+			if budget, _ := transport.Budget(); budget.Requests != 2 || budget.Retries != 1 {
+				t.Errorf("Expected 2 requests and 1 retry, got %+v", budget)
+			}
+		})
+	}
+}
+
+func TestRateLimitTransport_NoRetry(t *testing.T) {
+	tests := []struct {
+		name       string
+		statusCode int
+		headers    map[string]string
+		body       string
+	}{
+		{"success", http.StatusOK, nil, "{}"},
+		{"not found", http.StatusNotFound, nil, `{"message": "Not Found"}`},
+		{"forbidden", http.StatusForbidden, nil, `{"message": "Resource not accessible by integration"}`},
+		{"reset too far away", http.StatusForbidden, rateLimitHeaders(0, testNow.Add(time.Hour)), `{"message": "API rate limit exceeded"}`},
+		{"retry after too long", http.StatusTooManyRequests, map[string]string{"Retry-After": "3600"}, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			transport, delays, calls := newTestTransport(newResponse(tt.statusCode, tt.headers, tt.body))
+
+			// Act
+			resp := sendRequest(t, transport)
+
+			// Assert
+			if resp.StatusCode != tt.statusCode {
+				t.Errorf("Expected status %d, got %d", tt.statusCode, resp.StatusCode)
+			}
+
+			if *calls != 1 || len(*delays) != 0 {
+				t.Errorf("Expected a single call without delays, got %d calls and delays %v", *calls, *delays)
+			}
+
+			// The body must still be readable after it was checked for a secondary rate limit
+			if body, _ := io.ReadAll(resp.Body); string(body) != tt.body {
+				t.Errorf("Expected the body %q, got %q", tt.body, string(body))
+			}
+		})
+	}
+}
+
+func TestRateLimitTransport_GivesUp(t *testing.T) {
+	// Arrange
+	transport, delays, calls := newTestTransport(newResponse(http.StatusServiceUnavailable, nil, ""))
+
+	// Act
+	resp := sendRequest(t, transport)
+
+	// Assert
+	if resp.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("Expected the last response to be returned, got status %d", resp.StatusCode)
+	}
+
+	if *calls != MaxRetries+1 {
+		t.Errorf("Expected %d calls, got %d", MaxRetries+1, *calls)
+	}
+
+	// Each delay is at least double the previous one
+	for i := 1; i < len(*delays); i++ {
+		if (*delays)[i] < RetryBaseDelay<<i {
+			t.Errorf("Expected delay %d to be at least %v, got %v", i, RetryBaseDelay<<i, (*delays)[i])
+		}
+	}
+}
+
+func TestRateLimitTransport_WaitsForReset(t *testing.T) {
+	// Arrange - the first response uses up the budget
+	reset := testNow.Add(20 * time.Second)
+	transport, delays, _ := newTestTransport(
+		newResponse(http.StatusOK, rateLimitHeaders(0, reset), "{}"),
+		newResponse(http.StatusOK, rateLimitHeaders(4999, reset.Add(time.Hour)), "{}"))
+
+	// Act
+	sendRequest(t, transport)
+	sendRequest(t, transport)
+
+	// Assert
+	if len(*delays) != 1 || (*delays)[0] != 20*time.Second {
+		t.Errorf("Expected to wait 20s for the reset, got %v", *delays)
+	}
+
+	if budget, _ := transport.Budget(); budget.Remaining != 4999 {
+		t.Errorf("Expected the budget of the new window, got %+v", budget)
+	}
+}
+
+func TestRateLimitTransport_OutOfOrderResponses(t *testing.T) {
+	// Arrange
+	reset := testNow.Add(time.Hour)
+	transport := NewRateLimitTransport(nil)
+
+	// Act
+	transport.updateBudget(headerFromMap(rateLimitHeaders(100, reset)))
+	transport.updateBudget(headerFromMap(rateLimitHeaders(120, reset)))
+	transport.updateBudget(headerFromMap(rateLimitHeaders(4000, reset.Add(-time.Hour))))
+	transport.updateBudget(headerFromMap(map[string]string{
+		"X-RateLimit-Limit":     "30",
+		"X-RateLimit-Remaining": "1",
+		"X-RateLimit-Reset":     strconv.FormatInt(reset.Unix(), 10),
+		"X-RateLimit-Resource":  "search",
+	}))
+
+	// Assert
+	if budget, _ := transport.Budget(); budget.Remaining != 100 || budget.Limit != 5000 {
+		t.Errorf("Expected the lowest core budget to be kept, got %+v", budget)
+	}
+}
+
+func TestRateLimitTransport_RetriesBody(t *testing.T) {
+	// Arrange
+	bodies := []string{}
+	transport, _, _ := newTestTransport(
+		func(req *http.Request) (*http.Response, error) {
+			body, _ := io.ReadAll(req.Body)
+			bodies = append(bodies, string(body))
+			return newResponse(http.StatusTooManyRequests, map[string]string{"Retry-After": "1"}, "")(req)
+		},
+		func(req *http.Request) (*http.Response, error) {
+			body, _ := io.ReadAll(req.Body)
+			bodies = append(bodies, string(body))
+			return newResponse(http.StatusCreated, nil, "")(req)
+		})
+
+	req, _ := http.NewRequest(http.MethodPost, "https://api.github.com/repos/owner/repo/pulls", strings.NewReader(`{"title": "test"}`))
+
+	// Act
+	resp, err := transport.RoundTrip(req)
+
+	// Assert
+	if err != nil || resp.StatusCode != http.StatusCreated {
+		t.Fatalf("Expected the retry to succeed, got %v %v", resp, err)
+	}
+
+	if len(bodies) != 2 || bodies[0] != bodies[1] {
+		t.Errorf("Expected the body to be sent twice, got %v", bodies)
+	}
+}
+
+func TestRateLimitTransport_NoRetryNonIdempotent(t *testing.T) {
+	tests := []struct {
+		name     string
+		method   string
+		response func(req *http.Request) (*http.Response, error)
+	}{
+		{"post server error", http.MethodPost, newResponse(http.StatusBadGateway, nil, "")},
+		{"patch server error", http.MethodPatch, newResponse(http.StatusServiceUnavailable, nil, "")},
+		{"put server error", http.MethodPut, newResponse(http.StatusGatewayTimeout, nil, "")},
+		{"post transport error", http.MethodPost, func(req *http.Request) (*http.Response, error) {
+			return nil, io.ErrUnexpectedEOF
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			transport, delays, calls := newTestTransport(tt.response, newResponse(http.StatusCreated, nil, ""))
+			req, _ := http.NewRequest(tt.method, "https://api.github.com/repos/owner/repo/pulls", strings.NewReader(`{"title": "test"}`))
+
+			// Act
+			resp, err := transport.RoundTrip(req)
+
+			// Assert
+			if err == nil && resp.StatusCode == http.StatusCreated {
+				t.Error("Expected the failed response to be returned")
+			}
+
+			if *calls != 1 || len(*delays) != 0 {
+				t.Errorf("Expected a single call without delays, got %d calls and delays %v", *calls, *delays)
+			}
+		})
+	}
+}
+
+func TestRateLimitTransport_Cancelled(t *testing.T) {
+	// Arrange
+	transport, _, calls := newTestTransport(newResponse(http.StatusBadGateway, nil, ""))
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, "https://api.github.com/repos/owner/repo", nil)
+
+	// Act
+	_, err := transport.RoundTrip(req)
+
+	// Assert
+	if err == nil {
+		t.Error("Expected an error for a cancelled request")
+	}
+
+	if *calls > 1 {
+		t.Errorf("Expected a cancelled request not to be retried, got %d calls", *calls)
+	}
+}
+
+func TestRateLimitTransport_BoundedConcurrency(t *testing.T) {
+	// Arrange
+	var inFlight, maxInFlight atomic.Int32
+	transport := NewRateLimitTransport(roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		current := inFlight.Add(1)
+		defer inFlight.Add(-1)
+
+		for {
+			observed := maxInFlight.Load()
+			if current <= observed || maxInFlight.CompareAndSwap(observed, current) {
+				break
+			}
+		}
+
+		time.Sleep(5 * time.Millisecond)
+		return newResponse(http.StatusOK, nil, "{}")(req)
+	}))
+
+	// Act
+	var wg sync.WaitGroup
+	for i := 0; i < MaxConcurrentRequests*4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			sendRequest(t, transport)
+		}()
+	}
+	wg.Wait()
+
+	// Assert
+	if maxInFlight.Load() > MaxConcurrentRequests {
+		t.Errorf("Expected at most %d concurrent requests, got %d", MaxConcurrentRequests, maxInFlight.Load())
+	}
+
+	if budget, _ := transport.Budget(); budget.Requests != MaxConcurrentRequests*4 {
+		t.Errorf("Expected %d requests, got %d", MaxConcurrentRequests*4, budget.Requests)
+	}
+}
+
+func TestRateLimitTransport_ReleasesSlotWhileWaiting(t *testing.T) {
+	// Arrange
+	transport, _, _ := newTestTransport(
+		newResponse(http.StatusTooManyRequests, map[string]string{"Retry-After": "1"}, ""),
+		newResponse(http.StatusOK, nil, "{}"))
+
+	slotsHeld := []int{}
+	transport.sleep = func(ctx context.Context, duration time.Duration) error {
+		slotsHeld = append(slotsHeld, len(transport.slots))
+		return ctx.Err()
+	}
+
+	// Act
+	resp := sendRequest(t, transport)
+
+	// Assert
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("Expected the retry to succeed, got %d", resp.StatusCode)
+	}
+
+	if len(slotsHeld) != 1 || slotsHeld[0] != 0 {
+		t.Errorf("Expected no slots to be held while waiting to retry, got %v", slotsHeld)
+	}
+
+	if len(transport.slots) != 0 {
+		t.Errorf("Expected the slot to be released, got %d held", len(transport.slots))
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected time.Duration
+		ok       bool
+	}{
+		{"", 0, false},
+		{"60", time.Minute, true},
+		{"-5", 0, true},
+		{testNow.Add(time.Minute).Format(http.TimeFormat), time.Minute, true},
+		{testNow.Add(-time.Minute).Format(http.TimeFormat), 0, true},
+		{"soon", 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			result, ok := parseRetryAfter(tt.value, testNow)
+			if result != tt.expected || ok != tt.ok {
+				t.Errorf("parseRetryAfter(%q) = %v, %v, expected %v, %v", tt.value, result, ok, tt.expected, tt.ok)
+			}
+		})
+	}
+}
+
+func headerFromMap(headers map[string]string) http.Header {
+	header := http.Header{}
+	for key, value := range headers {
+		header.Set(key, value)
+	}
+	return header
+}
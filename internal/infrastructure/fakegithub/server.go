@@ -0,0 +1,617 @@
+package fakegithub
+
+import (
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"maps"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/google/go-github/v57/github"
+)
+
+// Server is a local HTTP server that fakes the parts of the GitHub API used to read workflows and open pull
+// requests. It keeps the repositories, branches, commits, and pull requests in memory. It is used as a fixture
+// to exercise the code that changes repositories without a GitHub API.
+type Server struct {
+	server *httptest.Server
+	mutex  sync.Mutex
+	repos  map[string]*repository
+}
+
+// PullRequest is a pull request opened in a fake repository.
+type PullRequest struct {
+	Number int
+	Title  string
+	Body   string
+	// Head is the branch the pull request merges from.
+	Head string
+	// Base is the branch the pull request merges into.
+	Base string
+	// State is "open" or "closed".
+	State string
+}
+
+type repository struct {
+	owner         string
+	defaultBranch string
+	// branches maps the branch names to the SHA of their commit.
+	branches map[string]string
+	commits  map[string]commit
+	// trees maps the SHA of a tree to the files in it, by path.
+	trees        map[string]map[string]string
+	pullRequests []*PullRequest
+}
+
+type commit struct {
+	message string
+	tree    string
+	parents []string
+}
+
+// NewServer starts a fake GitHub API. Close must be called when it is no longer used.
+func NewServer() *Server {
+	s := &Server{repos: map[string]*repository{}}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /repos/{owner}/{repo}", s.getRepository)
+	mux.HandleFunc("GET /repos/{owner}/{repo}/contents/{path...}", s.getContents)
+	mux.HandleFunc("GET /repos/{owner}/{repo}/commits", s.listEmpty)
+	mux.HandleFunc("GET /repos/{owner}/{repo}/tags", s.listEmpty)
+	mux.HandleFunc("GET /repos/{owner}/{repo}/security-advisories", s.listEmpty)
+	mux.HandleFunc("GET /repos/{owner}/{repo}/git/ref/{ref...}", s.getRef)
+	mux.HandleFunc("POST /repos/{owner}/{repo}/git/refs", s.createRef)
+	mux.HandleFunc("PATCH /repos/{owner}/{repo}/git/refs/{ref...}", s.updateRef)
+	mux.HandleFunc("GET /repos/{owner}/{repo}/git/commits/{sha}", s.getCommit)
+	mux.HandleFunc("POST /repos/{owner}/{repo}/git/commits", s.createCommit)
+	mux.HandleFunc("POST /repos/{owner}/{repo}/git/trees", s.createTree)
+	mux.HandleFunc("GET /repos/{owner}/{repo}/pulls", s.listPullRequests)
+	mux.HandleFunc("POST /repos/{owner}/{repo}/pulls", s.createPullRequest)
+	mux.HandleFunc("PATCH /repos/{owner}/{repo}/pulls/{number}", s.editPullRequest)
+
+	s.server = httptest.NewServer(mux)
+
+	return s
+}
+
+// Close shuts down the server.
+func (s *Server) Close() {
+	s.server.Close()
+}
+
+// Client returns a GitHub client that sends its requests to the server.
+func (s *Server) Client() *github.Client {
+	client := github.NewClient(s.server.Client())
+	client.BaseURL, _ = url.Parse(s.server.URL + "/")
+	return client
+}
+
+// AddRepo adds a repository whose "main" branch has a single commit with the files, by path, and returns the
+// server to allow chaining.
+func (s *Server) AddRepo(repo string, files map[string]string) *Server {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	owner, _, _ := strings.Cut(repo, "/")
+	r := &repository{
+		owner:         owner,
+		defaultBranch: "main",
+		branches:      map[string]string{},
+		commits:       map[string]commit{},
+		trees:         map[string]map[string]string{},
+	}
+
+	tree := r.addTree(maps.Clone(files))
+	r.branches["main"] = r.addCommit(commit{message: "Initial commit", tree: tree})
+	s.repos[strings.ToLower(repo)] = r
+
+	return s
+}
+
+// GetFiles returns the files, by path, in a branch of a repository, or nil if the branch doesn't exist.
+func (s *Server) GetFiles(repo string, branch string) map[string]string {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	r, ok := s.repos[strings.ToLower(repo)]
+	if !ok {
+		return nil
+	}
+
+	sha, ok := r.branches[branch]
+	if !ok {
+		return nil
+	}
+
+	return maps.Clone(r.trees[r.commits[sha].tree])
+}
+
+// GetBranch returns the SHA of the commit of a branch of a repository, or an empty string if it doesn't exist.
+func (s *Server) GetBranch(repo string, branch string) string {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if r, ok := s.repos[strings.ToLower(repo)]; ok {
+		return r.branches[branch]
+	}
+
+	return ""
+}
+
+// GetCommitCount returns the number of commits in the history of a branch of a repository.
+func (s *Server) GetCommitCount(repo string, branch string) int {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	r, ok := s.repos[strings.ToLower(repo)]
+	if !ok {
+		return 0
+	}
+
+	sha, ok := r.branches[branch]
+	if !ok {
+		return 0
+	}
+
+	count := 1
+	for parents := r.commits[sha].parents; len(parents) != 0; parents = r.commits[parents[0]].parents {
+		count++
+	}
+
+	return count
+}
+
+// GetPullRequests returns copies of the pull requests opened in a repository.
+func (s *Server) GetPullRequests(repo string) []PullRequest {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	r, ok := s.repos[strings.ToLower(repo)]
+	if !ok {
+		return nil
+	}
+
+	pullRequests := []PullRequest{}
+	for _, pullRequest := range r.pullRequests {
+		pullRequests = append(pullRequests, *pullRequest)
+	}
+
+	return pullRequests
+}
+
+// ClosePullRequest closes a pull request, like when it is merged or rejected.
+func (s *Server) ClosePullRequest(repo string, number int) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if r, ok := s.repos[strings.ToLower(repo)]; ok && number > 0 && number <= len(r.pullRequests) {
+		r.pullRequests[number-1].State = "closed"
+	}
+}
+
+// addTree stores the files and returns the SHA of the tree. Trees with the same files have the same SHA.
+func (r *repository) addTree(files map[string]string) string {
+	hash := sha1.New()
+	for _, filePath := range slices.Sorted(maps.Keys(files)) {
+		hash.Write([]byte(filePath + "\x00" + files[filePath] + "\x00"))
+	}
+
+	sha := hex.EncodeToString(hash.Sum(nil))
+	r.trees[sha] = files
+
+	return sha
+}
+
+// addCommit stores the commit and returns its SHA.
+func (r *repository) addCommit(c commit) string {
+	hash := sha1.New()
+	hash.Write([]byte(strconv.Itoa(len(r.commits)) + "\x00" + c.message + "\x00" + c.tree + "\x00" + strings.Join(c.parents, ",")))
+
+	sha := hex.EncodeToString(hash.Sum(nil))
+	r.commits[sha] = c
+
+	return sha
+}
+
+// lockRepo locks the server and returns the repository in the request path. A 404 response is written, and
+// the server is unlocked, if the repository doesn't exist.
+func (s *Server) lockRepo(w http.ResponseWriter, req *http.Request) (*repository, bool) {
+	s.mutex.Lock()
+
+	r, ok := s.repos[strings.ToLower(req.PathValue("owner")+"/"+req.PathValue("repo"))]
+	if !ok {
+		s.mutex.Unlock()
+		writeError(w, http.StatusNotFound, "Not Found")
+		return nil, false
+	}
+
+	return r, true
+}
+
+func (s *Server) getRepository(w http.ResponseWriter, req *http.Request) {
+	r, ok := s.lockRepo(w, req)
+	if !ok {
+		return
+	}
+	defer s.mutex.Unlock()
+
+	writeJSON(w, http.StatusOK, map[string]any{
+		"name":           req.PathValue("repo"),
+		"full_name":      req.PathValue("owner") + "/" + req.PathValue("repo"),
+		"default_branch": r.defaultBranch,
+	})
+}
+
+// getContents returns a file, or the files in a directory, from the default branch.
+func (s *Server) getContents(w http.ResponseWriter, req *http.Request) {
+	r, ok := s.lockRepo(w, req)
+	if !ok {
+		return
+	}
+	defer s.mutex.Unlock()
+
+	files := r.trees[r.commits[r.branches[r.defaultBranch]].tree]
+	contentPath := strings.Trim(req.PathValue("path"), "/")
+
+	if content, ok := files[contentPath]; ok {
+		writeJSON(w, http.StatusOK, map[string]any{
+			"type":     "file",
+			"name":     path.Base(contentPath),
+			"path":     contentPath,
+			"encoding": "base64",
+			"content":  base64.StdEncoding.EncodeToString([]byte(content)),
+		})
+		return
+	}
+
+	entries := []map[string]any{}
+	for _, filePath := range slices.Sorted(maps.Keys(files)) {
+		if path.Dir(filePath) == contentPath {
+			entries = append(entries, map[string]any{"type": "file", "name": path.Base(filePath), "path": filePath})
+		}
+	}
+
+	if len(entries) == 0 {
+		writeError(w, http.StatusNotFound, "Not Found")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, entries)
+}
+
+func (s *Server) listEmpty(w http.ResponseWriter, req *http.Request) {
+	if _, ok := s.lockRepo(w, req); !ok {
+		return
+	}
+	defer s.mutex.Unlock()
+
+	writeJSON(w, http.StatusOK, []any{})
+}
+
+func (s *Server) getRef(w http.ResponseWriter, req *http.Request) {
+	r, ok := s.lockRepo(w, req)
+	if !ok {
+		return
+	}
+	defer s.mutex.Unlock()
+
+	branch := strings.TrimPrefix(req.PathValue("ref"), "heads/")
+	sha, ok := r.branches[branch]
+	if !ok {
+		writeError(w, http.StatusNotFound, "Not Found")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, newRef(branch, sha))
+}
+
+func (s *Server) createRef(w http.ResponseWriter, req *http.Request) {
+	r, ok := s.lockRepo(w, req)
+	if !ok {
+		return
+	}
+	defer s.mutex.Unlock()
+
+	var body struct {
+		Ref string `json:"ref"`
+		SHA string `json:"sha"`
+	}
+	if !readJSON(w, req, &body) {
+		return
+	}
+
+	branch := strings.TrimPrefix(body.Ref, "refs/heads/")
+	if _, ok := r.branches[branch]; ok {
+		writeError(w, http.StatusUnprocessableEntity, "Reference already exists")
+		return
+	}
+
+	if _, ok := r.commits[body.SHA]; !ok {
+		writeError(w, http.StatusUnprocessableEntity, "Object does not exist")
+		return
+	}
+
+	r.branches[branch] = body.SHA
+	writeJSON(w, http.StatusCreated, newRef(branch, body.SHA))
+}
+
+func (s *Server) updateRef(w http.ResponseWriter, req *http.Request) {
+	r, ok := s.lockRepo(w, req)
+	if !ok {
+		return
+	}
+	defer s.mutex.Unlock()
+
+	var body struct {
+		SHA   string `json:"sha"`
+		Force bool   `json:"force"`
+	}
+	if !readJSON(w, req, &body) {
+		return
+	}
+
+	branch := strings.TrimPrefix(req.PathValue("ref"), "heads/")
+	current, ok := r.branches[branch]
+	if !ok {
+		writeError(w, http.StatusUnprocessableEntity, "Reference does not exist")
+		return
+	}
+
+	if _, ok := r.commits[body.SHA]; !ok {
+		writeError(w, http.StatusUnprocessableEntity, "Object does not exist")
+		return
+	}
+
+	if !body.Force && !slices.Contains(r.commits[body.SHA].parents, current) {
+		writeError(w, http.StatusUnprocessableEntity, "Update is not a fast forward")
+		return
+	}
+
+	r.branches[branch] = body.SHA
+	writeJSON(w, http.StatusOK, newRef(branch, body.SHA))
+}
+
+func (s *Server) getCommit(w http.ResponseWriter, req *http.Request) {
+	r, ok := s.lockRepo(w, req)
+	if !ok {
+		return
+	}
+	defer s.mutex.Unlock()
+
+	c, ok := r.commits[req.PathValue("sha")]
+	if !ok {
+		writeError(w, http.StatusNotFound, "Not Found")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, newCommit(req.PathValue("sha"), c))
+}
+
+func (s *Server) createCommit(w http.ResponseWriter, req *http.Request) {
+	r, ok := s.lockRepo(w, req)
+	if !ok {
+		return
+	}
+	defer s.mutex.Unlock()
+
+	var body struct {
+		Message string   `json:"message"`
+		Tree    string   `json:"tree"`
+		Parents []string `json:"parents"`
+	}
+	if !readJSON(w, req, &body) {
+		return
+	}
+
+	if _, ok := r.trees[body.Tree]; !ok {
+		writeError(w, http.StatusUnprocessableEntity, "Tree does not exist")
+		return
+	}
+
+	c := commit{message: body.Message, tree: body.Tree, parents: body.Parents}
+	writeJSON(w, http.StatusCreated, newCommit(r.addCommit(c), c))
+}
+
+func (s *Server) createTree(w http.ResponseWriter, req *http.Request) {
+	r, ok := s.lockRepo(w, req)
+	if !ok {
+		return
+	}
+	defer s.mutex.Unlock()
+
+	var body struct {
+		BaseTree string `json:"base_tree"`
+		Tree     []struct {
+			Path    string  `json:"path"`
+			Content *string `json:"content"`
+		} `json:"tree"`
+	}
+	if !readJSON(w, req, &body) {
+		return
+	}
+
+	files := maps.Clone(r.trees[body.BaseTree])
+	if files == nil {
+		files = map[string]string{}
+	}
+
+	for _, entry := range body.Tree {
+		if entry.Content == nil {
+			delete(files, entry.Path)
+		} else {
+			files[entry.Path] = *entry.Content
+		}
+	}
+
+	writeJSON(w, http.StatusCreated, map[string]any{"sha": r.addTree(files)})
+}
+
+func (s *Server) listPullRequests(w http.ResponseWriter, req *http.Request) {
+	r, ok := s.lockRepo(w, req)
+	if !ok {
+		return
+	}
+	defer s.mutex.Unlock()
+
+	query := req.URL.Query()
+	state := query.Get("state")
+	if state == "" {
+		state = "open"
+	}
+
+	result := []map[string]any{}
+	for _, pullRequest := range r.pullRequests {
+		if state != "all" && pullRequest.State != state {
+			continue
+		}
+
+		if head := query.Get("head"); head != "" && head != r.owner+":"+pullRequest.Head {
+			continue
+		}
+
+		if base := query.Get("base"); base != "" && base != pullRequest.Base {
+			continue
+		}
+
+		result = append(result, s.newPullRequest(req, pullRequest))
+	}
+
+	writeJSON(w, http.StatusOK, result)
+}
+
+func (s *Server) createPullRequest(w http.ResponseWriter, req *http.Request) {
+	r, ok := s.lockRepo(w, req)
+	if !ok {
+		return
+	}
+	defer s.mutex.Unlock()
+
+	var body struct {
+		Title string `json:"title"`
+		Head  string `json:"head"`
+		Base  string `json:"base"`
+		Body  string `json:"body"`
+	}
+	if !readJSON(w, req, &body) {
+		return
+	}
+
+	head := strings.TrimPrefix(body.Head, r.owner+":")
+	if _, ok := r.branches[head]; !ok {
+		writeError(w, http.StatusUnprocessableEntity, "Head branch does not exist")
+		return
+	}
+
+	for _, pullRequest := range r.pullRequests {
+		if pullRequest.State == "open" && pullRequest.Head == head && pullRequest.Base == body.Base {
+			writeError(w, http.StatusUnprocessableEntity, "A pull request already exists for "+r.owner+":"+head)
+			return
+		}
+	}
+
+	pullRequest := &PullRequest{
+		Number: len(r.pullRequests) + 1,
+		Title:  body.Title,
+		Body:   body.Body,
+		Head:   head,
+		Base:   body.Base,
+		State:  "open",
+	}
+	r.pullRequests = append(r.pullRequests, pullRequest)
+
+	writeJSON(w, http.StatusCreated, s.newPullRequest(req, pullRequest))
+}
+
+func (s *Server) editPullRequest(w http.ResponseWriter, req *http.Request) {
+	r, ok := s.lockRepo(w, req)
+	if !ok {
+		return
+	}
+	defer s.mutex.Unlock()
+
+	number, err := strconv.Atoi(req.PathValue("number"))
+	if err != nil || number <= 0 || number > len(r.pullRequests) {
+		writeError(w, http.StatusNotFound, "Not Found")
+		return
+	}
+
+	var body struct {
+		Title *string `json:"title"`
+		Body  *string `json:"body"`
+	}
+	if !readJSON(w, req, &body) {
+		return
+	}
+
+	pullRequest := r.pullRequests[number-1]
+	if body.Title != nil {
+		pullRequest.Title = *body.Title
+	}
+	if body.Body != nil {
+		pullRequest.Body = *body.Body
+	}
+
+	writeJSON(w, http.StatusOK, s.newPullRequest(req, pullRequest))
+}
+
+func (s *Server) newPullRequest(req *http.Request, pullRequest *PullRequest) map[string]any {
+	repo := req.PathValue("owner") + "/" + req.PathValue("repo")
+
+	return map[string]any{
+		"number":   pullRequest.Number,
+		"title":    pullRequest.Title,
+		"body":     pullRequest.Body,
+		"state":    pullRequest.State,
+		"html_url": s.server.URL + "/" + repo + "/pull/" + strconv.Itoa(pullRequest.Number),
+		"head":     map[string]any{"ref": pullRequest.Head},
+		"base":     map[string]any{"ref": pullRequest.Base},
+	}
+}
+
+func newRef(branch string, sha string) map[string]any {
+	return map[string]any{
+		"ref":    "refs/heads/" + branch,
+		"object": map[string]any{"type": "commit", "sha": sha},
+	}
+}
+
+func newCommit(sha string, c commit) map[string]any {
+	parents := []map[string]any{}
+	for _, parent := range c.parents {
+		parents = append(parents, map[string]any{"sha": parent})
+	}
+
+	return map[string]any{
+		"sha":     sha,
+		"message": c.message,
+		"tree":    map[string]any{"sha": c.tree},
+		"parents": parents,
+	}
+}
+
+func readJSON(w http.ResponseWriter, req *http.Request, body any) bool {
+	if err := json.NewDecoder(req.Body).Decode(body); err != nil {
+		writeError(w, http.StatusBadRequest, "Problems parsing JSON")
+		return false
+	}
+
+	return true
+}
+
+func writeJSON(w http.ResponseWriter, status int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string]any{"message": message})
+}
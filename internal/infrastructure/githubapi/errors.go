@@ -0,0 +1,50 @@
+package githubapi
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/OctopusSolutionsEngineering/DuplicationCostCalculator/internal/domain/models"
+	"github.com/OctopusSolutionsEngineering/DuplicationCostCalculator/internal/domain/parsing"
+	"github.com/google/go-github/v57/github"
+)
+
+// ErrNoClient is returned when a GitHub client has not been supplied.
+var ErrNoClient = errors.New("no GitHub client")
+
+// ClassifyError returns the category of an error returned by the GitHub API.
+func ClassifyError(err error) string {
+	var rateLimitError *github.RateLimitError
+	var abuseRateLimitError *github.AbuseRateLimitError
+	var errorResponse *github.ErrorResponse
+
+	switch {
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		return models.ErrorCategoryCancelled
+	case errors.Is(err, parsing.ErrInvalidRepository):
+		return models.ErrorCategoryInvalidRepository
+	case errors.As(err, &rateLimitError), errors.As(err, &abuseRateLimitError):
+		return models.ErrorCategoryRateLimited
+	case errors.As(err, &errorResponse) && errorResponse.Response != nil:
+		switch errorResponse.Response.StatusCode {
+		case http.StatusNotFound:
+			return models.ErrorCategoryNotFound
+		case http.StatusUnauthorized, http.StatusForbidden:
+			return models.ErrorCategoryForbidden
+		case http.StatusTooManyRequests:
+			return models.ErrorCategoryRateLimited
+		}
+	}
+
+	return models.ErrorCategoryUnknown
+}
+
+// NewRepoError captures an error returned while loading a repository for the report.
+func NewRepoError(operation string, err error) models.RepoError {
+	return models.RepoError{
+		Category:  ClassifyError(err),
+		Operation: operation,
+		Message:   err.Error(),
+	}
+}
@@ -0,0 +1,176 @@
+package githubapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/OctopusSolutionsEngineering/DuplicationCostCalculator/internal/domain/models"
+	"github.com/OctopusSolutionsEngineering/DuplicationCostCalculator/internal/domain/parsing"
+	"github.com/google/go-github/v57/github"
+	"github.com/migueleliasweb/go-github-mock/src/mock"
+)
+
+func newErrorResponse(statusCode int) error {
+	return &github.ErrorResponse{Response: &http.Response{StatusCode: statusCode}, Message: http.StatusText(statusCode)}
+}
+
+func TestClassifyError(t *testing.T) {
+	_, _, invalidRepoErr := parsing.SplitRepo("invalid")
+
+	tests := []struct {
+		name     string
+		err      error
+		expected string
+	}{
+		{"not found", newErrorResponse(http.StatusNotFound), models.ErrorCategoryNotFound},
+		{"forbidden", newErrorResponse(http.StatusForbidden), models.ErrorCategoryForbidden},
+		{"unauthorized", newErrorResponse(http.StatusUnauthorized), models.ErrorCategoryForbidden},
+		{"too many requests", newErrorResponse(http.StatusTooManyRequests), models.ErrorCategoryRateLimited},
+		{"rate limit", &github.RateLimitError{Response: &http.Response{StatusCode: http.StatusForbidden}}, models.ErrorCategoryRateLimited},
+		{"secondary rate limit", &github.AbuseRateLimitError{Response: &http.Response{StatusCode: http.StatusForbidden}}, models.ErrorCategoryRateLimited},
+		{"wrapped", fmt.Errorf("loading: %w", newErrorResponse(http.StatusNotFound)), models.ErrorCategoryNotFound},
+		{"invalid repository", invalidRepoErr, models.ErrorCategoryInvalidRepository},
+		{"server error", newErrorResponse(http.StatusInternalServerError), models.ErrorCategoryUnknown},
+		{"other error", errors.New("connection reset"), models.ErrorCategoryUnknown},
+		{"cancelled", context.Canceled, models.ErrorCategoryCancelled},
+		{"timed out", &url.Error{Op: "Get", URL: "https://api.github.com", Err: context.DeadlineExceeded}, models.ErrorCategoryCancelled},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if result := ClassifyError(tt.err); result != tt.expected {
+				t.Errorf("ClassifyError(%v) = %q, expected %q", tt.err, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestFindWorkflowsWithErr_NoWorkflowsDirectory(t *testing.T) {
+	// Arrange - the repository exists, but has no workflows directory
+	mockedHTTPClient := mock.NewMockedHTTPClient(
+		mock.WithRequestMatchHandler(
+			mock.GetReposContentsByOwnerByRepoByPath,
+			http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				mock.WriteError(w, http.StatusNotFound, "Not Found")
+			}),
+		),
+		mock.WithRequestMatch(
+			mock.GetReposByOwnerByRepo,
+			github.Repository{FullName: github.String("owner/repo")},
+		),
+	)
+
+	// Act
+	workflows, err := FindWorkflowsWithErr(context.Background(), github.NewClient(mockedHTTPClient), "owner/repo")
+
+	// Assert
+	if err != nil {
+		t.Errorf("Expected no error for a repository without workflows, got %v", err)
+	}
+
+	if workflows == nil || len(workflows) != 0 {
+		t.Errorf("Expected an empty slice, got %v", workflows)
+	}
+}
+
+func TestFindWorkflowsWithErr_RepoNotFound(t *testing.T) {
+	// Arrange
+	notFound := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mock.WriteError(w, http.StatusNotFound, "Not Found")
+	})
+
+	mockedHTTPClient := mock.NewMockedHTTPClient(
+		mock.WithRequestMatchHandler(mock.GetReposContentsByOwnerByRepoByPath, notFound),
+		mock.WithRequestMatchHandler(mock.GetReposByOwnerByRepo, notFound),
+	)
+
+	// Act
+	_, err := FindWorkflowsWithErr(context.Background(), github.NewClient(mockedHTTPClient), "owner/typo")
+
+	// Assert
+	if ClassifyError(err) != models.ErrorCategoryNotFound {
+		t.Errorf("Expected a not found error, got %v", err)
+	}
+}
+
+func TestFindWorkflowsWithErr_Cancelled(t *testing.T) {
+	// Arrange
+	requests := 0
+	mockedHTTPClient := mock.NewMockedHTTPClient(
+		mock.WithRequestMatchHandler(
+			mock.GetReposContentsByOwnerByRepoByPath,
+			http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				requests++
+				w.Write(mock.MustMarshal([]github.RepositoryContent{}))
+			}),
+		),
+	)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	// Act
+	_, err := FindWorkflowsWithErr(ctx, github.NewClient(mockedHTTPClient), "owner/repo")
+
+	// Assert
+	if ClassifyError(err) != models.ErrorCategoryCancelled {
+		t.Errorf("Expected a cancelled error, got %v", err)
+	}
+
+	if requests != 0 {
+		t.Errorf("Expected no requests to be sent, got %d", requests)
+	}
+}
+
+func TestFindWorkflowsWithErr_NilClient(t *testing.T) {
+	if _, err := FindWorkflowsWithErr(context.Background(), nil, "owner/repo"); !errors.Is(err, ErrNoClient) {
+		t.Errorf("Expected ErrNoClient, got %v", err)
+	}
+}
+
+func TestWorkflowSource_GetErrors(t *testing.T) {
+	// Arrange
+	mockedHTTPClient := mock.NewMockedHTTPClient(
+		mock.WithRequestMatchHandler(
+			mock.GetReposContentsByOwnerByRepoByPath,
+			http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				mock.WriteError(w, http.StatusForbidden, "Resource not accessible by integration")
+			}),
+		),
+		mock.WithRequestMatchHandler(
+			mock.GetReposSecurityAdvisoriesByOwnerByRepo,
+			http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				mock.WriteError(w, http.StatusForbidden, "Resource not accessible by integration")
+			}),
+		),
+	)
+
+	source := NewWorkflowSource(github.NewClient(mockedHTTPClient))
+
+	// Act
+	source.FindWorkflows(context.Background(), "https://github.com/owner/repo")
+	source.GetWorkflowAdvisories(context.Background(), "owner/repo")
+	source.FindWorkflows(context.Background(), "invalid")
+
+	// Assert
+	errors := source.GetErrors("owner/repo")
+	if len(errors) != 1 {
+		t.Fatalf("Expected 1 error, got %+v", errors)
+	}
+
+	if errors[0].Category != models.ErrorCategoryForbidden || errors[0].Operation != "list workflows" || errors[0].Message == "" {
+		t.Errorf("Unexpected error %+v", errors[0])
+	}
+
+	if invalid := source.GetErrors("invalid"); len(invalid) != 1 || invalid[0].Category != models.ErrorCategoryInvalidRepository {
+		t.Errorf("Expected an invalid repository error, got %+v", invalid)
+	}
+
+	if other := source.GetErrors("owner/other"); len(other) != 0 {
+		t.Errorf("Expected no errors for another repo, got %+v", other)
+	}
+}
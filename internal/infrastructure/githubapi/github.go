@@ -2,29 +2,39 @@ package githubapi
 
 import (
 	"context"
+	"path"
 	"strings"
 
+	"github.com/OctopusSolutionsEngineering/DuplicationCostCalculator/internal/domain/models"
 	"github.com/OctopusSolutionsEngineering/DuplicationCostCalculator/internal/domain/parsing"
 	"github.com/google/go-github/v57/github"
 	"github.com/samber/lo"
 )
 
-// FindWorkflows loads all the GitHub Actions workflows for a given repository.
-// jwt is the JSON Web Token used for authentication.
-// repo is the repository in the format "owner/repo".
-func FindWorkflows(client *github.Client, repo string) []string {
-	ctx := context.Background()
+// FindWorkflowsWithErr loads the file names of the GitHub Actions workflows of a repository, in the format
+// "owner/repo", returning any error from the GitHub API. A repository that exists but has no .github/workflows
+// directory has no workflows, and is not an error.
+func FindWorkflowsWithErr(ctx context.Context, client *github.Client, repo string) ([]string, error) {
+	if client == nil {
+		return []string{}, ErrNoClient
+	}
 
 	owner, repoName, err := parsing.SplitRepo(repo)
 	if err != nil {
-		return []string{}
+		return []string{}, err
 	}
 
 	// List contents of .github/workflows directory
 	_, dirContent, _, err := client.Repositories.GetContents(ctx, owner, repoName, ".github/workflows", nil)
 	if err != nil {
-		println("Error fetching workflows for repo", repo, ":", err.Error())
-		return []string{}
+		// The workflows directory is also not found when the repository doesn't exist, so check the repository
+		if ClassifyError(err) == models.ErrorCategoryNotFound {
+			if _, _, repoErr := client.Repositories.Get(ctx, owner, repoName); repoErr == nil {
+				return []string{}, nil
+			}
+		}
+
+		return []string{}, err
 	}
 
 	files := lo.Filter(dirContent, func(item *github.RepositoryContent, index int) bool {
@@ -33,47 +43,97 @@ func FindWorkflows(client *github.Client, repo string) []string {
 
 	return lo.Map(files, func(item *github.RepositoryContent, index int) string {
 		return item.GetName()
-	})
+	}), nil
 }
 
-func WorkflowToString(client *github.Client, repo string, workflow string) string {
-	if client == nil {
+func WorkflowToString(ctx context.Context, client *github.Client, repo string, workflow string) string {
+	workflowStr, err := WorkflowToStringWithErr(ctx, client, repo, workflow)
+	if err != nil {
 		return ""
 	}
 
-	ctx := context.Background()
+	return workflowStr
+}
+
+// WorkflowToStringWithErr is WorkflowToString, returning any error from the GitHub API.
+func WorkflowToStringWithErr(ctx context.Context, client *github.Client, repo string, workflow string) (string, error) {
+	if client == nil {
+		return "", ErrNoClient
+	}
 
 	owner, repoName, err := parsing.SplitRepo(repo)
 	if err != nil {
-		return ""
+		return "", err
 	}
 
 	// Get file content
 	fileContent, _, _, err := client.Repositories.GetContents(ctx, owner, repoName, ".github/workflows/"+workflow, nil)
 	if err != nil {
-		return ""
+		return "", err
 	}
 
 	// Decode content
 	contentStr, err := fileContent.GetContent()
 	if err != nil {
-		return ""
+		return "", err
 	}
 
-	return contentStr
+	return contentStr, nil
 }
 
-func FindContributorsToWorkflow(client *github.Client, repo string, workflow string) []string {
+// ActionFileNames are the names of the files that can define an action, in the order they are searched.
+var ActionFileNames = []string{"action.yml", "action.yaml"}
+
+// ActionToString loads the action.yml or action.yaml file from a directory in a repository.
+// ref is the branch, tag, or commit to load the file from. An empty ref uses the default branch.
+func ActionToString(ctx context.Context, client *github.Client, repo string, actionPath string, ref string) string {
 	if client == nil {
+		return ""
+	}
+
+	owner, repoName, err := parsing.SplitRepo(repo)
+	if err != nil {
+		return ""
+	}
+
+	opts := &github.RepositoryContentGetOptions{Ref: ref}
+
+	for _, fileName := range ActionFileNames {
+		fileContent, _, _, err := client.Repositories.GetContents(ctx, owner, repoName, path.Join(actionPath, fileName), opts)
+		if err != nil || fileContent == nil {
+			continue
+		}
+
+		contentStr, err := fileContent.GetContent()
+		if err != nil {
+			continue
+		}
+
+		return contentStr
+	}
+
+	return ""
+}
+
+func FindContributorsToWorkflow(ctx context.Context, client *github.Client, repo string, workflow string) []string {
+	contributors, err := FindContributorsToWorkflowWithErr(ctx, client, repo, workflow)
+	if err != nil {
 		return []string{}
 	}
 
-	ctx := context.Background()
+	return contributors
+}
+
+// FindContributorsToWorkflowWithErr is FindContributorsToWorkflow, returning any error from the GitHub API.
+func FindContributorsToWorkflowWithErr(ctx context.Context, client *github.Client, repo string, workflow string) ([]string, error) {
+	if client == nil {
+		return []string{}, ErrNoClient
+	}
 
 	// Split repo into owner and name
 	owner, repoName, err := parsing.SplitRepo(repo)
 	if err != nil {
-		return []string{}
+		return []string{}, err
 	}
 
 	// Construct the workflow file path
@@ -94,7 +154,7 @@ func FindContributorsToWorkflow(client *github.Client, repo string, workflow str
 	for {
 		commits, resp, err := client.Repositories.ListCommits(ctx, owner, repoName, opts)
 		if err != nil {
-			return []string{}
+			return []string{}, err
 		}
 
 		// Extract unique contributor names
@@ -115,19 +175,69 @@ func FindContributorsToWorkflow(client *github.Client, repo string, workflow str
 		opts.Page = resp.NextPage
 	}
 
-	return contributors
+	return contributors, nil
 }
 
-func GetWorkflowAdvisories(client *github.Client, repo string) []string {
+// FindTags loads all the tags of a repository, along with the commit SHA each tag points to.
+func FindTags(ctx context.Context, client *github.Client, repo string) []models.Tag {
 	if client == nil {
+		return []models.Tag{}
+	}
+
+	owner, repoName, err := parsing.SplitRepo(repo)
+	if err != nil {
+		return []models.Tag{}
+	}
+
+	opts := &github.ListOptions{
+		PerPage: 100,
+	}
+
+	tags := []models.Tag{}
+
+	// Fetch all tags for the repository (handle pagination)
+	for {
+		repoTags, resp, err := client.Repositories.ListTags(ctx, owner, repoName, opts)
+		if err != nil {
+			return []models.Tag{}
+		}
+
+		tags = append(tags, lo.Map(repoTags, func(item *github.RepositoryTag, index int) models.Tag {
+			return models.Tag{
+				Name:   item.GetName(),
+				Commit: item.GetCommit().GetSHA(),
+			}
+		})...)
+
+		// Check if there are more pages
+		if resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+
+	return tags
+}
+
+func GetWorkflowAdvisories(ctx context.Context, client *github.Client, repo string) []string {
+	advisories, err := GetWorkflowAdvisoriesWithErr(ctx, client, repo)
+	if err != nil {
+		// If there's an error (e.g., no access, repo doesn't exist), return empty list
 		return []string{}
 	}
 
-	ctx := context.Background()
+	return advisories
+}
+
+// GetWorkflowAdvisoriesWithErr is GetWorkflowAdvisories, returning any error from the GitHub API.
+func GetWorkflowAdvisoriesWithErr(ctx context.Context, client *github.Client, repo string) ([]string, error) {
+	if client == nil {
+		return []string{}, ErrNoClient
+	}
 
 	owner, repoName, err := parsing.SplitRepo(repo)
 	if err != nil {
-		return []string{}
+		return []string{}, err
 	}
 
 	opts := &github.ListRepositorySecurityAdvisoriesOptions{
@@ -142,8 +252,7 @@ func GetWorkflowAdvisories(client *github.Client, repo string) []string {
 	for {
 		advisoryList, resp, err := client.SecurityAdvisories.ListRepositorySecurityAdvisories(ctx, owner, repoName, opts)
 		if err != nil {
-			// If there's an error (e.g., no access, repo doesn't exist), return empty list
-			return []string{}
+			return []string{}, err
 		}
 
 		// Extract advisory IDs or summaries
@@ -161,8 +270,8 @@ func GetWorkflowAdvisories(client *github.Client, repo string) []string {
 	}
 
 	if advisories == nil {
-		return []string{}
+		return []string{}, nil
 	}
 
-	return advisories
+	return advisories, nil
 }
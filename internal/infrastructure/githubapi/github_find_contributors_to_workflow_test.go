@@ -1,6 +1,7 @@
 package githubapi
 
 import (
+	"context"
 	"net/http"
 	"testing"
 
@@ -35,7 +36,7 @@ func TestFindContributorsToWorkflow_Success(t *testing.T) {
 	)
 	client := github.NewClient(mockedHTTPClient)
 	// Act
-	contributors := FindContributorsToWorkflow(client, "owner/repo", "ci.yml")
+	contributors := FindContributorsToWorkflow(context.Background(), client, "owner/repo", "ci.yml")
 	// Assert
 	if len(contributors) != 2 {
 		t.Errorf("Expected 2 contributors, got %d", len(contributors))
@@ -76,7 +77,7 @@ func TestFindContributorsToWorkflow_SingleContributor(t *testing.T) {
 	)
 	client := github.NewClient(mockedHTTPClient)
 	// Act
-	contributors := FindContributorsToWorkflow(client, "owner/repo", "deploy.yaml")
+	contributors := FindContributorsToWorkflow(context.Background(), client, "owner/repo", "deploy.yaml")
 	// Assert
 	if len(contributors) != 1 {
 		t.Errorf("Expected 1 contributor, got %d", len(contributors))
@@ -118,7 +119,7 @@ func TestFindContributorsToWorkflow_DuplicateContributors(t *testing.T) {
 	)
 	client := github.NewClient(mockedHTTPClient)
 	// Act
-	contributors := FindContributorsToWorkflow(client, "owner/repo", "test.yml")
+	contributors := FindContributorsToWorkflow(context.Background(), client, "owner/repo", "test.yml")
 	// Assert - should deduplicate to single contributor
 	if len(contributors) != 1 {
 		t.Errorf("Expected 1 unique contributor, got %d", len(contributors))
@@ -167,7 +168,7 @@ func TestFindContributorsToWorkflow_MultipleContributorsWithDuplicates(t *testin
 	)
 	client := github.NewClient(mockedHTTPClient)
 	// Act
-	contributors := FindContributorsToWorkflow(client, "owner/repo", "workflow.yml")
+	contributors := FindContributorsToWorkflow(context.Background(), client, "owner/repo", "workflow.yml")
 	// Assert - should have 2 unique contributors
 	if len(contributors) != 2 {
 		t.Errorf("Expected 2 unique contributors, got %d", len(contributors))
@@ -198,7 +199,7 @@ func TestFindContributorsToWorkflow_NoCommits(t *testing.T) {
 	)
 	client := github.NewClient(mockedHTTPClient)
 	// Act
-	contributors := FindContributorsToWorkflow(client, "owner/repo", "new-workflow.yml")
+	contributors := FindContributorsToWorkflow(context.Background(), client, "owner/repo", "new-workflow.yml")
 	// Assert
 	if len(contributors) != 0 {
 		t.Errorf("Expected 0 contributors for workflow with no commits, got %d", len(contributors))
@@ -208,7 +209,7 @@ func TestFindContributorsToWorkflow_InvalidRepoFormat(t *testing.T) {
 	// Arrange
 	client := github.NewClient(nil)
 	// Act
-	contributors := FindContributorsToWorkflow(client, "invalid-repo-format", "ci.yml")
+	contributors := FindContributorsToWorkflow(context.Background(), client, "invalid-repo-format", "ci.yml")
 	// Assert
 	if len(contributors) != 0 {
 		t.Errorf("Expected 0 contributors for invalid repo format, got %d", len(contributors))
@@ -218,7 +219,7 @@ func TestFindContributorsToWorkflow_RepoWithoutSlash(t *testing.T) {
 	// Arrange
 	client := github.NewClient(nil)
 	// Act
-	contributors := FindContributorsToWorkflow(client, "justreponame", "ci.yml")
+	contributors := FindContributorsToWorkflow(context.Background(), client, "justreponame", "ci.yml")
 	// Assert
 	if len(contributors) != 0 {
 		t.Errorf("Expected 0 contributors for repo without slash, got %d", len(contributors))
@@ -236,7 +237,7 @@ func TestFindContributorsToWorkflow_APIError(t *testing.T) {
 	)
 	client := github.NewClient(mockedHTTPClient)
 	// Act
-	contributors := FindContributorsToWorkflow(client, "owner/repo", "ci.yml")
+	contributors := FindContributorsToWorkflow(context.Background(), client, "owner/repo", "ci.yml")
 	// Assert
 	if len(contributors) != 0 {
 		t.Errorf("Expected 0 contributors when API returns error, got %d", len(contributors))
@@ -254,7 +255,7 @@ func TestFindContributorsToWorkflow_FileNotFound(t *testing.T) {
 	)
 	client := github.NewClient(mockedHTTPClient)
 	// Act
-	contributors := FindContributorsToWorkflow(client, "owner/repo", "nonexistent.yml")
+	contributors := FindContributorsToWorkflow(context.Background(), client, "owner/repo", "nonexistent.yml")
 	// Assert
 	if len(contributors) != 0 {
 		t.Errorf("Expected 0 contributors when workflow file not found, got %d", len(contributors))
@@ -272,7 +273,7 @@ func TestFindContributorsToWorkflow_Unauthorized(t *testing.T) {
 	)
 	client := github.NewClient(mockedHTTPClient)
 	// Act
-	contributors := FindContributorsToWorkflow(client, "owner/private-repo", "ci.yml")
+	contributors := FindContributorsToWorkflow(context.Background(), client, "owner/private-repo", "ci.yml")
 	// Assert
 	if len(contributors) != 0 {
 		t.Errorf("Expected 0 contributors when unauthorized, got %d", len(contributors))
@@ -305,7 +306,7 @@ func TestFindContributorsToWorkflow_CommitsWithNilAuthor(t *testing.T) {
 	)
 	client := github.NewClient(mockedHTTPClient)
 	// Act
-	contributors := FindContributorsToWorkflow(client, "owner/repo", "ci.yml")
+	contributors := FindContributorsToWorkflow(context.Background(), client, "owner/repo", "ci.yml")
 	// Assert - should only include the valid author
 	if len(contributors) != 1 {
 		t.Errorf("Expected 1 contributor (filtering out nil authors), got %d", len(contributors))
@@ -338,7 +339,7 @@ func TestFindContributorsToWorkflow_CommitsWithNilName(t *testing.T) {
 	)
 	client := github.NewClient(mockedHTTPClient)
 	// Act
-	contributors := FindContributorsToWorkflow(client, "owner/repo", "ci.yml")
+	contributors := FindContributorsToWorkflow(context.Background(), client, "owner/repo", "ci.yml")
 	// Assert - should only include the valid author
 	if len(contributors) != 1 {
 		t.Errorf("Expected 1 contributor (filtering out nil names), got %d", len(contributors))
@@ -371,7 +372,7 @@ func TestFindContributorsToWorkflow_ManyContributors(t *testing.T) {
 	)
 	client := github.NewClient(mockedHTTPClient)
 	// Act
-	contributors := FindContributorsToWorkflow(client, "owner/repo", "ci.yml")
+	contributors := FindContributorsToWorkflow(context.Background(), client, "owner/repo", "ci.yml")
 	// Assert
 	if len(contributors) != 10 {
 		t.Errorf("Expected 10 contributors, got %d", len(contributors))
@@ -403,7 +404,7 @@ func TestFindContributorsToWorkflow_YamlExtension(t *testing.T) {
 	)
 	client := github.NewClient(mockedHTTPClient)
 	// Act
-	contributors := FindContributorsToWorkflow(client, "owner/repo", "deploy.yaml")
+	contributors := FindContributorsToWorkflow(context.Background(), client, "owner/repo", "deploy.yaml")
 	// Assert
 	if len(contributors) != 1 {
 		t.Errorf("Expected 1 contributor, got %d", len(contributors))
@@ -429,7 +430,7 @@ func TestFindContributorsToWorkflow_HTTPSRepoURL(t *testing.T) {
 	)
 	client := github.NewClient(mockedHTTPClient)
 	// Act
-	contributors := FindContributorsToWorkflow(client, "https://github.com/owner/repo", "ci.yml")
+	contributors := FindContributorsToWorkflow(context.Background(), client, "https://github.com/owner/repo", "ci.yml")
 	// Assert
 	if len(contributors) != 1 {
 		t.Errorf("Expected 1 contributor, got %d", len(contributors))
@@ -469,7 +470,7 @@ func TestFindContributorsToWorkflow_ContributorWithSpecialCharacters(t *testing.
 	)
 	client := github.NewClient(mockedHTTPClient)
 	// Act
-	contributors := FindContributorsToWorkflow(client, "owner/repo", "ci.yml")
+	contributors := FindContributorsToWorkflow(context.Background(), client, "owner/repo", "ci.yml")
 	// Assert
 	if len(contributors) != 3 {
 		t.Errorf("Expected 3 contributors, got %d", len(contributors))
@@ -507,7 +508,7 @@ func TestFindContributorsToWorkflow_OrganizationRepo(t *testing.T) {
 	)
 	client := github.NewClient(mockedHTTPClient)
 	// Act
-	contributors := FindContributorsToWorkflow(client, "my-organization/my-project", "ci.yml")
+	contributors := FindContributorsToWorkflow(context.Background(), client, "my-organization/my-project", "ci.yml")
 	// Assert
 	if len(contributors) != 1 {
 		t.Errorf("Expected 1 contributor, got %d", len(contributors))
@@ -0,0 +1,69 @@
+package githubapi
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/google/go-github/v57/github"
+	"github.com/migueleliasweb/go-github-mock/src/mock"
+)
+
+func TestFindTags_Success(t *testing.T) {
+	// Arrange
+	mockedHTTPClient := mock.NewMockedHTTPClient(
+		mock.WithRequestMatch(
+			mock.GetReposTagsByOwnerByRepo,
+			[]github.RepositoryTag{
+				{Name: github.String("v4"), Commit: &github.Commit{SHA: github.String("abc123")}},
+				{Name: github.String("v4.1.0"), Commit: &github.Commit{SHA: github.String("abc123")}},
+			},
+		),
+	)
+
+	client := github.NewClient(mockedHTTPClient)
+
+	// Act
+	tags := FindTags(context.Background(), client, "actions/checkout")
+
+	// Assert
+	if len(tags) != 2 {
+		t.Fatalf("Expected 2 tags, got %d", len(tags))
+	}
+
+	if tags[0].Name != "v4" || tags[0].Commit != "abc123" {
+		t.Errorf("Unexpected first tag: %+v", tags[0])
+	}
+}
+
+func TestFindTags_APIError(t *testing.T) {
+	// Arrange
+	mockedHTTPClient := mock.NewMockedHTTPClient(
+		mock.WithRequestMatchHandler(
+			mock.GetReposTagsByOwnerByRepo,
+			http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				mock.WriteError(w, http.StatusNotFound, "Not Found")
+			}),
+		),
+	)
+
+	client := github.NewClient(mockedHTTPClient)
+
+	// Act
+	tags := FindTags(context.Background(), client, "actions/missing")
+
+	// Assert
+	if tags == nil || len(tags) != 0 {
+		t.Errorf("Expected an empty slice, got %v", tags)
+	}
+}
+
+func TestFindTags_InvalidRepoFormat(t *testing.T) {
+	if tags := FindTags(context.Background(), github.NewClient(nil), "invalid"); len(tags) != 0 {
+		t.Errorf("Expected no tags for an invalid repo, got %v", tags)
+	}
+
+	if tags := FindTags(context.Background(), nil, "actions/checkout"); len(tags) != 0 {
+		t.Errorf("Expected no tags for a nil client, got %v", tags)
+	}
+}
@@ -1,6 +1,7 @@
 package githubapi
 
 import (
+	"context"
 	"testing"
 
 	"github.com/google/go-github/v57/github"
@@ -11,7 +12,7 @@ func TestGetWorkflowAdvisories_InvalidRepoFormat(t *testing.T) {
 	client := github.NewClient(nil)
 
 	// Act
-	advisories := GetWorkflowAdvisories(client, "invalid-repo-format")
+	advisories := GetWorkflowAdvisories(context.Background(), client, "invalid-repo-format")
 
 	// Assert
 	if len(advisories) != 0 {
@@ -24,7 +25,7 @@ func TestGetWorkflowAdvisories_RepoWithoutSlash(t *testing.T) {
 	client := github.NewClient(nil)
 
 	// Act
-	advisories := GetWorkflowAdvisories(client, "justreponame")
+	advisories := GetWorkflowAdvisories(context.Background(), client, "justreponame")
 
 	// Assert
 	if len(advisories) != 0 {
@@ -37,7 +38,7 @@ func TestGetWorkflowAdvisories_EmptyRepo(t *testing.T) {
 	client := github.NewClient(nil)
 
 	// Act
-	advisories := GetWorkflowAdvisories(client, "")
+	advisories := GetWorkflowAdvisories(context.Background(), client, "")
 
 	// Assert
 	if len(advisories) != 0 {
@@ -50,7 +51,7 @@ func TestGetWorkflowAdvisories_HTTPSRepoURL(t *testing.T) {
 	client := github.NewClient(nil)
 
 	// Act
-	advisories := GetWorkflowAdvisories(client, "https://github.com/owner/repo")
+	advisories := GetWorkflowAdvisories(context.Background(), client, "https://github.com/owner/repo")
 
 	// Assert - Should return empty due to nil client causing API error
 	if len(advisories) != 0 {
@@ -63,7 +64,7 @@ func TestGetWorkflowAdvisories_RepoWithDashes(t *testing.T) {
 	client := github.NewClient(nil)
 
 	// Act
-	advisories := GetWorkflowAdvisories(client, "my-org-name/my-repo-name")
+	advisories := GetWorkflowAdvisories(context.Background(), client, "my-org-name/my-repo-name")
 
 	// Assert - Should return empty due to nil client causing API error
 	if len(advisories) != 0 {
@@ -76,7 +77,7 @@ func TestGetWorkflowAdvisories_RepoWithSpecialChars(t *testing.T) {
 	client := github.NewClient(nil)
 
 	// Act
-	advisories := GetWorkflowAdvisories(client, "owner/repo@#$%")
+	advisories := GetWorkflowAdvisories(context.Background(), client, "owner/repo@#$%")
 
 	// Assert - Should return empty due to nil client
 	if len(advisories) != 0 {
@@ -89,7 +90,7 @@ func TestGetWorkflowAdvisories_NilClient(t *testing.T) {
 	var client *github.Client = nil
 
 	// Act
-	advisories := GetWorkflowAdvisories(client, "owner/repo")
+	advisories := GetWorkflowAdvisories(context.Background(), client, "owner/repo")
 
 	// Assert - Should return empty due to nil client
 	if len(advisories) != 0 {
@@ -102,7 +103,7 @@ func TestGetWorkflowAdvisories_MultipleSlashes(t *testing.T) {
 	client := github.NewClient(nil)
 
 	// Act
-	advisories := GetWorkflowAdvisories(client, "owner/repo/extra/path")
+	advisories := GetWorkflowAdvisories(context.Background(), client, "owner/repo/extra/path")
 
 	// Assert - SplitRepo should handle this, returning empty on API error
 	if len(advisories) != 0 {
@@ -115,7 +116,7 @@ func TestGetWorkflowAdvisories_RepoWithWhitespace(t *testing.T) {
 	client := github.NewClient(nil)
 
 	// Act
-	advisories := GetWorkflowAdvisories(client, "  owner / repo  ")
+	advisories := GetWorkflowAdvisories(context.Background(), client, "  owner / repo  ")
 
 	// Assert - Should handle whitespace gracefully
 	if len(advisories) != 0 {
@@ -129,7 +130,7 @@ func TestGetWorkflowAdvisories_VeryLongRepoName(t *testing.T) {
 	longName := "owner/" + string(make([]byte, 500))
 
 	// Act
-	advisories := GetWorkflowAdvisories(client, longName)
+	advisories := GetWorkflowAdvisories(context.Background(), client, longName)
 
 	// Assert - Should handle long names without panic
 	if len(advisories) != 0 {
@@ -0,0 +1,137 @@
+package githubapi
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/OctopusSolutionsEngineering/DuplicationCostCalculator/internal/domain/models"
+	"github.com/google/go-github/v57/github"
+	"github.com/migueleliasweb/go-github-mock/src/mock"
+)
+
+func TestListOrgRepositories_Success(t *testing.T) {
+	// Arrange
+	mockedHTTPClient := mock.NewMockedHTTPClient(
+		mock.WithRequestMatch(
+			mock.GetOrgsReposByOrg,
+			[]github.Repository{
+				{
+					FullName: github.String("my-org/service-api"),
+					Name:     github.String("service-api"),
+					Topics:   []string{"platform"},
+					Language: github.String("Go"),
+				},
+				{
+					FullName: github.String("my-org/legacy"),
+					Name:     github.String("legacy"),
+					Archived: github.Bool(true),
+					Fork:     github.Bool(true),
+				},
+			},
+		),
+	)
+
+	client := github.NewClient(mockedHTTPClient)
+
+	// Act
+	repositories, err := ListOrgRepositories(context.Background(), client, "my-org")
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+
+	if len(repositories) != 2 {
+		t.Fatalf("Expected 2 repositories, got %d", len(repositories))
+	}
+
+	if repositories[0].FullName != "my-org/service-api" || repositories[0].Name != "service-api" ||
+		repositories[0].Language != "Go" || len(repositories[0].Topics) != 1 {
+		t.Errorf("Unexpected first repository: %+v", repositories[0])
+	}
+
+	if !repositories[1].Archived || !repositories[1].Fork || repositories[1].Topics == nil {
+		t.Errorf("Unexpected second repository: %+v", repositories[1])
+	}
+}
+
+func TestListOrgRepositories_Pagination(t *testing.T) {
+	// Arrange
+	mockedHTTPClient := mock.NewMockedHTTPClient(
+		mock.WithRequestMatchPages(
+			mock.GetOrgsReposByOrg,
+			[]github.Repository{{FullName: github.String("my-org/repo1")}},
+			[]github.Repository{{FullName: github.String("my-org/repo2")}},
+		),
+	)
+
+	client := github.NewClient(mockedHTTPClient)
+
+	// Act
+	repositories, _ := ListOrgRepositories(context.Background(), client, "my-org")
+
+	// Assert
+	if len(repositories) != 2 || repositories[1].FullName != "my-org/repo2" {
+		t.Errorf("Expected repositories from both pages, got %+v", repositories)
+	}
+}
+
+func TestListOrgRepositories_APIError(t *testing.T) {
+	// Arrange
+	mockedHTTPClient := mock.NewMockedHTTPClient(
+		mock.WithRequestMatchHandler(
+			mock.GetOrgsReposByOrg,
+			http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				mock.WriteError(w, http.StatusNotFound, "Not Found")
+			}),
+		),
+	)
+
+	client := github.NewClient(mockedHTTPClient)
+
+	// Act
+	repositories, err := ListOrgRepositories(context.Background(), client, "missing")
+
+	// Assert
+	if ClassifyError(err) != models.ErrorCategoryNotFound {
+		t.Errorf("Expected a not found error, got %v", err)
+	}
+
+	if repositories == nil || len(repositories) != 0 {
+		t.Errorf("Expected an empty slice, got %v", repositories)
+	}
+}
+
+func TestListUserRepositories_Success(t *testing.T) {
+	// Arrange
+	mockedHTTPClient := mock.NewMockedHTTPClient(
+		mock.WithRequestMatch(
+			mock.GetUsersReposByUsername,
+			[]github.Repository{
+				{FullName: github.String("octocat/hello-world"), Name: github.String("hello-world")},
+			},
+		),
+	)
+
+	source := NewWorkflowSource(github.NewClient(mockedHTTPClient))
+
+	// Act
+	repositories, err := source.ListUserRepositories(context.Background(), "octocat")
+
+	// Assert
+	if err != nil || len(repositories) != 1 || repositories[0].FullName != "octocat/hello-world" {
+		t.Errorf("Unexpected repositories %+v", repositories)
+	}
+}
+
+func TestListRepositories_NilClient(t *testing.T) {
+	if repositories, err := ListOrgRepositories(context.Background(), nil, "my-org"); len(repositories) != 0 || !errors.Is(err, ErrNoClient) {
+		t.Errorf("Expected no repositories for a nil client, got %v %v", repositories, err)
+	}
+
+	if repositories, err := ListUserRepositories(context.Background(), nil, "octocat"); len(repositories) != 0 || !errors.Is(err, ErrNoClient) {
+		t.Errorf("Expected no repositories for a nil client, got %v %v", repositories, err)
+	}
+}
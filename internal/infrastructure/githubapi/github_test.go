@@ -1,6 +1,7 @@
 package githubapi
 
 import (
+	"context"
 	"net/http"
 	"testing"
 
@@ -30,9 +31,13 @@ func TestFindWorkflows_Success(t *testing.T) {
 	client := github.NewClient(mockedHTTPClient)
 
 	// Act
-	workflows := FindWorkflows(client, "owner/repo")
+	workflows, err := FindWorkflowsWithErr(context.Background(), client, "owner/repo")
 
 	// Assert
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
 	if len(workflows) != 2 {
 		t.Errorf("Expected 2 workflows, got %d", len(workflows))
 	}
@@ -76,9 +81,13 @@ func TestFindWorkflows_MixedFiles(t *testing.T) {
 	client := github.NewClient(mockedHTTPClient)
 
 	// Act
-	workflows := FindWorkflows(client, "owner/repo")
+	workflows, err := FindWorkflowsWithErr(context.Background(), client, "owner/repo")
 
 	// Assert
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
 	if len(workflows) != 2 {
 		t.Errorf("Expected 2 workflows (only YAML files), got %d", len(workflows))
 	}
@@ -116,9 +125,13 @@ func TestFindWorkflows_EmptyDirectory(t *testing.T) {
 	client := github.NewClient(mockedHTTPClient)
 
 	// Act
-	workflows := FindWorkflows(client, "owner/repo")
+	workflows, err := FindWorkflowsWithErr(context.Background(), client, "owner/repo")
 
 	// Assert
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
 	if len(workflows) != 0 {
 		t.Errorf("Expected 0 workflows for empty directory, got %d", len(workflows))
 	}
@@ -129,9 +142,13 @@ func TestFindWorkflows_InvalidRepoFormat(t *testing.T) {
 	client := github.NewClient(nil)
 
 	// Act
-	workflows := FindWorkflows(client, "invalid-repo-format")
+	workflows, err := FindWorkflowsWithErr(context.Background(), client, "invalid-repo-format")
 
 	// Assert
+	if err == nil {
+		t.Error("Expected an error")
+	}
+
 	if len(workflows) != 0 {
 		t.Errorf("Expected 0 workflows for invalid repo format, got %d", len(workflows))
 	}
@@ -151,9 +168,13 @@ func TestFindWorkflows_APIError(t *testing.T) {
 	client := github.NewClient(mockedHTTPClient)
 
 	// Act
-	workflows := FindWorkflows(client, "owner/repo")
+	workflows, err := FindWorkflowsWithErr(context.Background(), client, "owner/repo")
 
 	// Assert
+	if err == nil {
+		t.Error("Expected an error")
+	}
+
 	if len(workflows) != 0 {
 		t.Errorf("Expected 0 workflows when API returns error, got %d", len(workflows))
 	}
@@ -181,9 +202,13 @@ func TestFindWorkflows_OnlyDirectories(t *testing.T) {
 	client := github.NewClient(mockedHTTPClient)
 
 	// Act
-	workflows := FindWorkflows(client, "owner/repo")
+	workflows, err := FindWorkflowsWithErr(context.Background(), client, "owner/repo")
 
 	// Assert
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
 	if len(workflows) != 0 {
 		t.Errorf("Expected 0 workflows when directory contains only subdirectories, got %d", len(workflows))
 	}
@@ -223,9 +248,13 @@ func TestFindWorkflows_CaseInsensitiveExtension(t *testing.T) {
 	client := github.NewClient(mockedHTTPClient)
 
 	// Act
-	workflows := FindWorkflows(client, "owner/repo")
+	workflows, err := FindWorkflowsWithErr(context.Background(), client, "owner/repo")
 
 	// Assert
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
 	if len(workflows) != 5 {
 		t.Errorf("Expected 5 workflows (all case variations), got %d", len(workflows))
 	}
@@ -249,9 +278,13 @@ func TestFindWorkflows_RepoWithOrganization(t *testing.T) {
 	client := github.NewClient(mockedHTTPClient)
 
 	// Act
-	workflows := FindWorkflows(client, "myorg/myrepo")
+	workflows, err := FindWorkflowsWithErr(context.Background(), client, "myorg/myrepo")
 
 	// Assert
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
 	if len(workflows) != 1 {
 		t.Errorf("Expected 1 workflow, got %d", len(workflows))
 	}
@@ -270,14 +303,22 @@ func TestFindWorkflows_NoWorkflowsDirectory(t *testing.T) {
 				w.WriteHeader(http.StatusNotFound)
 			}),
 		),
+		mock.WithRequestMatch(
+			mock.GetReposByOwnerByRepo,
+			github.Repository{Name: github.String("repo-without-workflows")},
+		),
 	)
 
 	client := github.NewClient(mockedHTTPClient)
 
 	// Act
-	workflows := FindWorkflows(client, "owner/repo-without-workflows")
+	workflows, err := FindWorkflowsWithErr(context.Background(), client, "owner/repo-without-workflows")
 
 	// Assert
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
 	if len(workflows) != 0 {
 		t.Errorf("Expected 0 workflows when .github/workflows doesn't exist, got %d", len(workflows))
 	}
@@ -304,9 +345,13 @@ func TestFindWorkflows_ContextUsage(t *testing.T) {
 	client := github.NewClient(mockedHTTPClient)
 
 	// Act
-	workflows := FindWorkflows(client, "owner/repo")
+	workflows, err := FindWorkflowsWithErr(context.Background(), client, "owner/repo")
 
 	// Assert
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
 	if len(workflows) != 1 {
 		t.Errorf("Expected 1 workflow, got %d", len(workflows))
 	}
@@ -1,6 +1,7 @@
 package githubapi
 
 import (
+	"context"
 	"encoding/base64"
 	"net/http"
 	"testing"
@@ -36,7 +37,7 @@ jobs:
 	)
 	client := github.NewClient(mockedHTTPClient)
 	// Act
-	result := WorkflowToString(client, "owner/repo", "ci.yml")
+	result := WorkflowToString(context.Background(), client, "owner/repo", "ci.yml")
 	// Assert
 	if result != workflowContent {
 		t.Errorf("Expected workflow content to match.\nExpected:\n%s\n\nGot:\n%s", workflowContent, result)
@@ -69,7 +70,7 @@ jobs:
 	)
 	client := github.NewClient(mockedHTTPClient)
 	// Act
-	result := WorkflowToString(client, "owner/repo", "deploy.yaml")
+	result := WorkflowToString(context.Background(), client, "owner/repo", "deploy.yaml")
 	// Assert
 	if result != workflowContent {
 		t.Errorf("Expected workflow content to match.\nExpected:\n%s\n\nGot:\n%s", workflowContent, result)
@@ -131,7 +132,7 @@ jobs:
 	)
 	client := github.NewClient(mockedHTTPClient)
 	// Act
-	result := WorkflowToString(client, "owner/repo", "complex.yml")
+	result := WorkflowToString(context.Background(), client, "owner/repo", "complex.yml")
 	// Assert
 	if result != workflowContent {
 		t.Errorf("Expected workflow content to match.\nExpected:\n%s\n\nGot:\n%s", workflowContent, result)
@@ -156,7 +157,7 @@ func TestWorkflowToString_EmptyWorkflow(t *testing.T) {
 	)
 	client := github.NewClient(mockedHTTPClient)
 	// Act
-	result := WorkflowToString(client, "owner/repo", "empty.yml")
+	result := WorkflowToString(context.Background(), client, "owner/repo", "empty.yml")
 	// Assert
 	if result != workflowContent {
 		t.Errorf("Expected empty string, got '%s'", result)
@@ -166,7 +167,7 @@ func TestWorkflowToString_InvalidRepoFormat(t *testing.T) {
 	// Arrange
 	client := github.NewClient(nil)
 	// Act
-	result := WorkflowToString(client, "invalid-repo-format", "ci.yml")
+	result := WorkflowToString(context.Background(), client, "invalid-repo-format", "ci.yml")
 	// Assert
 	if result != "" {
 		t.Errorf("Expected empty string for invalid repo format, got '%s'", result)
@@ -176,7 +177,7 @@ func TestWorkflowToString_RepoWithoutSlash(t *testing.T) {
 	// Arrange
 	client := github.NewClient(nil)
 	// Act
-	result := WorkflowToString(client, "justreponame", "ci.yml")
+	result := WorkflowToString(context.Background(), client, "justreponame", "ci.yml")
 	// Assert
 	if result != "" {
 		t.Errorf("Expected empty string for repo without slash, got '%s'", result)
@@ -194,7 +195,7 @@ func TestWorkflowToString_FileNotFound(t *testing.T) {
 	)
 	client := github.NewClient(mockedHTTPClient)
 	// Act
-	result := WorkflowToString(client, "owner/repo", "nonexistent.yml")
+	result := WorkflowToString(context.Background(), client, "owner/repo", "nonexistent.yml")
 	// Assert
 	if result != "" {
 		t.Errorf("Expected empty string when file not found, got '%s'", result)
@@ -212,7 +213,7 @@ func TestWorkflowToString_APIError(t *testing.T) {
 	)
 	client := github.NewClient(mockedHTTPClient)
 	// Act
-	result := WorkflowToString(client, "owner/repo", "ci.yml")
+	result := WorkflowToString(context.Background(), client, "owner/repo", "ci.yml")
 	// Assert
 	if result != "" {
 		t.Errorf("Expected empty string when API returns error, got '%s'", result)
@@ -230,7 +231,7 @@ func TestWorkflowToString_Unauthorized(t *testing.T) {
 	)
 	client := github.NewClient(mockedHTTPClient)
 	// Act
-	result := WorkflowToString(client, "owner/private-repo", "ci.yml")
+	result := WorkflowToString(context.Background(), client, "owner/private-repo", "ci.yml")
 	// Assert
 	if result != "" {
 		t.Errorf("Expected empty string when unauthorized, got '%s'", result)
@@ -261,7 +262,7 @@ jobs:
 	)
 	client := github.NewClient(mockedHTTPClient)
 	// Act
-	result := WorkflowToString(client, "myorg/myrepo", "test.yml")
+	result := WorkflowToString(context.Background(), client, "myorg/myrepo", "test.yml")
 	// Assert
 	if result != workflowContent {
 		t.Errorf("Expected workflow content to match.\nExpected:\n%s\n\nGot:\n%s", workflowContent, result)
@@ -300,7 +301,7 @@ jobs:
 	)
 	client := github.NewClient(mockedHTTPClient)
 	// Act
-	result := WorkflowToString(client, "owner/repo", "multiline.yml")
+	result := WorkflowToString(context.Background(), client, "owner/repo", "multiline.yml")
 	// Assert
 	if result != workflowContent {
 		t.Errorf("Expected workflow content to match.\nExpected:\n%s\n\nGot:\n%s", workflowContent, result)
@@ -332,7 +333,7 @@ jobs:
 	)
 	client := github.NewClient(mockedHTTPClient)
 	// Act
-	result := WorkflowToString(client, "owner/repo", "unicode.yml")
+	result := WorkflowToString(context.Background(), client, "owner/repo", "unicode.yml")
 	// Assert
 	if result != workflowContent {
 		t.Errorf("Expected workflow content to match.\nExpected:\n%s\n\nGot:\n%s", workflowContent, result)
@@ -363,7 +364,7 @@ jobs:
 	)
 	client := github.NewClient(mockedHTTPClient)
 	// Act
-	result := WorkflowToString(client, "https://github.com/owner/repo", "test.yml")
+	result := WorkflowToString(context.Background(), client, "https://github.com/owner/repo", "test.yml")
 	// Assert
 	if result != workflowContent {
 		t.Errorf("Expected workflow content to match.\nExpected:\n%s\n\nGot:\n%s", workflowContent, result)
@@ -399,7 +400,7 @@ jobs:
 	)
 	client := github.NewClient(mockedHTTPClient)
 	// Act
-	result := WorkflowToString(client, "owner/repo", "special.yml")
+	result := WorkflowToString(context.Background(), client, "owner/repo", "special.yml")
 	// Assert
 	if result != workflowContent {
 		t.Errorf("Expected workflow content to match.\nExpected:\n%s\n\nGot:\n%s", workflowContent, result)
@@ -0,0 +1,146 @@
+package githubapi
+
+import (
+	"context"
+	"maps"
+	"slices"
+
+	"github.com/OctopusSolutionsEngineering/DuplicationCostCalculator/internal/domain/models"
+	"github.com/OctopusSolutionsEngineering/DuplicationCostCalculator/internal/domain/parsing"
+	"github.com/google/go-github/v57/github"
+)
+
+// PullRequestChange is a change to commit to a branch, and the pull request that proposes it.
+type PullRequestChange struct {
+	// Branch is the branch the change is committed to. It is created from the default branch.
+	Branch        string
+	Title         string
+	Body          string
+	CommitMessage string
+	// Files maps the paths of the changed files to their new content.
+	Files map[string]string
+}
+
+// CreateOrUpdatePullRequest commits the files to a branch based on the default branch of the repository, and
+// opens a pull request from the branch into the default branch.
+// Running it again is safe. The branch is moved to a new commit on the latest default branch, and only if the
+// files changed, and an open pull request from the branch is updated instead of opening another one.
+// The returned bool is true if a pull request was opened, and false if an existing one was updated.
+func CreateOrUpdatePullRequest(ctx context.Context, client *github.Client, repo string, change PullRequestChange) (*github.PullRequest, bool, error) {
+	if client == nil {
+		return nil, false, ErrNoClient
+	}
+
+	owner, repoName, err := parsing.SplitRepo(repo)
+	if err != nil {
+		return nil, false, err
+	}
+
+	repository, _, err := client.Repositories.Get(ctx, owner, repoName)
+	if err != nil {
+		return nil, false, err
+	}
+
+	baseBranch := repository.GetDefaultBranch()
+	baseRef, _, err := client.Git.GetRef(ctx, owner, repoName, "heads/"+baseBranch)
+	if err != nil {
+		return nil, false, err
+	}
+
+	baseCommit, _, err := client.Git.GetCommit(ctx, owner, repoName, baseRef.GetObject().GetSHA())
+	if err != nil {
+		return nil, false, err
+	}
+
+	// The tree is the default branch with the changed files, so the branch is rebased when it is updated
+	entries := []*github.TreeEntry{}
+	for _, path := range slices.Sorted(maps.Keys(change.Files)) {
+		entries = append(entries, &github.TreeEntry{
+			Path:    github.String(path),
+			Mode:    github.String("100644"),
+			Type:    github.String("blob"),
+			Content: github.String(change.Files[path]),
+		})
+	}
+
+	tree, _, err := client.Git.CreateTree(ctx, owner, repoName, baseCommit.GetTree().GetSHA(), entries)
+	if err != nil {
+		return nil, false, err
+	}
+
+	if err := updateBranch(ctx, client, owner, repoName, change, baseCommit, tree); err != nil {
+		return nil, false, err
+	}
+
+	pullRequests, _, err := client.PullRequests.List(ctx, owner, repoName, &github.PullRequestListOptions{
+		State: "open",
+		Head:  owner + ":" + change.Branch,
+		Base:  baseBranch,
+	})
+	if err != nil {
+		return nil, false, err
+	}
+
+	if len(pullRequests) != 0 {
+		pullRequest, _, err := client.PullRequests.Edit(ctx, owner, repoName, pullRequests[0].GetNumber(), &github.PullRequest{
+			Title: github.String(change.Title),
+			Body:  github.String(change.Body),
+		})
+
+		return pullRequest, false, err
+	}
+
+	pullRequest, _, err := client.PullRequests.Create(ctx, owner, repoName, &github.NewPullRequest{
+		Title: github.String(change.Title),
+		Head:  github.String(change.Branch),
+		Base:  github.String(baseBranch),
+		Body:  github.String(change.Body),
+	})
+
+	return pullRequest, err == nil, err
+}
+
+// updateBranch points the branch at a commit of the tree on top of the base commit. A branch that already has
+// the same files on top of the base commit is left alone, so running again doesn't add commits.
+func updateBranch(ctx context.Context, client *github.Client, owner string, repoName string, change PullRequestChange, baseCommit *github.Commit, tree *github.Tree) error {
+	branchRef, _, err := client.Git.GetRef(ctx, owner, repoName, "heads/"+change.Branch)
+	if err != nil && ClassifyError(err) != models.ErrorCategoryNotFound {
+		return err
+	}
+
+	branchExists := err == nil
+	if branchExists {
+		branchCommit, _, err := client.Git.GetCommit(ctx, owner, repoName, branchRef.GetObject().GetSHA())
+		if err != nil {
+			return err
+		}
+
+		if branchCommit.GetTree().GetSHA() == tree.GetSHA() && len(branchCommit.Parents) == 1 &&
+			branchCommit.Parents[0].GetSHA() == baseCommit.GetSHA() {
+			return nil
+		}
+	}
+
+	commit, _, err := client.Git.CreateCommit(ctx, owner, repoName, &github.Commit{
+		Message: github.String(change.CommitMessage),
+		Tree:    &github.Tree{SHA: tree.SHA},
+		Parents: []*github.Commit{{SHA: baseCommit.SHA}},
+	}, nil)
+	if err != nil {
+		return err
+	}
+
+	ref := &github.Reference{
+		Ref:    github.String("refs/heads/" + change.Branch),
+		Object: &github.GitObject{SHA: commit.SHA},
+	}
+
+	if branchExists {
+		// The branch only holds the generated change, so it is replaced rather than merged
+		_, _, err = client.Git.UpdateRef(ctx, owner, repoName, ref, true)
+	} else {
+		_, _, err = client.Git.CreateRef(ctx, owner, repoName, ref)
+	}
+
+	return err
+}
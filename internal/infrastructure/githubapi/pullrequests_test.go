@@ -0,0 +1,139 @@
+package githubapi
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/OctopusSolutionsEngineering/DuplicationCostCalculator/internal/infrastructure/fakegithub"
+)
+
+func newTestPullRequestChange(content string) PullRequestChange {
+	return PullRequestChange{
+		Branch:        "dupcost/align-action-versions",
+		Title:         "Align action versions",
+		Body:          "Aligns actions/checkout to " + content,
+		CommitMessage: "Align action versions",
+		Files:         map[string]string{".github/workflows/build.yml": content},
+	}
+}
+
+func TestCreateOrUpdatePullRequest(t *testing.T) {
+	// Arrange
+	server := fakegithub.NewServer().AddRepo("owner/repo", map[string]string{
+		".github/workflows/build.yml":  "v3",
+		".github/workflows/deploy.yml": "deploy",
+	})
+	defer server.Close()
+
+	// Act
+	pullRequest, created, err := CreateOrUpdatePullRequest(context.Background(), server.Client(), "owner/repo", newTestPullRequestChange("v4"))
+
+	// Assert - the changed file is committed to the branch, and the other files are kept
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if !created || pullRequest.GetNumber() != 1 || pullRequest.GetHTMLURL() == "" {
+		t.Errorf("Expected pull request 1 to be created, got %v %+v", created, pullRequest)
+	}
+
+	files := server.GetFiles("owner/repo", "dupcost/align-action-versions")
+	if files[".github/workflows/build.yml"] != "v4" || files[".github/workflows/deploy.yml"] != "deploy" {
+		t.Errorf("Unexpected files on the branch %v", files)
+	}
+
+	if main := server.GetFiles("owner/repo", "main"); main[".github/workflows/build.yml"] != "v3" {
+		t.Errorf("Expected the default branch to be unchanged, got %v", main)
+	}
+
+	pullRequests := server.GetPullRequests("owner/repo")
+	if len(pullRequests) != 1 || pullRequests[0].Head != "dupcost/align-action-versions" || pullRequests[0].Base != "main" {
+		t.Errorf("Unexpected pull requests %+v", pullRequests)
+	}
+}
+
+func TestCreateOrUpdatePullRequest_Idempotent(t *testing.T) {
+	tests := []struct {
+		name          string
+		secondContent string
+		expectSameSha bool
+	}{
+		{"same change", "v4", true},
+		{"new change", "v4.1.0", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			server := fakegithub.NewServer().AddRepo("owner/repo", map[string]string{".github/workflows/build.yml": "v3"})
+			defer server.Close()
+
+			if _, _, err := CreateOrUpdatePullRequest(context.Background(), server.Client(), "owner/repo", newTestPullRequestChange("v4")); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			firstSha := server.GetBranch("owner/repo", "dupcost/align-action-versions")
+
+			// Act
+			pullRequest, created, err := CreateOrUpdatePullRequest(context.Background(), server.Client(), "owner/repo", newTestPullRequestChange(tt.secondContent))
+
+			// Assert - the existing pull request is updated, and the branch still has a single commit on main
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			if created || pullRequest.GetNumber() != 1 {
+				t.Errorf("Expected pull request 1 to be updated, got %v %+v", created, pullRequest)
+			}
+
+			pullRequests := server.GetPullRequests("owner/repo")
+			if len(pullRequests) != 1 || pullRequests[0].Body != "Aligns actions/checkout to "+tt.secondContent {
+				t.Errorf("Unexpected pull requests %+v", pullRequests)
+			}
+
+			if files := server.GetFiles("owner/repo", "dupcost/align-action-versions"); files[".github/workflows/build.yml"] != tt.secondContent {
+				t.Errorf("Unexpected files on the branch %v", files)
+			}
+
+			if count := server.GetCommitCount("owner/repo", "dupcost/align-action-versions"); count != 2 {
+				t.Errorf("Expected the change to replace the previous commit, got %d commits", count)
+			}
+
+			if sameSha := server.GetBranch("owner/repo", "dupcost/align-action-versions") == firstSha; sameSha != tt.expectSameSha {
+				t.Errorf("Expected the branch to be moved only when the files change, same commit %v", sameSha)
+			}
+		})
+	}
+}
+
+func TestCreateOrUpdatePullRequest_ClosedPullRequest(t *testing.T) {
+	// Arrange
+	server := fakegithub.NewServer().AddRepo("owner/repo", map[string]string{".github/workflows/build.yml": "v3"})
+	defer server.Close()
+
+	if _, _, err := CreateOrUpdatePullRequest(context.Background(), server.Client(), "owner/repo", newTestPullRequestChange("v4")); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	server.ClosePullRequest("owner/repo", 1)
+
+	// Act
+	pullRequest, created, err := CreateOrUpdatePullRequest(context.Background(), server.Client(), "owner/repo", newTestPullRequestChange("v4"))
+
+	// Assert - a closed pull request is not reopened, so a new one is opened
+	if err != nil || !created || pullRequest.GetNumber() != 2 {
+		t.Errorf("Expected pull request 2 to be created, got %v %+v %v", created, pullRequest, err)
+	}
+}
+
+func TestCreateOrUpdatePullRequest_Errors(t *testing.T) {
+	server := fakegithub.NewServer()
+	defer server.Close()
+
+	if _, _, err := CreateOrUpdatePullRequest(context.Background(), nil, "owner/repo", newTestPullRequestChange("v4")); !errors.Is(err, ErrNoClient) {
+		t.Errorf("Expected ErrNoClient, got %v", err)
+	}
+
+	if _, _, err := CreateOrUpdatePullRequest(context.Background(), server.Client(), "owner/missing", newTestPullRequestChange("v4")); err == nil {
+		t.Error("Expected an error for a missing repository")
+	}
+}
@@ -0,0 +1,103 @@
+package githubapi
+
+import (
+	"context"
+
+	"github.com/OctopusSolutionsEngineering/DuplicationCostCalculator/internal/domain/models"
+	"github.com/google/go-github/v57/github"
+	"github.com/samber/lo"
+)
+
+// ListOrgRepositories lists all the repositories in a GitHub organization. An organization that doesn't exist, or
+// can't be read, returns the error from the GitHub API rather than no repositories.
+func ListOrgRepositories(ctx context.Context, client *github.Client, org string) ([]models.Repository, error) {
+	if client == nil {
+		return []models.Repository{}, ErrNoClient
+	}
+
+	if org == "" {
+		return []models.Repository{}, nil
+	}
+
+	opts := &github.RepositoryListByOrgOptions{
+		ListOptions: github.ListOptions{
+			PerPage: 100,
+		},
+	}
+
+	repositories := []models.Repository{}
+
+	// Fetch all repositories for the organization (handle pagination)
+	for {
+		repos, resp, err := client.Repositories.ListByOrg(ctx, org, opts)
+		if err != nil {
+			return []models.Repository{}, err
+		}
+
+		repositories = append(repositories, lo.Map(repos, ConvertRepository)...)
+
+		// Check if there are more pages
+		if resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+
+	return repositories, nil
+}
+
+// ListUserRepositories lists all the repositories owned by a GitHub user. A user that doesn't exist returns the
+// error from the GitHub API rather than no repositories.
+func ListUserRepositories(ctx context.Context, client *github.Client, user string) ([]models.Repository, error) {
+	if client == nil {
+		return []models.Repository{}, ErrNoClient
+	}
+
+	if user == "" {
+		return []models.Repository{}, nil
+	}
+
+	opts := &github.RepositoryListByUserOptions{
+		Type: "owner",
+		ListOptions: github.ListOptions{
+			PerPage: 100,
+		},
+	}
+
+	repositories := []models.Repository{}
+
+	// Fetch all repositories for the user (handle pagination)
+	for {
+		repos, resp, err := client.Repositories.ListByUser(ctx, user, opts)
+		if err != nil {
+			return []models.Repository{}, err
+		}
+
+		repositories = append(repositories, lo.Map(repos, ConvertRepository)...)
+
+		// Check if there are more pages
+		if resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+
+	return repositories, nil
+}
+
+// ConvertRepository captures the details of a GitHub repository used to filter the repositories to scan.
+func ConvertRepository(repository *github.Repository, index int) models.Repository {
+	topics := repository.Topics
+	if topics == nil {
+		topics = []string{}
+	}
+
+	return models.Repository{
+		FullName: repository.GetFullName(),
+		Name:     repository.GetName(),
+		Topics:   topics,
+		Archived: repository.GetArchived(),
+		Fork:     repository.GetFork(),
+		Language: repository.GetLanguage(),
+	}
+}
@@ -0,0 +1,119 @@
+package githubapi
+
+import (
+	"context"
+	"slices"
+	"sync"
+
+	"github.com/OctopusSolutionsEngineering/DuplicationCostCalculator/internal/domain/models"
+	"github.com/OctopusSolutionsEngineering/DuplicationCostCalculator/internal/domain/parsing"
+	"github.com/google/go-github/v57/github"
+)
+
+// RateLimitReporter is implemented by HTTP transports that track the GitHub API rate limit, like
+// client.RateLimitTransport.
+type RateLimitReporter interface {
+	Budget() (models.RateLimitBudget, bool)
+}
+
+// WorkflowSource loads workflows, contributors, and advisories from GitHub repositories.
+// Errors returned by the GitHub API are recorded against the repository, and are available from GetErrors.
+type WorkflowSource struct {
+	Client *github.Client
+	errors map[string][]models.RepoError
+	mutex  sync.Mutex
+}
+
+func NewWorkflowSource(client *github.Client) *WorkflowSource {
+	return &WorkflowSource{Client: client, errors: map[string][]models.RepoError{}}
+}
+
+// GetErrors returns the errors recorded while loading the repository.
+func (s *WorkflowSource) GetErrors(repo string) []models.RepoError {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return slices.Clone(s.errors[s.RepoName(repo)])
+}
+
+func (s *WorkflowSource) recordError(repo string, operation string, err error) {
+	if err == nil {
+		return
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.errors == nil {
+		s.errors = map[string][]models.RepoError{}
+	}
+
+	repoName := s.RepoName(repo)
+	s.errors[repoName] = append(s.errors[repoName], NewRepoError(operation, err))
+}
+
+// GetRateLimit returns the budget tracked by the transport of the client, or nil if the transport does not
+// track the rate limit or no response has included the rate limit headers.
+func (s *WorkflowSource) GetRateLimit() *models.RateLimitBudget {
+	if s.Client == nil {
+		return nil
+	}
+
+	reporter, ok := s.Client.Client().Transport.(RateLimitReporter)
+	if !ok {
+		return nil
+	}
+
+	budget, known := reporter.Budget()
+	if !known {
+		return nil
+	}
+
+	return &budget
+}
+
+// RepoName normalizes the repository URL or name to the format "owner/repo".
+func (s *WorkflowSource) RepoName(repo string) string {
+	owner, repoName := parsing.SplitRepoNoErr(repo)
+	return owner + "/" + repoName
+}
+
+func (s *WorkflowSource) FindWorkflows(ctx context.Context, repo string) []string {
+	workflows, err := FindWorkflowsWithErr(ctx, s.Client, repo)
+	s.recordError(repo, "list workflows", err)
+	return workflows
+}
+
+func (s *WorkflowSource) WorkflowToString(ctx context.Context, repo string, workflow string) string {
+	workflowStr, err := WorkflowToStringWithErr(ctx, s.Client, repo, workflow)
+	s.recordError(repo, "load workflow "+workflow, err)
+	return workflowStr
+}
+
+func (s *WorkflowSource) FindContributorsToWorkflow(ctx context.Context, repo string, workflow string) []string {
+	contributors, err := FindContributorsToWorkflowWithErr(ctx, s.Client, repo, workflow)
+	s.recordError(repo, "list contributors to "+workflow, err)
+	return contributors
+}
+
+func (s *WorkflowSource) ActionToString(ctx context.Context, repo string, path string, ref string) string {
+	return ActionToString(ctx, s.Client, repo, path, ref)
+}
+
+func (s *WorkflowSource) FindTags(ctx context.Context, repo string) []models.Tag {
+	return FindTags(ctx, s.Client, repo)
+}
+
+// GetWorkflowAdvisories returns the security advisories of the repository. Listing advisories usually fails
+// because the token does not have permission to read them, so errors are not recorded against the repository.
+func (s *WorkflowSource) GetWorkflowAdvisories(ctx context.Context, repo string) []string {
+	return GetWorkflowAdvisories(ctx, s.Client, repo)
+}
+
+func (s *WorkflowSource) ListOrgRepositories(ctx context.Context, org string) ([]models.Repository, error) {
+	return ListOrgRepositories(ctx, s.Client, org)
+}
+
+func (s *WorkflowSource) ListUserRepositories(ctx context.Context, user string) ([]models.Repository, error) {
+	return ListUserRepositories(ctx, s.Client, user)
+}
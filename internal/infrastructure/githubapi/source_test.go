@@ -0,0 +1,171 @@
+package githubapi
+
+import (
+	"context"
+	"encoding/base64"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/OctopusSolutionsEngineering/DuplicationCostCalculator/internal/domain/models"
+	"github.com/google/go-github/v57/github"
+	"github.com/migueleliasweb/go-github-mock/src/mock"
+)
+
+func TestWorkflowSource_RepoName(t *testing.T) {
+	source := NewWorkflowSource(nil)
+
+	tests := map[string]string{
+		"owner/repo":                         "owner/repo",
+		"https://github.com/owner/repo":      "owner/repo",
+		"https://github.com/owner/repo.git":  "owner/repo",
+		"https://github.com/owner/repo/tree": "owner/repo",
+	}
+
+	for repo, expected := range tests {
+		if result := source.RepoName(repo); result != expected {
+			t.Errorf("RepoName(%q) = %q, expected %q", repo, result, expected)
+		}
+	}
+}
+
+func TestWorkflowSource_FindWorkflows(t *testing.T) {
+	// Arrange
+	mockedHTTPClient := mock.NewMockedHTTPClient(
+		mock.WithRequestMatch(
+			mock.GetReposContentsByOwnerByRepoByPath,
+			[]github.RepositoryContent{{
+				Name: github.String("build.yml"),
+				Type: github.String("file"),
+			}},
+			nil,
+		),
+	)
+
+	source := NewWorkflowSource(github.NewClient(mockedHTTPClient))
+
+	// Act
+	workflows := source.FindWorkflows(context.Background(), "owner/repo")
+
+	// Assert
+	if len(workflows) != 1 || workflows[0] != "build.yml" {
+		t.Errorf("Expected [build.yml], got %v", workflows)
+	}
+}
+
+func TestWorkflowSource_NilClient(t *testing.T) {
+	source := NewWorkflowSource(nil)
+
+	if source.WorkflowToString(context.Background(), "owner/repo", "build.yml") != "" {
+		t.Error("Expected empty workflow content with a nil client")
+	}
+
+	if len(source.FindContributorsToWorkflow(context.Background(), "owner/repo", "build.yml")) != 0 {
+		t.Error("Expected no contributors with a nil client")
+	}
+
+	if len(source.GetWorkflowAdvisories(context.Background(), "owner/repo")) != 0 {
+		t.Error("Expected no advisories with a nil client")
+	}
+}
+
+func TestActionToString_FallsBackToYaml(t *testing.T) {
+	// Arrange
+	actionContent := "name: Setup\nruns:\n  using: composite\n"
+	encodedContent := base64.StdEncoding.EncodeToString([]byte(actionContent))
+
+	mockedHTTPClient := mock.NewMockedHTTPClient(
+		mock.WithRequestMatchHandler(
+			mock.GetReposContentsByOwnerByRepoByPath,
+			http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if strings.HasSuffix(r.URL.Path, "action.yml") {
+					mock.WriteError(w, http.StatusNotFound, "Not Found")
+					return
+				}
+
+				if r.URL.Query().Get("ref") != "v1" {
+					mock.WriteError(w, http.StatusBadRequest, "Missing ref")
+					return
+				}
+
+				w.Write(mock.MustMarshal(github.RepositoryContent{
+					Name:     github.String("action.yaml"),
+					Type:     github.String("file"),
+					Content:  &encodedContent,
+					Encoding: github.String("base64"),
+				}))
+			}),
+		),
+	)
+
+	client := github.NewClient(mockedHTTPClient)
+
+	// Act
+	result := ActionToString(context.Background(), client, "my-org/actions", "setup", "v1")
+
+	// Assert
+	if result != actionContent {
+		t.Errorf("Expected action content, got '%s'", result)
+	}
+}
+
+func TestActionToString_NotFound(t *testing.T) {
+	// Arrange
+	mockedHTTPClient := mock.NewMockedHTTPClient(
+		mock.WithRequestMatchHandler(
+			mock.GetReposContentsByOwnerByRepoByPath,
+			http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				mock.WriteError(w, http.StatusNotFound, "Not Found")
+			}),
+		),
+	)
+
+	client := github.NewClient(mockedHTTPClient)
+
+	// Act & Assert
+	if result := ActionToString(context.Background(), client, "my-org/actions", "setup", ""); result != "" {
+		t.Errorf("Expected empty string, got '%s'", result)
+	}
+
+	if result := ActionToString(context.Background(), nil, "my-org/actions", "setup", ""); result != "" {
+		t.Errorf("Expected empty string for a nil client, got '%s'", result)
+	}
+}
+
+type fakeRateLimitTransport struct {
+	budget models.RateLimitBudget
+	known  bool
+}
+
+func (t *fakeRateLimitTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func (t *fakeRateLimitTransport) Budget() (models.RateLimitBudget, bool) {
+	return t.budget, t.known
+}
+
+func TestWorkflowSource_GetRateLimit(t *testing.T) {
+	budget := models.RateLimitBudget{Limit: 5000, Remaining: 4000, Requests: 12}
+
+	tests := []struct {
+		name     string
+		client   *github.Client
+		expected *models.RateLimitBudget
+	}{
+		{"nil client", nil, nil},
+		{"untracked transport", github.NewClient(nil), nil},
+		{"unknown budget", github.NewClient(&http.Client{Transport: &fakeRateLimitTransport{}}), nil},
+		{"known budget", github.NewClient(&http.Client{Transport: &fakeRateLimitTransport{budget: budget, known: true}}), &budget},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := NewWorkflowSource(tt.client).GetRateLimit()
+
+			if (result == nil) != (tt.expected == nil) || (result != nil && *result != *tt.expected) {
+				t.Errorf("Expected %+v, got %+v", tt.expected, result)
+			}
+		})
+	}
+}
@@ -0,0 +1,23 @@
+package githubapi
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/google/go-github/v57/github"
+)
+
+// GetUserIdentity returns an identity for the user the client is authenticated as. It is based on the user's id,
+// which, unlike an access token or login, does not change.
+func GetUserIdentity(ctx context.Context, client *github.Client) (string, error) {
+	if client == nil {
+		return "", ErrNoClient
+	}
+
+	user, _, err := client.Users.Get(ctx, "")
+	if err != nil {
+		return "", err
+	}
+
+	return "user:" + strconv.FormatInt(user.GetID(), 10), nil
+}
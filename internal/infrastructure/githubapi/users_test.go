@@ -0,0 +1,59 @@
+package githubapi
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/google/go-github/v57/github"
+	"github.com/migueleliasweb/go-github-mock/src/mock"
+)
+
+func TestGetUserIdentity(t *testing.T) {
+	// Arrange
+	mockedHTTPClient := mock.NewMockedHTTPClient(
+		mock.WithRequestMatch(
+			mock.GetUser,
+			github.User{ID: github.Int64(1234), Login: github.String("octocat")},
+		),
+	)
+
+	// Act
+	identity, err := GetUserIdentity(context.Background(), github.NewClient(mockedHTTPClient))
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if identity != "user:1234" {
+		t.Errorf("Expected the identity to be based on the user id, got %q", identity)
+	}
+}
+
+func TestGetUserIdentity_Unauthorized(t *testing.T) {
+	// Arrange
+	mockedHTTPClient := mock.NewMockedHTTPClient(
+		mock.WithRequestMatchHandler(
+			mock.GetUser,
+			http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				mock.WriteError(w, http.StatusUnauthorized, "Bad credentials")
+			}),
+		),
+	)
+
+	// Act
+	_, err := GetUserIdentity(context.Background(), github.NewClient(mockedHTTPClient))
+
+	// Assert
+	if err == nil {
+		t.Error("Expected an error for bad credentials")
+	}
+}
+
+func TestGetUserIdentity_NilClient(t *testing.T) {
+	if _, err := GetUserIdentity(context.Background(), nil); !errors.Is(err, ErrNoClient) {
+		t.Errorf("Expected ErrNoClient, got %v", err)
+	}
+}
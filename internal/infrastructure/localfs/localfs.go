@@ -0,0 +1,129 @@
+package localfs
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/OctopusSolutionsEngineering/DuplicationCostCalculator/internal/domain/models"
+	"github.com/OctopusSolutionsEngineering/DuplicationCostCalculator/internal/domain/parsing"
+	"github.com/samber/lo"
+)
+
+const WorkflowsDir = ".github/workflows"
+
+// FindWorkflows loads all the GitHub Actions workflows for a local repository checkout.
+// dir is the path to the root of the checkout.
+func FindWorkflows(dir string) []string {
+	entries, err := os.ReadDir(filepath.Join(dir, WorkflowsDir))
+	if err != nil {
+		return []string{}
+	}
+
+	files := lo.Filter(entries, func(item os.DirEntry, index int) bool {
+		name := strings.ToLower(item.Name())
+		return !item.IsDir() && (strings.HasSuffix(name, ".yml") || strings.HasSuffix(name, ".yaml"))
+	})
+
+	return lo.Map(files, func(item os.DirEntry, index int) string {
+		return item.Name()
+	})
+}
+
+// WorkflowToString reads the contents of a workflow file from a local repository checkout.
+func WorkflowToString(dir string, workflow string) string {
+	content, err := os.ReadFile(filepath.Join(dir, WorkflowsDir, workflow))
+	if err != nil {
+		return ""
+	}
+
+	return string(content)
+}
+
+// ActionFileNames are the names of the files that can define an action, in the order they are searched.
+var ActionFileNames = []string{"action.yml", "action.yaml"}
+
+// ActionToString reads the action.yml or action.yaml file from a directory in a local repository checkout.
+func ActionToString(dir string, actionPath string) string {
+	for _, fileName := range ActionFileNames {
+		content, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(actionPath), fileName))
+		if err == nil {
+			return string(content)
+		}
+	}
+
+	return ""
+}
+
+// GetRemoteOwner returns the owner of the GitHub repository that the "origin" remote of a local checkout points to,
+// like "owner" for https://github.com/owner/repo.git or git@github.com:owner/repo.git. It is empty if the checkout
+// has no origin remote on GitHub.
+func GetRemoteOwner(dir string) string {
+	content, err := os.ReadFile(filepath.Join(dir, ".git", "config"))
+	if err != nil {
+		return ""
+	}
+
+	inOrigin := false
+	for _, line := range strings.Split(string(content), "\n") {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, "[") {
+			inOrigin = line == `[remote "origin"]`
+			continue
+		}
+
+		key, value, ok := strings.Cut(line, "=")
+		if !inOrigin || !ok || strings.TrimSpace(key) != "url" {
+			continue
+		}
+
+		_, path, ok := strings.Cut(strings.TrimSpace(value), "github.com")
+		if !ok {
+			return ""
+		}
+
+		owner, _ := parsing.SplitRepoNoErr(strings.TrimLeft(path, ":/"))
+		return owner
+	}
+
+	return ""
+}
+
+// RepoName returns the name used to identify a local repository checkout in a report.
+func RepoName(dir string) string {
+	return filepath.ToSlash(filepath.Clean(dir))
+}
+
+// GetErrors checks that the checkout exists and can be read. A checkout without a workflows directory is not an error.
+func GetErrors(dir string) []models.RepoError {
+	info, err := os.Stat(dir)
+	if err == nil && !info.IsDir() {
+		err = errors.New(dir + " is not a directory")
+	}
+
+	if err == nil {
+		_, err = os.ReadDir(filepath.Join(dir, WorkflowsDir))
+		if errors.Is(err, fs.ErrNotExist) {
+			err = nil
+		}
+	}
+
+	if err == nil {
+		return []models.RepoError{}
+	}
+
+	category := models.ErrorCategoryUnknown
+	if errors.Is(err, fs.ErrNotExist) {
+		category = models.ErrorCategoryNotFound
+	} else if errors.Is(err, fs.ErrPermission) {
+		category = models.ErrorCategoryForbidden
+	}
+
+	return []models.RepoError{{
+		Category:  category,
+		Operation: "list workflows",
+		Message:   err.Error(),
+	}}
+}
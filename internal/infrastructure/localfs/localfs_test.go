@@ -0,0 +1,303 @@
+package localfs
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/OctopusSolutionsEngineering/DuplicationCostCalculator/internal/domain/models"
+)
+
+func writeFile(t *testing.T, path string, content string) {
+	t.Helper()
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatalf("Failed to create directory: %v", err)
+	}
+
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+}
+
+func TestFindWorkflows_Success(t *testing.T) {
+	// Arrange
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, WorkflowsDir, "build.yml"), "name: Build")
+	writeFile(t, filepath.Join(dir, WorkflowsDir, "test.yaml"), "name: Test")
+
+	// Act
+	workflows := FindWorkflows(dir)
+
+	// Assert
+	if len(workflows) != 2 {
+		t.Fatalf("Expected 2 workflows, got %d", len(workflows))
+	}
+
+	if workflows[0] != "build.yml" {
+		t.Errorf("Expected first workflow to be 'build.yml', got '%s'", workflows[0])
+	}
+
+	if workflows[1] != "test.yaml" {
+		t.Errorf("Expected second workflow to be 'test.yaml', got '%s'", workflows[1])
+	}
+}
+
+func TestFindWorkflows_MixedFiles(t *testing.T) {
+	// Arrange - mix of YAML files, other files and directories
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, WorkflowsDir, "build.yml"), "name: Build")
+	writeFile(t, filepath.Join(dir, WorkflowsDir, "deploy.YAML"), "name: Deploy")
+	writeFile(t, filepath.Join(dir, WorkflowsDir, "readme.txt"), "Not a workflow")
+	writeFile(t, filepath.Join(dir, WorkflowsDir, "subdir.yml", "nested.yml"), "name: Nested")
+
+	// Act
+	workflows := FindWorkflows(dir)
+
+	// Assert
+	if len(workflows) != 2 {
+		t.Fatalf("Expected 2 workflows, got %d: %v", len(workflows), workflows)
+	}
+
+	if workflows[0] != "build.yml" || workflows[1] != "deploy.YAML" {
+		t.Errorf("Unexpected workflows: %v", workflows)
+	}
+}
+
+func TestFindWorkflows_NoWorkflowsDirectory(t *testing.T) {
+	// Arrange
+	dir := t.TempDir()
+
+	// Act
+	workflows := FindWorkflows(dir)
+
+	// Assert
+	if len(workflows) != 0 {
+		t.Errorf("Expected 0 workflows when .github/workflows doesn't exist, got %d", len(workflows))
+	}
+}
+
+func TestFindWorkflows_MissingDirectory(t *testing.T) {
+	// Act
+	workflows := FindWorkflows(filepath.Join(t.TempDir(), "does-not-exist"))
+
+	// Assert
+	if workflows == nil || len(workflows) != 0 {
+		t.Errorf("Expected an empty slice, got %v", workflows)
+	}
+}
+
+func TestWorkflowToString_Success(t *testing.T) {
+	// Arrange
+	workflowContent := `name: CI
+on: [push, pull_request]
+jobs:
+  build:
+    runs-on: ubuntu-latest
+    steps:
+      - uses: actions/checkout@v2`
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, WorkflowsDir, "ci.yml"), workflowContent)
+
+	// Act
+	result := WorkflowToString(dir, "ci.yml")
+
+	// Assert
+	if result != workflowContent {
+		t.Errorf("Expected workflow content to match.\nExpected:\n%s\n\nGot:\n%s", workflowContent, result)
+	}
+}
+
+func TestWorkflowToString_FileNotFound(t *testing.T) {
+	// Act
+	result := WorkflowToString(t.TempDir(), "missing.yml")
+
+	// Assert
+	if result != "" {
+		t.Errorf("Expected empty string for missing file, got '%s'", result)
+	}
+}
+
+func TestRepoName(t *testing.T) {
+	tests := []struct {
+		name     string
+		dir      string
+		expected string
+	}{
+		{
+			name:     "relative path",
+			dir:      "repo",
+			expected: "repo",
+		},
+		{
+			name:     "relative path with dot prefix",
+			dir:      "./checkouts/repo",
+			expected: "checkouts/repo",
+		},
+		{
+			name:     "trailing slash",
+			dir:      "checkouts/repo/",
+			expected: "checkouts/repo",
+		},
+		{
+			name:     "absolute path",
+			dir:      "/work/repo",
+			expected: "/work/repo",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := RepoName(tt.dir)
+			if result != tt.expected {
+				t.Errorf("RepoName(%q) = %q, expected %q", tt.dir, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestGetRemoteOwner(t *testing.T) {
+	tests := []struct {
+		name     string
+		config   string
+		expected string
+	}{
+		{
+			name:     "https remote",
+			config:   "[core]\n\tbare = false\n[remote \"origin\"]\n\turl = https://github.com/my-org/repo.git\n\tfetch = +refs/heads/*:refs/remotes/origin/*\n",
+			expected: "my-org",
+		},
+		{
+			name:     "ssh remote",
+			config:   "[remote \"origin\"]\n\turl = git@github.com:my-org/repo.git\n",
+			expected: "my-org",
+		},
+		{
+			name:     "other remotes are ignored",
+			config:   "[remote \"upstream\"]\n\turl = https://github.com/upstream/repo.git\n[remote \"origin\"]\n\turl = https://github.com/my-fork/repo.git\n",
+			expected: "my-fork",
+		},
+		{
+			name:     "not on GitHub",
+			config:   "[remote \"origin\"]\n\turl = https://gitlab.com/my-org/repo.git\n",
+			expected: "",
+		},
+		{
+			name:     "no origin",
+			config:   "[core]\n\tbare = false\n",
+			expected: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			writeFile(t, filepath.Join(dir, ".git", "config"), tt.config)
+
+			if result := GetRemoteOwner(dir); result != tt.expected {
+				t.Errorf("GetRemoteOwner() = %q, expected %q", result, tt.expected)
+			}
+		})
+	}
+
+	if result := GetRemoteOwner(t.TempDir()); result != "" {
+		t.Errorf("Expected no owner for a directory that is not a checkout, got %q", result)
+	}
+}
+
+func TestWorkflowSource(t *testing.T) {
+	// Arrange
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, WorkflowsDir, "build.yml"), "name: Build")
+	source := NewWorkflowSource()
+
+	// Act
+	workflows := source.FindWorkflows(context.Background(), dir)
+
+	// Assert
+	if len(workflows) != 1 || source.WorkflowToString(context.Background(), dir, workflows[0]) != "name: Build" {
+		t.Errorf("Unexpected workflows: %v", workflows)
+	}
+
+	if source.RepoName(dir) != RepoName(dir) {
+		t.Errorf("Expected repo name %q, got %q", RepoName(dir), source.RepoName(dir))
+	}
+
+	if contributors := source.FindContributorsToWorkflow(context.Background(), dir, "build.yml"); contributors == nil || len(contributors) != 0 {
+		t.Errorf("Expected an empty contributors slice, got %v", contributors)
+	}
+
+	if advisories := source.GetWorkflowAdvisories(context.Background(), dir); advisories == nil || len(advisories) != 0 {
+		t.Errorf("Expected an empty advisories slice, got %v", advisories)
+	}
+}
+
+func TestActionToString(t *testing.T) {
+	// Arrange
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, ".github", "actions", "setup", "action.yaml"), "name: Setup")
+
+	// Act & Assert
+	if result := ActionToString(dir, ".github/actions/setup"); result != "name: Setup" {
+		t.Errorf("Expected action.yaml content, got '%s'", result)
+	}
+
+	if result := ActionToString(dir, ".github/actions/missing"); result != "" {
+		t.Errorf("Expected empty string for a missing action, got '%s'", result)
+	}
+}
+
+func TestWorkflowSource_ActionToStringRemote(t *testing.T) {
+	// Arrange
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "setup", "action.yml"), "name: Setup")
+	source := NewWorkflowSource()
+
+	// Act & Assert
+	if result := source.ActionToString(context.Background(), dir, "setup", ""); result != "name: Setup" {
+		t.Errorf("Expected local action content, got '%s'", result)
+	}
+
+	// Actions with a ref are in other repositories and can't be read offline
+	if result := source.ActionToString(context.Background(), dir, "setup", "v1"); result != "" {
+		t.Errorf("Expected empty string for a remote action, got '%s'", result)
+	}
+}
+
+func TestGetErrors(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "with-workflows", WorkflowsDir, "build.yml"), "name: Build")
+	writeFile(t, filepath.Join(dir, "file.txt"), "Not a checkout")
+	if err := os.MkdirAll(filepath.Join(dir, "empty"), 0755); err != nil {
+		t.Fatalf("Failed to create directory: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		dir      string
+		expected string
+	}{
+		{"with workflows", filepath.Join(dir, "with-workflows"), ""},
+		{"without workflows directory", filepath.Join(dir, "empty"), ""},
+		{"missing checkout", filepath.Join(dir, "does-not-exist"), models.ErrorCategoryNotFound},
+		{"not a directory", filepath.Join(dir, "file.txt"), models.ErrorCategoryUnknown},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			errors := GetErrors(tt.dir)
+
+			if tt.expected == "" {
+				if errors == nil || len(errors) != 0 {
+					t.Errorf("Expected no errors, got %+v", errors)
+				}
+				return
+			}
+
+			if len(errors) != 1 || errors[0].Category != tt.expected || errors[0].Message == "" {
+				t.Errorf("Expected a single %s error, got %+v", tt.expected, errors)
+			}
+		})
+	}
+}
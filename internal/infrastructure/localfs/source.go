@@ -0,0 +1,61 @@
+package localfs
+
+import (
+	"context"
+
+	"github.com/OctopusSolutionsEngineering/DuplicationCostCalculator/internal/domain/models"
+)
+
+// WorkflowSource loads workflows from local repository checkouts. Each repository is identified
+// by the path to its checkout. Contributors, advisories, tags, and actions in other repositories are not
+// available offline.
+type WorkflowSource struct{}
+
+func NewWorkflowSource() *WorkflowSource {
+	return &WorkflowSource{}
+}
+
+func (s *WorkflowSource) RepoName(repo string) string {
+	return RepoName(repo)
+}
+
+func (s *WorkflowSource) FindWorkflows(ctx context.Context, repo string) []string {
+	return FindWorkflows(repo)
+}
+
+func (s *WorkflowSource) WorkflowToString(ctx context.Context, repo string, workflow string) string {
+	return WorkflowToString(repo, workflow)
+}
+
+func (s *WorkflowSource) FindContributorsToWorkflow(ctx context.Context, repo string, workflow string) []string {
+	return []string{}
+}
+
+// ActionToString reads actions from the local checkout. Actions referenced with a ref live in other
+// repositories, so they can not be read.
+func (s *WorkflowSource) ActionToString(ctx context.Context, repo string, path string, ref string) string {
+	if ref != "" {
+		return ""
+	}
+
+	return ActionToString(repo, path)
+}
+
+// FindTags returns no tags, as the tags of other repositories are not available offline.
+func (s *WorkflowSource) FindTags(ctx context.Context, repo string) []models.Tag {
+	return []models.Tag{}
+}
+
+func (s *WorkflowSource) GetWorkflowAdvisories(ctx context.Context, repo string) []string {
+	return []string{}
+}
+
+// GetErrors reports a checkout that does not exist or can not be read.
+func (s *WorkflowSource) GetErrors(repo string) []models.RepoError {
+	return GetErrors(repo)
+}
+
+// GetRateLimit returns nil, because local checkouts are not rate limited.
+func (s *WorkflowSource) GetRateLimit() *models.RateLimitBudget {
+	return nil
+}
@@ -0,0 +1,151 @@
+package memory
+
+import (
+	"context"
+	"maps"
+	"slices"
+
+	"github.com/OctopusSolutionsEngineering/DuplicationCostCalculator/internal/domain/models"
+)
+
+// WorkflowSource serves workflows, contributors, and advisories from memory. It is used as a
+// fixture to exercise the report pipeline without a GitHub API.
+type WorkflowSource struct {
+	// Workflows maps the repository name to a map of workflow file names and their content.
+	Workflows map[string]map[string]string
+	// Contributors maps the repository name to a map of workflow file names and their contributors.
+	Contributors map[string]map[string][]string
+	// Advisories maps the repository name to the IDs of its security advisories.
+	Advisories map[string][]string
+	// Actions maps the location of an action, in the format "repo/path@ref", to the content of its action.yml file.
+	Actions map[string]string
+	// Tags maps the repository name to its tags.
+	Tags map[string][]models.Tag
+	// Repositories maps the name of an organization or user to the repositories it owns.
+	Repositories map[string][]models.Repository
+	// ListErrors maps the name of an organization or user to the error returned when its repositories are listed.
+	ListErrors map[string]error
+	// Errors maps the repository name to the errors reported when it is loaded.
+	Errors map[string][]models.RepoError
+	// RateLimit is the budget returned by GetRateLimit.
+	RateLimit *models.RateLimitBudget
+}
+
+func NewWorkflowSource() *WorkflowSource {
+	return &WorkflowSource{
+		Workflows:    map[string]map[string]string{},
+		Contributors: map[string]map[string][]string{},
+		Advisories:   map[string][]string{},
+		Actions:      map[string]string{},
+		Tags:         map[string][]models.Tag{},
+		Repositories: map[string][]models.Repository{},
+		ListErrors:   map[string]error{},
+		Errors:       map[string][]models.RepoError{},
+	}
+}
+
+// AddWorkflow adds a workflow file to a repository and returns the source to allow chaining.
+func (s *WorkflowSource) AddWorkflow(repo string, workflow string, content string, contributors ...string) *WorkflowSource {
+	if _, ok := s.Workflows[repo]; !ok {
+		s.Workflows[repo] = map[string]string{}
+	}
+
+	if _, ok := s.Contributors[repo]; !ok {
+		s.Contributors[repo] = map[string][]string{}
+	}
+
+	s.Workflows[repo][workflow] = content
+	s.Contributors[repo][workflow] = contributors
+
+	return s
+}
+
+// AddAction adds an action.yml file to a repository and returns the source to allow chaining.
+func (s *WorkflowSource) AddAction(repo string, path string, ref string, content string) *WorkflowSource {
+	s.Actions[ActionKey(repo, path, ref)] = content
+	return s
+}
+
+// AddRepository adds a repository to the repositories owned by an organization or user and returns the
+// source to allow chaining.
+func (s *WorkflowSource) AddRepository(owner string, repository models.Repository) *WorkflowSource {
+	s.Repositories[owner] = append(s.Repositories[owner], repository)
+	return s
+}
+
+// ActionKey builds the key used to look up an action in the Actions map.
+func ActionKey(repo string, path string, ref string) string {
+	return repo + "/" + path + "@" + ref
+}
+
+func (s *WorkflowSource) RepoName(repo string) string {
+	return repo
+}
+
+func (s *WorkflowSource) FindWorkflows(ctx context.Context, repo string) []string {
+	return slices.Sorted(maps.Keys(s.Workflows[repo]))
+}
+
+func (s *WorkflowSource) WorkflowToString(ctx context.Context, repo string, workflow string) string {
+	return s.Workflows[repo][workflow]
+}
+
+func (s *WorkflowSource) FindContributorsToWorkflow(ctx context.Context, repo string, workflow string) []string {
+	contributors := s.Contributors[repo][workflow]
+	if contributors == nil {
+		return []string{}
+	}
+	return contributors
+}
+
+func (s *WorkflowSource) ActionToString(ctx context.Context, repo string, path string, ref string) string {
+	return s.Actions[ActionKey(repo, path, ref)]
+}
+
+func (s *WorkflowSource) FindTags(ctx context.Context, repo string) []models.Tag {
+	tags := s.Tags[repo]
+	if tags == nil {
+		return []models.Tag{}
+	}
+	return tags
+}
+
+func (s *WorkflowSource) GetWorkflowAdvisories(ctx context.Context, repo string) []string {
+	advisories := s.Advisories[repo]
+	if advisories == nil {
+		return []string{}
+	}
+	return advisories
+}
+
+func (s *WorkflowSource) ListOrgRepositories(ctx context.Context, org string) ([]models.Repository, error) {
+	return s.listRepositories(org)
+}
+
+func (s *WorkflowSource) ListUserRepositories(ctx context.Context, user string) ([]models.Repository, error) {
+	return s.listRepositories(user)
+}
+
+func (s *WorkflowSource) listRepositories(owner string) ([]models.Repository, error) {
+	if err := s.ListErrors[owner]; err != nil {
+		return []models.Repository{}, err
+	}
+
+	repositories := s.Repositories[owner]
+	if repositories == nil {
+		return []models.Repository{}, nil
+	}
+	return repositories, nil
+}
+
+func (s *WorkflowSource) GetErrors(repo string) []models.RepoError {
+	errors := s.Errors[repo]
+	if errors == nil {
+		return []models.RepoError{}
+	}
+	return errors
+}
+
+func (s *WorkflowSource) GetRateLimit() *models.RateLimitBudget {
+	return s.RateLimit
+}
@@ -0,0 +1,83 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/OctopusSolutionsEngineering/DuplicationCostCalculator/internal/domain/models"
+)
+
+func TestWorkflowSource(t *testing.T) {
+	source := NewWorkflowSource().
+		AddWorkflow("owner/repo", "test.yml", "name: Test", "Bob").
+		AddWorkflow("owner/repo", "build.yml", "name: Build", "Alice")
+
+	workflows := source.FindWorkflows(context.Background(), "owner/repo")
+	if !slices.Equal(workflows, []string{"build.yml", "test.yml"}) {
+		t.Errorf("Expected sorted workflows, got %v", workflows)
+	}
+
+	if source.WorkflowToString(context.Background(), "owner/repo", "build.yml") != "name: Build" {
+		t.Errorf("Unexpected workflow content: %s", source.WorkflowToString(context.Background(), "owner/repo", "build.yml"))
+	}
+
+	contributors := source.FindContributorsToWorkflow(context.Background(), "owner/repo", "test.yml")
+	if !slices.Equal(contributors, []string{"Bob"}) {
+		t.Errorf("Expected contributors [Bob], got %v", contributors)
+	}
+}
+
+func TestWorkflowSourceUnknownRepo(t *testing.T) {
+	source := NewWorkflowSource()
+
+	if len(source.FindWorkflows(context.Background(), "owner/missing")) != 0 {
+		t.Error("Expected no workflows for an unknown repo")
+	}
+
+	if source.WorkflowToString(context.Background(), "owner/missing", "build.yml") != "" {
+		t.Error("Expected empty content for an unknown repo")
+	}
+
+	if contributors := source.FindContributorsToWorkflow(context.Background(), "owner/missing", "build.yml"); contributors == nil || len(contributors) != 0 {
+		t.Errorf("Expected an empty contributors slice, got %v", contributors)
+	}
+
+	if advisories := source.GetWorkflowAdvisories(context.Background(), "owner/missing"); advisories == nil || len(advisories) != 0 {
+		t.Errorf("Expected an empty advisories slice, got %v", advisories)
+	}
+}
+
+func TestWorkflowSourceRepositories(t *testing.T) {
+	source := NewWorkflowSource().
+		AddRepository("my-org", models.Repository{FullName: "my-org/repo1", Name: "repo1"}).
+		AddRepository("my-org", models.Repository{FullName: "my-org/repo2", Name: "repo2"})
+
+	source.ListErrors["missing"] = errors.New("not found")
+
+	if repositories, err := source.ListOrgRepositories(context.Background(), "my-org"); err != nil || len(repositories) != 2 || repositories[1].FullName != "my-org/repo2" {
+		t.Errorf("Unexpected repositories %+v %v", repositories, err)
+	}
+
+	if repositories, err := source.ListUserRepositories(context.Background(), "octocat"); err != nil || repositories == nil || len(repositories) != 0 {
+		t.Errorf("Expected an empty repositories slice, got %v %v", repositories, err)
+	}
+
+	if _, err := source.ListOrgRepositories(context.Background(), "missing"); err == nil {
+		t.Error("Expected the list error to be returned")
+	}
+}
+
+func TestWorkflowSourceErrors(t *testing.T) {
+	source := NewWorkflowSource()
+	source.Errors["owner/repo"] = []models.RepoError{{Category: models.ErrorCategoryForbidden, Operation: "list workflows"}}
+
+	if errors := source.GetErrors("owner/repo"); len(errors) != 1 || errors[0].Category != models.ErrorCategoryForbidden {
+		t.Errorf("Unexpected errors %+v", errors)
+	}
+
+	if errors := source.GetErrors("owner/missing"); errors == nil || len(errors) != 0 {
+		t.Errorf("Expected an empty errors slice, got %v", errors)
+	}
+}
@@ -0,0 +1,237 @@
+package reportstore
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/OctopusSolutionsEngineering/DuplicationCostCalculator/internal/domain/models"
+)
+
+var ErrReportNotFound = errors.New("report not found")
+
+// validId matches the ids created by NewReportId, so an id from a request can't refer to a file outside the store.
+var validId = regexp.MustCompile(`^[0-9]{8}T[0-9]{6}Z-[0-9a-f]{8}$`)
+
+// Store saves reports as JSON files in a directory, one file per report, so no database is required. The summary
+// of each report is saved in a separate file, so listing the reports does not have to read every full report.
+type Store struct {
+	// Dir is the directory the reports are written to.
+	Dir string
+	now func() time.Time
+}
+
+func NewStore(dir string) *Store {
+	return &Store{
+		Dir: dir,
+		now: time.Now,
+	}
+}
+
+// Save writes the report to the store, assigning it an id and creation time, and returns the saved report.
+func (s *Store) Save(report models.StoredReport) (models.StoredReport, error) {
+	report.CreatedAt = s.now().UTC()
+	report.Id = NewReportId(report.CreatedAt)
+
+	content, err := json.Marshal(report)
+	if err != nil {
+		return models.StoredReport{}, err
+	}
+
+	if err := writeFile(s.getPath(report.Id), content); err != nil {
+		return models.StoredReport{}, err
+	}
+
+	summary, err := json.Marshal(storedSummary{Owner: report.Owner, ReportSummary: Summarize(report)})
+	if err != nil {
+		return models.StoredReport{}, err
+	}
+
+	if err := writeFile(s.getSummaryPath(report.Id), summary); err != nil {
+		return models.StoredReport{}, err
+	}
+
+	return report, nil
+}
+
+// List returns a summary of each report owned by the owner, newest first. An empty owner lists every report.
+// Files that can't be read are skipped.
+func (s *Store) List(owner string) ([]models.ReportSummary, error) {
+	entries, err := os.ReadDir(s.Dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return []models.ReportSummary{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	summaries := []models.ReportSummary{}
+	for _, entry := range entries {
+		id, found := strings.CutSuffix(entry.Name(), ".json")
+		if entry.IsDir() || !found || !validId.MatchString(id) {
+			continue
+		}
+
+		summary, err := s.getSummary(owner, id)
+		if err != nil {
+			continue
+		}
+
+		summaries = append(summaries, summary)
+	}
+
+	slices.SortFunc(summaries, func(a, b models.ReportSummary) int {
+		return b.CreatedAt.Compare(a.CreatedAt)
+	})
+
+	return summaries, nil
+}
+
+// storedSummary is the content of a summary file. The owner is included so reports can be listed by owner.
+type storedSummary struct {
+	Owner string `json:"owner,omitempty"`
+	models.ReportSummary
+}
+
+// getSummary returns the summary of the report with the id. Reports saved without a summary file are summarized
+// from the full report.
+func (s *Store) getSummary(owner string, id string) (models.ReportSummary, error) {
+	content, err := os.ReadFile(s.getSummaryPath(id))
+	if errors.Is(err, os.ErrNotExist) {
+		report, err := s.Get(owner, id)
+		if err != nil {
+			return models.ReportSummary{}, err
+		}
+		return Summarize(report), nil
+	}
+	if err != nil {
+		return models.ReportSummary{}, err
+	}
+
+	var summary storedSummary
+	if err := json.Unmarshal(content, &summary); err != nil {
+		return models.ReportSummary{}, err
+	}
+
+	if owner != "" && summary.Owner != owner {
+		return models.ReportSummary{}, ErrReportNotFound
+	}
+
+	return summary.ReportSummary, nil
+}
+
+// Get returns the report with the id. ErrReportNotFound is returned if the report does not exist, or is owned by
+// someone else. An empty owner can read every report.
+func (s *Store) Get(owner string, id string) (models.StoredReport, error) {
+	if !validId.MatchString(id) {
+		return models.StoredReport{}, ErrReportNotFound
+	}
+
+	content, err := os.ReadFile(s.getPath(id))
+	if errors.Is(err, os.ErrNotExist) {
+		return models.StoredReport{}, ErrReportNotFound
+	}
+	if err != nil {
+		return models.StoredReport{}, err
+	}
+
+	var report models.StoredReport
+	if err := json.Unmarshal(content, &report); err != nil {
+		return models.StoredReport{}, err
+	}
+
+	if owner != "" && report.Owner != owner {
+		return models.StoredReport{}, ErrReportNotFound
+	}
+
+	return report, nil
+}
+
+// Delete removes the report with the id. ErrReportNotFound is returned if the report does not exist, or is owned
+// by someone else.
+func (s *Store) Delete(owner string, id string) error {
+	if _, err := s.Get(owner, id); err != nil {
+		return err
+	}
+
+	err := os.Remove(s.getPath(id))
+	if errors.Is(err, os.ErrNotExist) {
+		return ErrReportNotFound
+	}
+	if err != nil {
+		return err
+	}
+
+	if err := os.Remove(s.getSummaryPath(id)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+
+	return nil
+}
+
+func (s *Store) getPath(id string) string {
+	return filepath.Join(s.Dir, id+".json")
+}
+
+// getSummaryPath returns the path of the summary file of a report. Summary files don't match validId, so they
+// are not mistaken for reports.
+func (s *Store) getSummaryPath(id string) string {
+	return filepath.Join(s.Dir, id+".summary.json")
+}
+
+// Summarize returns the summary of a report that is included when listing reports.
+func Summarize(report models.StoredReport) models.ReportSummary {
+	return models.ReportSummary{
+		Id:                                  report.Id,
+		CreatedAt:                           report.CreatedAt,
+		Repositories:                        report.Repositories,
+		Parameters:                          report.Parameters,
+		NumberOfRepos:                       report.Report.NumberOfRepos,
+		NumberOfReposWithDuplicationOrDrift: report.Report.NumberOfReposWithDuplicationOrDrift,
+		Incomplete:                          report.Report.Metadata.Incomplete,
+	}
+}
+
+// NewReportId returns an id that sorts by the time the report was created, with a random suffix so reports
+// created at the same time don't collide.
+func NewReportId(createdAt time.Time) string {
+	suffix := make([]byte, 4)
+	if _, err := rand.Read(suffix); err != nil {
+		panic(err)
+	}
+	return createdAt.UTC().Format("20060102T150405Z") + "-" + hex.EncodeToString(suffix)
+}
+
+// writeFile writes the content to a temporary file and renames it, so a report is never read while it is partially
+// written. Reports can describe private repositories, so only the owner can read them.
+func writeFile(path string, content []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+
+	file, err := os.CreateTemp(filepath.Dir(path), ".tmp-*")
+	if err != nil {
+		return err
+	}
+
+	_, writeErr := file.Write(content)
+	closeErr := file.Close()
+	if err := errors.Join(writeErr, closeErr); err != nil {
+		os.Remove(file.Name())
+		return err
+	}
+
+	if err := os.Rename(file.Name(), path); err != nil {
+		os.Remove(file.Name())
+		return err
+	}
+
+	return nil
+}
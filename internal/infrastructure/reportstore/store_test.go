@@ -0,0 +1,209 @@
+package reportstore
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/OctopusSolutionsEngineering/DuplicationCostCalculator/internal/domain/models"
+)
+
+// newTestStore returns a store in a temporary directory, where each report is created a minute after the last.
+func newTestStore(t *testing.T) *Store {
+	t.Helper()
+
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	store := NewStore(filepath.Join(t.TempDir(), "reports"))
+	store.now = func() time.Time {
+		now = now.Add(time.Minute)
+		return now
+	}
+
+	return store
+}
+
+func newStoredReport(owner string, repos ...string) models.StoredReport {
+	return models.StoredReport{
+		Owner:        owner,
+		Repositories: repos,
+		Parameters:   models.ReportParameters{Targets: repos},
+		Report:       models.Report{NumberOfRepos: len(repos), NumberOfReposWithDuplicationOrDrift: 1},
+	}
+}
+
+func TestStore_SaveAndGet(t *testing.T) {
+	// Arrange
+	store := newTestStore(t)
+
+	// Act
+	saved, err := store.Save(newStoredReport("user:1", "owner/repo1", "owner/repo2"))
+	if err != nil {
+		t.Fatalf("Failed to save the report: %v", err)
+	}
+
+	report, err := store.Get("user:1", saved.Id)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Failed to get the report: %v", err)
+	}
+
+	if !validId.MatchString(saved.Id) || saved.CreatedAt.IsZero() {
+		t.Errorf("Expected an id and creation time to be assigned, got %q %v", saved.Id, saved.CreatedAt)
+	}
+
+	if report.Id != saved.Id || !report.CreatedAt.Equal(saved.CreatedAt) || report.Report.NumberOfRepos != 2 || len(report.Repositories) != 2 {
+		t.Errorf("Unexpected report %+v", report)
+	}
+
+	info, err := os.Stat(filepath.Join(store.Dir, saved.Id+".json"))
+	if err != nil || info.Mode().Perm() != 0600 {
+		t.Errorf("Expected the report to only be readable by the owner, got %v %v", info, err)
+	}
+}
+
+func TestStore_Get(t *testing.T) {
+	// Arrange
+	store := newTestStore(t)
+	saved, _ := store.Save(newStoredReport("user:1", "owner/repo1"))
+
+	tests := []struct {
+		name        string
+		owner       string
+		id          string
+		expectedErr error
+	}{
+		{"owner", "user:1", saved.Id, nil},
+		{"every owner", "", saved.Id, nil},
+		{"another owner", "user:2", saved.Id, ErrReportNotFound},
+		{"missing", "user:1", "20240501T120000Z-00000000", ErrReportNotFound},
+		{"path traversal", "", "../reports/" + saved.Id, ErrReportNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			_, err := store.Get(tt.owner, tt.id)
+
+			// Assert
+			if !errors.Is(err, tt.expectedErr) {
+				t.Errorf("Get(%q, %q) error = %v, expected %v", tt.owner, tt.id, err, tt.expectedErr)
+			}
+		})
+	}
+}
+
+func TestStore_List(t *testing.T) {
+	// Arrange
+	store := newTestStore(t)
+	first, _ := store.Save(newStoredReport("user:1", "owner/repo1"))
+	store.Save(newStoredReport("user:2", "owner/repo2"))
+	second, _ := store.Save(newStoredReport("user:1", "owner/repo1", "owner/repo3"))
+
+	// Files that aren't reports are ignored
+	os.WriteFile(filepath.Join(store.Dir, "notes.txt"), []byte("notes"), 0600)
+	os.WriteFile(filepath.Join(store.Dir, "20240501T120000Z-00000000.json"), []byte("{invalid"), 0600)
+
+	// Act
+	summaries, err := store.List("user:1")
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Failed to list the reports: %v", err)
+	}
+
+	if len(summaries) != 2 || summaries[0].Id != second.Id || summaries[1].Id != first.Id {
+		t.Fatalf("Expected the owner's reports, newest first, got %+v", summaries)
+	}
+
+	if summaries[0].NumberOfRepos != 2 || len(summaries[0].Repositories) != 2 || summaries[0].NumberOfReposWithDuplicationOrDrift != 1 {
+		t.Errorf("Unexpected summary %+v", summaries[0])
+	}
+
+	if all, _ := store.List(""); len(all) != 3 {
+		t.Errorf("Expected an empty owner to list every report, got %+v", all)
+	}
+}
+
+func TestStore_ListReadsSummaries(t *testing.T) {
+	// Arrange
+	store := newTestStore(t)
+	saved, _ := store.Save(newStoredReport("user:1", "owner/repo1"))
+	legacy, _ := store.Save(newStoredReport("user:1", "owner/repo2"))
+
+	// The full report is not read when the summary exists
+	os.WriteFile(filepath.Join(store.Dir, saved.Id+".json"), []byte("{invalid"), 0600)
+
+	// Reports saved before summaries were stored are summarized from the full report
+	os.Remove(filepath.Join(store.Dir, legacy.Id+".summary.json"))
+
+	// Act
+	summaries, err := store.List("user:1")
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Failed to list the reports: %v", err)
+	}
+
+	if len(summaries) != 2 || summaries[0].Id != legacy.Id || summaries[1].Id != saved.Id {
+		t.Fatalf("Expected both reports, newest first, got %+v", summaries)
+	}
+
+	if summaries[1].NumberOfRepos != 1 || summaries[1].Repositories[0] != "owner/repo1" {
+		t.Errorf("Unexpected summary %+v", summaries[1])
+	}
+
+	if other, _ := store.List("user:2"); len(other) != 0 {
+		t.Errorf("Expected the summaries of another owner to be hidden, got %+v", other)
+	}
+}
+
+func TestStore_ListMissingDir(t *testing.T) {
+	store := NewStore(filepath.Join(t.TempDir(), "missing"))
+
+	summaries, err := store.List("")
+
+	if err != nil || len(summaries) != 0 {
+		t.Errorf("Expected no reports, got %+v %v", summaries, err)
+	}
+}
+
+func TestStore_Delete(t *testing.T) {
+	// Arrange
+	store := newTestStore(t)
+	saved, _ := store.Save(newStoredReport("user:1", "owner/repo1"))
+
+	// Act & Assert
+	if err := store.Delete("user:2", saved.Id); !errors.Is(err, ErrReportNotFound) {
+		t.Errorf("Expected another owner not to delete the report, got %v", err)
+	}
+
+	if err := store.Delete("user:1", saved.Id); err != nil {
+		t.Errorf("Failed to delete the report: %v", err)
+	}
+
+	if _, err := store.Get("user:1", saved.Id); !errors.Is(err, ErrReportNotFound) {
+		t.Errorf("Expected the report to be deleted, got %v", err)
+	}
+
+	if _, err := os.Stat(filepath.Join(store.Dir, saved.Id+".summary.json")); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("Expected the summary to be deleted, got %v", err)
+	}
+
+	if err := store.Delete("user:1", saved.Id); !errors.Is(err, ErrReportNotFound) {
+		t.Errorf("Expected deleting a missing report to fail, got %v", err)
+	}
+}
+
+func TestNewReportId(t *testing.T) {
+	createdAt := time.Date(2024, 5, 1, 12, 30, 45, 0, time.UTC)
+
+	id1 := NewReportId(createdAt)
+	id2 := NewReportId(createdAt)
+
+	if !validId.MatchString(id1) || id1[:16] != "20240501T123045Z" || id1 == id2 {
+		t.Errorf("Expected unique ids that start with the creation time, got %s and %s", id1, id2)
+	}
+}